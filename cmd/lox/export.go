@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/VirajAgarwal1/lox/errorhandler"
+	"github.com/VirajAgarwal1/lox/grammar_file"
+	"github.com/VirajAgarwal1/lox/grammar_file/formats"
+	"github.com/VirajAgarwal1/lox/lexer"
+	"github.com/VirajAgarwal1/lox/source"
+)
+
+// exportGrammar runs `lox grammar export -to w3c-ebnf|abnf|antlr4 [-o FILE] FILE`, which writes a grammar file in the notation of other grammar tools (see `grammar_file/formats`)
+func exportGrammar(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("lox grammar export", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	to := flags.String("to", "", "notation to write: w3c-ebnf, abnf or antlr4")
+	output := flags.String("o", "", "write to this file instead of stdout")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 || (*to != "w3c-ebnf" && *to != "abnf" && *to != "antlr4") {
+		fmt.Fprintln(stderr, "usage: lox grammar export -to w3c-ebnf|abnf|antlr4 [-o FILE] FILE")
+		return 2
+	}
+
+	filename := flags.Arg(0)
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		fmt.Fprintf(stderr, "lox: %v\n", err)
+		return 1
+	}
+	fset := source.NewFileSet()
+	file := fset.AddFile(filename, src)
+
+	scanner := lexer.LexicalAnalyzer{}
+	scanner.InitializeWithFile(bufio.NewReader(bytes.NewReader(src)), file)
+	grammar, err := grammar_file.ParseGrammar(&scanner)
+	if err != nil && err != io.EOF { // io.EOF is how the grammar parser reports that it read the whole file
+		errorhandler.NewRenderer(fset, stderr).RenderError(stderr, err)
+		return 1
+	}
+
+	var exported []byte
+	switch *to {
+	case "w3c-ebnf":
+		exported = formats.To_w3c_ebnf(grammar)
+	case "abnf":
+		exported = formats.To_abnf(grammar)
+	case "antlr4":
+		name := filepath.Base(filename)
+		if *output != "" {
+			name = filepath.Base(*output) // ANTLR4 wants the grammar named like its file
+		}
+		exported = formats.To_antlr4(grammar, formats.Antlr4_grammar_name(name))
+	}
+
+	if *output == "" {
+		stdout.Write(exported)
+		return 0
+	}
+	if err := os.WriteFile(*output, exported, 0o644); err != nil {
+		fmt.Fprintf(stderr, "lox: %v\n", err)
+		return 1
+	}
+	return 0
+}
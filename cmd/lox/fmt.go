@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/VirajAgarwal1/lox/errorhandler"
+	"github.com/VirajAgarwal1/lox/grammar_file"
+	"github.com/VirajAgarwal1/lox/lexer"
+	"github.com/VirajAgarwal1/lox/source"
+)
+
+// formatGrammars runs `lox grammar fmt [-check] [-w] FILE...`, which prints the files in the canonical form of `grammar_file.Format`. With `-check` it only lists the files which are not formatted, and exits with 1 if there are any.
+func formatGrammars(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("lox grammar fmt", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	check := flags.Bool("check", false, "list the files which are not formatted instead of printing them, exit with 1 if there are any")
+	write := flags.Bool("w", false, "write the result back to the files instead of printing it")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() < 1 || (*check && *write) {
+		fmt.Fprintln(stderr, "usage: lox grammar fmt [-check | -w] FILE...")
+		return 2
+	}
+
+	fset := source.NewFileSet()
+	status := 0
+	for _, filename := range flags.Args() {
+		src, err := os.ReadFile(filename)
+		if err != nil {
+			fmt.Fprintf(stderr, "lox: %v\n", err)
+			status = 1
+			continue
+		}
+		file := fset.AddFile(filename, src)
+
+		scanner := lexer.LexicalAnalyzer{}
+		scanner.InitializeWithFile(bufio.NewReader(bytes.NewReader(src)), file)
+		grammar, err := grammar_file.ParseGrammar(&scanner)
+		if err != nil && err != io.EOF { // io.EOF is how the grammar parser reports that it read the whole file
+			errorhandler.NewRenderer(fset, stderr).RenderError(stderr, err)
+			status = 1
+			continue
+		}
+
+		formatted := grammar_file.Format(grammar)
+		switch {
+		case *check:
+			if !bytes.Equal(src, formatted) {
+				fmt.Fprintln(stdout, filename)
+				status = 1
+			}
+		case *write:
+			if bytes.Equal(src, formatted) {
+				continue
+			}
+			if err := os.WriteFile(filename, formatted, 0o644); err != nil {
+				fmt.Fprintf(stderr, "lox: %v\n", err)
+				status = 1
+			}
+		default:
+			stdout.Write(formatted)
+		}
+	}
+	return status
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/VirajAgarwal1/lox/errorhandler"
+	"github.com/VirajAgarwal1/lox/grammar_file"
+	"github.com/VirajAgarwal1/lox/grammar_file/formats"
+	"github.com/VirajAgarwal1/lox/source"
+)
+
+// Readers of the notations `lox grammar import` knows, and the file extensions which pick them when `-from` is not given
+var importers = map[string]func(file *source.File) (*grammar_file.Grammar, *errorhandler.Diagnostics){
+	"antlr4":   formats.Read_antlr4,
+	"yacc":     formats.Read_yacc,
+	"w3c-ebnf": formats.Read_w3c_ebnf,
+	"abnf":     formats.Read_abnf,
+}
+var import_extensions = map[string]string{".g4": "antlr4", ".y": "yacc", ".yy": "yacc", ".ebnf": "w3c-ebnf", ".abnf": "abnf"}
+
+// importGrammar runs `lox grammar import [-from antlr4|yacc|w3c-ebnf|abnf] [-o FILE] FILE`, which converts a grammar written for another tool into a grammar file. What cannot be converted is reported, see `grammar_file/formats`.
+func importGrammar(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("lox grammar import", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	from := flags.String("from", "", "notation to read: antlr4, yacc, w3c-ebnf or abnf (default: from the file extension)")
+	output := flags.String("o", "", "write to this file instead of stdout")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 1 && *from == "" {
+		*from = import_extensions[filepath.Ext(flags.Arg(0))]
+	}
+	read, known := importers[*from]
+	if flags.NArg() != 1 || !known {
+		fmt.Fprintln(stderr, "usage: lox grammar import [-from antlr4|yacc|w3c-ebnf|abnf] [-o FILE] FILE")
+		return 2
+	}
+
+	filename := flags.Arg(0)
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		fmt.Fprintf(stderr, "lox: %v\n", err)
+		return 1
+	}
+	fset := source.NewFileSet()
+	grammar, ds := read(fset.AddFile(filename, src))
+	ds.Render(stderr, errorhandler.NewRenderer(fset, stderr))
+	if ds.HasErrors() {
+		return 1
+	}
+
+	imported := grammar_file.Format(grammar)
+	if *output == "" {
+		stdout.Write(imported)
+		return 0
+	}
+	if err := os.WriteFile(*output, imported, 0o644); err != nil {
+		fmt.Fprintf(stderr, "lox: %v\n", err)
+		return 1
+	}
+	return 0
+}
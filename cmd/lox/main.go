@@ -0,0 +1,220 @@
+/*
+lox is the command line front end of the project. It runs one stage of the pipeline over a file and reports the diagnostics.
+
+	lox lex     [-format text|json|sarif] FILE      prints the tokens of a Lox file
+	lox parse   [-format text|json|sarif] FILE      parses a Lox file with the generated streamable parser
+	lox grammar [-format text|json|sarif] FILE      checks a grammar file and its LL(1) conflicts, and tells how many rules its BNF has
+	lox grammar fmt [-check | -w] FILE...           prints grammar files in their canonical form
+	lox grammar railroad [-o FILE | -svg DIR] FILE  draws the railroad diagrams of a grammar file
+	lox grammar export -to NOTATION [-o FILE] FILE  writes a grammar file in W3C EBNF, ABNF or ANTLR4
+	lox grammar import [-from NOTATION] [-o FILE] FILE
+	                                                converts an ANTLR4, yacc, W3C EBNF or ABNF grammar to a grammar file
+
+The `-format` flag picks how diagnostics are written: `text` renders them for humans on stderr, `json` and `sarif` write them on stdout for tools (see errorhandler/README.md). The exit status is 1 if there was any error, 2 for bad usage.
+*/
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/VirajAgarwal1/lox/errorhandler"
+	"github.com/VirajAgarwal1/lox/grammar_file"
+	"github.com/VirajAgarwal1/lox/lexer"
+	"github.com/VirajAgarwal1/lox/source"
+	"github.com/VirajAgarwal1/lox/streamable_parser"
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/generator"
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/grammar_validator"
+)
+
+const (
+	formatText  = "text"
+	formatJSON  = "json"
+	formatSARIF = "sarif"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(file *source.File, src []byte, ds *errorhandler.Diagnostics, stdout io.Writer, format string)
+}
+
+var commands = []command{
+	{"lex", "prints the tokens of a Lox file", lexFile},
+	{"parse", "parses a Lox file with the generated streamable parser", parseFile},
+	{"grammar", "checks a grammar file", checkGrammar},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: lox <command> [-format text|json|sarif] FILE")
+	fmt.Fprintln(w, "       lox grammar fmt [-check | -w] FILE...")
+	fmt.Fprintln(w, "       lox grammar railroad [-o FILE | -svg DIR] FILE")
+	fmt.Fprintln(w, "       lox grammar export -to w3c-ebnf|abnf|antlr4 [-o FILE] FILE")
+	fmt.Fprintln(w, "       lox grammar import [-from antlr4|yacc|w3c-ebnf|abnf] [-o FILE] FILE")
+	fmt.Fprintln(w, "commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-8s %s\n", cmd.name, cmd.usage)
+	}
+}
+
+func run(args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) < 1 {
+		usage(stderr)
+		return 2
+	}
+	if args[0] == "grammar" && len(args) > 1 && args[1] == "fmt" {
+		return formatGrammars(args[2:], stdout, stderr)
+	}
+	if args[0] == "grammar" && len(args) > 1 && args[1] == "railroad" {
+		return drawGrammar(args[2:], stdout, stderr)
+	}
+	if args[0] == "grammar" && len(args) > 1 && args[1] == "export" {
+		return exportGrammar(args[2:], stdout, stderr)
+	}
+	if args[0] == "grammar" && len(args) > 1 && args[1] == "import" {
+		return importGrammar(args[2:], stdout, stderr)
+	}
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == args[0] {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(stderr, "lox: unknown command %q\n", args[0])
+		usage(stderr)
+		return 2
+	}
+
+	flags := flag.NewFlagSet("lox "+cmd.name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	format := flags.String("format", formatText, "output format of the diagnostics: text, json or sarif")
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if *format != formatText && *format != formatJSON && *format != formatSARIF {
+		fmt.Fprintf(stderr, "lox: unknown format %q\n", *format)
+		return 2
+	}
+	if flags.NArg() != 1 {
+		usage(stderr)
+		return 2
+	}
+
+	filename := flags.Arg(0)
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		fmt.Fprintf(stderr, "lox: %v\n", err)
+		return 1
+	}
+	fset := source.NewFileSet()
+	file := fset.AddFile(filename, src)
+	ds := errorhandler.NewDiagnostics(0)
+
+	cmd.run(file, src, ds, stdout, *format)
+
+	switch *format {
+	case formatJSON:
+		err = ds.WriteJSON(stdout, fset)
+	case formatSARIF:
+		err = ds.WriteSARIF(stdout, fset)
+	default:
+		err = ds.Render(stderr, errorhandler.NewRenderer(fset, stderr))
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "lox: %v\n", err)
+		return 1
+	}
+	if ds.HasErrors() {
+		return 1
+	}
+	return 0
+}
+
+func lexFile(file *source.File, src []byte, ds *errorhandler.Diagnostics, stdout io.Writer, format string) {
+	scanner := lexer.LexicalAnalyzer{}
+	scanner.InitializeWithFile(bufio.NewReader(bytes.NewReader(src)), file)
+	scanner.SetDiagnostics(ds)
+	for {
+		tok, err := scanner.ReadToken()
+		if err == io.EOF {
+			return
+		}
+		if err == nil && format == formatText {
+			fmt.Fprintf(stdout, "%v\t%s\n", file.Position(tok.Pos), tok.ToString())
+		}
+	}
+}
+
+func parseFile(file *source.File, src []byte, ds *errorhandler.Diagnostics, stdout io.Writer, format string) {
+	scanner := lexer.BufferedLexicalAnalyzer{}
+	scanner.InitializeWithFile(bufio.NewReader(bytes.NewReader(src)), file)
+	scanner.SetDiagnostics(ds)
+	sp := streamable_parser.StreamableParser{}
+	sp.Initialize(&scanner)
+	sp.SetDiagnostics(ds)
+	for {
+		ev := sp.Parse()
+		if ev == nil {
+			continue
+		}
+		if ev.Type == streamable_parser.EmitElemType_Error && ev.Err == io.EOF {
+			return
+		}
+	}
+}
+
+func checkGrammar(file *source.File, src []byte, ds *errorhandler.Diagnostics, stdout io.Writer, format string) {
+	scanner := lexer.LexicalAnalyzer{}
+	scanner.InitializeWithFile(bufio.NewReader(bytes.NewReader(src)), file)
+	scanner.SetDiagnostics(ds)
+	grammar, err := grammar_file.ParseGrammar(&scanner)
+	if err != nil && err != io.EOF { // io.EOF is how the grammar parser reports that it read the whole file
+		ds.AddError(err)
+		return
+	}
+	diagnostics := grammar_validator.Validate(grammar)
+	for _, diag := range diagnostics.Sorted() {
+		ds.Add(diag)
+	}
+	if format == formatText {
+		fmt.Fprintf(stdout, "%s: %d rules\n", file.Name(), len(grammar.Rules))
+	}
+	if diagnostics.Err() != nil {
+		return
+	}
+	// What the streamable parser is generated from, with the conflicts of its parse table
+	parser_generator := generator.Generator{}
+	result, err := parser_generator.Generate(grammar, generator.Options{})
+	var conflicts *generator.Conflicts_error
+	if errors.As(err, &conflicts) {
+		for _, diag := range conflicts.Diagnostics() {
+			ds.Add(diag)
+		}
+		return
+	}
+	if err != nil {
+		ds.AddError(err)
+		return
+	}
+	if format != formatText {
+		return
+	}
+	if len(result.Grammar.Left_recursions) > 0 {
+		fmt.Fprintf(stdout, "%s: %d left-recursive rules rewritten\n", file.Name(), len(result.Grammar.Left_recursions))
+	}
+	if len(result.Conflicts) > 0 {
+		fmt.Fprintf(stdout, "%s: %d conflicts resolved with %%resolve\n", file.Name(), len(result.Conflicts))
+	}
+	report := result.Report
+	fmt.Fprintf(stdout, "%s: %d non-terminals in BNF, %d of the %d artificial ones saved (%d shared, %d inlined)\n", file.Name(), len(result.Bnf), report.Saved(), report.Artificial, report.Shared, report.Inlined)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/VirajAgarwal1/lox/errorhandler"
+	"github.com/VirajAgarwal1/lox/grammar_file"
+	"github.com/VirajAgarwal1/lox/grammar_file/railroad"
+	"github.com/VirajAgarwal1/lox/lexer"
+	"github.com/VirajAgarwal1/lox/source"
+)
+
+// drawGrammar runs `lox grammar railroad [-o FILE | -svg DIR] FILE`, which draws the railroad diagrams of a grammar file: an HTML page on stdout or in `-o`, or one SVG file per non-terminal in `-svg`.
+func drawGrammar(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("lox grammar railroad", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	output := flags.String("o", "", "write the HTML page to this file instead of stdout")
+	svg_dir := flags.String("svg", "", "write one SVG file per non-terminal in this directory instead of an HTML page")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 || (*output != "" && *svg_dir != "") {
+		fmt.Fprintln(stderr, "usage: lox grammar railroad [-o FILE | -svg DIR] FILE")
+		return 2
+	}
+
+	filename := flags.Arg(0)
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		fmt.Fprintf(stderr, "lox: %v\n", err)
+		return 1
+	}
+	fset := source.NewFileSet()
+	file := fset.AddFile(filename, src)
+
+	scanner := lexer.LexicalAnalyzer{}
+	scanner.InitializeWithFile(bufio.NewReader(bytes.NewReader(src)), file)
+	grammar, err := grammar_file.ParseGrammar(&scanner)
+	if err != nil && err != io.EOF { // io.EOF is how the grammar parser reports that it read the whole file
+		errorhandler.NewRenderer(fset, stderr).RenderError(stderr, err)
+		return 1
+	}
+
+	switch {
+	case *svg_dir != "":
+		err = railroad.Write_svgs(*svg_dir, grammar)
+	case *output != "":
+		var page bytes.Buffer
+		if err = railroad.Write_html(&page, grammar, filepath.Base(filename)); err == nil {
+			err = os.WriteFile(*output, page.Bytes(), 0o644)
+		}
+	default:
+		err = railroad.Write_html(stdout, grammar, filepath.Base(filename))
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "lox: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -57,6 +57,16 @@ func TokenTypeName(t dfa.TokenType) string {
 		return "SLASH"
 	case dfa.STAR:
 		return "STAR"
+	case dfa.QUESTION:
+		return "QUESTION"
+	case dfa.LEFT_BRACKET:
+		return "LEFT_BRACKET"
+	case dfa.RIGHT_BRACKET:
+		return "RIGHT_BRACKET"
+	case dfa.PERCENT:
+		return "PERCENT"
+	case dfa.COLON:
+		return "COLON"
 	case dfa.BANG:
 		return "BANG"
 	case dfa.BANG_EQUAL:
@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/VirajAgarwal1/lox/errorhandler"
+	"github.com/VirajAgarwal1/lox/grammar_file"
 	"github.com/VirajAgarwal1/lox/lexer"
 	"github.com/VirajAgarwal1/lox/parser/grammar"
 )
@@ -21,13 +22,13 @@ func WriteGrammarParserDemo() {
 	scanner := lexer.LexicalAnalyzer{}
 	scanner.Initialize(buf_file_reader)
 
-	processed_grammar, err := grammar.ProcessGrammarDefinition(&scanner)
+	processed_grammar, err := grammar_file.ParseGrammar(&scanner)
 	if err != nil && err != io.EOF {
 		errorhandler.ReportErr(err)
 		return
 	}
 
-	err = grammar.GenerateGrammarParserFile(processed_grammar, "parser/generated_parser.go")
+	err = grammar.GenerateGrammarParserFileForGrammar(processed_grammar, "parser/generated_parser.go")
 	if err != nil {
 		errorhandler.ReportErr(err)
 		return
@@ -2,11 +2,13 @@ package parser_demos
 
 import (
 	"bufio"
-	"fmt"
+	"os"
 	"strings"
 
+	"github.com/VirajAgarwal1/lox/errorhandler"
 	"github.com/VirajAgarwal1/lox/lexer"
 	"github.com/VirajAgarwal1/lox/parser"
+	"github.com/VirajAgarwal1/lox/source"
 )
 
 func ParserDemo() {
@@ -14,15 +16,14 @@ func ParserDemo() {
 
 	// Sample input:  42, "hello", true
 	sample_input := bufio.NewReader(strings.NewReader("42,\"hello\",true,identifier,false,2.89"))
+	files := source.NewFileSet()
 	buf_scanner := lexer.BufferedLexer{}
-	buf_scanner.Initialize(sample_input, uint32(scanner_buf_cap))
+	buf_scanner.InitializeWithFile(sample_input, uint32(scanner_buf_cap), files.AddFile("sample.lox", nil))
 
 	// Run the parser
-	_, _, err := parser.Parse_expression(&buf_scanner)
+	_, err := parser.Parse(&buf_scanner, parser.Parse_expression)
 	if err != nil {
-		fmt.Println("Parser error:", err)
-		fmt.Println()
-		fmt.Println()
+		errorhandler.NewRenderer(files, os.Stderr).RenderError(os.Stderr, err)
 	}
 }
 
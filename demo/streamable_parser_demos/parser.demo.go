@@ -7,11 +7,9 @@ import (
 	"os"
 	"strings"
 
+	"github.com/VirajAgarwal1/lox/grammar_file"
 	"github.com/VirajAgarwal1/lox/lexer"
 	"github.com/VirajAgarwal1/lox/streamable_parser"
-	ebnf_to_bnf "github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/ebnf_to_bnf"
-	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/first_follow"
-	grammar_file_parser "github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/grammar_file_parser"
 	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/parser_writer"
 )
 
@@ -120,20 +118,16 @@ func Sample_generate_streamable_parser() {
 
 	scanner := lexer.LexicalAnalyzer{}
 	scanner.Initialize(bufio.NewReader(grammarFile))
-	ebnfGrammar, err := grammar_file_parser.ProcessGrammarDefinition(&scanner)
+	grammar, err := grammar_file.ParseGrammar(&scanner)
 	if err != nil && err != io.EOF {
 		panic(err)
 	}
 
-	// Convert EBNF to BNF and compute parsing tables
-	bnfGrammar := ebnf_to_bnf.EbnfToBnfConverter(ebnfGrammar)
-	firsts := first_follow.ComputeFirstSets(bnfGrammar)
-	follows := first_follow.ComputeFollowSets(bnfGrammar)
-
-	// Generate the parser code
+	// Convert EBNF to BNF, compute parsing tables and generate the parser code.
+	// The start symbol and the skipped tokens come from the `%start` and `%skip` directives of the grammar file
 	currentDir, _ := os.Getwd()
 	outputPath := currentDir + "/streamable_parser/generated_parser.go"
-	err = parser_writer.WriteParser(outputPath, bnfGrammar, "expression", firsts, follows)
+	err = parser_writer.WriteParserForGrammar(outputPath, grammar)
 	if err != nil {
 		panic(err)
 	}
@@ -6,10 +6,10 @@ import (
 	"io"
 	"os"
 
+	"github.com/VirajAgarwal1/lox/grammar_file"
 	"github.com/VirajAgarwal1/lox/lexer"
 	ebnf_to_bnf "github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/ebnf_to_bnf"
 	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/first_follow"
-	grammar_file_parser "github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/grammar_file_parser"
 )
 
 func Sample_compute_firsts() {
@@ -21,7 +21,7 @@ func Sample_compute_firsts() {
 
 	scanner := lexer.LexicalAnalyzer{}
 	scanner.Initialize(source)
-	ebnf_grammar, err := grammar_file_parser.ProcessGrammarDefinition(&scanner)
+	ebnf_grammar, err := grammar_file.ProcessGrammarDefinition(&scanner)
 	if err != nil && err != io.EOF {
 		panic(err)
 	}
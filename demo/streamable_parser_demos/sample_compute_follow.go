@@ -6,10 +6,10 @@ import (
 	"io"
 	"os"
 
+	"github.com/VirajAgarwal1/lox/grammar_file"
 	"github.com/VirajAgarwal1/lox/lexer"
 	ebnf_to_bnf "github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/ebnf_to_bnf"
 	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/first_follow"
-	grammar_file_parser "github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/grammar_file_parser"
 )
 
 func Sample_compute_follow() {
@@ -21,7 +21,7 @@ func Sample_compute_follow() {
 
 	scanner := lexer.LexicalAnalyzer{}
 	scanner.Initialize(source)
-	ebnf_grammar, err := grammar_file_parser.ProcessGrammarDefinition(&scanner)
+	ebnf_grammar, err := grammar_file.ProcessGrammarDefinition(&scanner)
 	if err != nil && err != io.EOF {
 		panic(err)
 	}
@@ -29,9 +29,8 @@ func Sample_compute_follow() {
 	bnf_grammar := ebnf_to_bnf.EbnfToBnfConverter(ebnf_grammar)
 	fmt.Println(bnf_grammar)
 
-	firsts := first_follow.ComputeFirstSets(bnf_grammar)
-	fmt.Println(firsts)
-
-	follow := first_follow.ComputeFollowSets(bnf_grammar)
-	fmt.Println(follow)
+	sets := first_follow.Compute(bnf_grammar, "expression")
+	fmt.Println(sets.First)
+	fmt.Println(sets.Nullable)
+	fmt.Println(sets.Follow)
 }
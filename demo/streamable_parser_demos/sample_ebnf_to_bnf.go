@@ -6,9 +6,9 @@ import (
 	"io"
 	"os"
 
+	"github.com/VirajAgarwal1/lox/grammar_file"
 	"github.com/VirajAgarwal1/lox/lexer"
 	ebnf_to_bnf "github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/ebnf_to_bnf"
-	grammar_file_parser "github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/grammar_file_parser"
 )
 
 func Sample_EBNF_to_BNF() {
@@ -20,7 +20,7 @@ func Sample_EBNF_to_BNF() {
 
 	scanner := lexer.LexicalAnalyzer{}
 	scanner.Initialize(source)
-	ebnf_grammar, err := grammar_file_parser.ProcessGrammarDefinition(&scanner)
+	ebnf_grammar, err := grammar_file.ProcessGrammarDefinition(&scanner)
 	if err != nil && err != io.EOF {
 		panic(err)
 	}
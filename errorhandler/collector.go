@@ -0,0 +1,170 @@
+package errorhandler
+
+import (
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/VirajAgarwal1/lox/source"
+)
+
+/*
+Diagnostics collects everything which went wrong (or looks suspicious) during one compilation run. One collector can be handed to the lexer, the grammar file parser, the generators and the parsers at the same time, even when they run in different goroutines.
+
+	- Diagnostics with the same severity, code, message and primary span are only kept once
+	- Once `MaxCount` diagnostics are kept, the rest are dropped (but still counted in `Dropped()`)
+	- `Sorted()` returns them ordered by position, which is file order and then offset inside the file, because of how `source.FileSet` hands out positions
+*/
+
+type Diagnostics struct {
+	mutex    sync.Mutex
+	MaxCount int // 0 means no limit
+	list     []*Diagnostic
+	seen     map[string]struct{}
+	dropped  int
+}
+
+func NewDiagnostics(max_count int) *Diagnostics {
+	return &Diagnostics{MaxCount: max_count}
+}
+
+func NewWarning(code string, message string, span Span, label string) *Diagnostic {
+	diag := NewDiagnostic(code, message, span, label)
+	diag.Severity = SeverityWarning
+	return diag
+}
+
+func diagnostic_key(d *Diagnostic) string {
+	var key strings.Builder
+	key.WriteString(d.Severity.String())
+	key.WriteString("|" + d.Code + "|" + d.Message)
+	if primary := d.PrimaryLabel(); primary != nil {
+		key.WriteString("|" + strconv.Itoa(int(primary.Span.Start)) + "|" + strconv.Itoa(int(primary.Span.End)))
+	}
+	return key.String()
+}
+
+func primary_pos(d *Diagnostic) source.Pos {
+	if primary := d.PrimaryLabel(); primary != nil {
+		return primary.Span.Start
+	}
+	return source.NoPos
+}
+
+// Add records the diagnostic. It returns false if it was a duplicate or was dropped because of `MaxCount`.
+func (ds *Diagnostics) Add(d *Diagnostic) bool {
+	if d == nil {
+		return false
+	}
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	if ds.seen == nil {
+		ds.seen = map[string]struct{}{}
+	}
+	key := diagnostic_key(d)
+	if _, duplicate := ds.seen[key]; duplicate {
+		return false
+	}
+	if ds.MaxCount > 0 && len(ds.list) >= ds.MaxCount {
+		ds.dropped++
+		return false
+	}
+	ds.seen[key] = struct{}{}
+	ds.list = append(ds.list, d)
+	return true
+}
+
+// AddError records the diagnostic carried by err. Errors without a diagnostic (like I/O errors) are recorded with only their message.
+func (ds *Diagnostics) AddError(err error) bool {
+	if err == nil || err == io.EOF {
+		return false
+	}
+	var diagnosable Diagnosable
+	if errors.As(err, &diagnosable) {
+		return ds.Add(diagnosable.Diagnostic())
+	}
+	var d *Diagnostic
+	if errors.As(err, &d) {
+		return ds.Add(d)
+	}
+	return ds.Add(&Diagnostic{Severity: SeverityError, Message: err.Error()})
+}
+
+// Len returns the number of diagnostics kept
+func (ds *Diagnostics) Len() int {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+	return len(ds.list)
+}
+
+// Dropped returns the number of diagnostics which were not kept because of `MaxCount`
+func (ds *Diagnostics) Dropped() int {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+	return ds.dropped
+}
+
+func (ds *Diagnostics) count(severity Severity) int {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+	n := 0
+	for _, d := range ds.list {
+		if d.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+func (ds *Diagnostics) ErrorCount() int {
+	return ds.count(SeverityError)
+}
+func (ds *Diagnostics) WarningCount() int {
+	return ds.count(SeverityWarning)
+}
+func (ds *Diagnostics) HasErrors() bool {
+	return ds.ErrorCount() > 0
+}
+
+// Sorted returns the diagnostics ordered by the position of their primary label. Diagnostics without a position come last, ties keep the order in which they were added.
+func (ds *Diagnostics) Sorted() []*Diagnostic {
+	ds.mutex.Lock()
+	out := make([]*Diagnostic, len(ds.list))
+	copy(out, ds.list)
+	ds.mutex.Unlock()
+
+	sort.SliceStable(out, func(i, j int) bool {
+		pi, pj := primary_pos(out[i]), primary_pos(out[j])
+		if pi.IsValid() != pj.IsValid() {
+			return pi.IsValid()
+		}
+		if pi != pj {
+			return pi < pj
+		}
+		return out[i].Severity < out[j].Severity
+	})
+	return out
+}
+
+// Err returns the first error (by position) as an error value, or nil if there are no errors
+func (ds *Diagnostics) Err() error {
+	for _, d := range ds.Sorted() {
+		if d.Severity == SeverityError {
+			return d
+		}
+	}
+	return nil
+}
+
+// Render writes all the diagnostics, sorted by position, with the given renderer
+func (ds *Diagnostics) Render(w io.Writer, r *Renderer) error {
+	for _, d := range ds.Sorted() {
+		if err := r.Render(w, d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
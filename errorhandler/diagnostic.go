@@ -0,0 +1,122 @@
+package errorhandler
+
+import (
+	"strings"
+
+	"github.com/VirajAgarwal1/lox/source"
+)
+
+/*
+`RetErr` builds errors which point to the Go code which produced them, that is great for us while debugging but means nothing to someone writing Lox. A Diagnostic is the user facing side of an error: it points into the Lox (or grammar) source with spans, and carries labels, notes and help text which the `Renderer` turns into rustc style output with the offending lines and carets under them.
+
+A Diagnostic is also an `error`, so it can be returned as is or wrapped inside `RetErr` and dug back out with `errors.As`.
+*/
+
+type Severity byte
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+	SeverityNote
+	SeverityHelp
+)
+
+// Span marks the source between Start and End (End excluded). An invalid End means that the span is one rune long.
+type Span struct {
+	Start source.Pos
+	End   source.Pos
+}
+
+type Label struct {
+	Span    Span
+	Message string
+	Primary bool // primary labels are underlined with '^', the others with '-'
+}
+
+type Diagnostic struct {
+	Severity Severity
+	Code     string // stable identifier for this kind of diagnostic, can be empty
+	Message  string
+	Labels   []Label
+	Notes    []string
+	Help     []string
+	Position source.Position // resolved position of the primary label, filled in by whoever creates the diagnostic so that `Error()` can show it
+}
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityNote:
+		return "note"
+	case SeverityHelp:
+		return "help"
+	}
+	return "unknown"
+}
+
+func NewSpan(start source.Pos, end source.Pos) Span {
+	return Span{Start: start, End: end}
+}
+
+// NewDiagnostic creates an error diagnostic with a primary label at the given span
+func NewDiagnostic(code string, message string, span Span, label string) *Diagnostic {
+	return &Diagnostic{
+		Severity: SeverityError,
+		Code:     code,
+		Message:  message,
+		Labels:   []Label{{Span: span, Message: label, Primary: true}},
+	}
+}
+
+func (d *Diagnostic) WithLabel(span Span, message string) *Diagnostic {
+	d.Labels = append(d.Labels, Label{Span: span, Message: message})
+	return d
+}
+func (d *Diagnostic) WithNote(note string) *Diagnostic {
+	d.Notes = append(d.Notes, note)
+	return d
+}
+func (d *Diagnostic) WithHelp(help string) *Diagnostic {
+	d.Help = append(d.Help, help)
+	return d
+}
+
+// Resolve fills in `Position` from the primary label using the given file set
+func (d *Diagnostic) Resolve(fset *source.FileSet) *Diagnostic {
+	if primary := d.PrimaryLabel(); primary != nil && fset != nil {
+		d.Position = fset.Position(primary.Span.Start)
+	}
+	return d
+}
+
+// PrimaryLabel returns the first primary label, or the first label if none is marked primary
+func (d *Diagnostic) PrimaryLabel() *Label {
+	for i := range d.Labels {
+		if d.Labels[i].Primary {
+			return &d.Labels[i]
+		}
+	}
+	if len(d.Labels) > 0 {
+		return &d.Labels[0]
+	}
+	return nil
+}
+
+// Error gives a one line summary, use a `Renderer` to show the full diagnostic
+func (d *Diagnostic) Error() string {
+	var out strings.Builder
+	if d.Position.IsValid() || d.Position.Filename != "" {
+		out.WriteString(d.Position.String())
+		out.WriteString(": ")
+	}
+	out.WriteString(d.Severity.String())
+	if d.Code != "" {
+		out.WriteString("[" + d.Code + "]")
+	}
+	out.WriteString(": ")
+	out.WriteString(d.Message)
+	return out.String()
+}
@@ -5,23 +5,20 @@ import (
 	"runtime"
 )
 
-var runtimme_lineNum int
-var runtime_file string
-var runtime_pc uintptr
-
+// The caller information is kept in local variables, so that errors can be created from multiple goroutines at once
 func RetErr(msg string, stacked_err error) error {
-	runtime_pc, runtime_file, runtimme_lineNum, _ = runtime.Caller(1)
+	runtime_pc, runtime_file, runtime_lineNum, _ := runtime.Caller(1)
 	if stacked_err != nil {
 		if len(msg) != 0 {
-			return fmt.Errorf("%d| %v/%v  ->  %v\n%w", runtimme_lineNum, runtime_file, runtime.FuncForPC(runtime_pc).Name(), msg, stacked_err)
+			return fmt.Errorf("%d| %v/%v  ->  %v\n%w", runtime_lineNum, runtime_file, runtime.FuncForPC(runtime_pc).Name(), msg, stacked_err)
 		}
-		return fmt.Errorf("%d| %v/%v\n%w", runtimme_lineNum, runtime_file, runtime.FuncForPC(runtime_pc).Name(), stacked_err)
+		return fmt.Errorf("%d| %v/%v\n%w", runtime_lineNum, runtime_file, runtime.FuncForPC(runtime_pc).Name(), stacked_err)
 	}
-	return fmt.Errorf("%d| %v/%v)\n%v", runtimme_lineNum, runtime_file, runtime.FuncForPC(runtime_pc).Name(), msg)
+	return fmt.Errorf("%d| %v/%v)\n%v", runtime_lineNum, runtime_file, runtime.FuncForPC(runtime_pc).Name(), msg)
 }
 
 func ReportErr(err_stack error) {
-	runtime_pc, runtime_file, runtimme_lineNum, _ = runtime.Caller(1)
-	err_stack = fmt.Errorf("%d| %v/%v\n%w", runtimme_lineNum, runtime_file, runtime.FuncForPC(runtime_pc).Name(), err_stack)
+	runtime_pc, runtime_file, runtime_lineNum, _ := runtime.Caller(1)
+	err_stack = fmt.Errorf("%d| %v/%v\n%w", runtime_lineNum, runtime_file, runtime.FuncForPC(runtime_pc).Name(), err_stack)
 	fmt.Println(err_stack)
 }
@@ -0,0 +1,186 @@
+package errorhandler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/VirajAgarwal1/lox/source"
+)
+
+/*
+Typed errors, so that callers can tell the different kinds of failures apart without matching on the message:
+
+	var parse_err *errorhandler.ParseError
+	if errors.As(err, &parse_err) { ... parse_err.Expected ... }
+	if errors.Is(err, errorhandler.ErrLimit) { ... }
+
+Each of them carries a stable `Code` and can give its user facing `Diagnostic`. They still go through `RetErr` like every other error in the project, `errors.As` and `errors.Is` can see through it because `RetErr` wraps with `%w`.
+*/
+
+// Stable codes. The first letter tells which stage produced the error: L(exer), P(arser), G(rammar file), R(esource limits)
+const (
+	CodeInvalidToken         = "L0001"
+	CodeUnexpectedToken      = "P0001"
+	CodeNoMatchingProduction = "P0002"
+	CodeGrammarSyntax        = "G0001"
+	CodeUndefinedNonTerminal = "G0002"
+	CodeUnknownTerminal      = "G0003"
+	CodeDuplicateRule        = "G0004"
+	CodeEmptyAlternative     = "G0005"
+	CodeUnproductiveRule     = "G0006"
+	CodeUnusedRule           = "G0007"
+	CodeUnreachableRule      = "G0008"
+	CodeUnsupportedConstruct = "G0009"
+	CodeDroppedConstruct     = "G0010"
+	CodeInvalidAction        = "G0011"
+	CodeConflict             = "G0012"
+	CodeBufferOverflow       = "R0001"
+)
+
+// Sentinels for `errors.Is`, they match every error of their kind
+var (
+	ErrLexical = errors.New("lexical error")
+	ErrSyntax  = errors.New("syntax error")
+	ErrGrammar = errors.New("grammar error")
+	ErrLimit   = errors.New("limit exceeded")
+)
+
+// Diagnosable is implemented by all the errors which can be shown to users as a `Diagnostic`
+type Diagnosable interface {
+	error
+	Diagnostic() *Diagnostic
+}
+
+// ---------------------------------------------------------------------------------
+// Lexer
+// ---------------------------------------------------------------------------------
+
+type LexError struct {
+	Code       string
+	Pos        source.Pos
+	End        source.Pos
+	Position   source.Position
+	Lexemme    string
+	Resembling string // name of the token type whose DFA got the furthest before failing, empty if none
+	Help       string // how to fix the token, empty if the lexer has no hint
+}
+
+func (e *LexError) Error() string {
+	return e.Diagnostic().Error()
+}
+func (e *LexError) Is(target error) bool {
+	return target == ErrLexical
+}
+func (e *LexError) Diagnostic() *Diagnostic {
+	diag := NewDiagnostic(e.Code, "invalid token", NewSpan(e.Pos, e.End), "not a valid token")
+	if e.Resembling != "" {
+		diag.WithNote(fmt.Sprintf("the most resembling token type was %v", e.Resembling))
+	}
+	if e.Help != "" {
+		diag.WithHelp(e.Help)
+	}
+	diag.Position = e.Position
+	return diag
+}
+
+// ---------------------------------------------------------------------------------
+// Parsers
+// ---------------------------------------------------------------------------------
+
+// EndOfFile is the name of the token found at the end of the input
+const EndOfFile = "EOF"
+
+type ParseError struct {
+	Code        string
+	Pos         source.Pos // the unexpected token
+	End         source.Pos
+	Position    source.Position
+	Found       string // name of the unexpected token type, `EndOfFile` at the end of the input
+	Lexemme     string
+	Expected    []string   // names of the token types which would have been accepted
+	NonTerminal string     // non-terminal being parsed when the error happened, empty if not known
+	RecoveredAt source.Pos // position of the token where error recovery resumed parsing, `NoPos` if no recovery happened
+}
+
+func (e *ParseError) Error() string {
+	return e.Diagnostic().Error()
+}
+func (e *ParseError) Is(target error) bool {
+	return target == ErrSyntax
+}
+func (e *ParseError) Diagnostic() *Diagnostic {
+	message := "unexpected " + strconv.Quote(e.Lexemme)
+	if e.Found == EndOfFile {
+		message = "unexpected end of file"
+	}
+	if e.NonTerminal != "" {
+		message += " while parsing " + e.NonTerminal
+	}
+
+	names := make([]string, len(e.Expected))
+	for i, tok := range e.Expected {
+		names[i] = strconv.Quote(tok)
+	}
+	label := ""
+	if len(names) == 1 {
+		label = "expected " + names[0]
+	} else if len(names) > 1 {
+		label = "expected one of " + strings.Join(names, ", ")
+	}
+
+	diag := NewDiagnostic(e.Code, message, NewSpan(e.Pos, e.End), label)
+	if e.RecoveredAt.IsValid() && e.RecoveredAt > e.End {
+		diag.WithLabel(NewSpan(e.End, e.RecoveredAt), "skipped while recovering")
+	}
+	diag.Position = e.Position
+	return diag
+}
+
+// GrammarError is a syntax error in a grammar file
+type GrammarError struct {
+	Code     string
+	Pos      source.Pos
+	End      source.Pos
+	Position source.Position
+	Message  string
+}
+
+func (e *GrammarError) Error() string {
+	return e.Diagnostic().Error()
+}
+func (e *GrammarError) Is(target error) bool {
+	return target == ErrGrammar
+}
+func (e *GrammarError) Diagnostic() *Diagnostic {
+	diag := NewDiagnostic(e.Code, "invalid grammar: "+e.Message, NewSpan(e.Pos, e.End), "")
+	diag.Position = e.Position
+	return diag
+}
+
+// ---------------------------------------------------------------------------------
+// Limits
+// ---------------------------------------------------------------------------------
+
+// LimitError is returned when a fixed size resource (like the lexer's token buffer) runs out
+type LimitError struct {
+	Code     string
+	What     string // which resource ran out, eg. "token buffer"
+	Limit    int
+	Pos      source.Pos
+	Position source.Position
+}
+
+func (e *LimitError) Error() string {
+	return e.Diagnostic().Error()
+}
+func (e *LimitError) Is(target error) bool {
+	return target == ErrLimit
+}
+func (e *LimitError) Diagnostic() *Diagnostic {
+	diag := NewDiagnostic(e.Code, fmt.Sprintf("%s overflow, limit is %d", e.What, e.Limit), NewSpan(e.Pos, source.NoPos), "")
+	diag.WithHelp(fmt.Sprintf("increase the capacity of the %s", e.What))
+	diag.Position = e.Position
+	return diag
+}
@@ -0,0 +1,152 @@
+package errorhandler
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/VirajAgarwal1/lox/source"
+)
+
+/*
+Stable JSON form of the diagnostics, consumed by the web IDE. Fields are only ever added to this schema, never renamed or removed, and `version` is bumped if that ever has to change.
+
+	{
+	  "version": 1,
+	  "diagnostics": [
+	    {
+	      "severity": "error",
+	      "code": "L0001",
+	      "message": "invalid token",
+	      "location": {"file": "main.lox", "line": 1, "column": 9, "offset": 8, "endLine": 1, "endColumn": 10, "endOffset": 9},
+	      "snippet": "var x = @;",
+	      "labels": [{"message": "not a valid token", "primary": true, "location": {...}}],
+	      "notes": [],
+	      "help": []
+	    }
+	  ]
+	}
+
+Lines and columns start at 1, columns count runes. The end of a location is excluded.
+*/
+
+const JSONSchemaVersion = 1
+
+type JSONLocation struct {
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	Offset    int    `json:"offset"`
+	EndLine   int    `json:"endLine"`
+	EndColumn int    `json:"endColumn"`
+	EndOffset int    `json:"endOffset"`
+}
+
+type JSONLabel struct {
+	Message  string        `json:"message"`
+	Primary  bool          `json:"primary"`
+	Location *JSONLocation `json:"location"`
+}
+
+type JSONDiagnostic struct {
+	Severity string        `json:"severity"`
+	Code     string        `json:"code"`
+	Message  string        `json:"message"`
+	Location *JSONLocation `json:"location"`
+	Snippet  string        `json:"snippet"`
+	Labels   []JSONLabel   `json:"labels"`
+	Notes    []string      `json:"notes"`
+	Help     []string      `json:"help"`
+}
+
+type JSONReport struct {
+	Version     int              `json:"version"`
+	Diagnostics []JSONDiagnostic `json:"diagnostics"`
+}
+
+// resolve_span turns a span into a location, nil if the span does not belong to any file of the set
+func resolve_span(fset *source.FileSet, span Span) *JSONLocation {
+	if fset == nil {
+		return nil
+	}
+	file := fset.File(span.Start)
+	if file == nil {
+		return nil
+	}
+	start := file.Position(span.Start)
+	end := start
+	if span.End.IsValid() && file.Contains(span.End) && span.End > span.Start {
+		end = file.Position(span.End)
+	} else {
+		// A span without an end is one rune long
+		end.Column++
+		end.Offset++
+	}
+	return &JSONLocation{
+		File:      start.Filename,
+		Line:      start.Line,
+		Column:    start.Column,
+		Offset:    start.Offset,
+		EndLine:   end.Line,
+		EndColumn: end.Column,
+		EndOffset: end.Offset,
+	}
+}
+
+// snippet_of returns the text of the line on which the location starts
+func snippet_of(fset *source.FileSet, span Span) string {
+	if fset == nil {
+		return ""
+	}
+	file := fset.File(span.Start)
+	if file == nil {
+		return ""
+	}
+	text, _ := file.Line(file.Position(span.Start).Line)
+	return text
+}
+
+func ToJSONDiagnostic(fset *source.FileSet, d *Diagnostic) JSONDiagnostic {
+	out := JSONDiagnostic{
+		Severity: d.Severity.String(),
+		Code:     d.Code,
+		Message:  d.Message,
+		Labels:   []JSONLabel{},
+		Notes:    append([]string{}, d.Notes...),
+		Help:     append([]string{}, d.Help...),
+	}
+	if primary := d.PrimaryLabel(); primary != nil {
+		out.Location = resolve_span(fset, primary.Span)
+		out.Snippet = snippet_of(fset, primary.Span)
+	}
+	if out.Location == nil && d.Position.IsValid() {
+		out.Location = &JSONLocation{
+			File:      d.Position.Filename,
+			Line:      d.Position.Line,
+			Column:    d.Position.Column,
+			Offset:    d.Position.Offset,
+			EndLine:   d.Position.Line,
+			EndColumn: d.Position.Column + 1,
+			EndOffset: d.Position.Offset + 1,
+		}
+	}
+	for _, label := range d.Labels {
+		out.Labels = append(out.Labels, JSONLabel{
+			Message:  label.Message,
+			Primary:  label.Primary,
+			Location: resolve_span(fset, label.Span),
+		})
+	}
+	return out
+}
+
+// WriteJSON writes all the diagnostics, sorted by position, in the JSON schema described above
+func (ds *Diagnostics) WriteJSON(w io.Writer, fset *source.FileSet) error {
+	report := JSONReport{Version: JSONSchemaVersion, Diagnostics: []JSONDiagnostic{}}
+	for _, d := range ds.Sorted() {
+		report.Diagnostics = append(report.Diagnostics, ToJSONDiagnostic(fset, d))
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false) // The snippets are shown as they are, like the `->` of the grammar files
+	return encoder.Encode(report)
+}
@@ -0,0 +1,271 @@
+package errorhandler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/VirajAgarwal1/lox/source"
+)
+
+/*
+The Renderer prints diagnostics the way rustc does:
+
+	error[P0001]: unexpected token
+	 --> main.lox:3:9
+	  |
+	3 | var a = );
+	  |         ^ expected an expression
+	  |
+	  = help: remove the ')'
+
+Colours are used only when the output is a terminal (and `NO_COLOR` is not set), otherwise plain text is written.
+*/
+
+const (
+	ansi_reset  = "\x1b[0m"
+	ansi_bold   = "\x1b[1m"
+	ansi_red    = "\x1b[31m"
+	ansi_yellow = "\x1b[33m"
+	ansi_blue   = "\x1b[34m"
+	ansi_cyan   = "\x1b[36m"
+	ansi_green  = "\x1b[32m"
+)
+
+type Renderer struct {
+	Files  *source.FileSet
+	Colour bool
+}
+
+// NewRenderer creates a renderer which uses colours only if `w` is a terminal
+func NewRenderer(fset *source.FileSet, w io.Writer) *Renderer {
+	return &Renderer{Files: fset, Colour: IsTerminal(w)}
+}
+
+// IsTerminal reports whether w is a character device (and colours are not disabled with the NO_COLOR environment variable)
+func IsTerminal(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func (r *Renderer) paint(text string, codes ...string) string {
+	if !r.Colour || len(codes) == 0 {
+		return text
+	}
+	return strings.Join(codes, "") + text + ansi_reset
+}
+
+func (r *Renderer) severityColour(s Severity) string {
+	switch s {
+	case SeverityError:
+		return ansi_red
+	case SeverityWarning:
+		return ansi_yellow
+	case SeverityNote:
+		return ansi_green
+	}
+	return ansi_cyan
+}
+
+// one underline to draw below a line of source
+type underline struct {
+	line     int
+	startCol int // starting at 1
+	endCol   int // excluded
+	message  string
+	primary  bool
+}
+
+func (r *Renderer) underlinesFor(label Label) (*source.File, []underline) {
+	if r.Files == nil {
+		return nil, nil
+	}
+	file := r.Files.File(label.Span.Start)
+	if file == nil {
+		return nil, nil
+	}
+	start := clampToLastLine(file, file.Position(label.Span.Start))
+	end := start
+	if label.Span.End.IsValid() && file.Contains(label.Span.End) && label.Span.End > label.Span.Start {
+		end = clampToLastLine(file, file.Position(label.Span.End))
+	} else {
+		end.Column++ // A span without an end is one rune long
+	}
+
+	out := []underline{}
+	for line := start.Line; line <= end.Line; line++ {
+		text, ok := file.Line(line)
+		if !ok {
+			break
+		}
+		width := len([]rune(text))
+		u := underline{line: line, startCol: 1, endCol: width + 1, primary: label.Primary}
+		if line == start.Line {
+			u.startCol = start.Column
+		}
+		if line == end.Line {
+			u.endCol = end.Column
+		}
+		if u.endCol <= u.startCol {
+			u.endCol = u.startCol + 1 // Always draw at least one caret, even at the end of a line
+		}
+		out = append(out, u)
+	}
+	if len(out) > 0 {
+		out[len(out)-1].message = label.Message
+	}
+	return file, out
+}
+
+// clampToLastLine moves a position after the trailing newline of a file, like the end of file, to the end of the last line, as there is no text after the newline to draw it under
+func clampToLastLine(file *source.File, pos source.Position) source.Position {
+	if pos.Line <= 1 || pos.Column != 1 || pos.Line != file.LineCount() {
+		return pos
+	}
+	if text, _ := file.Line(pos.Line); text != "" {
+		return pos
+	}
+	previous, _ := file.Line(pos.Line - 1)
+	pos.Line--
+	pos.Column = len([]rune(previous)) + 1
+	pos.Offset = file.Offset(file.LineStart(pos.Line)) + len(previous)
+	return pos
+}
+
+// Render writes the full diagnostic with source snippets to w
+func (r *Renderer) Render(w io.Writer, d *Diagnostic) error {
+	var out strings.Builder
+
+	colour := r.severityColour(d.Severity)
+	header := d.Severity.String()
+	if d.Code != "" {
+		header += "[" + d.Code + "]"
+	}
+	out.WriteString(r.paint(header, ansi_bold, colour))
+	out.WriteString(r.paint(": "+d.Message, ansi_bold))
+	out.WriteString("\n")
+
+	// Collect the underlines of all the labels, per file in the order the labels were given
+	var files []*source.File
+	per_file := map[*source.File][]underline{}
+	for _, label := range d.Labels {
+		file, lines := r.underlinesFor(label)
+		if file == nil {
+			continue
+		}
+		if _, seen := per_file[file]; !seen {
+			files = append(files, file)
+		}
+		per_file[file] = append(per_file[file], lines...)
+	}
+
+	gutter_width := 1
+	for _, lines := range per_file {
+		for _, u := range lines {
+			gutter_width = max(gutter_width, len(strconv.Itoa(u.line)))
+		}
+	}
+	gutter := strings.Repeat(" ", gutter_width)
+	bar := r.paint("|", ansi_bold, ansi_blue)
+
+	if len(files) == 0 && d.Position.IsValid() {
+		out.WriteString(gutter + r.paint("-->", ansi_bold, ansi_blue) + " " + d.Position.String() + "\n")
+	}
+
+	for _, file := range files {
+		lines := per_file[file]
+		sort.SliceStable(lines, func(i, j int) bool { return lines[i].line < lines[j].line })
+
+		location := file.Position(file.LineStart(lines[0].line))
+		if primary := d.PrimaryLabel(); primary != nil && r.Files.File(primary.Span.Start) == file {
+			location = clampToLastLine(file, file.Position(primary.Span.Start))
+		}
+		out.WriteString(gutter + r.paint("-->", ansi_bold, ansi_blue) + " " + location.String() + "\n")
+		out.WriteString(gutter + " " + bar + "\n")
+
+		prev_line := -1
+		for i, u := range lines {
+			if u.line != prev_line {
+				if prev_line != -1 && u.line > prev_line+1 {
+					out.WriteString(r.paint("...", ansi_bold, ansi_blue) + "\n")
+				}
+				text, _ := file.Line(u.line)
+				out.WriteString(r.paint(fmt.Sprintf("%*d", gutter_width, u.line), ansi_bold, ansi_blue) + " " + bar + " " + text + "\n")
+				prev_line = u.line
+			}
+
+			text, _ := file.Line(u.line)
+			marker, marker_colour := "-", ansi_blue
+			if u.primary {
+				marker, marker_colour = "^", colour
+			}
+			carets := strings.Repeat(marker, u.endCol-u.startCol)
+			if u.message != "" {
+				carets += " " + u.message
+			}
+			out.WriteString(gutter + " " + bar + " " + padding(text, u.startCol) + r.paint(carets, ansi_bold, marker_colour) + "\n")
+
+			// Separate the snippet from the notes
+			if i == len(lines)-1 && (len(d.Notes) > 0 || len(d.Help) > 0) {
+				out.WriteString(gutter + " " + bar + "\n")
+			}
+		}
+	}
+
+	for _, note := range d.Notes {
+		out.WriteString(gutter + " " + r.paint("=", ansi_bold, ansi_blue) + " " + r.paint("note", ansi_bold) + ": " + note + "\n")
+	}
+	for _, help := range d.Help {
+		out.WriteString(gutter + " " + r.paint("=", ansi_bold, ansi_blue) + " " + r.paint("help", ansi_bold) + ": " + help + "\n")
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// RenderError renders the diagnostic found in the chain of err. Errors which do not carry a diagnostic are printed as they are.
+func (r *Renderer) RenderError(w io.Writer, err error) error {
+	var diagnosable Diagnosable
+	if errors.As(err, &diagnosable) {
+		return r.Render(w, diagnosable.Diagnostic())
+	}
+	var d *Diagnostic
+	if errors.As(err, &d) {
+		return r.Render(w, d)
+	}
+	_, werr := io.WriteString(w, r.paint("error", ansi_bold, ansi_red)+r.paint(": "+err.Error(), ansi_bold)+"\n")
+	return werr
+}
+
+// padding keeps the tabs of the source line so that the carets line up with the text above them
+func padding(text string, col int) string {
+	var out strings.Builder
+	for i, r := range []rune(text) {
+		if i >= col-1 {
+			break
+		}
+		if r == '\t' {
+			out.WriteRune('\t')
+		} else {
+			out.WriteRune(' ')
+		}
+	}
+	for i := len([]rune(text)); i < col-1; i++ {
+		out.WriteRune(' ')
+	}
+	return out.String()
+}
@@ -0,0 +1,200 @@
+package errorhandler
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/VirajAgarwal1/lox/source"
+)
+
+/*
+SARIF 2.1.0 output (https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html), which is what CI uses to annotate pull requests. Only the parts of the format we need are modelled here:
+
+	- one run, with the tool's rules built from the codes of the diagnostics
+	- one result per diagnostic, located at its primary label with the line as snippet
+	- the other labels as related locations
+	- notes and help appended to the message text
+*/
+
+const (
+	SarifVersion = "2.1.0"
+	SarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	ToolName     = "lox"
+	ToolURI      = "https://github.com/VirajAgarwal1/lox"
+)
+
+// RuleDescriptions gives the short description of every stable code, used for the rules of SARIF output
+var RuleDescriptions = map[string]string{
+	CodeInvalidToken:         "Invalid token",
+	CodeUnexpectedToken:      "Unexpected token",
+	CodeNoMatchingProduction: "No production matches the token",
+	CodeGrammarSyntax:        "Invalid grammar file syntax",
+	CodeUndefinedNonTerminal: "Non-terminal without a production rule",
+	CodeUnknownTerminal:      "Terminal which is not a known token",
+	CodeDuplicateRule:        "Non-terminal defined more than once",
+	CodeEmptyAlternative:     "Empty alternative in a production",
+	CodeUnproductiveRule:     "Non-terminal which can never finish",
+	CodeUnusedRule:           "Non-terminal which is never used",
+	CodeUnreachableRule:      "Non-terminal unreachable from the start symbol",
+	CodeUnsupportedConstruct: "Construct of an imported grammar which cannot be converted",
+	CodeDroppedConstruct:     "Construct of an imported grammar which is left out",
+	CodeBufferOverflow:       "Fixed size buffer overflowed",
+}
+
+type sarifText struct {
+	Text string `json:"text"`
+}
+type sarifRule struct {
+	ID               string    `json:"id"`
+	ShortDescription sarifText `json:"shortDescription"`
+}
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+type sarifRegion struct {
+	StartLine   int        `json:"startLine"`
+	StartColumn int        `json:"startColumn"`
+	EndLine     int        `json:"endLine"`
+	EndColumn   int        `json:"endColumn"`
+	Snippet     *sarifText `json:"snippet,omitempty"`
+}
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+type sarifLocation struct {
+	ID               *int                  `json:"id,omitempty"`
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifText            `json:"message,omitempty"`
+}
+type sarifResult struct {
+	RuleID           string          `json:"ruleId,omitempty"`
+	RuleIndex        *int            `json:"ruleIndex,omitempty"`
+	Level            string          `json:"level"`
+	Message          sarifText       `json:"message"`
+	Locations        []sarifLocation `json:"locations"`
+	RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+}
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+func sarif_level(s Severity) string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	}
+	return "note"
+}
+
+func sarif_location(location *JSONLocation, snippet string) sarifPhysicalLocation {
+	out := sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{URI: location.File},
+		Region: sarifRegion{
+			StartLine:   location.Line,
+			StartColumn: location.Column,
+			EndLine:     location.EndLine,
+			EndColumn:   location.EndColumn,
+		},
+	}
+	if snippet != "" {
+		out.Region.Snippet = &sarifText{Text: snippet}
+	}
+	return out
+}
+
+// WriteSARIF writes all the diagnostics, sorted by position, as a SARIF 2.1.0 log
+func (ds *Diagnostics) WriteSARIF(w io.Writer, fset *source.FileSet) error {
+	diagnostics := ds.Sorted()
+
+	// Rules are listed once per code, sorted so that the output is stable
+	codes := []string{}
+	seen := map[string]struct{}{}
+	for _, d := range diagnostics {
+		if _, found := seen[d.Code]; !found && d.Code != "" {
+			seen[d.Code] = struct{}{}
+			codes = append(codes, d.Code)
+		}
+	}
+	sort.Strings(codes)
+	rule_index := map[string]int{}
+	rules := []sarifRule{}
+	for i, code := range codes {
+		rule_index[code] = i
+		description, found := RuleDescriptions[code]
+		if !found {
+			description = code
+		}
+		rules = append(rules, sarifRule{ID: code, ShortDescription: sarifText{Text: description}})
+	}
+
+	results := []sarifResult{}
+	for _, d := range diagnostics {
+		jd := ToJSONDiagnostic(fset, d)
+		text := d.Message
+		for _, note := range d.Notes {
+			text += "\nnote: " + note
+		}
+		for _, help := range d.Help {
+			text += "\nhelp: " + help
+		}
+
+		result := sarifResult{
+			Level:     sarif_level(d.Severity),
+			Message:   sarifText{Text: text},
+			Locations: []sarifLocation{},
+		}
+		if d.Code != "" {
+			idx := rule_index[d.Code]
+			result.RuleID = d.Code
+			result.RuleIndex = &idx
+		}
+		if jd.Location != nil {
+			result.Locations = append(result.Locations, sarifLocation{PhysicalLocation: sarif_location(jd.Location, jd.Snippet)})
+		}
+		related_id := 0
+		for i, label := range jd.Labels {
+			if d.Labels[i].Primary || label.Location == nil {
+				continue
+			}
+			id := related_id
+			related_id++
+			result.RelatedLocations = append(result.RelatedLocations, sarifLocation{
+				ID:               &id,
+				PhysicalLocation: sarif_location(label.Location, ""),
+				Message:          &sarifText{Text: label.Message},
+			})
+		}
+		results = append(results, result)
+	}
+
+	log := sarifLog{
+		Schema:  SarifSchema,
+		Version: SarifVersion,
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: sarifDriver{Name: ToolName, InformationURI: ToolURI, Rules: rules}},
+			Results: results,
+		}},
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false) // The snippets are shown as they are, like the `->` of the grammar files
+	return encoder.Encode(log)
+}
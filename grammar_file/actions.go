@@ -0,0 +1,157 @@
+package grammar_file
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Alternative_actions gives the action ending each alternative of a rule, nil for the alternatives which have none
+func Alternative_actions(terms []Generic_grammar_term) []*Action {
+	alts := Alternatives(terms)
+	actions := make([]*Action, len(alts))
+	for i, alternative := range alts {
+		if len(alternative) == 0 {
+			continue
+		}
+		if action, is_action := alternative[len(alternative)-1].(*Action); is_action {
+			actions[i] = action
+		}
+	}
+	return actions
+}
+
+// Alternative_labels gives the labels of each alternative of a rule, as `Labels` does, for `Expand_action`
+func Alternative_labels(terms []Generic_grammar_term) []map[string]bool {
+	alts := Alternatives(terms)
+	labels := make([]map[string]bool, len(alts))
+	for i, alternative := range alts {
+		_, labels[i] = Labels(alternative)
+	}
+	return labels
+}
+
+// has_action tells if an alternative of the production ends with an action
+func has_action(terms []Generic_grammar_term) bool {
+	for _, action := range Alternative_actions(terms) {
+		if action != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Has_actions tells if any rule of the grammar has an action, the rewritten left-recursive rules as they were written
+func (grammar *Grammar) Has_actions() bool {
+	for _, rule := range grammar.Rules {
+		if has_action(rule) {
+			return true
+		}
+	}
+	for _, left_recursion := range grammar.Left_recursions {
+		if has_action(left_recursion.Written) {
+			return true
+		}
+	}
+	return false
+}
+
+// Without_actions gives the terms of a rule with their actions left out, for the code which only cares about what the rule matches
+func Without_actions(terms []Generic_grammar_term) []Generic_grammar_term {
+	out := make([]Generic_grammar_term, 0, len(terms))
+	for _, term := range terms {
+		if term.Get_grammar_term_type() != "action" {
+			out = append(out, term)
+		}
+	}
+	return out
+}
+
+/*
+Expand_action turns the code of an action into Go code which reads the values of the alternative from a variable `values`:
+
+  - `$1`, `$2`, ... are the values of the tokens and non-terminals the alternative matched, in order, counting the ones inside its brackets and repetitions: `values.At(0)`, `values.At(1)`, ...
+  - `$label` is the value labelled `label`: `values.Get("label")`, or `values.All("label")` when `many[label]` tells that it can match more than once
+
+`many` is given by `Labels` on the alternative, so a label which is not in it is an error. A `$` inside a string, a rune or a comment of the code is left as it is.
+*/
+func Expand_action(code string, many map[string]bool) (string, error) {
+	var out strings.Builder
+	runes := []rune(code)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '"' || r == '\'' || r == '`':
+			end := skip_quoted(runes, i)
+			out.WriteString(string(runes[i:end]))
+			i = end - 1
+		case r == '/' && i+1 < len(runes) && (runes[i+1] == '/' || runes[i+1] == '*'):
+			end := skip_comment(runes, i)
+			out.WriteString(string(runes[i:end]))
+			i = end - 1
+		case r == '$':
+			end := i + 1
+			for end < len(runes) && (runes[end] == '_' || unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end])) {
+				end++
+			}
+			reference := string(runes[i+1 : end])
+			if reference == "" {
+				return "", errors.New("'$' must be followed by the number or the label of a value")
+			}
+			if unicode.IsDigit(runes[i+1]) {
+				n, err := strconv.Atoi(reference)
+				if err != nil || n < 1 {
+					return "", errors.New("'$" + reference + "' is not a value, they are numbered from $1")
+				}
+				out.WriteString("values.At(" + strconv.Itoa(n-1) + ")")
+			} else {
+				is_many, found := many[reference]
+				if !found {
+					return "", errors.New("'$" + reference + "' is not a label of the alternative")
+				}
+				if is_many {
+					out.WriteString(`values.All("` + reference + `")`)
+				} else {
+					out.WriteString(`values.Get("` + reference + `")`)
+				}
+			}
+			i = end - 1
+		default:
+			out.WriteRune(r)
+		}
+	}
+	return out.String(), nil
+}
+
+// skip_quoted gives the index after the string, rune or raw string starting at runes[start]
+func skip_quoted(runes []rune, start int) int {
+	quote := runes[start]
+	for i := start + 1; i < len(runes); i++ {
+		switch {
+		case runes[i] == '\\' && quote != '`':
+			i++
+		case runes[i] == quote:
+			return i + 1
+		}
+	}
+	return len(runes)
+}
+
+// skip_comment gives the index after the comment starting at runes[start]
+func skip_comment(runes []rune, start int) int {
+	if runes[start+1] == '/' {
+		for i := start + 2; i < len(runes); i++ {
+			if runes[i] == '\n' {
+				return i
+			}
+		}
+		return len(runes)
+	}
+	for i := start + 2; i+1 < len(runes); i++ {
+		if runes[i] == '*' && runes[i+1] == '/' {
+			return i + 2
+		}
+	}
+	return len(runes)
+}
@@ -0,0 +1,110 @@
+package grammar_file
+
+import (
+	"strconv"
+
+	"github.com/VirajAgarwal1/lox/errorhandler"
+)
+
+// Precedence_level is one `%left`, `%right` or `%nonassoc` declaration: operators which bind as tightly as each other
+type Precedence_level struct {
+	Associativity string   // "left", "right" or "nonassoc"
+	Operators     []string // Terminals, as written without their quotes
+}
+
+// Expression is a `%expr name operand` directive: `name` matches operands separated by the operators declared before it
+type Expression struct {
+	Name    string
+	Operand string
+	Levels  []Precedence_level // The precedence declarations it was given, from the loosest to the tightest
+	Span    errorhandler.Span
+}
+
+// Level_name gives the name of the rule which matches the operators of `Levels[level]`. The loosest level is the expression itself.
+func (expression *Expression) Level_name(level int) string {
+	if level == 0 {
+		return expression.Name
+	}
+	return expression.Name + "_" + strconv.Itoa(level)
+}
+
+// Is_level tells if a non-terminal is one of the rules of the expression
+func (expression *Expression) Is_level(name string) bool {
+	for level := range expression.Levels {
+		if expression.Level_name(level) == name {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+add_expression adds the rules of an expression: one rule per precedence level, each matching the next (tighter) one between its operators, and the tightest matching the operand. For `%left "+" "-"`, `%right "^"` and `%expr sum unary`:
+
+	sum   -> sum ( "+" or "-" ) sum_1 or sum_1
+	sum_1 -> unary ( "^" sum_1 )?
+
+A left-associative level is left-recursive, `1 - 2 - 3` groups as `(1 - 2) - 3`. `Eliminate_left_recursion` rewrites it into a repetition, whose nodes are always reassociated (see `Reassociates`). A right-associative level nests, `2 ^ 3 ^ 4` groups as `2 ^ (3 ^ 4)`. A non-associative one allows a single operator, `a < b < c` is not matched.
+
+The rules are not productions of the file: the formatter keeps the directive as it is.
+*/
+func (grammar *Grammar) add_expression(expression Expression) {
+	locate := func(term Generic_grammar_term) Generic_grammar_term {
+		grammar.Locations[term] = expression.Span
+		return term
+	}
+
+	for level, precedence := range expression.Levels {
+		next := expression.Operand
+		if level+1 < len(expression.Levels) {
+			next = expression.Level_name(level + 1)
+		}
+
+		var operators Generic_grammar_term
+		if len(precedence.Operators) == 1 {
+			operators = locate(&Terminal{Content: []rune(precedence.Operators[0])})
+		} else {
+			choices := []Generic_grammar_term{}
+			for i, operator := range precedence.Operators {
+				if i > 0 {
+					choices = append(choices, locate(&Or{}))
+				}
+				choices = append(choices, locate(&Terminal{Content: []rune(operator)}))
+			}
+			operators = locate(&Bracket{Contents: choices})
+		}
+
+		name := Non_terminal{Name: expression.Level_name(level)}
+		grammar.Definitions[name] = append(grammar.Definitions[name], expression.Span)
+		if _, found := grammar.Rules[name]; !found {
+			grammar.Order = append(grammar.Order, name)
+		}
+		if precedence.Associativity == "left" {
+			grammar.Rules[name] = []Generic_grammar_term{locate(&Non_terminal{Name: name.Name}), operators, locate(&Non_terminal{Name: next}), locate(&Or{}), locate(&Non_terminal{Name: next})}
+			continue
+		}
+
+		right := next
+		if precedence.Associativity == "right" {
+			right = name.Name
+		}
+		tail := locate(&Optional{Content: locate(&Bracket{Contents: []Generic_grammar_term{operators, locate(&Non_terminal{Name: right})}})})
+		grammar.Rules[name] = []Generic_grammar_term{locate(&Non_terminal{Name: next}), tail}
+	}
+	grammar.Expressions = append(grammar.Expressions, expression)
+}
+
+// Expression_of gives the expression which a non-terminal is a rule of, nil if it is an ordinary rule
+func (grammar *Grammar) Expression_of(name string) *Expression {
+	for i := range grammar.Expressions {
+		if grammar.Expressions[i].Is_level(name) {
+			return &grammar.Expressions[i]
+		}
+	}
+	return nil
+}
+
+// Reassociates tells if the parsers give back the nodes of a left-recursive rule as written: with `%reassociate`, and always for the left-associative levels of an expression
+func (grammar *Grammar) Reassociates(name string) bool {
+	return grammar.Reassociate || grammar.Expression_of(name) != nil
+}
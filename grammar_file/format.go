@@ -0,0 +1,262 @@
+package grammar_file
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/VirajAgarwal1/lox/errorhandler"
+	"github.com/VirajAgarwal1/lox/source"
+)
+
+/*
+Format prints a grammar file in its canonical form, which is what `lox grammar fmt` writes:
+
+  - one production per line, with the `->` of consecutive productions aligned (a blank line or a directive starts a new block)
+  - single spaces between terms, around `or` and inside brackets: `( a or b )*`, `[ a ]` (also for `( ... )?`), and none around the `:` of a label: `left:term`
+  - productions longer than `Format_line_width` are wrapped before an `or`, with the `or` under the `->`
+  - directives as `%name arg ...`, on their own lines
+  - comments are kept: on their own line where they were, or at the end of the line they followed. Comments written inside a production stay next to the alternative they were written at, or go above the production if they were in the middle of one.
+  - at most one blank line between things, where the file had at least one, and no `;`
+*/
+
+const Format_line_width = 80
+
+// format_item is one top-level thing of the file: a production, a directive or a comment on its own line
+type format_item struct {
+	start      source.Pos
+	end        source.Pos // Where its last token ends
+	production *Rule
+	directive  *Directive
+	comment    *Comment
+
+	leading  []*Comment       // Comments to print on their own lines above the item
+	trailing *Comment         // Comment at the end of the item's (last) line
+	alt_lead map[int][]string // Comments to print on their own lines above an alternative of a production
+	alt_tail map[int]string   // Comment at the end of the line of an alternative of a production
+}
+
+func Format(grammar *Grammar) []byte {
+	items := format_items(grammar)
+	line_of := func(pos source.Pos) int {
+		return grammar.Position(errorhandler.NewSpan(pos, pos)).Line
+	}
+
+	var out strings.Builder
+	width := 0 // Width of the names in the current block of productions
+	for i, item := range items {
+		if i > 0 {
+			blank := line_of(item.start)-line_of(items[i-1].end) > 1
+			if blank {
+				out.WriteString("\n")
+			}
+			if blank || item.directive != nil || items[i-1].directive != nil {
+				width = 0
+			}
+		}
+		if item.production != nil && width == 0 {
+			width = block_width(items[i:], line_of)
+		}
+
+		for _, comment := range item.leading {
+			out.WriteString(comment_text(comment) + "\n")
+		}
+		switch {
+		case item.comment != nil:
+			out.WriteString(comment_text(item.comment) + "\n")
+		case item.directive != nil:
+			line := "%" + item.directive.Name
+			for _, arg := range item.directive.Args {
+				line += " " + arg
+			}
+			out.WriteString(with_trailing(line, item.trailing) + "\n")
+		case item.production != nil:
+			out.WriteString(format_production(item, width))
+		}
+	}
+	return []byte(out.String())
+}
+
+// block_width gives the width of the longest name in the block of productions starting at items[0]
+func block_width(items []*format_item, line_of func(source.Pos) int) int {
+	width := 0
+	for i, item := range items {
+		if i > 0 && (item.directive != nil || line_of(item.start)-line_of(items[i-1].end) > 1) {
+			break
+		}
+		if item.production != nil {
+			width = max(width, utf8.RuneCountInString(item.production.Name.Name))
+		}
+	}
+	return width
+}
+
+func comment_text(comment *Comment) string {
+	return strings.TrimRight(comment.Text, " \t\r")
+}
+func with_trailing(line string, comment *Comment) string {
+	if comment == nil {
+		return line
+	}
+	return line + " " + comment_text(comment)
+}
+
+func format_production(item *format_item, width int) string {
+	name := item.production.Name.Name
+	header := name + strings.Repeat(" ", width-utf8.RuneCountInString(name)) + " ->"
+	indent := strings.Repeat(" ", width+1)
+
+	lines := []string{header}
+	for j, alt := range Alternatives(item.production.Terms) {
+		piece := Format_terms(alt)
+		if j > 0 {
+			piece = strings.TrimSpace("or " + piece)
+		}
+		current := lines[len(lines)-1]
+		_, tail_before := item.alt_tail[j-1]
+		breaks := j > 0 && (len(item.alt_lead[j]) > 0 || tail_before || utf8.RuneCountInString(current)+1+utf8.RuneCountInString(piece) > Format_line_width)
+		if breaks {
+			for _, comment := range item.alt_lead[j] {
+				lines = append(lines, indent+comment)
+			}
+			lines = append(lines, indent+piece)
+		} else if piece != "" {
+			lines[len(lines)-1] = current + " " + piece
+		}
+		if tail, found := item.alt_tail[j]; found {
+			lines[len(lines)-1] += " " + tail
+		}
+	}
+	if item.trailing != nil {
+		lines[len(lines)-1] = with_trailing(lines[len(lines)-1], item.trailing)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// Format_terms prints terms in the notation of grammar files
+func Format_terms(terms []Generic_grammar_term) string {
+	parts := make([]string, 0, len(terms))
+	for _, term := range terms {
+		parts = append(parts, Format_term(term))
+	}
+	return strings.Join(parts, " ")
+}
+
+func Format_term(term Generic_grammar_term) string {
+	switch term := term.(type) {
+	case *Terminal:
+		return "\"" + string(term.Content) + "\""
+	case *Non_terminal:
+		return term.Name
+	case *Or:
+		return "or"
+	case *Star:
+		return Format_term(term.Content) + "*"
+	case *Plus:
+		return Format_term(term.Content) + "+"
+	case *Optional:
+		if bracket, is_bracket := term.Content.(*Bracket); is_bracket && !bracket.Is_left {
+			return "[ " + Format_terms(bracket.Contents) + " ]"
+		}
+		return Format_term(term.Content) + "?"
+	case *Bracket:
+		if term.Is_left {
+			// Only left unclosed at the end of a production
+			if term.Is_square {
+				return "["
+			}
+			return "("
+		}
+		return "( " + Format_terms(term.Contents) + " )"
+	case *Action:
+		return "{" + term.Code + "}"
+	case *Labelled:
+		if term.Content == nil {
+			return term.Label + ":"
+		}
+		return term.Label + ":" + Format_term(term.Content)
+	}
+	return ""
+}
+
+// format_items puts the productions, directives and comments in the order of the file, with every comment either on its own or attached to the item it was written in or after
+func format_items(grammar *Grammar) []*format_item {
+	items := []*format_item{}
+	for i := range grammar.Productions {
+		production := &grammar.Productions[i]
+		item := &format_item{start: production.Span.Start, end: production.Span.End, production: production}
+		for _, term := range production.Terms {
+			item.end = max(item.end, grammar.Locations[term].End)
+		}
+		items = append(items, item)
+	}
+	for i := range grammar.Directives {
+		directive := &grammar.Directives[i]
+		items = append(items, &format_item{start: directive.Span.Start, end: directive.Span.End, directive: directive})
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].start < items[j].start })
+
+	standalone := []*format_item{}
+	owner := -1 // Index of the last item which starts before the comment
+	for c := range grammar.Comments {
+		comment := &grammar.Comments[c]
+		for owner+1 < len(items) && items[owner+1].start < comment.Span.Start {
+			owner++
+		}
+		if owner < 0 {
+			standalone = append(standalone, &format_item{start: comment.Span.Start, end: comment.Span.End, comment: comment})
+			continue
+		}
+		item := items[owner]
+		switch {
+		case comment.Span.Start < item.end && item.production != nil:
+			attach_inner_comment(grammar, item, comment)
+		case !comment.Own_line && item.trailing == nil:
+			item.trailing = comment
+		case comment.Span.Start < item.end || !comment.Own_line:
+			item.leading = append(item.leading, comment)
+		default:
+			standalone = append(standalone, &format_item{start: comment.Span.Start, end: comment.Span.End, comment: comment})
+		}
+	}
+
+	items = append(items, standalone...)
+	sort.SliceStable(items, func(i, j int) bool { return items[i].start < items[j].start })
+	return items
+}
+
+// attach_inner_comment places a comment written between the terms of a production
+func attach_inner_comment(grammar *Grammar, item *format_item, comment *Comment) {
+	if item.alt_lead == nil {
+		item.alt_lead = map[int][]string{}
+		item.alt_tail = map[int]string{}
+	}
+	pos := comment.Span.Start
+
+	alts := Alternatives(item.production.Terms)
+	current := 0 // Alternative in which, or after which, the comment was written
+	for j := 1; j < len(alts); j++ {
+		if len(alts[j]) > 0 && grammar.Locations[alts[j][0]].Start < pos {
+			current = j
+		}
+	}
+	alt_end := item.start
+	if alt := alts[current]; len(alt) > 0 {
+		alt_end = grammar.Locations[alt[len(alt)-1]].End
+	}
+
+	if !comment.Own_line {
+		if _, found := item.alt_tail[current]; !found {
+			item.alt_tail[current] = comment_text(comment)
+			return
+		}
+		item.leading = append(item.leading, comment)
+		return
+	}
+	if pos > alt_end && current+1 < len(alts) {
+		// Between two alternatives, so it is kept above the next one
+		item.alt_lead[current+1] = append(item.alt_lead[current+1], comment_text(comment))
+		return
+	}
+	item.leading = append(item.leading, comment)
+}
@@ -0,0 +1,343 @@
+package formats
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/VirajAgarwal1/lox/errorhandler"
+	"github.com/VirajAgarwal1/lox/grammar_file"
+	"github.com/VirajAgarwal1/lox/source"
+)
+
+/*
+ABNF is the notation of RFC 5234:
+
+	comma = equality *("," equality)
+	unary = ("!" / "-") unary / primary
+
+Alternatives are separated by `/`, repetitions are written before what they apply to (`*x` for `x*`, `1*x` for `x+`) and `[ ... ]` is optional.
+
+  - Rule names cannot contain `_`, it is written as `-` (and read back as `_`). Rule names are not case sensitive, a name is read as the rule it matches whatever its case.
+  - Quoted strings are not case sensitive either, so literals with letters (like the keywords) are written with their character codes: `%x74.72.75.65` for "true". Quoted strings are read as they are written.
+*/
+
+// To_abnf writes a grammar in ABNF
+func To_abnf(grammar *grammar_file.Grammar) []byte {
+	var out strings.Builder
+	lexer_tokens, declared := used_tokens(grammar)
+	if len(grammar.Skip) > 0 {
+		out.WriteString("; Skipped between the other tokens: " + strings.Join(skipped_tokens(grammar), " ") + "\n")
+	}
+	if len(declared) > 0 {
+		names := []string{}
+		for _, name := range declared {
+			names = append(names, abnf_name(name))
+		}
+		out.WriteString("; Tokens which are not defined here: " + strings.Join(names, " ") + "\n")
+	}
+	if out.Len() > 0 {
+		out.WriteString("\n")
+	}
+
+	for _, rule := range ordered_rules(grammar) {
+		out.WriteString(layout(abnf_name(rule.Name.Name)+" =", abnf_alternatives(rule.Terms, grammar), "/", ""))
+	}
+
+	written := false
+	for _, token := range lexer_tokens {
+		if definition := token_definitions[token].abnf; definition != "" {
+			if !written {
+				out.WriteString("\n; Tokens of the lexer, ALPHA DIGIT DQUOTE LF and WSP are the core rules of RFC 5234\n")
+				written = true
+			}
+			out.WriteString(grammar_file.Token_names[token] + " = " + definition + "\n")
+		}
+	}
+	return []byte(out.String())
+}
+
+func abnf_name(name string) string {
+	return strings.ReplaceAll(name, "_", "-")
+}
+
+func abnf_alternatives(terms []grammar_file.Generic_grammar_term, grammar *grammar_file.Grammar) []string {
+	alternatives := []string{}
+	for _, alternative := range split_alternatives(terms) {
+		parts := []string{}
+		for _, term := range alternative {
+			parts = append(parts, abnf_term(term, grammar))
+		}
+		if len(parts) == 0 {
+			parts = append(parts, `""`) // ABNF has no empty alternative, but an empty string
+		}
+		alternatives = append(alternatives, strings.Join(parts, " "))
+	}
+	return alternatives
+}
+
+// abnf_literal writes a literal as a quoted string if it has no letters, else with its character codes
+func abnf_literal(text string) string {
+	quoted := true
+	for _, char := range text {
+		if char < 0x20 || char > 0x7E || char == '"' || (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') {
+			quoted = false
+		}
+	}
+	if quoted {
+		return "\"" + text + "\""
+	}
+	codes := []string{}
+	for _, char := range text {
+		codes = append(codes, fmt.Sprintf("%02X", char))
+	}
+	return "%x" + strings.Join(codes, ".")
+}
+
+func abnf_term(term grammar_file.Generic_grammar_term, grammar *grammar_file.Grammar) string {
+	switch term := term.(type) {
+	case *grammar_file.Terminal:
+		rule, literal := terminal(string(term.Content), grammar)
+		if rule != "" {
+			return abnf_name(rule)
+		}
+		return abnf_literal(literal)
+	case *grammar_file.Non_terminal:
+		return abnf_name(term.Name)
+	case *grammar_file.Star:
+		return "*" + abnf_repeated(term.Content, grammar)
+	case *grammar_file.Plus:
+		return "1*" + abnf_repeated(term.Content, grammar)
+	case *grammar_file.Optional:
+		if bracket, is_bracket := term.Content.(*grammar_file.Bracket); is_bracket && !bracket.Is_left {
+			return "[" + strings.Join(abnf_alternatives(bracket.Contents, grammar), " / ") + "]"
+		}
+		return "[" + abnf_term(term.Content, grammar) + "]"
+	case *grammar_file.Bracket:
+		return "(" + strings.Join(abnf_alternatives(term.Contents, grammar), " / ") + ")"
+	case *grammar_file.Labelled:
+		return abnf_term(term.Content, grammar) // ABNF has no labels
+	}
+	return ""
+}
+
+// abnf_repeated writes what a repetition applies to, which cannot be a repetition itself
+func abnf_repeated(term grammar_file.Generic_grammar_term, grammar *grammar_file.Grammar) string {
+	switch term.(type) {
+	case *grammar_file.Star, *grammar_file.Plus:
+		return "(" + abnf_term(term, grammar) + ")"
+	}
+	return abnf_term(term, grammar)
+}
+
+var abnf_syntax = syntax{
+	line_comments: []string{";"},
+	symbols:       []string{"=/", "=", "/", "(", ")", "[", "]", "*"},
+	name_chars:    "-",
+	quotes:        `"`,
+	special: func(src string, i int) (token, int) {
+		switch {
+		case src[i] == '<':
+			end := strings.IndexByte(src[i:], '>')
+			if end < 0 {
+				return token{kind: token_end, text: "prose value is never closed"}, i + 1
+			}
+			return token{kind: token_other, text: src[i : i+end+1]}, i + end + 1
+		case src[i] != '%' || i+1 >= len(src):
+			return token{}, -1
+		}
+		switch src[i+1] {
+		case 's', 'S', 'i', 'I':
+			if i+2 < len(src) && src[i+2] == '"' {
+				text, end, err := scan_literal(src, i+2, false)
+				if err != "" {
+					return token{kind: token_end, text: err}, end
+				}
+				return token{kind: token_literal, text: text}, end
+			}
+		case 'x', 'X', 'd', 'D', 'b', 'B':
+			return scan_abnf_codes(src, i)
+		}
+		return token{kind: token_end, text: "expected 'x', 'd', 'b', 's' or 'i' after '%'"}, i + 1
+	},
+}
+
+// scan_abnf_codes reads a value like `%x74.72.75.65` as a literal, or a range like `%x30-39` as a token_other
+func scan_abnf_codes(src string, i int) (token, int) {
+	base := map[byte]int{'x': 16, 'd': 10, 'b': 2}[src[i+1]|0x20]
+	end := i + 2
+	for end < len(src) && (strings.IndexByte("0123456789abcdefABCDEF.-", src[end]) >= 0) {
+		end++
+	}
+	value := src[i+2 : end]
+	if strings.Contains(value, "-") {
+		return token{kind: token_other, text: src[i:end]}, end
+	}
+	var text strings.Builder
+	for _, code := range strings.Split(value, ".") {
+		char, err := strconv.ParseInt(code, base, 32)
+		if err != nil {
+			return token{kind: token_end, text: "invalid character code '" + code + "'"}, end
+		}
+		text.WriteRune(rune(char))
+	}
+	return token{kind: token_literal, text: text.String()}, end
+}
+
+// Read_abnf reads a grammar written in ABNF. The rules named like the tokens of the lexer (IDENTIFIER, NUMBER, ...), and the ones using value ranges or prose, are taken as the definitions of tokens and skipped.
+func Read_abnf(file *source.File) (*grammar_file.Grammar, *errorhandler.Diagnostics) {
+	return read(file, abnf_syntax, (*reader).read_abnf)
+}
+
+func (r *reader) read_abnf() error {
+	tokens := r.tokens
+	is_rule_start := func(offset int) bool {
+		next := r.peek_at(offset + 1)
+		return r.peek_at(offset).kind == token_name && next.kind == token_symbol && (next.text == "=" || next.text == "=/")
+	}
+
+	// Names are not case sensitive, every one is given the spelling of its first definition
+	spelling := map[string]string{}
+	for i := range tokens {
+		if r.at = i; is_rule_start(0) {
+			if _, found := spelling[strings.ToLower(tokens[i].text)]; !found {
+				spelling[strings.ToLower(tokens[i].text)] = strings.ReplaceAll(tokens[i].text, "-", "_")
+			}
+		}
+	}
+	for i := range tokens {
+		if tokens[i].kind != token_name {
+			continue
+		}
+		if name, found := spelling[strings.ToLower(tokens[i].text)]; found {
+			tokens[i].text = name
+		} else {
+			tokens[i].text = strings.ReplaceAll(tokens[i].text, "-", "_")
+		}
+	}
+	r.find_token_definitions(func() bool { return is_rule_start(0) })
+
+	var element func() (grammar_file.Generic_grammar_term, error)
+	element = func() (grammar_file.Generic_grammar_term, error) {
+		tok := r.peek()
+		if is_rule_start(0) {
+			return nil, nil
+		}
+		minimum, maximum, repeated := r.read_abnf_repeat()
+
+		var term grammar_file.Generic_grammar_term
+		inner := r.peek()
+		switch {
+		case inner.kind == token_name && !is_rule_start(0):
+			term = r.reference(r.next())
+		case inner.kind == token_literal:
+			if r.next(); inner.text == "" && !repeated {
+				return element() // `""` is how an empty alternative is written
+			}
+			term = r.literal(inner)
+		case r.is_symbol("("):
+			bracket, _, _, err := r.read_group("/", ")", element)
+			if err != nil {
+				return nil, err
+			}
+			term = bracket
+		case r.is_symbol("["):
+			bracket, start, end, err := r.read_group("/", "]", element)
+			if err != nil {
+				return nil, err
+			}
+			term = r.locate(&grammar_file.Optional{Content: abnf_unwrap(bracket)}, start, end)
+		default:
+			if repeated {
+				return nil, r.error_at(inner, "expected what the repetition applies to"+r.found())
+			}
+			return nil, nil
+		}
+
+		if !repeated {
+			return term, nil
+		}
+		end := r.tokens[r.at-1].end
+		switch {
+		case minimum == 0 && maximum < 0:
+			term = &grammar_file.Star{Content: term}
+		case minimum == 1 && maximum < 0:
+			term = &grammar_file.Plus{Content: term}
+		case minimum == 0 && maximum == 1:
+			term = &grammar_file.Optional{Content: term}
+		case minimum == 1 && maximum == 1:
+			return term, nil
+		default:
+			return nil, r.error_at(tok, "only the repetitions '*', '1*', '0*1' and '1' are supported")
+		}
+		return r.locate(term, tok.start, end), nil
+	}
+
+	order := []token{}
+	rules := map[string][]grammar_file.Generic_grammar_term{}
+	for r.peek().kind != token_end {
+		if !is_rule_start(0) {
+			return r.error_at(r.peek(), "expected a rule 'name = ...'"+r.found())
+		}
+		name := r.next()
+		incremental := r.next().text == "=/"
+		if !r.defined[name.text] {
+			for r.peek().kind != token_end && !is_rule_start(0) {
+				r.next()
+			}
+			continue
+		}
+		terms, err := r.read_alternatives("/", element)
+		if err != nil {
+			return err
+		}
+
+		existing, found := rules[name.text]
+		switch {
+		case incremental && !found:
+			return r.error_at(name, "'=/' adds alternatives to a rule which is not defined before it")
+		case found && !incremental:
+			return r.error_at(name, "rule '"+name.text+"' is defined more than once, use '=/' to add alternatives to it")
+		case incremental:
+			or := r.locate(&grammar_file.Or{}, name.start, name.end)
+			rules[name.text] = append(append(existing, or), terms...)
+		default:
+			order = append(order, name)
+			rules[name.text] = terms
+		}
+	}
+	for _, name := range order {
+		r.add_rule(name, rules[name.text])
+	}
+	return nil
+}
+
+// read_abnf_repeat reads the repetition before an element, like `*`, `1*` or `2*5`
+func (r *reader) read_abnf_repeat() (minimum int, maximum int, repeated bool) {
+	minimum, maximum = 1, 1
+	if r.peek().kind == token_number {
+		repeated = true
+		minimum, _ = strconv.Atoi(r.next().text)
+		maximum = minimum
+	}
+	if r.is_symbol("*") {
+		if !repeated {
+			minimum = 0
+		}
+		repeated = true
+		r.next()
+		maximum = -1
+		if r.peek().kind == token_number {
+			maximum, _ = strconv.Atoi(r.next().text)
+		}
+	}
+	return minimum, maximum, repeated
+}
+
+// abnf_unwrap gives what is inside `[ ... ]`: the single element written there, else the whole group
+func abnf_unwrap(bracket *grammar_file.Bracket) grammar_file.Generic_grammar_term {
+	if len(bracket.Contents) == 1 {
+		return bracket.Contents[0]
+	}
+	return bracket
+}
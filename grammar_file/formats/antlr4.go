@@ -0,0 +1,511 @@
+package formats
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/VirajAgarwal1/lox/errorhandler"
+	"github.com/VirajAgarwal1/lox/grammar_file"
+	"github.com/VirajAgarwal1/lox/lexer/dfa"
+	"github.com/VirajAgarwal1/lox/source"
+)
+
+/*
+ANTLR4 combined grammars (`.g4` files) hold the parser rules, whose names start with a lower case letter, and the lexer rules, whose names start with an upper case letter:
+
+	grammar Lox;
+
+	comma : equality ( ',' equality )* ;
+	unary : ( '!' | '-' ) unary | primary ;
+
+	IDENTIFIER : '_'? [a-zA-Z] [a-zA-Z0-9_]* ;
+	WHITESPACE : [ \t\u000B\f\r]+ -> skip ;
+
+  - Non-terminals which do not start with a lower case letter, or are keywords of ANTLR, are renamed: `Expr` is written `r_Expr`.
+  - The tokens declared with `%token` are written in a `tokens { ... }` block, `%skip` becomes `-> skip` on the lexer rules.
+  - Lexer rules are read as the tokens they define, only their `-> skip` (or `-> channel(...)`, which hides them from the parser as well) is kept.
+*/
+
+// Names which cannot be used for rules in ANTLR4
+var antlr4_keywords = []string{"catch", "finally", "fragment", "grammar", "import", "lexer", "locals", "mode", "options", "parser", "returns", "throws", "tokens"}
+
+// To_antlr4 writes a grammar as the ANTLR4 combined grammar `name`
+func To_antlr4(grammar *grammar_file.Grammar, name string) []byte {
+	var out strings.Builder
+	lexer_tokens, declared := used_tokens(grammar)
+	out.WriteString("grammar " + name + ";\n\n")
+	if len(declared) > 0 {
+		out.WriteString("tokens { " + strings.Join(declared, ", ") + " }\n\n")
+	}
+
+	for _, rule := range ordered_rules(grammar) {
+		out.WriteString(layout(antlr4_name(rule.Name.Name)+" :", antlr4_alternatives(rule.Terms, grammar), "|", " ;"))
+	}
+
+	skipped := skipped_tokens(grammar)
+	not_skipped := []string{}
+	for _, name := range skipped {
+		if _, found := token_rule_name(name); !found {
+			not_skipped = append(not_skipped, name)
+		}
+	}
+	if len(lexer_tokens) > 0 || len(not_skipped) > 0 {
+		out.WriteString("\n")
+	}
+	for _, token := range lexer_tokens {
+		definition := token_definitions[token].antlr4
+		if definition == "" {
+			continue // EOF is built in
+		}
+		token_name := grammar_file.Token_names[token]
+		if slices.Contains(skipped, token_name) {
+			definition += " -> skip"
+		}
+		out.WriteString(token_name + " : " + definition + " ;\n")
+	}
+	if len(not_skipped) > 0 {
+		out.WriteString("// Only lexer rules can be skipped, these tokens are not: " + strings.Join(not_skipped, " ") + "\n")
+	}
+	return []byte(out.String())
+}
+
+// antlr4_name gives the name of a parser rule for a non-terminal
+func antlr4_name(name string) string {
+	first := []rune(name)[0]
+	if !unicode.IsLower(first) || slices.Contains(antlr4_keywords, name) {
+		return "r_" + name
+	}
+	return name
+}
+
+// Antlr4_grammar_name turns a file name into the name of a grammar: `lox.grammar` gives `Lox`
+func Antlr4_grammar_name(file_name string) string {
+	name := []rune{}
+	for _, char := range strings.SplitN(file_name, ".", 2)[0] {
+		if unicode.IsLetter(char) || unicode.IsDigit(char) || char == '_' {
+			name = append(name, char)
+		}
+	}
+	if len(name) == 0 || !unicode.IsLetter(name[0]) {
+		name = append([]rune("G"), name...)
+	}
+	name[0] = unicode.ToUpper(name[0])
+	return string(name)
+}
+
+func antlr4_alternatives(terms []grammar_file.Generic_grammar_term, grammar *grammar_file.Grammar) []string {
+	alternatives := []string{}
+	for _, alternative := range split_alternatives(terms) {
+		parts := []string{}
+		for _, term := range alternative {
+			parts = append(parts, antlr4_term(term, grammar))
+		}
+		alternatives = append(alternatives, strings.Join(parts, " "))
+	}
+	return alternatives
+}
+
+func antlr4_literal(text string) string {
+	var out strings.Builder
+	out.WriteByte('\'')
+	for _, char := range text {
+		switch {
+		case char == '\'' || char == '\\':
+			out.WriteString("\\" + string(char))
+		case char == '\n':
+			out.WriteString("\\n")
+		case char == '\t':
+			out.WriteString("\\t")
+		case char == '\r':
+			out.WriteString("\\r")
+		case char < 0x20 || char == 0x7F:
+			out.WriteString(fmt.Sprintf("\\u%04X", char))
+		default:
+			out.WriteRune(char)
+		}
+	}
+	out.WriteByte('\'')
+	return out.String()
+}
+
+func antlr4_term(term grammar_file.Generic_grammar_term, grammar *grammar_file.Grammar) string {
+	switch term := term.(type) {
+	case *grammar_file.Terminal:
+		rule, literal := terminal(string(term.Content), grammar)
+		if rule != "" {
+			return rule
+		}
+		return antlr4_literal(literal)
+	case *grammar_file.Non_terminal:
+		return antlr4_name(term.Name)
+	case *grammar_file.Star:
+		return antlr4_term(term.Content, grammar) + "*"
+	case *grammar_file.Plus:
+		return antlr4_term(term.Content, grammar) + "+"
+	case *grammar_file.Optional:
+		return antlr4_term(term.Content, grammar) + "?"
+	case *grammar_file.Bracket:
+		return strings.TrimSpace("( " + strings.Join(antlr4_alternatives(term.Contents, grammar), " | ") + " )")
+	case *grammar_file.Labelled:
+		return antlr4_label(term, grammar)
+	}
+	return ""
+}
+
+// antlr4_label writes a label the way ANTLR allows it: `label=` on a token, a rule or a set of tokens, and `label+=` on a repeated one. ANTLR cannot label anything else, so the other labels are left out.
+func antlr4_label(term *grammar_file.Labelled, grammar *grammar_file.Grammar) string {
+	content := antlr4_term(term.Content, grammar)
+	if antlr4_can_label(term.Content) {
+		return term.Label + "=" + content
+	}
+	switch repeated := term.Content.(type) {
+	case *grammar_file.Star:
+		if antlr4_can_label(repeated.Content) {
+			return term.Label + "+=" + content
+		}
+	case *grammar_file.Plus:
+		if antlr4_can_label(repeated.Content) {
+			return term.Label + "+=" + content
+		}
+	case *grammar_file.Optional:
+		if antlr4_can_label(repeated.Content) {
+			return term.Label + "=" + content
+		}
+	}
+	return content
+}
+
+// antlr4_can_label tells if ANTLR accepts a label on a term: a token, a rule, or a block of alternatives which are single tokens
+func antlr4_can_label(term grammar_file.Generic_grammar_term) bool {
+	switch term := term.(type) {
+	case *grammar_file.Terminal, *grammar_file.Non_terminal:
+		return true
+	case *grammar_file.Bracket:
+		for i, content := range term.Contents {
+			if _, is_or := content.(*grammar_file.Or); is_or != (i%2 == 1) {
+				return false
+			}
+			if _, is_terminal := content.(*grammar_file.Terminal); i%2 == 0 && !is_terminal {
+				return false
+			}
+		}
+		return len(term.Contents)%2 == 1
+	}
+	return false
+}
+
+var antlr4_syntax = syntax{
+	line_comments:  []string{"//"},
+	block_comments: true,
+	symbols:        []string{"->", "+=", "::", "*?", "+?", "??", ":", ";", "|", "(", ")", "?", "*", "+", "~", ".", "=", "#", ",", "@", "<", ">"},
+	name_chars:     "_",
+	quotes:         "'",
+	escapes:        true,
+	special: func(src string, i int) (token, int) {
+		switch src[i] {
+		case '[': // Character set of a lexer rule
+			for end := i + 1; end < len(src); end++ {
+				switch src[end] {
+				case '\\':
+					end++
+				case ']':
+					return token{kind: token_other, text: src[i : end+1]}, end + 1
+				}
+			}
+			return token{kind: token_end, text: "character set is never closed"}, i + 1
+		case '{': // Action, or the contents of `tokens { ... }` and `options { ... }`
+			depth := 0
+			for end := i; end < len(src); end++ {
+				switch src[end] {
+				case '{':
+					depth++
+				case '}':
+					if depth--; depth == 0 {
+						return token{kind: token_other, text: src[i : end+1]}, end + 1
+					}
+				}
+			}
+			return token{kind: token_end, text: "'{' is never closed"}, i + 1
+		}
+		return token{}, -1
+	},
+}
+
+func is_parser_rule_name(name string) bool {
+	return unicode.IsLower([]rune(name)[0])
+}
+
+// Read_antlr4 reads the parser rules of an ANTLR4 grammar. Lexer rules only give the tokens which are skipped.
+//
+// Labels (`x=` and `x+=`) are read as labels of the grammar file. Actions, alternative labels, rule arguments and the other constructs which only matter to the code ANTLR generates are dropped with a G0010 warning. Semantic predicates, `~` and `.` are G0009 errors: the grammar model has no way to write them.
+func Read_antlr4(file *source.File) (*grammar_file.Grammar, *errorhandler.Diagnostics) {
+	return read(file, antlr4_syntax, (*reader).read_antlr4)
+}
+
+func (r *reader) read_antlr4() error {
+	is_rule_start := func() bool {
+		return r.peek().kind == token_name && r.antlr4_rule_header() >= 0
+	}
+	for i := range r.tokens {
+		if r.at = i; is_rule_start() && is_parser_rule_name(r.tokens[i].text) {
+			r.defined[r.tokens[i].text] = true
+		}
+	}
+	r.at = 0
+
+	var element func() (grammar_file.Generic_grammar_term, error)
+	element = func() (grammar_file.Generic_grammar_term, error) {
+		tok := r.peek()
+		var term grammar_file.Generic_grammar_term
+		switch {
+		case is_rule_start():
+			return nil, nil // The `;` of the rule before is missing
+		case tok.kind == token_name && (r.peek_at(1).text == "=" || r.peek_at(1).text == "+=") && r.peek_at(1).kind == token_symbol:
+			r.next()
+			r.next()
+			content, err := element()
+			if err != nil {
+				return nil, err
+			}
+			if content == nil {
+				return nil, r.error_at(r.peek(), "label '"+tok.text+"' must be followed by an element"+r.found())
+			}
+			return r.locate(&grammar_file.Labelled{Label: tok.text, Content: content}, tok.start, r.tokens[r.at-1].end), nil
+		case tok.kind == token_name:
+			term = r.reference(r.next())
+			if arguments := r.peek(); arguments.kind == token_other && strings.HasPrefix(arguments.text, "[") {
+				r.dropped(r.next().start, arguments.end, "arguments given to '"+tok.text+"' are dropped")
+			}
+		case tok.kind == token_literal:
+			term = r.literal(r.next())
+		case r.is_symbol("("):
+			bracket, _, _, err := r.read_group("|", ")", element)
+			if err != nil {
+				return nil, err
+			}
+			term = bracket
+		case tok.kind == token_other && strings.HasPrefix(tok.text, "{"):
+			r.next()
+			if r.is_symbol("?") {
+				r.unsupported(tok.start, r.next().end, "semantic predicates are not supported", "the alternatives must be told apart by their tokens alone")
+			} else {
+				r.dropped(tok.start, tok.end, "action is dropped")
+			}
+			return element()
+		case r.is_symbol("#"):
+			r.next()
+			end := tok.end
+			if r.peek().kind == token_name {
+				end = r.next().end
+			}
+			r.dropped(tok.start, end, "alternative label is dropped")
+			return element()
+		case r.is_symbol("<"):
+			for r.next(); !r.is_symbol(">") && r.peek().kind != token_end; {
+				r.next()
+			}
+			r.dropped(tok.start, r.next().end, "element options are dropped")
+			return element()
+		case r.is_symbol("~"):
+			r.next()
+			if _, err := element(); err != nil {
+				return nil, err
+			}
+			r.unsupported(tok.start, r.tokens[r.at-1].end, "'~' (not) is not supported", "list the tokens which are allowed instead")
+			return element()
+		case r.is_symbol("."):
+			for r.next(); r.is_symbol("*") || r.is_symbol("+") || r.is_symbol("?") || r.is_symbol("*?") || r.is_symbol("+?") || r.is_symbol("??"); {
+				r.next()
+			}
+			r.unsupported(tok.start, r.tokens[r.at-1].end, "the wildcard '.' is not supported", "list the tokens which are allowed instead")
+			return element()
+		case r.is_symbol(";"), r.is_symbol(")"), r.is_symbol("|"), tok.kind == token_end:
+			return nil, nil
+		default:
+			return nil, r.error_at(tok, "'"+tok.text+"' is not supported in parser rules")
+		}
+		term = r.postfix(term, tok.start)
+		for r.is_symbol("*?") || r.is_symbol("+?") || r.is_symbol("??") {
+			operator := r.next()
+			r.dropped(operator.end-1, operator.end, "'"+operator.text+"' is not greedy, it is read as '"+operator.text[:1]+"'")
+			term = r.postfix(r.locate(postfix_term(operator.text[:1], term), tok.start, operator.end), tok.start)
+		}
+		return term, nil
+	}
+
+	if err := r.read_antlr4_header(); err != nil {
+		return err
+	}
+	for r.peek().kind != token_end {
+		if found, err := r.read_antlr4_prequel(); err != nil {
+			return err
+		} else if found {
+			continue
+		}
+		if r.peek().kind == token_name && r.peek().text == "fragment" {
+			r.next()
+		}
+		if !is_rule_start() {
+			return r.error_at(r.peek(), "expected a rule 'name : ... ;'"+r.found())
+		}
+		header := r.antlr4_rule_header()
+		name := r.next()
+		if !is_parser_rule_name(name.text) {
+			if err := r.skip_antlr4_lexer_rule(name); err != nil {
+				return err
+			}
+			continue
+		}
+		r.drop_antlr4_rule_header(name, header)
+		terms, err := r.read_alternatives("|", element)
+		if err != nil {
+			return err
+		}
+		if _, err := r.expect_symbol(";"); err != nil {
+			return err
+		}
+		r.add_rule(name, terms)
+		r.drop_antlr4_exception_handlers()
+	}
+	return nil
+}
+
+// read_antlr4_header reads `grammar Name;`
+func (r *reader) read_antlr4_header() error {
+	if r.peek().kind == token_name && (r.peek().text == "parser" || r.peek().text == "lexer") {
+		r.next()
+	}
+	if r.peek().kind != token_name || r.peek().text != "grammar" {
+		return r.error_at(r.peek(), "expected 'grammar Name;'"+r.found())
+	}
+	r.next()
+	if r.peek().kind != token_name {
+		return r.error_at(r.peek(), "expected the name of the grammar"+r.found())
+	}
+	r.next()
+	_, err := r.expect_symbol(";")
+	return err
+}
+
+// read_antlr4_prequel reads what can come between the rules other than rules: the `tokens { ... }`, `options { ... }` and `channels { ... }` blocks, named actions like `@header { ... }`, `import` and `mode`. It returns false if the current token starts none of them.
+func (r *reader) read_antlr4_prequel() (bool, error) {
+	tok := r.peek()
+	switch {
+	case tok.kind == token_name && r.peek_at(1).kind == token_other && strings.HasPrefix(r.peek_at(1).text, "{"):
+		r.next()
+		contents := r.next()
+		switch tok.text {
+		case "tokens":
+			for _, name := range strings.Split(strings.Trim(contents.text, "{}"), ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					r.declare_token(name)
+				}
+			}
+		case "options", "channels":
+		default:
+			return true, r.error_at(tok, "unknown block '"+tok.text+"'")
+		}
+	case r.is_symbol("@"):
+		for r.peek().kind != token_other && r.peek().kind != token_end {
+			r.next()
+		}
+		r.dropped(tok.start, r.next().end, "named action is dropped")
+	case tok.kind == token_name && (tok.text == "import" || tok.text == "mode") && r.peek_at(1).kind == token_name:
+		for !r.is_symbol(";") && r.peek().kind != token_end {
+			r.next()
+		}
+		end := r.next().end
+		if tok.text == "import" {
+			r.unsupported(tok.start, end, "imported grammars are not read", "add the rules of the imported grammars to this file")
+		}
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// antlr4_rule_header gives the number of tokens from the current name to the `:` of its rule, -1 if the name does not start a rule. Parser rules can have arguments, `returns`, `locals`, `throws`, `options` and named actions before their `:`.
+func (r *reader) antlr4_rule_header() int {
+	offset := 1
+	is_block := func(open string) bool {
+		return r.peek_at(offset).kind == token_other && strings.HasPrefix(r.peek_at(offset).text, open)
+	}
+	for {
+		tok := r.peek_at(offset)
+		switch {
+		case tok.kind == token_symbol && tok.text == ":":
+			return offset
+		case is_block("["):
+			offset++
+		case tok.kind == token_name && (tok.text == "returns" || tok.text == "locals" || tok.text == "options"):
+			offset++
+			if !is_block("[") && !is_block("{") {
+				return -1
+			}
+			offset++
+		case tok.kind == token_name && tok.text == "throws":
+			for offset++; r.peek_at(offset).kind == token_name || r.peek_at(offset).text == ","; offset++ {
+			}
+		case tok.kind == token_symbol && tok.text == "@":
+			for offset++; r.peek_at(offset).kind == token_name || r.peek_at(offset).text == "::"; offset++ {
+			}
+			if !is_block("{") {
+				return -1
+			}
+			offset++
+		default:
+			return -1
+		}
+	}
+}
+
+// drop_antlr4_rule_header goes over the `length` tokens following the name of a parser rule up to its `:`, warning about what is dropped
+func (r *reader) drop_antlr4_rule_header(name token, length int) {
+	if length > 1 {
+		start := r.peek().start
+		r.at += length - 1
+		r.dropped(start, r.tokens[r.at-1].end, "arguments, return values and options of '"+name.text+"' are dropped")
+	}
+	r.next()
+}
+
+// drop_antlr4_exception_handlers goes over the `catch [...] { ... }` and `finally { ... }` following a parser rule
+func (r *reader) drop_antlr4_exception_handlers() {
+	for r.peek().kind == token_name && (r.peek().text == "catch" || r.peek().text == "finally") && r.peek_at(1).kind == token_other {
+		tok := r.next()
+		for r.peek().kind == token_other {
+			r.next()
+		}
+		r.dropped(tok.start, r.tokens[r.at-1].end, "exception handler is dropped")
+	}
+}
+
+// skip_antlr4_lexer_rule reads the definition of a lexer rule, and keeps its name if it is skipped or sent to another channel than the parser's
+func (r *reader) skip_antlr4_lexer_rule(name token) error {
+	if _, err := r.expect_symbol(":"); err != nil {
+		return err
+	}
+	for !r.is_symbol(";") {
+		if r.peek().kind == token_end {
+			return r.error_at(name, "lexer rule '"+name.text+"' does not end with ';'")
+		}
+		if r.is_symbol("->") && r.peek_at(1).kind == token_name && (r.peek_at(1).text == "skip" || r.peek_at(1).text == "channel") {
+			skipped := name.text
+			if token, found := token_rule_name(skipped); found {
+				if _, is_alias := grammar_file.Token_aliases[skipped]; !is_alias {
+					skipped = string(token)
+				}
+			} else if token, found := token_by_name(skipped); found && token != dfa.EOF {
+				skipped = string(token)
+			} else {
+				r.declare(skipped) // So that the grammar read is valid, though the lexer never gives this token
+			}
+			r.grammar.Skip = append(r.grammar.Skip, skipped)
+		}
+		r.next()
+	}
+	r.next()
+	return nil
+}
@@ -0,0 +1,687 @@
+/*
+Package formats translates grammars between the notation of grammar files and the notations other grammar tools read, so that our grammars can be checked with them:
+
+  - W3C EBNF, the notation of the XML specification (`w3c_ebnf.go`)
+  - ABNF, as defined by RFC 5234 (`abnf.go`)
+  - ANTLR4 combined grammars, `.g4` files (`antlr4.go`)
+  - yacc and bison grammars, `.y` files, which can only be read (`yacc.go`)
+
+Every format has a writer (`To_...`) and a reader (`Read_...`) which reads back what the writer wrote, and the common subset of the notation written by hand. The readers are also how grammars written for these tools are imported: they return the grammar read with diagnostics, G0009 errors for the constructs which would change its language (like semantic predicates) and G0010 warnings for the ones which are dropped (like actions).
+
+Terminals of our grammars are token types of the lexer. The ones standing for a fixed text (like "(" or "while") are written as literals, the others (IDENTIFIER, NUMBER, STRING, ...) as references to token rules, whose definitions follow the parser rules. Names which a file uses but does not define are read as tokens declared with `%token`, and literals which are not tokens of the lexer are declared too.
+
+The start symbol is written as the first rule, and is the first rule when reading. `%skip` can only be written in ANTLR4, as `-> skip` lexer rules, the other formats list the skipped tokens in a comment.
+*/
+package formats
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/VirajAgarwal1/lox/errorhandler"
+	"github.com/VirajAgarwal1/lox/grammar_file"
+	"github.com/VirajAgarwal1/lox/lexer/dfa"
+	"github.com/VirajAgarwal1/lox/source"
+)
+
+// Tokens of the lexer which are not a fixed text, so other notations need a rule for them
+var token_rules = []dfa.TokenType{dfa.IDENTIFIER, dfa.NUMBER, dfa.STRING, dfa.COMMENT, dfa.WHITESPACE, dfa.NEWLINE, dfa.EOF}
+
+// token_definition is how a token of `token_rules` is defined in each format, empty if the format has it built in
+type token_definition struct {
+	w3c_ebnf string
+	abnf     string
+	antlr4   string
+}
+
+// Definitions of what the DFAs of `lexer/dfa` accept
+var token_definitions = map[dfa.TokenType]token_definition{
+	dfa.IDENTIFIER: {
+		w3c_ebnf: `"_"? [a-zA-Z] [a-zA-Z0-9_]*`,
+		abnf:     `["_"] ALPHA *(ALPHA / DIGIT / "_")`,
+		antlr4:   `'_'? [a-zA-Z] [a-zA-Z0-9_]*`,
+	},
+	dfa.NUMBER: {
+		w3c_ebnf: `[0-9]+ ( "." [0-9]+ )?`,
+		abnf:     `1*DIGIT [ "." 1*DIGIT ]`,
+		antlr4:   `[0-9]+ ( '.' [0-9]+ )?`,
+	},
+	dfa.STRING: {
+		w3c_ebnf: `'"' [^"]* '"'`,
+		abnf:     `DQUOTE *(%x00-21 / %x23-10FFFF) DQUOTE`,
+		antlr4:   `'"' ~'"'* '"'`,
+	},
+	dfa.COMMENT: {
+		w3c_ebnf: `"//" [^#xA]*`,
+		abnf:     `"//" *(%x00-09 / %x0B-10FFFF)`,
+		antlr4:   `'//' ~'\n'*`,
+	},
+	dfa.WHITESPACE: {
+		w3c_ebnf: `[#x9#xB#xC#xD#x20]+`,
+		abnf:     `1*(WSP / %x0B-0D)`,
+		antlr4:   `[ \t\u000B\f\r]+`,
+	},
+	dfa.NEWLINE: {
+		w3c_ebnf: `#xA`,
+		abnf:     `LF`,
+		antlr4:   `'\n'`,
+	},
+	dfa.EOF: {},
+}
+
+// token_rule_name gives the token type of a rule name like IDENTIFIER, if it is one of `token_rules`
+func token_rule_name(name string) (dfa.TokenType, bool) {
+	for _, token := range token_rules {
+		if grammar_file.Token_names[token] == name {
+			return token, true
+		}
+	}
+	return "", false
+}
+
+// terminal tells how a terminal of a grammar is written: as a reference to the token rule `rule`, or else as the literal `literal`
+func terminal(name string, grammar *grammar_file.Grammar) (rule string, literal string) {
+	if token, found := grammar_file.Lookup_token(name); found {
+		if slices.Contains(token_rules, token) {
+			return grammar_file.Token_names[token], ""
+		}
+		return "", string(token)
+	}
+	if slices.Contains(grammar.Tokens, name) && is_name(name) {
+		return name, ""
+	}
+	return "", name
+}
+
+func is_name(text string) bool {
+	for i, char := range text {
+		if !(unicode.IsLetter(char) || char == '_' || (i > 0 && unicode.IsDigit(char))) {
+			return false
+		}
+	}
+	return text != ""
+}
+
+// used_tokens gives the token rules used by the rules of a grammar or given to `%skip`: the ones of the lexer (in the order of `token_rules`) and the declared ones (in the order of `%token`)
+func used_tokens(grammar *grammar_file.Grammar) (lexer_tokens []dfa.TokenType, declared []string) {
+	used := map[string]bool{}
+	var collect func(terms []grammar_file.Generic_grammar_term)
+	collect = func(terms []grammar_file.Generic_grammar_term) {
+		for _, term := range terms {
+			switch term := term.(type) {
+			case *grammar_file.Terminal:
+				if rule, _ := terminal(string(term.Content), grammar); rule != "" {
+					used[rule] = true
+				}
+			case *grammar_file.Star:
+				collect([]grammar_file.Generic_grammar_term{term.Content})
+			case *grammar_file.Plus:
+				collect([]grammar_file.Generic_grammar_term{term.Content})
+			case *grammar_file.Optional:
+				collect([]grammar_file.Generic_grammar_term{term.Content})
+			case *grammar_file.Labelled:
+				collect([]grammar_file.Generic_grammar_term{term.Content})
+			case *grammar_file.Bracket:
+				collect(term.Contents)
+			}
+		}
+	}
+	for _, rule := range grammar.Ordered_rules() {
+		collect(rule.Terms)
+	}
+	for _, name := range grammar.Skip {
+		if rule, _ := terminal(name, grammar); rule != "" {
+			used[rule] = true
+		}
+	}
+
+	for _, token := range token_rules {
+		if used[grammar_file.Token_names[token]] {
+			lexer_tokens = append(lexer_tokens, token)
+		}
+	}
+	for _, name := range grammar.Tokens {
+		if rule, _ := terminal(name, grammar); rule == name && !slices.Contains(declared, name) {
+			declared = append(declared, name)
+		}
+	}
+	return lexer_tokens, declared
+}
+
+// skipped_tokens gives the names of the token rules given to `%skip`
+func skipped_tokens(grammar *grammar_file.Grammar) []string {
+	names := []string{}
+	for _, name := range grammar.Skip {
+		if rule, literal := terminal(name, grammar); rule != "" {
+			names = append(names, rule)
+		} else {
+			names = append(names, literal)
+		}
+	}
+	return names
+}
+
+// ordered_rules gives the rules with the start symbol first, which is how the other notations tell which rule is the start
+func ordered_rules(grammar *grammar_file.Grammar) []grammar_file.Rule {
+	rules := grammar.Ordered_rules()
+	for i, rule := range rules {
+		if rule.Name.Name == grammar.Start && i > 0 {
+			return append(append([]grammar_file.Rule{rule}, rules[:i]...), rules[i+1:]...)
+		}
+	}
+	return rules
+}
+
+// split_alternatives splits terms at their top-level `or`s. The actions are left out, the Go code in them means nothing to the other tools.
+func split_alternatives(terms []grammar_file.Generic_grammar_term) [][]grammar_file.Generic_grammar_term {
+	alternatives := [][]grammar_file.Generic_grammar_term{{}}
+	for _, term := range grammar_file.Without_actions(terms) {
+		if term.Get_grammar_term_type() == "or" {
+			alternatives = append(alternatives, []grammar_file.Generic_grammar_term{})
+			continue
+		}
+		alternatives[len(alternatives)-1] = append(alternatives[len(alternatives)-1], term)
+	}
+	return alternatives
+}
+
+// layout writes a rule on one line, or with one alternative per line, `or` under the end of `head`, if it is longer than `grammar_file.Format_line_width`
+func layout(head string, alternatives []string, or string, end string) string {
+	pieces := []string{strings.TrimRight(head+" "+alternatives[0], " ")}
+	for _, alternative := range alternatives[1:] {
+		pieces = append(pieces, strings.TrimRight(or+" "+alternative, " "))
+	}
+	line := strings.Join(pieces, " ") + end
+	if utf8.RuneCountInString(line) <= grammar_file.Format_line_width || len(alternatives) == 1 {
+		return line + "\n"
+	}
+	indent := strings.Repeat(" ", max(0, utf8.RuneCountInString(head)-utf8.RuneCountInString(or)))
+	lines := []string{pieces[0]}
+	for _, piece := range pieces[1:] {
+		lines = append(lines, indent+piece)
+	}
+	lines[len(lines)-1] += end
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// ---------------------------------------------------------------------------------
+// Reading
+// ---------------------------------------------------------------------------------
+
+type token_kind int
+
+const (
+	token_name    token_kind = iota
+	token_literal            // `text` is the text of the literal, without quotes and escapes
+	token_symbol             // Punctuation of the notation, like "::=" or "|"
+	token_number             // Repetition counts of ABNF
+	token_other              // Things only used by token rules, like character classes, which are skipped
+	token_end
+)
+
+type token struct {
+	kind  token_kind
+	text  string
+	start int // Byte offsets in the file
+	end   int
+}
+
+// syntax is what the scanner of a format needs to know about it
+type syntax struct {
+	line_comments  []string
+	block_comments bool     // `/* ... */`
+	symbols        []string // Longest first
+	name_chars     string   // Characters which may follow the first letter of a name, besides letters and digits
+	quotes         string   // Characters starting a literal
+	escapes        bool     // Backslash escapes in literals, like '\n'
+	// special reads the tokens specific to a format at `src[i:]`, like `%x41` in ABNF. It returns the end of the token, or -1 if there is none there.
+	special func(src string, i int) (token, int)
+}
+
+// scan splits a file into tokens, dropping whitespace and comments
+func scan(file *source.File, syntax syntax) ([]token, error) {
+	src := file.Slice(file.Pos(0), file.Pos(file.Size()))
+	tokens := []token{}
+	scan_error := func(start int, end int, message string) error {
+		return syntax_error(file, start, end, message)
+	}
+
+	for i := 0; i < len(src); {
+		char, size := utf8.DecodeRuneInString(src[i:])
+		if unicode.IsSpace(char) {
+			i += size
+			continue
+		}
+		if comment := prefix_in(src[i:], syntax.line_comments); comment != "" {
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				end = len(src) - i
+			}
+			i += end
+			continue
+		}
+		if syntax.block_comments && strings.HasPrefix(src[i:], "/*") {
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, scan_error(i, i+2, "comment is never closed")
+			}
+			i += end + 4
+			continue
+		}
+		if syntax.special != nil {
+			if tok, end := syntax.special(src, i); end >= 0 {
+				if tok.kind == token_end {
+					return nil, scan_error(i, end, tok.text)
+				}
+				tok.start, tok.end = i, end
+				tokens = append(tokens, tok)
+				i = end
+				continue
+			}
+		}
+		if strings.ContainsRune(syntax.quotes, char) {
+			text, end, err := scan_literal(src, i, syntax.escapes)
+			if err != "" {
+				return nil, scan_error(i, end, err)
+			}
+			tokens = append(tokens, token{kind: token_literal, text: text, start: i, end: end})
+			i = end
+			continue
+		}
+		if unicode.IsLetter(char) || (char == '_' && strings.ContainsRune(syntax.name_chars, '_')) {
+			end := i + size
+			for end < len(src) {
+				next, next_size := utf8.DecodeRuneInString(src[end:])
+				if !(unicode.IsLetter(next) || unicode.IsDigit(next) || strings.ContainsRune(syntax.name_chars, next)) {
+					break
+				}
+				end += next_size
+			}
+			tokens = append(tokens, token{kind: token_name, text: src[i:end], start: i, end: end})
+			i = end
+			continue
+		}
+		if unicode.IsDigit(char) {
+			end := i
+			for end < len(src) && src[end] >= '0' && src[end] <= '9' {
+				end++
+			}
+			tokens = append(tokens, token{kind: token_number, text: src[i:end], start: i, end: end})
+			i = end
+			continue
+		}
+		if symbol := prefix_in(src[i:], syntax.symbols); symbol != "" {
+			tokens = append(tokens, token{kind: token_symbol, text: symbol, start: i, end: i + len(symbol)})
+			i += len(symbol)
+			continue
+		}
+		return nil, scan_error(i, i+size, "unexpected character '"+string(char)+"'")
+	}
+	return append(tokens, token{kind: token_end, start: len(src), end: len(src)}), nil
+}
+
+func prefix_in(text string, prefixes []string) string {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(text, prefix) {
+			return prefix
+		}
+	}
+	return ""
+}
+
+// scan_literal reads the literal starting with the quote at `src[start]`, and returns its text and end. The error is "" if it is valid.
+func scan_literal(src string, start int, escapes bool) (string, int, string) {
+	quote := src[start]
+	var text strings.Builder
+	for i := start + 1; i < len(src); i++ {
+		switch {
+		case src[i] == quote:
+			return text.String(), i + 1, ""
+		case src[i] == '\n':
+			return "", i, "literal is not closed on its line"
+		case src[i] == '\\' && escapes && i+1 < len(src):
+			i++
+			switch src[i] {
+			case 'n':
+				text.WriteByte('\n')
+			case 't':
+				text.WriteByte('\t')
+			case 'r':
+				text.WriteByte('\r')
+			case 'f':
+				text.WriteByte('\f')
+			case 'u':
+				var code rune
+				if i+4 >= len(src) || !parse_hex(src[i+1:i+5], &code) {
+					return "", i + 1, "'\\u' must be followed by 4 hexadecimal digits"
+				}
+				text.WriteRune(code)
+				i += 4
+			default:
+				text.WriteByte(src[i])
+			}
+		default:
+			text.WriteByte(src[i])
+		}
+	}
+	return "", len(src), "literal is never closed"
+}
+
+func parse_hex(digits string, value *rune) bool {
+	*value = 0
+	for _, digit := range digits {
+		switch {
+		case digit >= '0' && digit <= '9':
+			*value = *value*16 + digit - '0'
+		case digit >= 'a' && digit <= 'f':
+			*value = *value*16 + digit - 'a' + 10
+		case digit >= 'A' && digit <= 'F':
+			*value = *value*16 + digit - 'A' + 10
+		default:
+			return false
+		}
+	}
+	return digits != ""
+}
+
+func syntax_error(file *source.File, start int, end int, message string) error {
+	return &errorhandler.GrammarError{
+		Code:     errorhandler.CodeGrammarSyntax,
+		Pos:      file.Pos(start),
+		End:      file.Pos(end),
+		Position: file.Position(file.Pos(start)),
+		Message:  message,
+	}
+}
+
+// reader is the state shared by the readers of all formats: the tokens of the file and the grammar being built from them
+type reader struct {
+	file        *source.File
+	tokens      []token
+	at          int
+	grammar     *grammar_file.Grammar
+	defined     map[string]bool // Names of the parser rules of the file
+	diagnostics *errorhandler.Diagnostics
+}
+
+// read scans a file and runs `read_rules` over its tokens. A syntax error stops the reading, constructs which the grammar model has no place for are reported along the way (see `unsupported` and `dropped`). Use `Err()` on the diagnostics to know if the grammar can be used.
+func read(file *source.File, syntax syntax, read_rules func(r *reader) error) (*grammar_file.Grammar, *errorhandler.Diagnostics) {
+	r := &reader{file: file, grammar: grammar_file.New_grammar(file), defined: map[string]bool{}, diagnostics: errorhandler.NewDiagnostics(0)}
+	tokens, err := scan(file, syntax)
+	if err == nil {
+		r.tokens = tokens
+		err = read_rules(r)
+	}
+	if err == nil && len(r.grammar.Order) == 0 {
+		err = syntax_error(file, 0, 0, "the file has no parser rules")
+	}
+	r.diagnostics.AddError(err)
+	r.add_directives()
+	return r.grammar, r.diagnostics
+}
+
+// unsupported reports a construct which changes the language of the grammar, so the grammar read cannot be used
+func (r *reader) unsupported(start int, end int, message string, help string) {
+	diag := errorhandler.NewDiagnostic(errorhandler.CodeUnsupportedConstruct, message, r.span(start, end), "not supported")
+	diag.Position = r.file.Position(r.file.Pos(start))
+	if help != "" {
+		diag.WithHelp(help)
+	}
+	r.diagnostics.Add(diag)
+}
+
+// dropped warns about a construct which is left out of the grammar read, without changing its language
+func (r *reader) dropped(start int, end int, message string) *errorhandler.Diagnostic {
+	diag := errorhandler.NewWarning(errorhandler.CodeDroppedConstruct, message, r.span(start, end), "dropped")
+	diag.Position = r.file.Position(r.file.Pos(start))
+	r.diagnostics.Add(diag)
+	return diag
+}
+
+// add_directives gives the grammar read the directives which tell what it declares, so that `grammar_file.Format` writes them
+func (r *reader) add_directives() {
+	quoted := func(names []string) []string {
+		args := []string{}
+		for _, name := range names {
+			rule, literal := terminal(name, r.grammar)
+			switch {
+			case rule != "":
+				args = append(args, rule)
+			case is_name(literal):
+				args = append(args, literal)
+			default:
+				args = append(args, "\""+literal+"\"")
+			}
+		}
+		return args
+	}
+	if len(r.grammar.Order) > 0 && r.grammar.Start != r.grammar.Order[0].Name {
+		r.grammar.Directives = append(r.grammar.Directives, grammar_file.Directive{Name: "start", Args: []string{r.grammar.Start}})
+	}
+	if len(r.grammar.Tokens) > 0 {
+		r.grammar.Directives = append(r.grammar.Directives, grammar_file.Directive{Name: "token", Args: quoted(r.grammar.Tokens)})
+	}
+	if len(r.grammar.Skip) > 0 {
+		r.grammar.Directives = append(r.grammar.Directives, grammar_file.Directive{Name: "skip", Args: quoted(r.grammar.Skip)})
+	}
+}
+
+func (r *reader) peek() token {
+	return r.tokens[r.at]
+}
+func (r *reader) peek_at(offset int) token {
+	return r.tokens[min(r.at+offset, len(r.tokens)-1)]
+}
+func (r *reader) next() token {
+	tok := r.tokens[r.at]
+	if tok.kind != token_end {
+		r.at++
+	}
+	return tok
+}
+func (r *reader) is_symbol(text string) bool {
+	return r.peek().kind == token_symbol && r.peek().text == text
+}
+func (r *reader) expect_symbol(text string) (token, error) {
+	if !r.is_symbol(text) {
+		return token{}, r.error_at(r.peek(), "expected '"+text+"'"+r.found())
+	}
+	return r.next(), nil
+}
+
+// found describes the current token, for the end of error messages
+func (r *reader) found() string {
+	tok := r.peek()
+	if tok.kind == token_end {
+		return " at the end of the file"
+	}
+	return ", found '" + r.file.Slice(r.file.Pos(tok.start), r.file.Pos(tok.end)) + "'"
+}
+
+func (r *reader) error_at(tok token, message string) error {
+	return syntax_error(r.file, tok.start, tok.end, message)
+}
+
+func (r *reader) span(start int, end int) errorhandler.Span {
+	return errorhandler.NewSpan(r.file.Pos(start), r.file.Pos(end))
+}
+
+// locate records where a term was written, and returns it
+func (r *reader) locate(term grammar_file.Generic_grammar_term, start int, end int) grammar_file.Generic_grammar_term {
+	r.grammar.Locations[term] = r.span(start, end)
+	return term
+}
+
+// reference gives the term for a name used in a rule: a non-terminal if the file defines it, else a terminal
+func (r *reader) reference(tok token) grammar_file.Generic_grammar_term {
+	if r.defined[tok.text] {
+		return r.locate(&grammar_file.Non_terminal{Name: tok.text}, tok.start, tok.end)
+	}
+	return r.token_reference(tok)
+}
+
+// token_reference gives the terminal for the name of a token rule, declaring it if the lexer does not know it
+func (r *reader) token_reference(tok token) grammar_file.Generic_grammar_term {
+	name := tok.text
+	if token, found := token_rule_name(name); found {
+		if _, is_alias := grammar_file.Token_aliases[name]; !is_alias {
+			name = string(token)
+		}
+	} else if token, found := token_by_name(name); found {
+		name = string(token) // Like PLUS for "+"
+	} else {
+		r.declare(name)
+	}
+	return r.locate(&grammar_file.Terminal{Content: []rune(name)}, tok.start, tok.end)
+}
+
+// literal gives the terminal for a literal, declaring it if it is not a token of the lexer
+func (r *reader) literal(tok token) grammar_file.Generic_grammar_term {
+	if token, found := grammar_file.Lookup_token(tok.text); found && string(token) == tok.text && slices.Contains(token_rules, token) {
+		// The text of a token which has a rule, like "\n" for NEWLINE
+		return r.locate(&grammar_file.Terminal{Content: []rune(grammar_file.Token_names[token])}, tok.start, tok.end)
+	}
+	if !is_lexer_literal(tok.text) {
+		r.declare(tok.text)
+	}
+	return r.locate(&grammar_file.Terminal{Content: []rune(tok.text)}, tok.start, tok.end)
+}
+
+// declare_token declares a token named by the file (like in `tokens { ... }` or `%token`), unless the lexer knows it
+func (r *reader) declare_token(name string) {
+	if _, found := token_rule_name(name); found {
+		return
+	}
+	if _, found := token_by_name(name); found {
+		return
+	}
+	r.declare(name)
+}
+
+// is_lexer_literal tells if a text is a token of the lexer standing for a fixed text, like "(" or "while"
+func is_lexer_literal(text string) bool {
+	token, found := grammar_file.Lookup_token(text)
+	return found && !slices.Contains(token_rules, token)
+}
+
+func (r *reader) declare(name string) {
+	if !slices.Contains(r.grammar.Tokens, name) {
+		r.grammar.Tokens = append(r.grammar.Tokens, name)
+	}
+}
+
+func token_by_name(name string) (dfa.TokenType, bool) {
+	for token, token_name := range grammar_file.Token_names {
+		if token_name == name {
+			return token, true
+		}
+	}
+	return "", false
+}
+
+// find_token_definitions goes over the rules of the file, which start where `is_rule_start` is true, and takes the ones whose definition uses character classes or codes as the definitions of tokens. The other rules are the parser rules.
+func (r *reader) find_token_definitions(is_rule_start func() bool) {
+	names := map[string]bool{}
+	current := ""
+	for r.at = 0; r.peek().kind != token_end; r.next() {
+		if is_rule_start() {
+			current = r.peek().text
+			if _, is_token := names[current]; !is_token {
+				names[current] = false
+			}
+			if _, found := token_rule_name(current); found {
+				names[current] = true
+			}
+		} else if r.peek().kind == token_other && current != "" {
+			names[current] = true
+		}
+	}
+	for name, is_token := range names {
+		r.defined[name] = !is_token
+	}
+	r.at = 0
+}
+
+// add_rule adds a parser rule read from the file, the first one being the start symbol
+func (r *reader) add_rule(name token, terms []grammar_file.Generic_grammar_term) {
+	non_terminal := grammar_file.Non_terminal{Name: name.text}
+	r.grammar.Add_rule(non_terminal, terms, r.span(name.start, name.end))
+	if r.grammar.Start == "" {
+		r.grammar.Start = name.text
+	}
+}
+
+// join_alternatives puts `or`s between alternatives, the inverse of `split_alternatives`
+func join_alternatives(alternatives [][]grammar_file.Generic_grammar_term, ors []grammar_file.Generic_grammar_term) []grammar_file.Generic_grammar_term {
+	terms := []grammar_file.Generic_grammar_term{}
+	for i, alternative := range alternatives {
+		if i > 0 {
+			terms = append(terms, ors[i-1])
+		}
+		terms = append(terms, alternative...)
+	}
+	return terms
+}
+
+// read_alternatives reads sequences separated by `or`. `element` reads one element of a sequence, and returns nil where the sequence ends.
+func (r *reader) read_alternatives(or string, element func() (grammar_file.Generic_grammar_term, error)) ([]grammar_file.Generic_grammar_term, error) {
+	alternatives := [][]grammar_file.Generic_grammar_term{{}}
+	ors := []grammar_file.Generic_grammar_term{}
+	for {
+		if r.is_symbol(or) {
+			tok := r.next()
+			ors = append(ors, r.locate(&grammar_file.Or{}, tok.start, tok.end))
+			alternatives = append(alternatives, []grammar_file.Generic_grammar_term{})
+			continue
+		}
+		term, err := element()
+		if err != nil {
+			return nil, err
+		}
+		if term == nil && !r.is_symbol(or) { // An element can end its alternative after reading something which is dropped
+			return join_alternatives(alternatives, ors), nil
+		}
+		if term == nil {
+			continue
+		}
+		alternatives[len(alternatives)-1] = append(alternatives[len(alternatives)-1], term)
+	}
+}
+
+// read_group reads the alternatives between `open` and `close`, the open one being the current token
+func (r *reader) read_group(or string, close string, element func() (grammar_file.Generic_grammar_term, error)) (*grammar_file.Bracket, int, int, error) {
+	open := r.next()
+	contents, err := r.read_alternatives(or, element)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	closing, err := r.expect_symbol(close)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	bracket := &grammar_file.Bracket{Contents: contents}
+	r.locate(bracket, open.start, closing.end)
+	return bracket, open.start, closing.end, nil
+}
+
+// postfix applies the `*`, `+` or `?` following a term, if there is one
+func (r *reader) postfix(term grammar_file.Generic_grammar_term, start int) grammar_file.Generic_grammar_term {
+	for r.is_symbol("*") || r.is_symbol("+") || r.is_symbol("?") {
+		operator := r.next()
+		term = r.locate(postfix_term(operator.text, term), start, operator.end)
+	}
+	return term
+}
+
+// postfix_term wraps a term in the repetition written with `operator`
+func postfix_term(operator string, term grammar_file.Generic_grammar_term) grammar_file.Generic_grammar_term {
+	switch operator {
+	case "*":
+		return &grammar_file.Star{Content: term}
+	case "+":
+		return &grammar_file.Plus{Content: term}
+	}
+	return &grammar_file.Optional{Content: term}
+}
@@ -0,0 +1,167 @@
+package formats
+
+import (
+	"strings"
+
+	"github.com/VirajAgarwal1/lox/errorhandler"
+	"github.com/VirajAgarwal1/lox/grammar_file"
+	"github.com/VirajAgarwal1/lox/source"
+)
+
+/*
+W3C EBNF is the notation of the XML specification (https://www.w3.org/TR/xml/#sec-notation):
+
+	comma ::= equality ( "," equality )*
+	unary ::= ( "!" | "-" ) unary | primary
+
+Alternatives are separated by `|`, `?` `*` `+` follow what they apply to, and token rules are defined with character classes like `[a-zA-Z]` and character codes like `#xA`.
+*/
+
+// To_w3c_ebnf writes a grammar in W3C EBNF
+func To_w3c_ebnf(grammar *grammar_file.Grammar) []byte {
+	var out strings.Builder
+	lexer_tokens, declared := used_tokens(grammar)
+	if len(grammar.Skip) > 0 {
+		out.WriteString("/* Skipped between the other tokens: " + strings.Join(skipped_tokens(grammar), " ") + " */\n")
+	}
+	if len(declared) > 0 {
+		out.WriteString("/* Tokens which are not defined here: " + strings.Join(declared, " ") + " */\n")
+	}
+	if out.Len() > 0 {
+		out.WriteString("\n")
+	}
+
+	for _, rule := range ordered_rules(grammar) {
+		out.WriteString(layout(rule.Name.Name+" ::=", w3c_alternatives(rule.Terms, grammar), "|", ""))
+	}
+
+	written := false
+	for _, token := range lexer_tokens {
+		if definition := token_definitions[token].w3c_ebnf; definition != "" {
+			if !written {
+				out.WriteString("\n/* Tokens of the lexer */\n")
+				written = true
+			}
+			out.WriteString(grammar_file.Token_names[token] + " ::= " + definition + "\n")
+		}
+	}
+	return []byte(out.String())
+}
+
+func w3c_alternatives(terms []grammar_file.Generic_grammar_term, grammar *grammar_file.Grammar) []string {
+	alternatives := []string{}
+	for _, alternative := range split_alternatives(terms) {
+		parts := []string{}
+		for _, term := range alternative {
+			parts = append(parts, w3c_term(term, grammar))
+		}
+		alternatives = append(alternatives, strings.Join(parts, " "))
+	}
+	return alternatives
+}
+
+func w3c_term(term grammar_file.Generic_grammar_term, grammar *grammar_file.Grammar) string {
+	switch term := term.(type) {
+	case *grammar_file.Terminal:
+		rule, literal := terminal(string(term.Content), grammar)
+		if rule != "" {
+			return rule
+		}
+		if strings.Contains(literal, "\"") {
+			return "'" + literal + "'"
+		}
+		return "\"" + literal + "\""
+	case *grammar_file.Non_terminal:
+		return term.Name
+	case *grammar_file.Star:
+		return w3c_term(term.Content, grammar) + "*"
+	case *grammar_file.Plus:
+		return w3c_term(term.Content, grammar) + "+"
+	case *grammar_file.Optional:
+		return w3c_term(term.Content, grammar) + "?"
+	case *grammar_file.Bracket:
+		return strings.TrimSpace("( " + strings.Join(w3c_alternatives(term.Contents, grammar), " | ") + " )")
+	case *grammar_file.Labelled:
+		return w3c_term(term.Content, grammar) // W3C EBNF has no labels
+	}
+	return ""
+}
+
+var w3c_ebnf_syntax = syntax{
+	block_comments: true,
+	symbols:        []string{"::=", "|", "(", ")", "?", "*", "+", "-"},
+	name_chars:     "_.-",
+	quotes:         `"'`,
+	special: func(src string, i int) (token, int) {
+		switch {
+		case src[i] == '#' && strings.HasPrefix(src[i:], "#x"):
+			end := i + 2
+			for end < len(src) && strings.IndexByte("0123456789abcdefABCDEF", src[end]) >= 0 {
+				end++
+			}
+			return token{kind: token_other, text: src[i:end]}, end
+		case src[i] == '[':
+			end := strings.IndexByte(src[i+1:], ']')
+			if end < 0 {
+				return token{kind: token_end, text: "character class is never closed"}, i + 1
+			}
+			return token{kind: token_other, text: src[i : i+end+2]}, i + end + 2
+		}
+		return token{}, -1
+	},
+}
+
+// Read_w3c_ebnf reads a grammar written in W3C EBNF. The rules named like the tokens of the lexer (IDENTIFIER, NUMBER, ...), and the ones using character classes or codes, are taken as the definitions of tokens and skipped.
+func Read_w3c_ebnf(file *source.File) (*grammar_file.Grammar, *errorhandler.Diagnostics) {
+	return read(file, w3c_ebnf_syntax, (*reader).read_w3c_ebnf)
+}
+
+func (r *reader) read_w3c_ebnf() error {
+	is_rule_start := func(offset int) bool {
+		return r.peek_at(offset).kind == token_name && r.peek_at(offset+1).kind == token_symbol && r.peek_at(offset+1).text == "::="
+	}
+	r.find_token_definitions(func() bool { return is_rule_start(0) })
+
+	var element func() (grammar_file.Generic_grammar_term, error)
+	element = func() (grammar_file.Generic_grammar_term, error) {
+		tok := r.peek()
+		var term grammar_file.Generic_grammar_term
+		switch {
+		case tok.kind == token_name && !is_rule_start(0):
+			term = r.reference(r.next())
+		case tok.kind == token_literal:
+			term = r.literal(r.next())
+		case r.is_symbol("("):
+			bracket, _, _, err := r.read_group("|", ")", element)
+			if err != nil {
+				return nil, err
+			}
+			term = bracket
+		case r.is_symbol("-"):
+			return nil, r.error_at(tok, "exceptions ('A - B') are not supported")
+		default:
+			return nil, nil
+		}
+		return r.postfix(term, tok.start), nil
+	}
+
+	for r.peek().kind != token_end {
+		if !is_rule_start(0) {
+			return r.error_at(r.peek(), "expected a rule 'name ::= ...'"+r.found())
+		}
+		name := r.next()
+		r.next()
+		if !r.defined[name.text] {
+			for r.peek().kind != token_end && !is_rule_start(0) {
+				r.next()
+			}
+			continue
+		}
+		terms, err := r.read_alternatives("|", element)
+		if err != nil {
+			return err
+		}
+		r.add_rule(name, terms)
+	}
+	return nil
+}
@@ -0,0 +1,321 @@
+package formats
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/VirajAgarwal1/lox/errorhandler"
+	"github.com/VirajAgarwal1/lox/grammar_file"
+	"github.com/VirajAgarwal1/lox/source"
+)
+
+/*
+yacc and bison grammars (`.y` files) have declarations, rules and C code, separated by `%%`:
+
+	%token NUMBER
+	%left '+' '-'
+	%%
+	expr : expr '+' term { $$ = $1 + $3; }
+	     | term
+	     ;
+	%%
+	int main() { ... }
+
+Only the rules are read, with the tokens and the start symbol of the declarations:
+
+  - `%token NAME` declares NAME, unless the lexer knows it (like NUMBER). A token given an alias which the lexer knows, like `%token WHILE "while"`, is the token of the lexer.
+  - Character literals like `'+'` and aliases like `"while"` are literals.
+  - Empty alternatives (written with nothing or `%empty`) cannot be written in grammar files, the other alternatives of their rule are made optional: `list : item list | %empty ;` is read as `list -> [ item list ]`.
+  - Actions, `%prec` and the precedence declarations (`%left`, `%right`, `%nonassoc`, `%precedence`) are dropped with a G0010 warning. Without precedences, a grammar like `expr : expr '+' expr | expr '*' expr` is ambiguous, so its rules have to be rewritten by hand.
+  - The `error` token of yacc's error recovery and the `%?{ ... }` predicates of bison are G0009 errors.
+  - Names can have `.` and `-` in yacc, they are read with `_` instead.
+*/
+
+// Declarations which take tokens as their arguments, and drop the precedences they give them
+var yacc_precedences = []string{"%left", "%right", "%nonassoc", "%precedence"}
+
+// yacc_syntax gives the syntax of a yacc file. The C code after the second `%%` is read as one token.
+func yacc_syntax() syntax {
+	separators := 0
+	return syntax{
+		line_comments:  []string{"//"},
+		block_comments: true,
+		symbols:        []string{":", "|", ";"},
+		name_chars:     "_.-",
+		quotes:         `"'`,
+		escapes:        true,
+		special: func(src string, i int) (token, int) {
+			switch {
+			case strings.HasPrefix(src[i:], "%%"):
+				if separators++; separators == 2 {
+					return token{kind: token_symbol, text: "%%"}, len(src) // The C code is not read
+				}
+				return token{kind: token_symbol, text: "%%"}, i + 2
+			case strings.HasPrefix(src[i:], "%{"):
+				end := strings.Index(src[i:], "%}")
+				if end < 0 {
+					return token{kind: token_end, text: "'%{' is never closed"}, i + 2
+				}
+				return token{kind: token_other, text: src[i : i+end+2]}, i + end + 2
+			case strings.HasPrefix(src[i:], "%?"):
+				return token{kind: token_symbol, text: "%?"}, i + 2
+			case src[i] == '%':
+				end := i + 1
+				for end < len(src) && (src[end] == '_' || src[end] == '-' || (src[end]|0x20 >= 'a' && src[end]|0x20 <= 'z')) {
+					end++
+				}
+				if end == i+1 {
+					return token{kind: token_end, text: "expected a declaration name after '%'"}, end
+				}
+				return token{kind: token_symbol, text: src[i:end]}, end
+			case src[i] == '{':
+				return scan_c_block(src, i)
+			case src[i] == '<' || src[i] == '[':
+				closing := map[byte]byte{'<': '>', '[': ']'}[src[i]]
+				end := strings.IndexByte(src[i:], closing)
+				if end < 0 {
+					return token{kind: token_end, text: "'" + src[i:i+1] + "' is never closed"}, i + 1
+				}
+				return token{kind: token_other, text: src[i : i+end+1]}, i + end + 1
+			}
+			return token{}, -1
+		},
+	}
+}
+
+// scan_c_block reads the C code between the braces starting at `src[i]`, whose strings and comments can have braces too
+func scan_c_block(src string, i int) (token, int) {
+	depth := 0
+	for end := i; end < len(src); end++ {
+		switch src[end] {
+		case '{':
+			depth++
+		case '}':
+			if depth--; depth == 0 {
+				return token{kind: token_other, text: src[i : end+1]}, end + 1
+			}
+		case '"', '\'':
+			quote := src[end]
+			for end++; end < len(src) && src[end] != quote && src[end] != '\n'; end++ {
+				if src[end] == '\\' {
+					end++
+				}
+			}
+		case '/':
+			if strings.HasPrefix(src[end:], "/*") {
+				if close := strings.Index(src[end+2:], "*/"); close >= 0 {
+					end += close + 3
+				}
+			} else if strings.HasPrefix(src[end:], "//") {
+				for end < len(src) && src[end] != '\n' {
+					end++
+				}
+			}
+		}
+	}
+	return token{kind: token_end, text: "'{' is never closed"}, i + 1
+}
+
+// Read_yacc reads the rules of a yacc or bison grammar, with the tokens and the start symbol of its declarations
+func Read_yacc(file *source.File) (*grammar_file.Grammar, *errorhandler.Diagnostics) {
+	return read(file, yacc_syntax(), (*reader).read_yacc)
+}
+
+func (r *reader) read_yacc() error {
+	for i := range r.tokens {
+		if r.tokens[i].kind == token_name {
+			r.tokens[i].text = strings.NewReplacer(".", "_", "-", "_").Replace(r.tokens[i].text)
+		}
+	}
+	// Texts standing for the tokens given aliases: the alias of a token of the lexer for the name, the name for another alias
+	aliases := map[string]string{}
+
+	for !r.is_symbol("%%") {
+		if r.peek().kind == token_end {
+			return r.error_at(r.peek(), "expected '%%' before the rules"+r.found())
+		}
+		if err := r.read_yacc_declaration(aliases); err != nil {
+			return err
+		}
+	}
+	r.next()
+
+	is_rule_start := func() bool {
+		offset := 1
+		if r.peek_at(offset).kind == token_other && strings.HasPrefix(r.peek_at(offset).text, "[") {
+			offset++ // Named reference of the rule
+		}
+		return r.peek().kind == token_name && r.peek_at(offset).kind == token_symbol && r.peek_at(offset).text == ":"
+	}
+	rules_start := r.at
+	for ; r.peek().kind != token_end && !r.is_symbol("%%"); r.next() {
+		if is_rule_start() {
+			r.defined[r.peek().text] = true
+		}
+	}
+	r.at = rules_start
+
+	element := func() (grammar_file.Generic_grammar_term, error) {
+		for {
+			tok := r.peek()
+			switch {
+			case is_rule_start(), tok.kind == token_end, r.is_symbol("%%"), r.is_symbol(";"), r.is_symbol("|"):
+				return nil, nil // The `;` ending the rule is optional in bison
+			case tok.kind == token_name && tok.text == "error":
+				r.next()
+				r.unsupported(tok.start, tok.end, "the 'error' token of yacc's error recovery is not supported", "remove the alternatives using it")
+			case tok.kind == token_name:
+				r.next()
+				if named := r.peek(); named.kind == token_other && strings.HasPrefix(named.text, "[") {
+					r.next() // Named references are only used by actions
+				}
+				if alias, found := aliases[tok.text]; found && !r.defined[tok.text] {
+					return r.literal(token{kind: token_literal, text: alias, start: tok.start, end: tok.end}), nil
+				}
+				return r.reference(tok), nil
+			case tok.kind == token_literal:
+				r.next()
+				if name, found := aliases[tok.text]; found {
+					return r.reference(token{kind: token_name, text: name, start: tok.start, end: tok.end}), nil
+				}
+				return r.literal(tok), nil
+			case tok.kind == token_other && strings.HasPrefix(tok.text, "{"):
+				r.next()
+				r.dropped(tok.start, tok.end, "action is dropped")
+			case r.is_symbol("%empty"):
+				r.next()
+			case r.is_symbol("%prec"), r.is_symbol("%dprec"), r.is_symbol("%merge"):
+				r.next()
+				end := tok.end
+				if next := r.peek(); next.kind == token_name || next.kind == token_literal || next.kind == token_number || next.kind == token_other {
+					end = r.next().end
+				}
+				r.dropped(tok.start, end, "'"+tok.text+"' is dropped")
+			case r.is_symbol("%?"):
+				r.next()
+				end := tok.end
+				if r.peek().kind == token_other {
+					end = r.next().end
+				}
+				r.unsupported(tok.start, end, "semantic predicates are not supported", "the alternatives must be told apart by their tokens alone")
+			default:
+				return nil, r.error_at(tok, "'"+tok.text+"' is not supported in rules")
+			}
+		}
+	}
+
+	order := []token{}
+	rules := map[string][]grammar_file.Generic_grammar_term{}
+	for r.peek().kind != token_end && !r.is_symbol("%%") {
+		if !is_rule_start() {
+			return r.error_at(r.peek(), "expected a rule 'name : ... ;'"+r.found())
+		}
+		name := r.next()
+		for !r.is_symbol(":") {
+			r.next()
+		}
+		colon := r.next()
+		terms, err := r.read_alternatives("|", element)
+		if err != nil {
+			return err
+		}
+		if r.is_symbol(";") {
+			r.next()
+		}
+		if existing, found := rules[name.text]; found {
+			// yacc joins the rules of the same name
+			or := r.locate(&grammar_file.Or{}, colon.start, colon.end)
+			rules[name.text] = append(append(existing, or), terms...)
+			continue
+		}
+		order = append(order, name)
+		rules[name.text] = terms
+	}
+	for _, name := range order {
+		r.add_rule(name, r.yacc_optional(rules[name.text]))
+	}
+	return nil
+}
+
+// yacc_optional turns the empty alternatives of a rule into an optional, which is how grammar files write them: `a : b | c | %empty` is read as `a -> [ b or c ]`
+func (r *reader) yacc_optional(terms []grammar_file.Generic_grammar_term) []grammar_file.Generic_grammar_term {
+	ors := []grammar_file.Generic_grammar_term{}
+	for _, term := range terms {
+		if term.Get_grammar_term_type() == "or" {
+			ors = append(ors, term)
+		}
+	}
+	alternatives := [][]grammar_file.Generic_grammar_term{}
+	kept_ors := []grammar_file.Generic_grammar_term{}
+	for i, alternative := range split_alternatives(terms) {
+		if len(alternative) == 0 {
+			continue
+		}
+		if len(alternatives) > 0 {
+			kept_ors = append(kept_ors, ors[i-1])
+		}
+		alternatives = append(alternatives, alternative)
+	}
+	if len(alternatives) == 0 || len(alternatives) == len(ors)+1 {
+		return terms
+	}
+
+	contents := join_alternatives(alternatives, kept_ors)
+	span := errorhandler.NewSpan(r.grammar.Locations[contents[0]].Start, r.grammar.Locations[contents[len(contents)-1]].End)
+	bracket := &grammar_file.Bracket{Contents: contents}
+	optional := &grammar_file.Optional{Content: bracket}
+	r.grammar.Locations[bracket] = span
+	r.grammar.Locations[optional] = span
+	return []grammar_file.Generic_grammar_term{optional}
+}
+
+// read_yacc_declaration reads one declaration before the rules. Only the tokens, their precedences and the start symbol are kept.
+func (r *reader) read_yacc_declaration(aliases map[string]string) error {
+	declaration := r.peek()
+	if declaration.kind == token_other {
+		r.next() // Code between `%{` and `%}`
+		return nil
+	}
+	if declaration.kind != token_symbol || !strings.HasPrefix(declaration.text, "%") {
+		return r.error_at(declaration, "expected a declaration like '%token'"+r.found())
+	}
+	r.next()
+	arguments := []token{}
+	for r.peek().kind != token_end && !(r.peek().kind == token_symbol && strings.HasPrefix(r.peek().text, "%")) {
+		arguments = append(arguments, r.next())
+	}
+
+	switch {
+	case declaration.text == "%start":
+		if len(arguments) != 1 || arguments[0].kind != token_name {
+			return r.error_at(declaration, "'%start' needs exactly one rule name")
+		}
+		r.grammar.Start = arguments[0].text
+		r.grammar.Start_location = r.span(arguments[0].start, arguments[0].end)
+	case declaration.text == "%token" || slices.Contains(yacc_precedences, declaration.text):
+		for i, argument := range arguments {
+			switch argument.kind {
+			case token_name:
+				if next := arguments[min(i+1, len(arguments)-1)]; i+1 < len(arguments) && next.kind == token_literal && declaration.text == "%token" {
+					if is_lexer_literal(next.text) {
+						aliases[argument.text] = next.text
+						continue
+					}
+					aliases[next.text] = argument.text
+				}
+				r.declare_token(argument.text)
+			case token_literal:
+				if _, found := aliases[argument.text]; !found {
+					if !is_lexer_literal(argument.text) {
+						r.declare(argument.text)
+					}
+				}
+			}
+		}
+		if declaration.text != "%token" {
+			r.dropped(declaration.start, declaration.end, "precedence declaration '"+declaration.text+"' is dropped").
+				WithNote("the rules must give the precedence of the operators, else the grammar is ambiguous")
+		}
+	}
+	return nil
+}
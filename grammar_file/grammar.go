@@ -0,0 +1,112 @@
+/*
+Package grammar_file reads the grammar files (like `parser/lox.grammar`) which both parser generators take as input:
+
+  - `parser/grammar` writes the combinator parser of `parser/generated_parser.go`
+  - `streamable_parser/parser_generator` writes the LL(1) parser of `streamable_parser/generated_parser.go`
+
+`ParseGrammar` gives a `Grammar`: the rules in the order they were written, made of the terms of `terms.go`, with the position of every term in the file. The names terminals can use for the lexer's tokens are in `tokens.go`.
+*/
+package grammar_file
+
+import (
+	"github.com/VirajAgarwal1/lox/errorhandler"
+	"github.com/VirajAgarwal1/lox/source"
+)
+
+// Grammar is everything a grammar file describes: its production rules and its directives
+type Grammar struct {
+	Rules   map[Non_terminal]([]Generic_grammar_term)
+	Order   []Non_terminal // Non-terminals in the order in which their rules were first defined
+	Start   string         // Given by `%start`, else the first rule of the file
+	Tokens  []string       // Terminal names declared with `%token`
+	Skip    []string       // Token names given to `%skip`
+	Imports []string       // Go packages given to `%import`, which the code of the actions uses
+
+	Precedence  []Precedence_level // Given by `%left`, `%right` and `%nonassoc`, from the loosest to the tightest
+	Expressions []Expression       // Given by `%expr`, their rules are in `Rules` as well
+
+	Reassociate     bool             // Given by `%reassociate`: the rewritten left-recursive rules give back their nodes as written
+	Left_recursions []Left_recursion // Set by `Eliminate_left_recursion`, their rules in `Rules` are rewritten
+	Resolutions     []Resolution     // Given by `%resolve`
+
+	// Where things were written in the grammar file, so that later passes can point at them
+	File           *source.File
+	Locations      map[Generic_grammar_term]errorhandler.Span // Every term of the rules, by its pointer
+	Definitions    map[Non_terminal][]errorhandler.Span       // Name of each production of a non-terminal, more than one if it is defined several times
+	Start_location errorhandler.Span                          // Argument of `%start`, invalid if it was not given
+
+	// Everything of the file in the order it was written, for the passes which need more than the rules (like the formatter)
+	Productions []Rule // Every production, including the ones which are later redefined
+	Directives  []Directive
+	Comments    []Comment
+}
+
+// Directive is a `%name args...` line of the grammar file
+type Directive struct {
+	Name string
+	Args []string // As written, strings keep their quotes
+	Span errorhandler.Span
+}
+
+// Resolution is a `%resolve rule TOKEN ...` directive: the conflicts of the LL(1) parse table in `rule` on these tokens are accepted, the parser keeps the production it prefers
+type Resolution struct {
+	Rule   string
+	Tokens []string // As written, without their quotes
+	Span   errorhandler.Span
+}
+
+// Comment is a `// ...` comment of the grammar file
+type Comment struct {
+	Text     string
+	Span     errorhandler.Span
+	Own_line bool // Nothing but whitespace comes before it on its line
+}
+
+// New_grammar gives a grammar without any rule, read from `file` (which may be nil)
+func New_grammar(file *source.File) *Grammar {
+	return &Grammar{
+		Rules:       make(map[Non_terminal]([]Generic_grammar_term)),
+		File:        file,
+		Locations:   make(map[Generic_grammar_term]errorhandler.Span),
+		Definitions: make(map[Non_terminal][]errorhandler.Span),
+	}
+}
+
+// Add_rule adds a production whose name was written at `span`, like the grammar file parser does for every production it reads. It is used by the readers of the other grammar notations (see `grammar_file/formats`).
+func (grammar *Grammar) Add_rule(non_terminal Non_terminal, terms []Generic_grammar_term, span errorhandler.Span) {
+	grammar.Definitions[non_terminal] = append(grammar.Definitions[non_terminal], span)
+	grammar.add_rule(non_terminal, terms)
+}
+
+// locate records where a term was written
+func (grammar *Grammar) locate(term Generic_grammar_term, start source.Pos, end source.Pos) {
+	grammar.Locations[term] = errorhandler.NewSpan(start, end)
+}
+
+// Position resolves a span of the grammar file to its line and column
+func (grammar *Grammar) Position(span errorhandler.Span) source.Position {
+	if grammar.File == nil {
+		return source.Position{}
+	}
+	return grammar.File.Position(span.Start)
+}
+
+// Rule is one production rule, see `Ordered_rules`
+type Rule struct {
+	Name  Non_terminal
+	Terms []Generic_grammar_term
+	Span  errorhandler.Span // Name of the rule in its last definition, which is the one kept in `Rules`
+}
+
+// Ordered_rules gives the rules in the order in which they were first defined in the grammar file
+func (grammar *Grammar) Ordered_rules() []Rule {
+	rules := make([]Rule, 0, len(grammar.Order))
+	for _, non_term := range grammar.Order {
+		rule := Rule{Name: non_term, Terms: grammar.Rules[non_term]}
+		if spans := grammar.Definitions[non_term]; len(spans) > 0 {
+			rule.Span = spans[len(spans)-1]
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
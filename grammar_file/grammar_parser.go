@@ -0,0 +1,512 @@
+package grammar_file
+
+import (
+	"io"
+
+	"github.com/VirajAgarwal1/lox/errorhandler"
+	"github.com/VirajAgarwal1/lox/lexer"
+	"github.com/VirajAgarwal1/lox/lexer/dfa"
+)
+
+type Stack_type []Generic_grammar_term
+
+func (st *Stack_type) add(elem Generic_grammar_term) {
+	*st = append(*st, elem)
+}
+func (st *Stack_type) pop() Generic_grammar_term {
+	if len(*st) == 0 {
+		return nil
+	}
+	out := (*st)[len(*st)-1]
+	*st = (*st)[:(len(*st) - 1)]
+	return out
+}
+func (st *Stack_type) peek() Generic_grammar_term {
+	return (*st)[len(*st)-1]
+}
+
+// grammarError reports a syntax error in the grammar file at the given token
+func grammarError(scanner *lexer.LexicalAnalyzer, token *lexer.Token, message string) error {
+	grammar_err := &errorhandler.GrammarError{
+		Code:     errorhandler.CodeGrammarSyntax,
+		Pos:      token.Pos,
+		End:      token.End,
+		Position: scanner.File().Position(token.Pos),
+		Message:  message,
+	}
+	if scanner.Diagnostics() != nil {
+		scanner.Diagnostics().AddError(grammar_err)
+	}
+	return errorhandler.RetErr("", grammar_err)
+}
+
+// add_rule stores the production read so far for `non_terminal`
+func (grammar *Grammar) add_rule(non_terminal Non_terminal, stack []Generic_grammar_term) {
+	var new_non_terminal_def []Generic_grammar_term
+	for i := 0; i < len(stack); i++ {
+		new_non_terminal_def = append(new_non_terminal_def, stack[i])
+	}
+	if _, found := grammar.Rules[non_terminal]; !found {
+		grammar.Order = append(grammar.Order, non_terminal)
+	}
+	grammar.Rules[non_terminal] = new_non_terminal_def
+
+	production := Rule{Name: non_terminal, Terms: new_non_terminal_def}
+	if spans := grammar.Definitions[non_terminal]; len(spans) > 0 {
+		production.Span = spans[len(spans)-1]
+	}
+	grammar.Productions = append(grammar.Productions, production)
+}
+
+// end_rule stores the production read so far for `non_terminal`, once every `label:` in it has been given the element after it
+func (grammar *Grammar) end_rule(scanner *lexer.LexicalAnalyzer, non_terminal Non_terminal, stack []Generic_grammar_term) error {
+	// A left bracket is only taken off the stack by its right bracket
+	for i := len(stack) - 1; i >= 0; i-- {
+		if bracket, is_bracket := stack[i].(*Bracket); is_bracket && bracket.Is_left {
+			span := grammar.Locations[bracket]
+			open := "("
+			if bracket.Is_square {
+				open = "["
+			}
+			return grammarError(scanner, &lexer.Token{Pos: span.Start, End: span.End}, "unclosed '"+open+"'")
+		}
+	}
+	terms, err := grammar.fold_labels(scanner, stack)
+	if err != nil {
+		return err
+	}
+	grammar.add_rule(non_terminal, terms)
+	return nil
+}
+
+// fold_labels puts the element following each `label:` of a sequence inside of its label. It waits until the end of the sequence, so that `items:item*` labels the whole `item*`.
+func (grammar *Grammar) fold_labels(scanner *lexer.LexicalAnalyzer, terms []Generic_grammar_term) ([]Generic_grammar_term, error) {
+	out := make([]Generic_grammar_term, 0, len(terms))
+	for i := 0; i < len(terms); i++ {
+		label, is_label := terms[i].(*Labelled)
+		if !is_label || label.Content != nil {
+			out = append(out, terms[i])
+			continue
+		}
+		if i+1 >= len(terms) || !can_be_labelled(terms[i+1]) {
+			span := grammar.Locations[label]
+			return nil, grammarError(scanner, &lexer.Token{Pos: span.Start, End: span.End}, "label '"+label.Label+"' must be followed by an element")
+		}
+		label.Content = terms[i+1]
+		grammar.locate(label, grammar.Locations[label].Start, grammar.Locations[label.Content].End)
+		out = append(out, label)
+		i++
+	}
+	return out, nil
+}
+
+// can_be_labelled tells if a term of a sequence is an element, not an `or`, an action, a bracket which is still open or another `label:`
+func can_be_labelled(term Generic_grammar_term) bool {
+	switch term := term.(type) {
+	case *Or:
+		return false
+	case *Bracket:
+		return !term.Is_left
+	case *Labelled:
+		return term.Content != nil
+	case *Action:
+		return false
+	}
+	return true
+}
+
+// add_directive applies a directive, given as its tokens starting from the `%`
+func (grammar *Grammar) add_directive(scanner *lexer.LexicalAnalyzer, directive []*lexer.Token) error {
+	if len(directive) < 2 || directive[1].TypeOfToken != dfa.IDENTIFIER {
+		return grammarError(scanner, directive[0], "directive name missing after '%'")
+	}
+	name := string(directive[1].Lexemme)
+
+	args := []string{}
+	written := Directive{Name: name, Args: []string{}}
+	for _, token := range directive[2:] {
+		switch token.TypeOfToken {
+		case dfa.IDENTIFIER:
+			args = append(args, string(token.Lexemme))
+		case dfa.STRING:
+			args = append(args, string(token.Lexemme[1:len(token.Lexemme)-1])) // Excluding the apostrophies from the sides
+		default:
+			return grammarError(scanner, token, "arguments of '%"+name+"' must be names")
+		}
+		written.Args = append(written.Args, string(token.Lexemme))
+	}
+	written.Span = errorhandler.NewSpan(directive[0].Pos, directive[len(directive)-1].End)
+	grammar.Directives = append(grammar.Directives, written)
+
+	switch name {
+	case "start":
+		if len(args) != 1 {
+			return grammarError(scanner, directive[1], "'%start' needs exactly one non-terminal")
+		}
+		if grammar.Start != "" {
+			return grammarError(scanner, directive[1], "'%start' is given more than once")
+		}
+		grammar.Start = args[0]
+		grammar.Start_location = errorhandler.NewSpan(directive[2].Pos, directive[2].End)
+	case "token":
+		if len(args) < 1 {
+			return grammarError(scanner, directive[1], "'%token' needs at least one name")
+		}
+		grammar.Tokens = append(grammar.Tokens, args...)
+	case "skip":
+		if len(args) < 1 {
+			return grammarError(scanner, directive[1], "'%skip' needs at least one token")
+		}
+		grammar.Skip = append(grammar.Skip, args...)
+	case "left", "right", "nonassoc":
+		if len(args) < 1 {
+			return grammarError(scanner, directive[1], "'%"+name+"' needs at least one operator")
+		}
+		grammar.Precedence = append(grammar.Precedence, Precedence_level{Associativity: name, Operators: args})
+	case "expr":
+		if len(args) != 2 || directive[2].TypeOfToken != dfa.IDENTIFIER || directive[3].TypeOfToken != dfa.IDENTIFIER {
+			return grammarError(scanner, directive[1], "'%expr' needs the name of the expression and the name of its operands")
+		}
+		if len(grammar.Precedence) == 0 {
+			return grammarError(scanner, directive[1], "'%expr' needs operators, declared before it with '%left', '%right' or '%nonassoc'")
+		}
+		grammar.add_expression(Expression{
+			Name:    args[0],
+			Operand: args[1],
+			Levels:  append([]Precedence_level{}, grammar.Precedence...),
+			Span:    written.Span,
+		})
+	case "reassociate":
+		if len(args) != 0 {
+			return grammarError(scanner, directive[1], "'%reassociate' takes no arguments")
+		}
+		grammar.Reassociate = true
+	case "resolve":
+		if len(args) < 2 || directive[2].TypeOfToken != dfa.IDENTIFIER {
+			return grammarError(scanner, directive[1], "'%resolve' needs the name of a rule and the tokens on which its conflicts are resolved")
+		}
+		grammar.Resolutions = append(grammar.Resolutions, Resolution{Rule: args[0], Tokens: args[1:], Span: written.Span})
+	case "import":
+		if len(args) < 1 {
+			return grammarError(scanner, directive[1], "'%import' needs at least one package")
+		}
+		grammar.Imports = append(grammar.Imports, args...)
+	default:
+		return grammarError(scanner, directive[1], "unknown directive '%"+name+"'")
+	}
+	return nil
+}
+
+// ProcessGrammarDefinition reads the production rules of a grammar file, see `ParseGrammar`
+func ProcessGrammarDefinition(scanner *lexer.LexicalAnalyzer) (map[Non_terminal]([]Generic_grammar_term), error) {
+	grammar, err := ParseGrammar(scanner)
+	return grammar.Rules, err
+}
+
+/*
+ParseGrammar reads a grammar file. Like the other readers of this project, it returns `io.EOF` once it has read the whole file.
+
+  - A production ends at the end of its line, unless the next line with something on it is indented or does not start with a name, in which case the production continues on it
+  - A production can also be ended with a `;`, which allows more than one production on a line
+  - Comments (`// ...`) and blank lines can be placed anywhere
+  - Directives start with `%` and end at the end of their line (or at a `;`):
+    `%start name` picks the starting non-terminal,
+    `%token NAME ...` declares terminals which the lexer does not know about,
+    `%skip NAME ...` lists the tokens which the generated parser should ignore (like whitespace and comments),
+    `%import "path" ...` gives the Go packages which the code of the actions uses,
+    `%left`, `%right` and `%nonassoc` declare operators, from the loosest to the tightest, and `%expr name operand` defines the rules of `name` from them (see `add_expression`),
+    `%reassociate` makes the parsers of the left-recursive rules give back their nodes as written (see `Eliminate_left_recursion`),
+    `%resolve rule TOKEN ...` accepts the LL(1) conflicts of `rule` on these tokens, which the streamable parser generator refuses otherwise (see `generator.Find_conflicts`)
+  - An alternative can end with an action, Go code between braces: `binary -> left:term "+" right:term { return &Binary{Left: $left, Right: $right} }`. The parser generators run it when the alternative is matched, see `Expand_action`.
+  - An element can be given a label with `label:element`, like `left:term`, `op:( "+" or "-" )` or `items:item*`. The parser generators give the nodes a field for every label of their rule, so that the code using the tree does not have to know where a child is among the others.
+*/
+func ParseGrammar(scanner *lexer.LexicalAnalyzer) (*Grammar, error) {
+
+	i := -1
+	current_non_terminal := Non_terminal{}
+	var stack Stack_type
+	grammar := New_grammar(scanner.File())
+	var directive []*lexer.Token // Tokens of the directive being read, nil if not reading one
+
+	at_line_start := true  // Nothing but whitespace and comments has been read on the current line yet
+	line_indented := false // The current line started with whitespace
+
+	for {
+		token, err := scanner.ReadToken()
+		if err != nil && err != io.EOF {
+			return grammar, errorhandler.RetErr("Inavlid Grammar: token not recognized", err)
+		}
+		if err == io.EOF {
+			if directive != nil {
+				if dir_err := grammar.add_directive(scanner, directive); dir_err != nil {
+					return grammar, dir_err
+				}
+			}
+			if i >= 0 {
+				if rule_err := grammar.end_rule(scanner, current_non_terminal, stack); rule_err != nil {
+					return grammar, rule_err
+				}
+			}
+			if grammar.Start == "" && len(grammar.Order) > 0 {
+				grammar.Start = grammar.Order[0].Name
+			}
+			stack = nil
+			return grammar, err
+		}
+
+		if token.TypeOfToken == dfa.WHITESPACE {
+			if at_line_start {
+				line_indented = true
+			}
+			continue
+		}
+		if token.TypeOfToken == dfa.COMMENT {
+			grammar.Comments = append(grammar.Comments, Comment{
+				Text:     string(token.Lexemme),
+				Span:     errorhandler.NewSpan(token.Pos, token.End),
+				Own_line: at_line_start,
+			})
+			continue
+		}
+		if directive != nil {
+			if token.TypeOfToken != dfa.NEWLINE && token.TypeOfToken != dfa.SEMICOLON {
+				directive = append(directive, token)
+				continue
+			}
+			if err := grammar.add_directive(scanner, directive); err != nil {
+				return grammar, err
+			}
+			directive = nil
+			if token.TypeOfToken == dfa.SEMICOLON {
+				continue
+			}
+		}
+		if token.TypeOfToken == dfa.NEWLINE {
+			// The production may still continue on the next line, so it is only stored once we know it does not
+			at_line_start = true
+			line_indented = false
+			continue
+		}
+		if token.TypeOfToken == dfa.SEMICOLON {
+			if i < 0 {
+				return grammar, grammarError(scanner, token, "';' must end a production")
+			}
+			if err := grammar.end_rule(scanner, current_non_terminal, stack); err != nil {
+				return grammar, err
+			}
+			stack = stack[:0] // Clear out the stack
+			i = -1
+			continue
+		}
+
+		if at_line_start {
+			at_line_start = false
+			if i >= 0 && !line_indented && (token.TypeOfToken == dfa.IDENTIFIER || token.TypeOfToken == dfa.PERCENT) {
+				// A line which is not indented and starts with a name (or a directive) starts the next production
+				if err := grammar.end_rule(scanner, current_non_terminal, stack); err != nil {
+					return grammar, err
+				}
+				stack = stack[:0] // Clear out the stack
+				i = -1
+			}
+		}
+
+		if i < 0 && token.TypeOfToken == dfa.PERCENT {
+			directive = []*lexer.Token{token}
+			continue
+		}
+
+		i++
+
+		switch i {
+		case 0:
+			// We are the starting of a new production
+			if token.TypeOfToken != dfa.IDENTIFIER {
+				return grammar, grammarError(scanner, token, "left expression missing")
+			}
+			current_non_terminal.Name = string(token.Lexemme)
+			grammar.Definitions[current_non_terminal] = append(grammar.Definitions[current_non_terminal], errorhandler.NewSpan(token.Pos, token.End))
+			continue
+		case 1:
+			if token.TypeOfToken != dfa.MINUS {
+				return grammar, grammarError(scanner, token, "separator (->) is missing")
+			}
+			continue
+		case 2:
+			if token.TypeOfToken != dfa.GREATER {
+				return grammar, grammarError(scanner, token, "separator (->) is missing")
+			}
+			continue
+		}
+
+		if len(stack) > 0 && stack.peek().Get_grammar_term_type() == "action" && token.TypeOfToken != dfa.OR {
+			return grammar, grammarError(scanner, token, "an action must be the last thing of its alternative")
+		}
+		if token.TypeOfToken == dfa.LEFT_BRACE {
+			if len(stack) < 1 || stack.peek().Get_grammar_term_type() == "or" {
+				return grammar, grammarError(scanner, token, "an action needs the elements of its alternative before it")
+			}
+			for _, term := range stack {
+				if bracket, is_bracket := term.(*Bracket); is_bracket && bracket.Is_left {
+					return grammar, grammarError(scanner, token, "an action can only end an alternative of the rule, not be inside brackets")
+				}
+			}
+			code, end, err := scanner.ReadEnclosed('{', '}')
+			if err != nil {
+				return grammar, grammarError(scanner, token, "the action is never closed with '}'")
+			}
+			new_action := Action{Code: code}
+			stack.add(&new_action)
+			grammar.locate(&new_action, token.Pos, end)
+			continue
+		}
+		if token.TypeOfToken == dfa.IDENTIFIER {
+			arg := Non_terminal{}
+			arg.Name = string(token.Lexemme)
+			stack.add(&arg)
+			grammar.locate(&arg, token.Pos, token.End)
+			continue
+		}
+		if token.TypeOfToken == dfa.COLON {
+			if len(stack) < 1 || stack.peek().Get_grammar_term_type() != "non_terminal" {
+				return grammar, grammarError(scanner, token, "':' must come right after the name of a label")
+			}
+			name := stack.pop()
+			new_label := Labelled{Label: name.(*Non_terminal).Name}
+			stack.add(&new_label)
+			grammar.locate(&new_label, grammar.Locations[name].Start, token.End)
+			delete(grammar.Locations, name)
+			continue
+		}
+		if token.TypeOfToken == dfa.LEFT_PAREN {
+			open_bracket := Bracket{}
+			open_bracket.Is_left = true
+			stack.add(&open_bracket)
+			grammar.locate(&open_bracket, token.Pos, token.End)
+			continue
+		}
+		if token.TypeOfToken == dfa.RIGHT_PAREN || token.TypeOfToken == dfa.RIGHT_BRACKET {
+			is_square := token.TypeOfToken == dfa.RIGHT_BRACKET
+			i := len(stack) - 1
+			for i >= 0 {
+				if stack[i].Get_grammar_term_type() == "bracket" {
+					if stack[i].(*Bracket).Is_left {
+						break
+					}
+				}
+				i--
+			}
+			if i < 0 {
+				return grammar, grammarError(scanner, token, "no matching left bracket found for the right bracket")
+			}
+			if stack[i].(*Bracket).Is_square != is_square {
+				return grammar, grammarError(scanner, token, "the right bracket does not match the kind of its left bracket")
+			}
+			// Take all the elems out from the stack from this index and place them in the new bracket
+			close_bracket := Bracket{}
+			close_bracket.Is_left = false
+			contents, err := grammar.fold_labels(scanner, stack[i+1:])
+			if err != nil {
+				return grammar, err
+			}
+			close_bracket.Contents = contents
+			open_pos := grammar.Locations[stack[i]].Start
+			delete(grammar.Locations, stack[i])
+			grammar.locate(&close_bracket, open_pos, token.End)
+			stack = stack[:i] // Remove all the other things from the stack
+			if is_square {
+				// `[ ... ]` is the same as `( ... )?`
+				optional_bracket := Optional{Content: &close_bracket}
+				stack.add(&optional_bracket)
+				grammar.locate(&optional_bracket, open_pos, token.End)
+				continue
+			}
+			stack.add(&close_bracket)
+			continue
+		}
+		if token.TypeOfToken == dfa.LEFT_BRACKET {
+			open_bracket := Bracket{}
+			open_bracket.Is_left = true
+			open_bracket.Is_square = true
+			stack.add(&open_bracket)
+			grammar.locate(&open_bracket, token.Pos, token.End)
+			continue
+		}
+		if token.TypeOfToken == dfa.STAR {
+			if len(stack) < 1 {
+				return grammar, grammarError(scanner, token, "'*' needs an element before itself to function")
+			}
+			prev_elem := stack.peek()
+			if prev_elem.Get_grammar_term_type() == "bracket" && prev_elem.(*Bracket).Is_left {
+				return grammar, grammarError(scanner, token, "'*' cannot have a open bracket right before itself")
+			}
+			if prev_elem.Get_grammar_term_type() == "or" {
+				return grammar, grammarError(scanner, token, "'*' cannot have the 'or` operator right before itself")
+			}
+			if label, is_label := prev_elem.(*Labelled); is_label && label.Content == nil {
+				return grammar, grammarError(scanner, token, "'*' cannot come right after a label, the label needs an element")
+			}
+			prev_elem = stack.pop()
+			new_star := Star{}
+			new_star.Content = prev_elem
+			stack.add(&new_star)
+			grammar.locate(&new_star, grammar.Locations[prev_elem].Start, token.End)
+			continue
+		}
+		if token.TypeOfToken == dfa.PLUS {
+			if len(stack) < 1 {
+				return grammar, grammarError(scanner, token, "'+' needs an element before itself to function")
+			}
+			prev_elem := stack.peek()
+			if prev_elem.Get_grammar_term_type() == "bracket" && prev_elem.(*Bracket).Is_left {
+				return grammar, grammarError(scanner, token, "'+' cannot have a open bracket right before itself")
+			}
+			if prev_elem.Get_grammar_term_type() == "or" {
+				return grammar, grammarError(scanner, token, "'+' cannot have the 'or` operator right before itself")
+			}
+			if label, is_label := prev_elem.(*Labelled); is_label && label.Content == nil {
+				return grammar, grammarError(scanner, token, "'+' cannot come right after a label, the label needs an element")
+			}
+			prev_elem = stack.pop()
+			new_plus := Plus{}
+			new_plus.Content = prev_elem
+			stack.add(&new_plus)
+			grammar.locate(&new_plus, grammar.Locations[prev_elem].Start, token.End)
+			continue
+		}
+		if token.TypeOfToken == dfa.QUESTION {
+			if len(stack) < 1 {
+				return grammar, grammarError(scanner, token, "'?' needs an element before itself to function")
+			}
+			prev_elem := stack.peek()
+			if prev_elem.Get_grammar_term_type() == "bracket" && prev_elem.(*Bracket).Is_left {
+				return grammar, grammarError(scanner, token, "'?' cannot have a open bracket right before itself")
+			}
+			if prev_elem.Get_grammar_term_type() == "or" {
+				return grammar, grammarError(scanner, token, "'?' cannot have the 'or` operator right before itself")
+			}
+			if label, is_label := prev_elem.(*Labelled); is_label && label.Content == nil {
+				return grammar, grammarError(scanner, token, "'?' cannot come right after a label, the label needs an element")
+			}
+			prev_elem = stack.pop()
+			new_optional := Optional{}
+			new_optional.Content = prev_elem
+			stack.add(&new_optional)
+			grammar.locate(&new_optional, grammar.Locations[prev_elem].Start, token.End)
+			continue
+		}
+		if token.TypeOfToken == dfa.OR {
+			new_or := Or{}
+			stack.add(&new_or)
+			grammar.locate(&new_or, token.Pos, token.End)
+			continue
+		}
+		if token.TypeOfToken == dfa.STRING {
+			new_terminal := Terminal{}
+			new_terminal.Content = token.Lexemme[1 : len(token.Lexemme)-1] // Excluding the apostrophies from the sides
+			stack.add(&new_terminal)
+			grammar.locate(&new_terminal, token.Pos, token.End)
+			continue
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package grammar_file
+
+// Labels gives the labels written in terms (`label:element`), in the order they are first written. `many` tells for each of them if one match of the terms can give it more than one node: when it is inside a repetition, written more than once in a sequence, or labels something which can match more than one token or non-terminal.
+func Labels(terms []Generic_grammar_term) (labels []string, many map[string]bool) {
+	many = map[string]bool{}
+	var collect func(term Generic_grammar_term)
+	collect = func(term Generic_grammar_term) {
+		switch term := term.(type) {
+		case *Labelled:
+			if _, found := many[term.Label]; !found {
+				labels = append(labels, term.Label)
+				many[term.Label] = false
+			}
+			if !yields_one_node(term.Content) {
+				many[term.Label] = true
+			}
+			collect(term.Content)
+		case *Star:
+			collect(term.Content)
+		case *Plus:
+			collect(term.Content)
+		case *Optional:
+			collect(term.Content)
+		case *Bracket:
+			for _, content := range term.Contents {
+				collect(content)
+			}
+		}
+	}
+	for _, term := range terms {
+		collect(term)
+	}
+
+	for label, count := range label_counts(terms) {
+		if count > 1 {
+			many[label] = true
+		}
+	}
+	return labels, many
+}
+
+// label_counts gives how many times one match of the terms can match each of their labels, 2 standing for more than once
+func label_counts(terms []Generic_grammar_term) map[string]int {
+	counts := map[string]int{}
+	for _, alternative := range Alternatives(terms) {
+		alternative_counts := map[string]int{}
+		for _, term := range alternative {
+			for label, count := range term_label_counts(term) {
+				alternative_counts[label] = min(alternative_counts[label]+count, 2)
+			}
+		}
+		for label, count := range alternative_counts {
+			counts[label] = max(counts[label], count)
+		}
+	}
+	return counts
+}
+
+func term_label_counts(term Generic_grammar_term) map[string]int {
+	switch term := term.(type) {
+	case *Labelled:
+		counts := term_label_counts(term.Content)
+		counts[term.Label] = min(counts[term.Label]+1, 2)
+		return counts
+	case *Star:
+		return repeated_label_counts(term_label_counts(term.Content))
+	case *Plus:
+		return repeated_label_counts(term_label_counts(term.Content))
+	case *Optional:
+		return term_label_counts(term.Content)
+	case *Bracket:
+		return label_counts(term.Contents)
+	}
+	return map[string]int{}
+}
+
+func repeated_label_counts(counts map[string]int) map[string]int {
+	for label := range counts {
+		counts[label] = 2
+	}
+	return counts
+}
+
+// yields_one_node tells if a term matches at most one token or non-terminal: a terminal, a non-terminal, or a choice between them
+func yields_one_node(term Generic_grammar_term) bool {
+	switch term := term.(type) {
+	case *Terminal, *Non_terminal:
+		return true
+	case *Optional:
+		return yields_one_node(term.Content)
+	case *Labelled:
+		return yields_one_node(term.Content)
+	case *Bracket:
+		for _, alternative := range Alternatives(term.Contents) {
+			if len(alternative) != 1 || !yields_one_node(alternative[0]) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
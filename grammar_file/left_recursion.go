@@ -0,0 +1,331 @@
+package grammar_file
+
+import (
+	"errors"
+	"slices"
+	"sort"
+
+	"github.com/VirajAgarwal1/lox/errorhandler"
+)
+
+/*
+A rule is left-recursive when it can start with itself: `expr -> expr "+" term or term` directly, or through other rules, like `a -> b "x" or "y"` with `b -> a "z"`. A recursive descent parser calls itself forever on such a rule, and an LL(1) parser cannot compute its FIRST set. `Eliminate_left_recursion` rewrites them into rules which repeat instead:
+
+	expr -> expr "+" term or expr "-" term or term
+
+	expr -> term ( "+" term or "-" term )*
+
+The alternatives which do not start with the rule (its bases) are matched once, then the ones which do (its tails), without the rule they start with, as many times as they match.
+
+A rule which starts with another rule of its cycle first gets the alternatives of that rule in place of it (`a -> b "x"` with `b -> a "z" or "w"` becomes `a -> a "z" "x" or "w" "x"`), so that only the last rule of the cycle, in the order of the file, starts with itself and is rewritten. The node of the rule put in place is lost there, so the rules with actions cannot be put in place.
+
+The rewritten rule matches the same input, but gives one node for all its repetitions: `1 - 2 - 3` is `expr(1 - 2 - 3)` instead of `expr(expr(expr(1) - 2) - 3)`. With `%reassociate`, the parser generators give back the nodes of the rule as written: every tail makes the node of what was matched before it its first child. Actions need it, they are written for the nodes of the rule as written.
+
+Left recursion is only found through the first element of the alternatives, their brackets, and the `*`, `?` and `+` which start them. A rule starting with a rule which can match nothing is not looked through.
+*/
+
+// Left_recursion is a left-recursive rule, as `Eliminate_left_recursion` rewrote it
+type Left_recursion struct {
+	Name    string
+	Bases   []Left_recursive_alternative // The alternatives which do not start with the rule
+	Tails   []Left_recursive_alternative // The alternatives which start with the rule, without it
+	Written []Generic_grammar_term       // The production before it was rewritten, with its actions. For a rule of a longer cycle, with the other rules put in place.
+}
+
+// Left_recursive_alternative is one alternative of a left-recursive rule, without its action
+type Left_recursive_alternative struct {
+	Alternative int // Index of the alternative in `Written`
+	Terms       []Generic_grammar_term
+	Label       string // For a tail, the label of the rule it started with: `left` for `expr -> left:expr "+" term`
+}
+
+// Left_recursion_of gives how a rule was rewritten, nil if it was not left-recursive
+func (grammar *Grammar) Left_recursion_of(name string) *Left_recursion {
+	for i := range grammar.Left_recursions {
+		if grammar.Left_recursions[i].Name == name {
+			return &grammar.Left_recursions[i]
+		}
+	}
+	return nil
+}
+
+// Written_rule gives the production of a rule as the grammar file wrote it, before its left recursion was rewritten
+func (grammar *Grammar) Written_rule(non_terminal Non_terminal) []Generic_grammar_term {
+	if left_recursion := grammar.Left_recursion_of(non_terminal.Name); left_recursion != nil {
+		return left_recursion.Written
+	}
+	return grammar.Rules[non_terminal]
+}
+
+// Left_recursive_rules gives the names of the rules which can start with themselves, by name
+func Left_recursive_rules(rules map[Non_terminal]([]Generic_grammar_term)) []string {
+	corners := map[string][]string{}
+	for non_term, terms := range rules {
+		corners[non_term.Name] = left_corners(terms)
+	}
+
+	names := []string{}
+	for name := range corners {
+		if reaches(corners, name, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+/*
+Eliminate_left_recursion gives the grammar with its left-recursive rules rewritten, and recorded in `Left_recursions`. The grammar itself is not changed, it is given back as it is when no rule is left-recursive.
+
+It fails when a left recursion cannot be rewritten: a rule whose alternatives all start with itself, an alternative which is nothing but the rule, a cycle through a rule with actions, a left recursion hidden inside a bracket, or actions without `%reassociate`.
+*/
+func Eliminate_left_recursion(grammar *Grammar) (*Grammar, error) {
+	recursive := Left_recursive_rules(grammar.Rules)
+	if len(recursive) == 0 {
+		return grammar, nil
+	}
+
+	output := *grammar
+	output.Rules = make(map[Non_terminal]([]Generic_grammar_term), len(grammar.Rules))
+	for non_term, terms := range grammar.Rules {
+		output.Rules[non_term] = terms
+	}
+	output.Locations = make(map[Generic_grammar_term]errorhandler.Span, len(grammar.Locations))
+	for term, span := range grammar.Locations {
+		output.Locations[term] = span
+	}
+	output.Left_recursions = nil
+
+	corners := map[string][]string{}
+	for non_term, terms := range grammar.Rules {
+		corners[non_term.Name] = left_corners(terms)
+	}
+	done := []Non_terminal{} // The left-recursive rules already rewritten, in the order of the file
+	for _, non_term := range rule_order(grammar) {
+		if !slices.Contains(recursive, non_term.Name) {
+			continue
+		}
+		terms := output.Rules[non_term]
+		for _, previous := range done {
+			if reaches(corners, non_term.Name, previous.Name) && reaches(corners, previous.Name, non_term.Name) {
+				var err error
+				terms, err = output.put_in_place(non_term, terms, previous)
+				if err != nil {
+					return grammar, err
+				}
+			}
+		}
+
+		if is_directly_left_recursive(non_term.Name, terms) {
+			left_recursion, rewritten, err := output.rewrite_left_recursion(non_term, terms)
+			if err != nil {
+				return grammar, err
+			}
+			output.Left_recursions = append(output.Left_recursions, left_recursion)
+			terms = rewritten
+		}
+		output.Rules[non_term] = terms
+		done = append(done, non_term)
+	}
+
+	if left := Left_recursive_rules(output.Rules); len(left) > 0 {
+		return grammar, errors.New("the left recursion of '" + left[0] + "' cannot be rewritten, it is inside a bracket or a repetition")
+	}
+	return &output, nil
+}
+
+// rule_order gives the rules in the order of the file, then the ones which are not in `Order` (like those of a grammar made from its rules only) by name
+func rule_order(grammar *Grammar) []Non_terminal {
+	order := append([]Non_terminal{}, grammar.Order...)
+	others := []Non_terminal{}
+	for non_term := range grammar.Rules {
+		if !slices.Contains(order, non_term) {
+			others = append(others, non_term)
+		}
+	}
+	sort.Slice(others, func(i, j int) bool {
+		return others[i].Name < others[j].Name
+	})
+	return append(order, others...)
+}
+
+// left_corners gives the non-terminals which the alternatives of a production can start with
+func left_corners(terms []Generic_grammar_term) []string {
+	names := []string{}
+	for _, alternative := range Alternatives(terms) {
+		sequence_corners(alternative, &names)
+	}
+	return names
+}
+
+// sequence_corners adds the non-terminals a sequence can start with, and tells if it can match nothing as far as they are concerned
+func sequence_corners(terms []Generic_grammar_term, names *[]string) bool {
+	for _, term := range terms {
+		if !term_corners(term, names) {
+			return false
+		}
+	}
+	return true
+}
+
+// term_corners adds the non-terminals a term can start with, and tells if what follows it can start the sequence too
+func term_corners(term Generic_grammar_term, names *[]string) bool {
+	switch term := term.(type) {
+	case *Non_terminal:
+		*names = append(*names, term.Name)
+		return false
+	case *Labelled:
+		return term_corners(term.Content, names)
+	case *Bracket:
+		nullable := false
+		for _, alternative := range Alternatives(term.Contents) {
+			if sequence_corners(alternative, names) {
+				nullable = true
+			}
+		}
+		return nullable
+	case *Plus:
+		return term_corners(term.Content, names)
+	case *Star:
+		term_corners(term.Content, names)
+		return true
+	case *Optional:
+		term_corners(term.Content, names)
+		return true
+	case *Action:
+		return true
+	}
+	return false
+}
+
+// reaches tells if `from` can start with `to`, through as many rules as it takes
+func reaches(corners map[string][]string, from string, to string) bool {
+	seen := map[string]bool{}
+	pending := append([]string{}, corners[from]...)
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+		if name == to {
+			return true
+		}
+		if !seen[name] {
+			seen[name] = true
+			pending = append(pending, corners[name]...)
+		}
+	}
+	return false
+}
+
+// starts_with tells if an alternative starts with the non-terminal, and gives the label it was given there
+func starts_with(alternative []Generic_grammar_term, name string) (string, bool) {
+	if len(alternative) == 0 {
+		return "", false
+	}
+	label := ""
+	first := alternative[0]
+	if labelled, is_labelled := first.(*Labelled); is_labelled {
+		label, first = labelled.Label, labelled.Content
+	}
+	if non_term, is_non_term := first.(*Non_terminal); is_non_term && non_term.Name == name {
+		return label, true
+	}
+	return "", false
+}
+
+func is_directly_left_recursive(name string, terms []Generic_grammar_term) bool {
+	for _, alternative := range Alternatives(terms) {
+		if _, found := starts_with(alternative, name); found {
+			return true
+		}
+	}
+	return false
+}
+
+// join_alternatives puts alternatives back into one production, with an `or` between each of them
+func (grammar *Grammar) join_alternatives(alternatives [][]Generic_grammar_term, span errorhandler.Span) []Generic_grammar_term {
+	terms := []Generic_grammar_term{}
+	for i, alternative := range alternatives {
+		if i > 0 {
+			or := &Or{}
+			grammar.Locations[or] = span
+			terms = append(terms, or)
+		}
+		terms = append(terms, alternative...)
+	}
+	return terms
+}
+
+// definition gives where a rule was written, for the terms made when rewriting it
+func (grammar *Grammar) definition(non_term Non_terminal) errorhandler.Span {
+	if spans := grammar.Definitions[non_term]; len(spans) > 0 {
+		return spans[len(spans)-1]
+	}
+	return errorhandler.Span{}
+}
+
+// put_in_place replaces `previous` by its alternatives where an alternative of `non_term` starts with it
+func (grammar *Grammar) put_in_place(non_term Non_terminal, terms []Generic_grammar_term, previous Non_terminal) ([]Generic_grammar_term, error) {
+	alternatives := [][]Generic_grammar_term{}
+	replaced := false
+	for i, alternative := range Alternatives(terms) {
+		if _, found := starts_with(alternative, previous.Name); !found {
+			alternatives = append(alternatives, alternative)
+			continue
+		}
+		if Alternative_actions(terms)[i] != nil || has_action(grammar.Rules[previous]) {
+			return nil, errors.New("'" + non_term.Name + "' is left-recursive through '" + previous.Name + "', which cannot be put in its place as their alternatives have actions")
+		}
+		for _, inlined := range Alternatives(grammar.Rules[previous]) {
+			alternatives = append(alternatives, append(append([]Generic_grammar_term{}, inlined...), alternative[1:]...))
+		}
+		replaced = true
+	}
+	if !replaced {
+		return terms, nil
+	}
+	return grammar.join_alternatives(alternatives, grammar.definition(non_term)), nil
+}
+
+// rewrite_left_recursion rewrites a rule which starts with itself into its bases followed by its tails repeated
+func (grammar *Grammar) rewrite_left_recursion(non_term Non_terminal, terms []Generic_grammar_term) (Left_recursion, []Generic_grammar_term, error) {
+	left_recursion := Left_recursion{Name: non_term.Name, Written: terms}
+	for i, alternative := range Alternatives(terms) {
+		label, is_tail := starts_with(alternative, non_term.Name)
+		if !is_tail {
+			left_recursion.Bases = append(left_recursion.Bases, Left_recursive_alternative{Alternative: i, Terms: Without_actions(alternative)})
+			continue
+		}
+		rest := Without_actions(alternative[1:])
+		if len(rest) == 0 {
+			return left_recursion, nil, errors.New("an alternative of '" + non_term.Name + "' is nothing but '" + non_term.Name + "' itself")
+		}
+		left_recursion.Tails = append(left_recursion.Tails, Left_recursive_alternative{Alternative: i, Terms: rest, Label: label})
+	}
+	if len(left_recursion.Bases) == 0 {
+		return left_recursion, nil, errors.New("every alternative of '" + non_term.Name + "' starts with '" + non_term.Name + "', it can never be matched")
+	}
+	if has_action(terms) && !grammar.Reassociate {
+		return left_recursion, nil, errors.New("'" + non_term.Name + "' is left-recursive and has actions, which need its nodes as written: add '%reassociate' to the grammar")
+	}
+
+	span := grammar.definition(non_term)
+	locate := func(term Generic_grammar_term) Generic_grammar_term {
+		grammar.Locations[term] = span
+		return term
+	}
+	alternatives_of := func(alternatives []Left_recursive_alternative) [][]Generic_grammar_term {
+		output := [][]Generic_grammar_term{}
+		for _, alternative := range alternatives {
+			output = append(output, alternative.Terms)
+		}
+		return output
+	}
+
+	rewritten := left_recursion.Bases[0].Terms
+	if len(left_recursion.Bases) > 1 {
+		rewritten = []Generic_grammar_term{locate(&Bracket{Contents: grammar.join_alternatives(alternatives_of(left_recursion.Bases), span)})}
+	}
+	tails := locate(&Star{Content: locate(&Bracket{Contents: grammar.join_alternatives(alternatives_of(left_recursion.Tails), span)})})
+	rewritten = append(append([]Generic_grammar_term{}, rewritten...), tails)
+	return left_recursion, rewritten, nil
+}
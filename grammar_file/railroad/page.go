@@ -0,0 +1,123 @@
+package railroad
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/VirajAgarwal1/lox/grammar_file"
+)
+
+// Anchor is the id of the diagram of a non-terminal in the page of `Write_html`
+func Anchor(name string) string {
+	return "rule-" + name
+}
+
+const page_style = `body { font-family: sans-serif; margin: 2em; }
+h2 { font-family: monospace; font-size: 1.2em; margin-bottom: 0.2em; }
+h2 a, p.used-by a { color: inherit; }
+p.used-by { font-size: 0.9em; color: #555; }
+svg.railroad text { font-family: monospace; font-size: 14px; text-anchor: middle; fill: black; stroke: none; }
+svg.railroad g.terminal rect { fill: #feffdf; }
+svg.railroad g.nonterminal rect { fill: #e8f0ff; }
+svg.railroad a:hover rect { fill: #c8dcff; }
+`
+
+// Write_html writes one HTML page with the diagrams of all the non-terminals, in the order of the grammar file. Every non-terminal box links to the diagram of its rule, and every diagram lists the rules using it.
+func Write_html(w io.Writer, grammar *grammar_file.Grammar, title string) error {
+	link := func(name string) string {
+		if !is_defined(grammar, name) {
+			return ""
+		}
+		return "#" + Anchor(name)
+	}
+	used_by := users(grammar)
+
+	var out strings.Builder
+	out.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&out, "<title>%s</title>\n<style>\n%s</style>\n</head>\n<body>\n", html.EscapeString(title), page_style)
+	fmt.Fprintf(&out, "<h1>%s</h1>\n", html.EscapeString(title))
+	for _, rule := range grammar.Ordered_rules() {
+		name := rule.Name.Name
+		fmt.Fprintf(&out, "<section>\n<h2 id=\"%s\"><a href=\"#%s\">%s</a></h2>\n", Anchor(name), Anchor(name), html.EscapeString(name))
+		out.WriteString(Diagram(rule.Terms, link))
+		if len(used_by[name]) > 0 {
+			out.WriteString("<p class=\"used-by\">Used by:")
+			for _, user := range used_by[name] {
+				fmt.Fprintf(&out, " <a href=\"#%s\">%s</a>", Anchor(user), html.EscapeString(user))
+			}
+			out.WriteString("</p>\n")
+		}
+		out.WriteString("</section>\n")
+	}
+	out.WriteString("</body>\n</html>\n")
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// Write_svgs writes the diagram of every non-terminal to `dir/<name>.svg`, the non-terminal boxes linking to the other files
+func Write_svgs(dir string, grammar *grammar_file.Grammar) error {
+	link := func(name string) string {
+		if !is_defined(grammar, name) {
+			return ""
+		}
+		return name + ".svg"
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, rule := range grammar.Ordered_rules() {
+		// Standalone SVG files cannot use the page's style sheet, so they carry their own
+		svg := strings.Replace(Diagram(rule.Terms, link), "\n", "\n<style>\n"+svg_style+"</style>\n", 1)
+		if err := os.WriteFile(filepath.Join(dir, rule.Name.Name+".svg"), []byte(svg), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const svg_style = `text { font-family: monospace; font-size: 14px; text-anchor: middle; fill: black; stroke: none; }
+g.terminal rect { fill: #feffdf; }
+g.nonterminal rect { fill: #e8f0ff; }
+`
+
+func is_defined(grammar *grammar_file.Grammar, name string) bool {
+	_, found := grammar.Rules[grammar_file.Non_terminal{Name: name}]
+	return found
+}
+
+// users gives, for every non-terminal, the other rules using it, in the order of the grammar file
+func users(grammar *grammar_file.Grammar) map[string][]string {
+	used_by := map[string][]string{}
+	for _, rule := range grammar.Ordered_rules() {
+		seen := map[string]bool{}
+		var collect func(terms []grammar_file.Generic_grammar_term)
+		collect = func(terms []grammar_file.Generic_grammar_term) {
+			for _, term := range terms {
+				switch term := term.(type) {
+				case *grammar_file.Non_terminal:
+					if term.Name != rule.Name.Name && !seen[term.Name] {
+						seen[term.Name] = true
+						used_by[term.Name] = append(used_by[term.Name], rule.Name.Name)
+					}
+				case *grammar_file.Star:
+					collect([]grammar_file.Generic_grammar_term{term.Content})
+				case *grammar_file.Plus:
+					collect([]grammar_file.Generic_grammar_term{term.Content})
+				case *grammar_file.Optional:
+					collect([]grammar_file.Generic_grammar_term{term.Content})
+				case *grammar_file.Labelled:
+					collect([]grammar_file.Generic_grammar_term{term.Content})
+				case *grammar_file.Bracket:
+					collect(term.Contents)
+				}
+			}
+		}
+		collect(rule.Terms)
+	}
+	return used_by
+}
@@ -0,0 +1,273 @@
+/*
+Package railroad draws railroad (syntax) diagrams of grammar files as SVG: one diagram per non-terminal, following the terms of the grammar AST.
+
+  - terminals are rounded boxes, non-terminals square boxes linking to their own diagram
+  - a sequence is drawn left to right, every `or` choice is a branch below the first one
+  - `+` loops back under its content, `*` is a `+` which can also be skipped, and `?` / `[ ... ]` can be skipped
+  - brackets are drawn as their contents, they only group
+
+The layout is done in two steps, like most railroad generators: every node first tells its size (`measure`), and is then drawn with its entry at a given point of the track (`draw`). All nodes are entered on the left and left on the right at the same height, their baseline.
+*/
+package railroad
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/VirajAgarwal1/lox/grammar_file"
+)
+
+const (
+	arc_radius  = 10 // Radius of the bends of the track, also the space around the nodes
+	char_width  = 8  // Width of a character in the monospace font of the boxes
+	box_height  = 24
+	box_padding = 10 // Space between the text of a box and its sides
+	margin      = 10 // Space around the whole diagram
+)
+
+// Link gives the address of the diagram of a non-terminal, or "" if it should not be a link
+type Link func(name string) string
+
+type node interface {
+	// measure gives the width of the node and its height above and below the baseline
+	measure() (width int, up int, down int)
+	// draw writes the SVG of the node, entering it at (x, y)
+	draw(out *strings.Builder, x int, y int)
+}
+
+// ---------------------------------------------------------------------------------
+// Nodes
+// ---------------------------------------------------------------------------------
+
+type box struct {
+	text     string
+	terminal bool
+	href     string
+}
+
+func (b *box) measure() (int, int, int) {
+	return utf8.RuneCountInString(b.text)*char_width + 2*box_padding, box_height / 2, box_height / 2
+}
+func (b *box) draw(out *strings.Builder, x int, y int) {
+	width, up, _ := b.measure()
+	class, radius := "nonterminal", 0
+	if b.terminal {
+		class, radius = "terminal", box_height/2
+	}
+	if b.href != "" {
+		fmt.Fprintf(out, `<a href="%s">`, html.EscapeString(b.href))
+	}
+	fmt.Fprintf(out, `<g class="%s"><rect x="%d" y="%d" width="%d" height="%d" rx="%d" ry="%d"/>`, class, x, y-up, width, box_height, radius, radius)
+	fmt.Fprintf(out, `<text x="%d" y="%d">%s</text></g>`, x+width/2, y+4, html.EscapeString(b.text))
+	if b.href != "" {
+		out.WriteString(`</a>`)
+	}
+	out.WriteString("\n")
+}
+
+// skip is the empty track, for the alternatives which match nothing
+type skip struct{}
+
+func (s *skip) measure() (int, int, int) {
+	return 0, 0, 0
+}
+func (s *skip) draw(out *strings.Builder, x int, y int) {}
+
+type sequence struct {
+	items []node
+}
+
+func (s *sequence) measure() (int, int, int) {
+	width, up, down := 0, 0, 0
+	for i, item := range s.items {
+		w, u, d := item.measure()
+		if i > 0 {
+			width += arc_radius
+		}
+		width, up, down = width+w, max(up, u), max(down, d)
+	}
+	return width, up, down
+}
+func (s *sequence) draw(out *strings.Builder, x int, y int) {
+	for i, item := range s.items {
+		if i > 0 {
+			line(out, x, y, x+arc_radius, y)
+			x += arc_radius
+		}
+		item.draw(out, x, y)
+		w, _, _ := item.measure()
+		x += w
+	}
+}
+
+// choice has its first alternative on the baseline and the others branching below it
+type choice struct {
+	alternatives []node
+}
+
+// baselines gives how far below the choice's baseline each alternative is drawn
+func (c *choice) baselines() []int {
+	offsets := make([]int, len(c.alternatives))
+	_, _, below := c.alternatives[0].measure()
+	for i := 1; i < len(c.alternatives); i++ {
+		_, u, d := c.alternatives[i].measure()
+		offsets[i] = max(below+arc_radius+u, offsets[i-1]+2*arc_radius)
+		below = offsets[i] + d
+	}
+	return offsets
+}
+func (c *choice) inner_width() int {
+	width := 0
+	for _, alternative := range c.alternatives {
+		w, _, _ := alternative.measure()
+		width = max(width, w)
+	}
+	return width
+}
+func (c *choice) measure() (int, int, int) {
+	_, up, down := c.alternatives[0].measure()
+	offsets := c.baselines()
+	if last := len(c.alternatives) - 1; last > 0 {
+		_, _, d := c.alternatives[last].measure()
+		down = offsets[last] + d
+	}
+	return c.inner_width() + 4*arc_radius, up, down
+}
+func (c *choice) draw(out *strings.Builder, x int, y int) {
+	inner := c.inner_width()
+	end := x + inner + 4*arc_radius
+	for i, alternative := range c.alternatives {
+		w, _, _ := alternative.measure()
+		ay := y + c.baselines()[i]
+		if i == 0 {
+			line(out, x, y, x+2*arc_radius, y)
+		} else {
+			// Down from the entry, and back up to the exit
+			fmt.Fprintf(out, `<path d="M%d %d a%d %d 0 0 1 %d %d v%d a%d %d 0 0 0 %d %d"/>`+"\n", x, y, arc_radius, arc_radius, arc_radius, arc_radius, ay-y-2*arc_radius, arc_radius, arc_radius, arc_radius, arc_radius)
+			fmt.Fprintf(out, `<path d="M%d %d a%d %d 0 0 0 %d %d v%d a%d %d 0 0 1 %d %d"/>`+"\n", end-2*arc_radius, ay, arc_radius, arc_radius, arc_radius, -arc_radius, -(ay - y - 2*arc_radius), arc_radius, arc_radius, arc_radius, -arc_radius)
+		}
+		alternative.draw(out, x+2*arc_radius, ay)
+		line(out, x+2*arc_radius+w, ay, end-2*arc_radius, ay)
+		if i == 0 {
+			line(out, end-2*arc_radius, y, end, y)
+		}
+	}
+}
+
+// loop is drawn with its content on the baseline and a track going back under it
+type loop struct {
+	content node
+}
+
+func (l *loop) bottom() int {
+	_, _, d := l.content.measure()
+	return max(d+arc_radius, 2*arc_radius)
+}
+func (l *loop) measure() (int, int, int) {
+	w, u, _ := l.content.measure()
+	return w + 2*arc_radius, u, l.bottom()
+}
+func (l *loop) draw(out *strings.Builder, x int, y int) {
+	w, _, _ := l.content.measure()
+	line(out, x, y, x+arc_radius, y)
+	l.content.draw(out, x+arc_radius, y)
+	line(out, x+arc_radius+w, y, x+w+2*arc_radius, y)
+
+	bottom := y + l.bottom()
+	fmt.Fprintf(out, `<path d="M%d %d a%d %d 0 0 1 %d %d v%d a%d %d 0 0 1 %d %d H%d a%d %d 0 0 1 %d %d v%d a%d %d 0 0 1 %d %d"/>`+"\n",
+		x+w+arc_radius, y,
+		arc_radius, arc_radius, arc_radius, arc_radius,
+		bottom-y-2*arc_radius,
+		arc_radius, arc_radius, -arc_radius, arc_radius,
+		x+arc_radius,
+		arc_radius, arc_radius, -arc_radius, -arc_radius,
+		-(bottom - y - 2*arc_radius),
+		arc_radius, arc_radius, arc_radius, -arc_radius,
+	)
+}
+
+func line(out *strings.Builder, x1 int, y1 int, x2 int, y2 int) {
+	if x1 == x2 && y1 == y2 {
+		return
+	}
+	fmt.Fprintf(out, `<path d="M%d %d H%d"/>`+"\n", x1, y1, x2)
+}
+
+// ---------------------------------------------------------------------------------
+// From the grammar AST
+// ---------------------------------------------------------------------------------
+
+// build_sequence turns terms, which may contain `or`s, into a node
+func build_sequence(terms []grammar_file.Generic_grammar_term, link Link) node {
+	alternatives := [][]grammar_file.Generic_grammar_term{{}}
+	for _, term := range grammar_file.Without_actions(terms) { // Actions match nothing, the diagram has no place for them
+		if term.Get_grammar_term_type() == "or" {
+			alternatives = append(alternatives, []grammar_file.Generic_grammar_term{})
+			continue
+		}
+		alternatives[len(alternatives)-1] = append(alternatives[len(alternatives)-1], term)
+	}
+
+	nodes := make([]node, 0, len(alternatives))
+	for _, alternative := range alternatives {
+		items := make([]node, 0, len(alternative))
+		for _, term := range alternative {
+			items = append(items, build_term(term, link))
+		}
+		switch len(items) {
+		case 0:
+			nodes = append(nodes, &skip{})
+		case 1:
+			nodes = append(nodes, items[0])
+		default:
+			nodes = append(nodes, &sequence{items: items})
+		}
+	}
+	if len(nodes) == 1 {
+		return nodes[0]
+	}
+	return &choice{alternatives: nodes}
+}
+
+func build_term(term grammar_file.Generic_grammar_term, link Link) node {
+	switch term := term.(type) {
+	case *grammar_file.Terminal:
+		return &box{text: string(term.Content), terminal: true}
+	case *grammar_file.Non_terminal:
+		return &box{text: term.Name, href: link(term.Name)}
+	case *grammar_file.Plus:
+		return &loop{content: build_term(term.Content, link)}
+	case *grammar_file.Star:
+		return &choice{alternatives: []node{&skip{}, &loop{content: build_term(term.Content, link)}}}
+	case *grammar_file.Optional:
+		return &choice{alternatives: []node{&skip{}, build_term(term.Content, link)}}
+	case *grammar_file.Bracket:
+		return build_sequence(term.Contents, link)
+	case *grammar_file.Labelled:
+		return build_term(term.Content, link) // Labels name the nodes of the tree, they do not change what is matched
+	}
+	return &skip{}
+}
+
+// Diagram gives the SVG of the railroad diagram for the terms of a production
+func Diagram(terms []grammar_file.Generic_grammar_term, link Link) string {
+	content := build_sequence(terms, link)
+	width, up, down := content.measure()
+
+	// The track starts and ends with a short bar across it
+	total_width := width + 4*arc_radius + 2*margin
+	total_height := up + down + 2*margin + 2*arc_radius
+	x, y := margin, margin+arc_radius+up
+
+	var out strings.Builder
+	fmt.Fprintf(&out, `<svg xmlns="http://www.w3.org/2000/svg" class="railroad" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", total_width, total_height, total_width, total_height)
+	out.WriteString(`<g fill="none" stroke="black" stroke-width="2">` + "\n")
+	fmt.Fprintf(&out, `<path d="M%d %d v%d M%d %d H%d"/>`+"\n", x, y-arc_radius, 2*arc_radius, x, y, x+2*arc_radius)
+	content.draw(&out, x+2*arc_radius, y)
+	end := x + 2*arc_radius + width
+	fmt.Fprintf(&out, `<path d="M%d %d H%d M%d %d v%d"/>`+"\n", end, y, end+2*arc_radius, end+2*arc_radius, y-arc_radius, 2*arc_radius)
+	out.WriteString("</g>\n</svg>\n")
+	return out.String()
+}
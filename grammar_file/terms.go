@@ -0,0 +1,92 @@
+package grammar_file
+
+// TODO: This parser accepts grammar in EBNF* format.... It doesn't accept Epsilons on their own, they can only be written with `?` or `[ ... ]`
+
+// This interface actually is used to refer to all the types' pointers.
+type Generic_grammar_term interface {
+	Get_grammar_term_type() string
+	// Follow_set() []dfa.TokenType
+}
+
+type Terminal struct {
+	Content []rune
+}
+type Non_terminal struct {
+	Name string
+}
+type Or struct {
+	_ byte // Pointers to zero sized values may all be equal, this gives every `or` its own entry in `Grammar.Locations`
+}
+type Star struct {
+	Content Generic_grammar_term
+}
+type Plus struct {
+	Content Generic_grammar_term
+}
+type Bracket struct {
+	Contents  []Generic_grammar_term
+	Is_left   bool
+	Is_square bool // Only set on the left bracket of `[ ... ]`, while it is waiting on the stack for its right bracket
+}
+type Optional struct {
+	Content Generic_grammar_term
+}
+type Action struct {
+	Code string // Go code written between the braces of `{ ... }`, as it was written
+}
+type Labelled struct {
+	Label   string               // Name given to the element with `label:element`, which the parser generators turn into a field of the node
+	Content Generic_grammar_term // Nil while the parser has only read `label:`
+}
+
+func (t *Terminal) Get_grammar_term_type() string {
+	return "terminal"
+}
+func (t *Non_terminal) Get_grammar_term_type() string {
+	return "non_terminal"
+}
+func (t *Or) Get_grammar_term_type() string {
+	return "or"
+}
+func (t *Star) Get_grammar_term_type() string {
+	return "star"
+}
+func (t *Plus) Get_grammar_term_type() string {
+	return "plus"
+}
+func (t *Bracket) Get_grammar_term_type() string {
+	return "bracket"
+}
+func (t *Optional) Get_grammar_term_type() string {
+	return "optional"
+}
+func (t *Action) Get_grammar_term_type() string {
+	return "action"
+}
+func (t *Labelled) Get_grammar_term_type() string {
+	return "labelled"
+}
+
+// Detect_or_in_sequence gives the indices of the `or`s in a sequence of terms
+func Detect_or_in_sequence(description []Generic_grammar_term) []uint32 {
+	out := make([]uint32, 0, len(description)/4)
+	for i, term := range description {
+		if term.Get_grammar_term_type() == "or" {
+			out = append(out, uint32(i))
+		}
+	}
+	return out
+}
+
+// Alternatives splits the top-level terms of a production at its `or`s
+func Alternatives(terms []Generic_grammar_term) [][]Generic_grammar_term {
+	alts := [][]Generic_grammar_term{{}}
+	for _, term := range terms {
+		if term.Get_grammar_term_type() == "or" {
+			alts = append(alts, []Generic_grammar_term{})
+			continue
+		}
+		alts[len(alts)-1] = append(alts[len(alts)-1], term)
+	}
+	return alts
+}
@@ -0,0 +1,105 @@
+package grammar_file
+
+import (
+	"strconv"
+
+	"github.com/VirajAgarwal1/lox/lexer/dfa"
+)
+
+// Token_names gives the name of the `dfa` constant of every token type. A terminal is written in a grammar file with the value of its token type (like "(" or "NUMBER"), and generated parsers refer to it by this name.
+var Token_names = map[dfa.TokenType]string{
+	// Literals
+	dfa.EOF:        "EOF",
+	dfa.IDENTIFIER: "IDENTIFIER",
+	dfa.STRING:     "STRING",
+	dfa.NUMBER:     "NUMBER",
+	dfa.COMMENT:    "COMMENT",
+
+	// Single-char tokens
+	dfa.WHITESPACE:  "WHITESPACE",
+	dfa.NEWLINE:     "NEWLINE",
+	dfa.LEFT_PAREN:  "LEFT_PAREN",
+	dfa.RIGHT_PAREN: "RIGHT_PAREN",
+	dfa.LEFT_BRACE:  "LEFT_BRACE",
+	dfa.RIGHT_BRACE: "RIGHT_BRACE",
+	dfa.COMMA:       "COMMA",
+	dfa.DOT:         "DOT",
+	dfa.MINUS:       "MINUS",
+	dfa.PLUS:        "PLUS",
+	dfa.SEMICOLON:   "SEMICOLON",
+	dfa.SLASH:       "SLASH",
+	dfa.STAR:        "STAR",
+
+	dfa.QUESTION:      "QUESTION",
+	dfa.LEFT_BRACKET:  "LEFT_BRACKET",
+	dfa.RIGHT_BRACKET: "RIGHT_BRACKET",
+	dfa.PERCENT:       "PERCENT",
+	dfa.COLON:         "COLON",
+
+	// One-or-two char tokens
+	dfa.BANG:          "BANG",
+	dfa.BANG_EQUAL:    "BANG_EQUAL",
+	dfa.EQUAL:         "EQUAL",
+	dfa.EQUAL_EQUAL:   "EQUAL_EQUAL",
+	dfa.GREATER:       "GREATER",
+	dfa.GREATER_EQUAL: "GREATER_EQUAL",
+	dfa.LESS:          "LESS",
+	dfa.LESS_EQUAL:    "LESS_EQUAL",
+
+	// Keywords
+	dfa.AND:    "AND",
+	dfa.CLASS:  "CLASS",
+	dfa.ELSE:   "ELSE",
+	dfa.FALSE:  "FALSE",
+	dfa.FUN:    "FUN",
+	dfa.FOR:    "FOR",
+	dfa.IF:     "IF",
+	dfa.NIL:    "NIL",
+	dfa.OR:     "OR",
+	dfa.PRINT:  "PRINT",
+	dfa.RETURN: "RETURN",
+	dfa.SUPER:  "SUPER",
+	dfa.THIS:   "THIS",
+	dfa.TRUE:   "TRUE",
+	dfa.VAR:    "VAR",
+	dfa.WHILE:  "WHILE",
+}
+
+// Token_aliases are the other ways of writing the tokens which are hard to write between quotes, mostly used by `%skip`
+var Token_aliases = map[string]dfa.TokenType{
+	"WHITESPACE": dfa.WHITESPACE,
+	"NEWLINE":    dfa.NEWLINE,
+	"\\n":        dfa.NEWLINE,
+}
+
+// Lookup_token gives the lexer's token type for a terminal written in a grammar file
+func Lookup_token(name string) (dfa.TokenType, bool) {
+	if token, found := Token_aliases[name]; found {
+		return token, true
+	}
+	if _, found := Token_names[dfa.TokenType(name)]; found {
+		return dfa.TokenType(name), true
+	}
+	return dfa.TokenType(""), false
+}
+
+// Resolve_token is `Lookup_token` which also accepts the terminals declared with `%token`, as token types of their own name
+func Resolve_token(name string, declared_tokens []string) (dfa.TokenType, bool) {
+	if token, found := Lookup_token(name); found {
+		return token, true
+	}
+	for _, declared := range declared_tokens {
+		if declared == name {
+			return dfa.TokenType(name), true
+		}
+	}
+	return dfa.TokenType(""), false
+}
+
+// Token_type_code gives the Go code for a token type, for the generated parsers
+func Token_type_code(token dfa.TokenType) string {
+	if name, found := Token_names[token]; found {
+		return "dfa." + name
+	}
+	return "dfa.TokenType(" + strconv.Quote(string(token)) + ")"
+}
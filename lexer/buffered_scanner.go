@@ -3,6 +3,9 @@ package lexer
 import (
 	"bufio"
 	"fmt"
+
+	"github.com/VirajAgarwal1/lox/errorhandler"
+	"github.com/VirajAgarwal1/lox/source"
 )
 
 type BufferedLexicalAnalyzer struct {
@@ -17,9 +20,12 @@ type BufferedLexicalAnalyzer struct {
 	scanner *LexicalAnalyzer
 }
 
-func (b *BufferedLexicalAnalyzer) Initialize(source *bufio.Reader) {
+func (b *BufferedLexicalAnalyzer) Initialize(source_reader *bufio.Reader) {
+	b.InitializeWithFile(source_reader, source.NewFileSet().AddFile("", nil))
+}
+func (b *BufferedLexicalAnalyzer) InitializeWithFile(source_reader *bufio.Reader, file *source.File) {
 	scanner := LexicalAnalyzer{}
-	scanner.Initialize(source)
+	scanner.InitializeWithFile(source_reader, file)
 	b.scanner = &scanner
 
 	b.prev_i = 0
@@ -62,3 +68,20 @@ func (b *BufferedLexicalAnalyzer) Peek() (*Token, error) {
 func (b *BufferedLexicalAnalyzer) LookBack() (*Token, error) {
 	return b.buffer[b.prev_i].t, b.buffer[b.prev_i].err
 }
+
+// SetDiagnostics makes the scanner record every error it returns in the given collector, including the errors of the tokens already buffered
+func (b *BufferedLexicalAnalyzer) SetDiagnostics(diagnostics *errorhandler.Diagnostics) {
+	b.scanner.SetDiagnostics(diagnostics)
+	if diagnostics == nil {
+		return
+	}
+	for _, i := range []int8{b.cur_i, b.next_i} {
+		diagnostics.AddError(b.buffer[i].err)
+	}
+}
+func (b *BufferedLexicalAnalyzer) Diagnostics() *errorhandler.Diagnostics {
+	return b.scanner.Diagnostics()
+}
+func (b *BufferedLexicalAnalyzer) File() *source.File {
+	return b.scanner.File()
+}
@@ -26,6 +26,12 @@ const (
 	SEMICOLON   TokenType = ";"
 	SLASH       TokenType = "/"
 	STAR        TokenType = "*"
+	// Single-char tokens which are not part of Lox, but of the grammar file notation. Lox source is read by the same lexer, so they are tokens there as well, and the parsers report them as unexpected tokens instead of the lexer as invalid characters.
+	QUESTION      TokenType = "?"
+	LEFT_BRACKET  TokenType = "["
+	RIGHT_BRACKET TokenType = "]"
+	PERCENT       TokenType = "%"
+	COLON         TokenType = ":"
 	// One-or-two char tokens
 	BANG          TokenType = "!"
 	BANG_EQUAL    TokenType = "!="
@@ -76,6 +82,11 @@ var TokensList = []TokenType{
 	SEMICOLON,
 	SLASH,
 	STAR,
+	QUESTION,
+	LEFT_BRACKET,
+	RIGHT_BRACKET,
+	PERCENT,
+	COLON,
 
 	BANG,
 	BANG_EQUAL,
@@ -257,3 +268,23 @@ func (s *DfaResult) IsIntermediate() bool {
 func (s *DfaResult) IsInvalid() bool {
 	return *s == INVALID
 }
+
+// Name gives a printable name for token types whose value is not readable (like whitespace and newline)
+func (tokenType TokenType) Name() string {
+	switch tokenType {
+	case WHITESPACE:
+		return "WHITESPACE"
+	case NEWLINE:
+		return "NEWLINE"
+	}
+	return string(tokenType)
+}
+
+// Names gives the `Name` of each of the token types
+func Names(tokenTypes []TokenType) []string {
+	names := make([]string, len(tokenTypes))
+	for i, tokenType := range tokenTypes {
+		names[i] = tokenType.Name()
+	}
+	return names
+}
@@ -2,8 +2,11 @@ package lexer
 
 import (
 	"bufio"
+	"slices"
 
 	"github.com/VirajAgarwal1/lox/errorhandler"
+	"github.com/VirajAgarwal1/lox/lexer/dfa"
+	"github.com/VirajAgarwal1/lox/source"
 )
 
 type Checkpoint int
@@ -27,20 +30,31 @@ func (im *indexManager) rollbackTo(chk Checkpoint) {
 	im.currentIndex = int(chk)
 }
 
+// mismatch is the furthest token a parser read without expecting it, with the token types it expected there
+type mismatch struct {
+	index    int
+	expected []dfa.TokenType
+}
+
 type BufferedLexer struct {
-	scanner *LexicalAnalyzer
-	buffer  []lexerResult
-	index   indexManager
+	scanner  *LexicalAnalyzer
+	buffer   []lexerResult
+	index    indexManager
+	mismatch mismatch
 }
 
-func (buf_lex *BufferedLexer) Initialize(source *bufio.Reader, max_bufer_capacity uint32) {
+func (buf_lex *BufferedLexer) Initialize(source_reader *bufio.Reader, max_bufer_capacity uint32) {
+	buf_lex.InitializeWithFile(source_reader, max_bufer_capacity, source.NewFileSet().AddFile("", nil))
+}
+func (buf_lex *BufferedLexer) InitializeWithFile(source_reader *bufio.Reader, max_bufer_capacity uint32, file *source.File) {
 	scanner := LexicalAnalyzer{}
-	scanner.Initialize(source)
+	scanner.InitializeWithFile(source_reader, file)
 	buf_lex.scanner = &scanner
 
 	buf_lex.buffer = make([]lexerResult, 0, max_bufer_capacity)
 
 	buf_lex.index.initialize()
+	buf_lex.mismatch = mismatch{index: -1}
 }
 func (buf_lex *BufferedLexer) currentIndexPointsToLegitToken() bool {
 	return buf_lex.index.currentIndex >= 0 && buf_lex.index.currentIndex < len(buf_lex.buffer)
@@ -59,13 +73,58 @@ func (buf_lex *BufferedLexer) ReadToken() (*Token, error) {
 	}
 	// , then you read a new token from the lexer, but only if the buffer has space to accomodate the new token
 	if len(buf_lex.buffer) == cap(buf_lex.buffer) {
-		return nil, errorhandler.RetErr("Lexer Error: Input Buffer Overflow", nil)
+		overflow_pos := source.NoPos
+		if len(buf_lex.buffer) > 0 && buf_lex.buffer[len(buf_lex.buffer)-1].tok != nil {
+			overflow_pos = buf_lex.buffer[len(buf_lex.buffer)-1].tok.End
+		}
+		limit_err := &errorhandler.LimitError{
+			Code:     errorhandler.CodeBufferOverflow,
+			What:     "lexer input buffer",
+			Limit:    cap(buf_lex.buffer),
+			Pos:      overflow_pos,
+			Position: buf_lex.File().Position(overflow_pos),
+		}
+		if buf_lex.scanner.Diagnostics() != nil {
+			buf_lex.scanner.Diagnostics().AddError(limit_err)
+		}
+		return nil, errorhandler.RetErr("", limit_err)
 	}
 	tok, err := buf_lex.scanner.ReadToken()
 	buf_lex.buffer = append(buf_lex.buffer, lexerResult{tok, err})
 	return tok, err
 }
+
+// SetDiagnostics makes the lexer record every error it returns in the given collector as well
+func (buf_lex *BufferedLexer) SetDiagnostics(diagnostics *errorhandler.Diagnostics) {
+	buf_lex.scanner.SetDiagnostics(diagnostics)
+}
+func (buf_lex *BufferedLexer) File() *source.File {
+	return buf_lex.scanner.File()
+}
 func (buf_lex *BufferedLexer) ClearBuffer() {
 	buf_lex.index.currentIndex = -1
 	buf_lex.buffer = buf_lex.buffer[:0]
+	buf_lex.mismatch = mismatch{index: -1}
+}
+
+// Mismatch records that a parser expected a token of type `expected` where it read the last token. A parser which backtracks tries many alternatives before giving up, only the mismatches of the furthest token are kept as that is where the input went wrong.
+func (buf_lex *BufferedLexer) Mismatch(expected dfa.TokenType) {
+	index := buf_lex.index.currentIndex
+	if index < buf_lex.mismatch.index || !buf_lex.currentIndexPointsToLegitToken() {
+		return
+	}
+	if index > buf_lex.mismatch.index {
+		buf_lex.mismatch = mismatch{index: index}
+	}
+	if !slices.Contains(buf_lex.mismatch.expected, expected) {
+		buf_lex.mismatch.expected = append(buf_lex.mismatch.expected, expected)
+	}
+}
+
+// FurthestMismatch gives the furthest token given to `Mismatch` and the token types which were expected there, in the order they were, nil if there was none
+func (buf_lex *BufferedLexer) FurthestMismatch() (*Token, []dfa.TokenType) {
+	if buf_lex.mismatch.index < 0 || buf_lex.mismatch.index >= len(buf_lex.buffer) {
+		return nil, nil
+	}
+	return buf_lex.buffer[buf_lex.mismatch.index].tok, buf_lex.mismatch.expected
 }
@@ -4,9 +4,11 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"unicode/utf8"
 
 	errorhandler "github.com/VirajAgarwal1/lox/errorhandler"
 	dfa "github.com/VirajAgarwal1/lox/lexer/dfa"
+	"github.com/VirajAgarwal1/lox/source"
 )
 
 /*
@@ -22,6 +24,8 @@ import (
 	TODO: Fix the problem inefficiency of the 1st rune of a tokken going through all the lexemmes DFAs twice.
 
 	TODO: Setup a new struct which will have the lastInput properties along with the dfa tokens
+
+	Every rune read from the source is also recorded in a `source.File` (see the source package), this is what gives each token its `Pos` and `End` and what lets the error messages show the filename along with the line and column.
 */
 
 // type lastReadTokenRun
@@ -35,6 +39,8 @@ type Token struct {
 	Lexemme     []rune
 	Line        uint32
 	Offset      uint32
+	Pos         source.Pos // position of the first rune of the lexemme
+	End         source.Pos // position right after the last rune of the lexemme
 }
 type LexicalAnalyzer struct {
 	source              *bufio.Reader
@@ -43,6 +49,10 @@ type LexicalAnalyzer struct {
 	currentPos          *inputRunePosition
 	lexemme             []rune // TODO: Add a limit to this... A huge sequence of string/whitespace/newline/identifier/comment/number can make this blow up
 	sustainCurrentInput bool
+	file                *source.File
+	currentOffset       int // byte offset of `currentInput` in the file
+	nextOffset          int // byte offset of the next rune which will be read from the source
+	diagnostics         *errorhandler.Diagnostics
 }
 
 func (tokenPos *inputRunePosition) getPrevPos() (lineNum uint32, lineOffset uint32) {
@@ -88,15 +98,36 @@ func (tok *Token) ToString() string {
 	return fmt.Sprintf("|%d|%d| [%s]Token -> `%s`", tok.Line, tok.Offset, string(tok.TypeOfToken), string(tok.Lexemme))
 }
 
-func (scanner *LexicalAnalyzer) Initialize(source *bufio.Reader) {
+func (scanner *LexicalAnalyzer) Initialize(source_reader *bufio.Reader) {
+	scanner.InitializeWithFile(source_reader, source.NewFileSet().AddFile("", nil))
+}
+
+// InitializeWithFile is same as `Initialize` but the runes read are recorded in the given file, which lets multiple sources share one `source.FileSet`
+func (scanner *LexicalAnalyzer) InitializeWithFile(source_reader *bufio.Reader, file *source.File) {
 	scanner.stateManger = &dfa.DFAStatesManager{}
 	scanner.currentPos = &inputRunePosition{}
 
-	scanner.source = source
+	scanner.source = source_reader
 	scanner.stateManger.Initialize()
 	scanner.currentPos.initialize()
 	scanner.lexemme = nil
 	scanner.sustainCurrentInput = false
+	scanner.file = file
+	scanner.currentOffset = 0
+	scanner.nextOffset = 0
+}
+
+// SetDiagnostics makes the scanner record every error it returns in the given collector as well
+func (scanner *LexicalAnalyzer) SetDiagnostics(diagnostics *errorhandler.Diagnostics) {
+	scanner.diagnostics = diagnostics
+}
+func (scanner *LexicalAnalyzer) Diagnostics() *errorhandler.Diagnostics {
+	return scanner.diagnostics
+}
+
+// File returns the file in which the scanner is recording the source it reads
+func (scanner *LexicalAnalyzer) File() *source.File {
+	return scanner.file
 }
 func (scanner *LexicalAnalyzer) Reset() {
 	scanner.source = nil
@@ -104,11 +135,59 @@ func (scanner *LexicalAnalyzer) Reset() {
 	scanner.currentPos.reset()
 	scanner.lexemme = nil
 	scanner.sustainCurrentInput = false
+	scanner.currentOffset = 0
+	scanner.nextOffset = 0
 }
 func (scanner *LexicalAnalyzer) prepareForNextToken() {
 	scanner.stateManger.FullReset()
 	scanner.lexemme = nil
 }
+func (scanner *LexicalAnalyzer) readRune() error {
+	var size int
+	var err error
+	scanner.currentInput, size, err = scanner.source.ReadRune()
+	if err != nil {
+		return err
+	}
+	scanner.currentOffset = scanner.nextOffset
+	scanner.nextOffset += size
+	if scanner.nextOffset > scanner.file.Size() {
+		// Files registered along with their contents already know this rune
+		scanner.file.AppendRune(scanner.currentInput)
+	}
+	return nil
+}
+func (scanner *LexicalAnalyzer) setTokenPositions(tok *Token, startOffset int) {
+	length := 0
+	for _, r := range tok.Lexemme {
+		length += utf8.RuneLen(r)
+	}
+	if tok.TypeOfToken == dfa.EOF {
+		length = 0
+	}
+	tok.Pos = scanner.file.Pos(startOffset)
+	tok.End = scanner.file.Pos(startOffset + length)
+}
+
+// invalidTokenError reports the runes read since `startOffset` as an invalid token. `resembling` is the token type whose DFA got the furthest, if any.
+func (scanner *LexicalAnalyzer) invalidTokenError(startOffset int, resembling dfa.TokenType) error {
+	start := scanner.file.Pos(startOffset)
+	lex_err := &errorhandler.LexError{
+		Code:       errorhandler.CodeInvalidToken,
+		Pos:        start,
+		End:        scanner.file.Pos(scanner.nextOffset),
+		Position:   scanner.file.Position(start),
+		Lexemme:    string(scanner.lexemme),
+		Resembling: resembling.Name(),
+	}
+	if resembling == dfa.STRING {
+		lex_err.Help = "strings must be closed with a '\"'"
+	}
+	if scanner.diagnostics != nil {
+		scanner.diagnostics.AddError(lex_err)
+	}
+	return errorhandler.RetErr("", lex_err)
+}
 func (scanner *LexicalAnalyzer) ReadToken() (*Token, error) {
 	/*
 		This function reades one rune at a time from the source reader and returns 1 token at a time in return. It follows `maximal munching` methodlogy for settling tie between 2 valid token dfas being satisfied. And if both DFAs end up having the same token length then the token which is written later in the `dfa.TokensList` is given higher priority and is returned
@@ -116,13 +195,18 @@ func (scanner *LexicalAnalyzer) ReadToken() (*Token, error) {
 	returnToken := Token{}
 	var err error
 	tokenStartingLine, tokenStartingLineOffset := scanner.currentPos.getPrevPos()
+	tokenStartingOffset := scanner.nextOffset
+	if scanner.sustainCurrentInput {
+		tokenStartingOffset = scanner.currentOffset
+	}
 
 	defer scanner.prepareForNextToken()
+	defer scanner.setTokenPositions(&returnToken, tokenStartingOffset)
 
 	for i := 0; ; i++ {
 		// Read one rune from the source
 		if !scanner.sustainCurrentInput {
-			scanner.currentInput, _, err = scanner.source.ReadRune()
+			err = scanner.readRune()
 			if err != nil && err != io.EOF {
 				scanner.sustainCurrentInput = false
 				return &returnToken, errorhandler.RetErr("", err)
@@ -153,22 +237,11 @@ func (scanner *LexicalAnalyzer) ReadToken() (*Token, error) {
 				}
 				// If any intermediates were there then we will use those for error reporting
 				if scanner.stateManger.PreviousLoopDfaResults.IsAnyIntermediateToken {
-					return &returnToken, errorhandler.RetErr(
-						fmt.Sprintf(
-							"TokenError: invalid token found at line %v at offset %v, most resembling token type was %v",
-							tokenStartingLine,
-							tokenStartingLineOffset,
-							string(scanner.stateManger.PreviousLoopDfaResults.IntermediateToken),
-						),
-						nil,
-					)
+					return &returnToken, scanner.invalidTokenError(tokenStartingOffset, scanner.stateManger.PreviousLoopDfaResults.IntermediateToken)
 				}
 				// I do not expect the code to reach this line. Because, to reach here the last iteration of this function's loop would have to have all Invalid tokens, and still decide to continue parsing. Which should'nt happen.
 				// Return error and not EOF token here, users can get the EOF token in the next run despite the error
-				return &returnToken, errorhandler.RetErr(
-					fmt.Sprintf("TokenError: invalid token found at line %v at offset %v", scanner.currentPos.lineNum, scanner.currentPos.lineOffset-uint32(len(scanner.lexemme))),
-					nil,
-				)
+				return &returnToken, scanner.invalidTokenError(tokenStartingOffset, "")
 			}
 			// Record the offsets and the lineNums in the scanner
 			scanner.currentPos.step(scanner.currentInput)
@@ -194,24 +267,77 @@ func (scanner *LexicalAnalyzer) ReadToken() (*Token, error) {
 			scanner.sustainCurrentInput = false
 			// If any intermediates were there then we will use those for error reporting
 			if scanner.stateManger.PreviousLoopDfaResults.IsAnyIntermediateToken {
-				return &returnToken, errorhandler.RetErr(
-					fmt.Sprintf(
-						"TokenError: invalid token found at line %v at offset %v, most resembling token type was %v",
-						tokenStartingLine,
-						tokenStartingLineOffset,
-						string(scanner.stateManger.PreviousLoopDfaResults.IntermediateToken),
-					),
-					nil,
-				)
+				return &returnToken, scanner.invalidTokenError(tokenStartingOffset, scanner.stateManger.PreviousLoopDfaResults.IntermediateToken)
 			}
 			// The Program Counter can only get here if this is the 1st iteration and the very 1st rune did not satisfay any of the token types' dfa
-			return &returnToken, errorhandler.RetErr(
-				fmt.Sprintf("TokenError: invalid token found at line %v at offset %v", tokenStartingLine, tokenStartingLineOffset),
-				nil,
-			)
+			return &returnToken, scanner.invalidTokenError(tokenStartingOffset, "")
 		}
 
 		scanner.stateManger.ClearCurrentLoopDfaResults()
 		scanner.sustainCurrentInput = false
 	}
 }
+
+/*
+ReadEnclosed reads the source as it is, without making tokens of it, up to the `close` rune which matches an `open` rune read just before. It is how the grammar file reader takes the Go code of semantic actions, which the DFAs of the Lox tokens do not know how to read.
+
+The `open` and `close` runes inside Go strings, rune literals and comments are not counted. The text returned is the one between the two runes, and `end` is the position right after the `close` rune. If the source ends before the `close` rune, the error is `io.ErrUnexpectedEOF`.
+*/
+func (scanner *LexicalAnalyzer) ReadEnclosed(open rune, close rune) (text string, end source.Pos, err error) {
+	defer scanner.prepareForNextToken()
+
+	var read []rune
+	depth := 1
+	quote := rune(0)      // Quote of the string or rune literal being read, 0 if not in one
+	in_comment := rune(0) // '/' in a line comment, '*' in a block comment, 0 if not in one
+	escaped := false
+	previous := rune(0) // Rune read before, 0 if it cannot start or end a comment with the current one
+	for {
+		if scanner.sustainCurrentInput {
+			scanner.sustainCurrentInput = false
+		} else {
+			if err := scanner.readRune(); err != nil {
+				if err == io.EOF {
+					err = io.ErrUnexpectedEOF
+				}
+				return string(read), scanner.file.Pos(scanner.nextOffset), err
+			}
+			scanner.currentPos.step(scanner.currentInput)
+		}
+		char := scanner.currentInput
+		read = append(read, char)
+
+		switch {
+		case in_comment == '/':
+			if char == '\n' {
+				in_comment = 0
+			}
+		case in_comment == '*':
+			if previous == '*' && char == '/' {
+				in_comment = 0
+				char = 0 // The `/` cannot start another comment
+			}
+		case quote != 0:
+			if escaped {
+				escaped = false
+			} else if char == '\\' && quote != '`' {
+				escaped = true
+			} else if char == quote {
+				quote = 0
+			}
+		case previous == '/' && (char == '/' || char == '*'):
+			in_comment = char
+			char = 0 // `/*/` does not end the comment
+		case char == '"' || char == '\'' || char == '`':
+			quote = char
+		case char == open:
+			depth++
+		case char == close:
+			depth--
+			if depth == 0 {
+				return string(read[:len(read)-1]), scanner.file.Pos(scanner.nextOffset), nil
+			}
+		}
+		previous = char
+	}
+}
@@ -7,12 +7,11 @@ package parser
 import (
 	"io"
 
+	"github.com/VirajAgarwal1/lox/errorhandler"
 	"github.com/VirajAgarwal1/lox/lexer"
 	"github.com/VirajAgarwal1/lox/lexer/dfa"
 )
 
-var awiue lexer.BufferedLexer = lexer.BufferedLexer{}
-
 type Value struct {
 	LoxType string
 	Inner   any
@@ -23,7 +22,6 @@ type Node interface {
 type Literal struct {
 	Value *lexer.Token
 }
-type ParseFunc func(*lexer.BufferedLexer) ([]Node, bool, error)
 
 func (non_terminal *Literal) Evaluate() *Value {
 	return &Value{
@@ -48,12 +46,23 @@ func determineLoxType(tok *lexer.Token) string {
 	return string(tok.TypeOfToken)
 }
 
+func isSkipped(tok *lexer.Token) bool {
+	_, skipped := skipTokens[tok.TypeOfToken]
+	return skipped
+}
+
 // -------------------- COMBINATOR HELPERS --------------------
 
+// ParseFunc matches a part of the grammar. When it does not match, it leaves the buffer where it was, so that the next alternative reads the same tokens.
+type ParseFunc func(*lexer.BufferedLexer) ([]Node, bool, error)
+
 func matchToken(t dfa.TokenType) ParseFunc {
 	return func(buf *lexer.BufferedLexer) ([]Node, bool, error) {
 		chk := buf.MakeCheckpoint()
 		tok, err := buf.ReadToken()
+		for err == nil && isSkipped(tok) {
+			tok, err = buf.ReadToken()
+		}
 		if err != nil && err != io.EOF {
 			buf.RollbackTo(chk)
 			return nil, false, err
@@ -61,9 +70,9 @@ func matchToken(t dfa.TokenType) ParseFunc {
 		if tok.TypeOfToken == t {
 			return []Node{&Literal{tok}}, true, nil
 		}
+		buf.Mismatch(t)
 		buf.RollbackTo(chk)
 		return nil, false, nil
-		// fmt.Errorf("Unexpected token '%v' found at line %d, offset %d. Expected token '%v'", string(tok.TypeOfToken), tok.Line, tok.Offset, string(t))
 	}
 }
 
@@ -85,30 +94,25 @@ func sequence(parts ...ParseFunc) ParseFunc {
 
 func choice(parts ...ParseFunc) ParseFunc {
 	return func(buf *lexer.BufferedLexer) ([]Node, bool, error) {
-		chk := buf.MakeCheckpoint()
 		for _, part := range parts {
 			nodes, ok, err := part(buf)
 			if err != nil {
-				buf.RollbackTo(chk)
 				return nil, false, err
 			}
 			if ok {
 				return nodes, true, nil
 			}
 		}
-		buf.RollbackTo(chk)
 		return nil, false, nil
 	}
 }
 
 func zeroOrMore(part ParseFunc) ParseFunc {
 	return func(buf *lexer.BufferedLexer) ([]Node, bool, error) {
-		chk := buf.MakeCheckpoint()
 		output := []Node{}
 		for {
 			nodes, ok, err := part(buf)
 			if err != nil || !ok {
-				buf.RollbackTo(chk)
 				break
 			}
 			output = append(output, nodes...)
@@ -119,11 +123,9 @@ func zeroOrMore(part ParseFunc) ParseFunc {
 
 func oneOrMore(part ParseFunc) ParseFunc {
 	return func(buf *lexer.BufferedLexer) ([]Node, bool, error) {
-		chk := buf.MakeCheckpoint()
 		output := []Node{}
 		nodes, ok, err := part(buf)
 		if err != nil || !ok {
-			buf.RollbackTo(chk)
 			return nil, false, err
 		}
 		output = append(output, nodes...)
@@ -131,7 +133,6 @@ func oneOrMore(part ParseFunc) ParseFunc {
 		for {
 			nodes, ok, err = part(buf)
 			if err != nil || !ok {
-				buf.RollbackTo(chk)
 				break
 			}
 			output = append(output, nodes...)
@@ -140,80 +141,110 @@ func oneOrMore(part ParseFunc) ParseFunc {
 	}
 }
 
+func zeroOrOne(part ParseFunc) ParseFunc {
+	return func(buf *lexer.BufferedLexer) ([]Node, bool, error) {
+		nodes, ok, err := part(buf)
+		if err != nil || !ok {
+			return []Node{}, true, nil
+		}
+		return nodes, true, nil
+	}
+}
+
+// Parse matches the whole input with a Parse function. When the input does not match, the error is at the furthest token the parser read, with the tokens it expected there.
+func Parse(buf *lexer.BufferedLexer, parse ParseFunc) ([]Node, error) {
+	nodes, ok, err := sequence(parse, matchToken(dfa.EOF))(buf)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		tok, expected := buf.FurthestMismatch()
+		if tok == nil {
+			return nil, errorhandler.RetErr("", errorhandler.ErrSyntax)
+		}
+		return nil, errorhandler.RetErr("", &errorhandler.ParseError{
+			Code:     errorhandler.CodeUnexpectedToken,
+			Pos:      tok.Pos,
+			End:      tok.End,
+			Position: buf.File().Position(tok.Pos),
+			Found:    tok.TypeOfToken.Name(),
+			Lexemme:  string(tok.Lexemme),
+			Expected: dfa.Names(expected),
+		})
+	}
+	return nodes[:len(nodes)-1], nil
+}
+
 // -----------------------------------
 // CODE INDEPENDANT OF GRAMMAR END
 // -----------------------------------
 
-type Grammar_primary struct {
-	Arguments []Node
+var skipTokens = map[dfa.TokenType]struct{}{
+	dfa.WHITESPACE: {},
+	dfa.NEWLINE:    {},
+	dfa.COMMENT:    {},
 }
-type Grammar_equality struct {
+
+type Grammar_comma struct {
 	Arguments []Node
 }
-type Grammar_term struct {
+type Grammar_comparison struct {
 	Arguments []Node
 }
-type Grammar_unary struct {
+type Grammar_equality struct {
 	Arguments []Node
 }
-type Grammar_grammar struct {
+type Grammar_expression struct {
 	Arguments []Node
 }
-type Grammar_expression struct {
+type Grammar_factor struct {
 	Arguments []Node
 }
-type Grammar_comma struct {
+type Grammar_primary struct {
 	Arguments []Node
 }
-type Grammar_comparison struct {
+type Grammar_term struct {
 	Arguments []Node
 }
-type Grammar_factor struct {
+type Grammar_unary struct {
 	Arguments []Node
 }
 
-func (non_terminal *Grammar_equality) Evaluate() *Value {
-	return nil
-}
-func (non_terminal *Grammar_term) Evaluate() *Value {
+func (non_terminal *Grammar_comma) Evaluate() *Value {
 	return nil
 }
-func (non_terminal *Grammar_unary) Evaluate() *Value {
+func (non_terminal *Grammar_comparison) Evaluate() *Value {
 	return nil
 }
-func (non_terminal *Grammar_grammar) Evaluate() *Value {
+func (non_terminal *Grammar_equality) Evaluate() *Value {
 	return nil
 }
 func (non_terminal *Grammar_expression) Evaluate() *Value {
 	return nil
 }
-func (non_terminal *Grammar_comma) Evaluate() *Value {
+func (non_terminal *Grammar_factor) Evaluate() *Value {
 	return nil
 }
-func (non_terminal *Grammar_comparison) Evaluate() *Value {
+func (non_terminal *Grammar_primary) Evaluate() *Value {
 	return nil
 }
-func (non_terminal *Grammar_factor) Evaluate() *Value {
+func (non_terminal *Grammar_term) Evaluate() *Value {
 	return nil
 }
-func (non_terminal *Grammar_primary) Evaluate() *Value {
+func (non_terminal *Grammar_unary) Evaluate() *Value {
 	return nil
 }
 
-func Parse_primary(buf *lexer.BufferedLexer) ([]Node, bool, error) {
-	output := Grammar_primary{}
+func Parse_comma(buf *lexer.BufferedLexer) ([]Node, bool, error) {
+	output := Grammar_comma{}
 
-	args, ok, err := choice(
-		matchToken(dfa.IDENTIFIER),
-		matchToken(dfa.NUMBER),
-		matchToken(dfa.STRING),
-		matchToken(dfa.TRUE),
-		matchToken(dfa.FALSE),
-		matchToken(dfa.NIL),
-		sequence(
-			matchToken(dfa.LEFT_PAREN),
-			Parse_expression,
-			matchToken(dfa.RIGHT_PAREN),
+	args, ok, err := sequence(
+		Parse_equality,
+		zeroOrMore(
+			sequence(
+				matchToken(dfa.COMMA),
+				Parse_equality,
+			),
 		),
 	)(buf)
 
@@ -223,18 +254,20 @@ func Parse_primary(buf *lexer.BufferedLexer) ([]Node, bool, error) {
 	}
 	return []Node{&output}, true, nil
 }
-func Parse_equality(buf *lexer.BufferedLexer) ([]Node, bool, error) {
-	output := Grammar_equality{}
+func Parse_comparison(buf *lexer.BufferedLexer) ([]Node, bool, error) {
+	output := Grammar_comparison{}
 
 	args, ok, err := sequence(
-		Parse_comparison,
+		Parse_term,
 		zeroOrMore(
 			sequence(
 				choice(
-					matchToken(dfa.BANG_EQUAL),
-					matchToken(dfa.EQUAL_EQUAL),
+					matchToken(dfa.GREATER),
+					matchToken(dfa.GREATER_EQUAL),
+					matchToken(dfa.LESS),
+					matchToken(dfa.LESS_EQUAL),
 				),
-				Parse_comparison,
+				Parse_term,
 			),
 		),
 	)(buf)
@@ -245,18 +278,18 @@ func Parse_equality(buf *lexer.BufferedLexer) ([]Node, bool, error) {
 	}
 	return []Node{&output}, true, nil
 }
-func Parse_term(buf *lexer.BufferedLexer) ([]Node, bool, error) {
-	output := Grammar_term{}
+func Parse_equality(buf *lexer.BufferedLexer) ([]Node, bool, error) {
+	output := Grammar_equality{}
 
 	args, ok, err := sequence(
-		Parse_factor,
+		Parse_comparison,
 		zeroOrMore(
 			sequence(
 				choice(
-					matchToken(dfa.MINUS),
-					matchToken(dfa.PLUS),
+					matchToken(dfa.BANG_EQUAL),
+					matchToken(dfa.EQUAL_EQUAL),
 				),
-				Parse_factor,
+				Parse_comparison,
 			),
 		),
 	)(buf)
@@ -267,19 +300,10 @@ func Parse_term(buf *lexer.BufferedLexer) ([]Node, bool, error) {
 	}
 	return []Node{&output}, true, nil
 }
-func Parse_unary(buf *lexer.BufferedLexer) ([]Node, bool, error) {
-	output := Grammar_unary{}
+func Parse_expression(buf *lexer.BufferedLexer) ([]Node, bool, error) {
+	output := Grammar_expression{}
 
-	args, ok, err := choice(
-		sequence(
-			choice(
-				matchToken(dfa.BANG),
-				matchToken(dfa.MINUS),
-			),
-			Parse_unary,
-		),
-		Parse_primary,
-	)(buf)
+	args, ok, err := Parse_comma(buf)
 
 	output.Arguments = args
 	if err != nil || !ok {
@@ -287,22 +311,20 @@ func Parse_unary(buf *lexer.BufferedLexer) ([]Node, bool, error) {
 	}
 	return []Node{&output}, true, nil
 }
-func Parse_grammar(buf *lexer.BufferedLexer) ([]Node, bool, error) {
-	output := Grammar_grammar{}
+func Parse_factor(buf *lexer.BufferedLexer) ([]Node, bool, error) {
+	output := Grammar_factor{}
 
 	args, ok, err := sequence(
+		Parse_unary,
 		zeroOrMore(
 			sequence(
-				zeroOrMore(
-					matchToken(dfa.NEWLINE),
-				),
-				Parse_expression,
-				zeroOrMore(
-					matchToken(dfa.NEWLINE),
+				choice(
+					matchToken(dfa.SLASH),
+					matchToken(dfa.STAR),
 				),
+				Parse_unary,
 			),
 		),
-		matchToken(dfa.EOF),
 	)(buf)
 
 	output.Arguments = args
@@ -311,27 +333,20 @@ func Parse_grammar(buf *lexer.BufferedLexer) ([]Node, bool, error) {
 	}
 	return []Node{&output}, true, nil
 }
-func Parse_expression(buf *lexer.BufferedLexer) ([]Node, bool, error) {
-	output := Grammar_expression{}
-
-	args, ok, err := Parse_comma(buf)
-
-	output.Arguments = args
-	if err != nil || !ok {
-		return nil, false, err
-	}
-	return []Node{&output}, true, nil
-}
-func Parse_comma(buf *lexer.BufferedLexer) ([]Node, bool, error) {
-	output := Grammar_comma{}
+func Parse_primary(buf *lexer.BufferedLexer) ([]Node, bool, error) {
+	output := Grammar_primary{}
 
-	args, ok, err := sequence(
-		Parse_equality,
-		zeroOrMore(
-			sequence(
-				matchToken(dfa.COMMA),
-				Parse_equality,
-			),
+	args, ok, err := choice(
+		matchToken(dfa.IDENTIFIER),
+		matchToken(dfa.NUMBER),
+		matchToken(dfa.STRING),
+		matchToken(dfa.TRUE),
+		matchToken(dfa.FALSE),
+		matchToken(dfa.NIL),
+		sequence(
+			matchToken(dfa.LEFT_PAREN),
+			Parse_expression,
+			matchToken(dfa.RIGHT_PAREN),
 		),
 	)(buf)
 
@@ -341,20 +356,18 @@ func Parse_comma(buf *lexer.BufferedLexer) ([]Node, bool, error) {
 	}
 	return []Node{&output}, true, nil
 }
-func Parse_comparison(buf *lexer.BufferedLexer) ([]Node, bool, error) {
-	output := Grammar_comparison{}
+func Parse_term(buf *lexer.BufferedLexer) ([]Node, bool, error) {
+	output := Grammar_term{}
 
 	args, ok, err := sequence(
-		Parse_term,
+		Parse_factor,
 		zeroOrMore(
 			sequence(
 				choice(
-					matchToken(dfa.GREATER),
-					matchToken(dfa.GREATER_EQUAL),
-					matchToken(dfa.LESS),
-					matchToken(dfa.LESS_EQUAL),
+					matchToken(dfa.MINUS),
+					matchToken(dfa.PLUS),
 				),
-				Parse_term,
+				Parse_factor,
 			),
 		),
 	)(buf)
@@ -365,20 +378,18 @@ func Parse_comparison(buf *lexer.BufferedLexer) ([]Node, bool, error) {
 	}
 	return []Node{&output}, true, nil
 }
-func Parse_factor(buf *lexer.BufferedLexer) ([]Node, bool, error) {
-	output := Grammar_factor{}
+func Parse_unary(buf *lexer.BufferedLexer) ([]Node, bool, error) {
+	output := Grammar_unary{}
 
-	args, ok, err := sequence(
-		Parse_unary,
-		zeroOrMore(
-			sequence(
-				choice(
-					matchToken(dfa.SLASH),
-					matchToken(dfa.STAR),
-				),
-				Parse_unary,
+	args, ok, err := choice(
+		sequence(
+			choice(
+				matchToken(dfa.BANG),
+				matchToken(dfa.MINUS),
 			),
+			Parse_unary,
 		),
+		Parse_primary,
 	)(buf)
 
 	output.Arguments = args
@@ -0,0 +1,161 @@
+package grammar
+
+import (
+	"strings"
+
+	"github.com/VirajAgarwal1/lox/grammar_file"
+)
+
+// hasActions tells if any rule of the grammar has an action, in which case the generated parser needs the helpers of `actionHelpersCode`
+func hasActions(processedGrammar map[Non_terminal]([]Generic_grammar_term)) bool {
+	return (&Grammar{Rules: processedGrammar}).Has_actions()
+}
+
+// ruleHasActions tells if any alternative of the rule has an action
+func ruleHasActions(terms []Generic_grammar_term) bool {
+	for _, action := range grammar_file.Alternative_actions(terms) {
+		if action != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateRuleCode is `GenerateDescriptionCode` for the whole production of a rule: the alternatives which end with an action are wrapped in `action`, which runs its code on the values of what they matched
+func GenerateRuleCode(terms []Generic_grammar_term, endString string) (string, error) {
+	if !ruleHasActions(terms) {
+		return GenerateDescriptionCode(terms, endString), nil
+	}
+
+	actions := grammar_file.Alternative_actions(terms)
+	labels := grammar_file.Alternative_labels(terms)
+	alternatives := grammar_file.Alternatives(terms)
+	codes := make([]string, 0, len(alternatives))
+	for i, alternative := range alternatives {
+		code, err := actionCode(GenerateDescriptionCode(grammar_file.Without_actions(alternative), ",\n"), actions[i], labels[i])
+		if err != nil {
+			return "", err
+		}
+		codes = append(codes, code)
+	}
+
+	if len(codes) == 1 {
+		return strings.TrimSuffix(codes[0], ",\n") + endString, nil
+	}
+	output := "choice(\n"
+	for _, code := range codes {
+		output += IndentLines(code, 1)
+	}
+	return output + ")" + endString, nil
+}
+
+// actionCode wraps the code of an alternative in `action` when the alternative has one, `labels` being the labels of the alternative as `grammar_file.Labels` gives them
+func actionCode(code string, action *Action, labels map[string]bool) (string, error) {
+	if action == nil {
+		return code, nil
+	}
+	expanded, err := grammar_file.Expand_action(action.Code, labels)
+	if err != nil {
+		return "", err
+	}
+	return "action(func(values ActionValues) any {" + expanded + "},\n" + IndentLines(code, 1) + "),\n", nil
+}
+
+// Written after the label helpers when the grammar has actions. `action` runs the code of an action on the values of the nodes its alternative matched and gives back an `actionResult`, which the Parse function of the rule turns into an `ActionValue` in place of the node of the rule.
+const actionHelpersCode = `// -------------------- ACTION HELPERS --------------------
+
+// ActionValue is the node of a non-terminal whose alternative has an action, holding what the action returned
+type ActionValue struct {
+	Value any
+}
+
+func (non_terminal *ActionValue) Evaluate() *Value {
+	return nil
+}
+
+// ActionValues are the values of what an alternative matched, which its action reads: the token of every terminal, and for every non-terminal what its action returned, or its node when it has none
+type ActionValues struct {
+	values []any
+	labels []string
+}
+
+func (values *ActionValues) At(index int) any {
+	if index < 0 || index >= len(values.values) {
+		return nil
+	}
+	return values.values[index]
+}
+
+func (values *ActionValues) Get(label string) any {
+	for i, value := range values.values {
+		if values.labels[i] == label {
+			return value
+		}
+	}
+	return nil
+}
+
+func (values *ActionValues) All(label string) []any {
+	output := []any{}
+	for i, value := range values.values {
+		if values.labels[i] == label {
+			output = append(output, value)
+		}
+	}
+	return output
+}
+
+func (values *ActionValues) Len() int {
+	return len(values.values)
+}
+
+func (values *ActionValues) add(nodes []Node, label string) {
+	for _, node := range nodes {
+		var value any = node
+		switch node := node.(type) {
+		case *labelledNodes:
+			values.add(node.Nodes, node.Label)
+			continue
+		case *Literal:
+			value = node.Value
+		case *ActionValue:
+			value = node.Value
+		}
+		values.values = append(values.values, value)
+		values.labels = append(values.labels, label)
+	}
+}
+
+type actionResult struct {
+	Value any
+}
+
+func (non_terminal *actionResult) Evaluate() *Value {
+	return nil
+}
+
+func action(code func(values ActionValues) any, part ParseFunc) ParseFunc {
+	return func(buf *lexer.BufferedLexer) ([]Node, bool, error) {
+		nodes, ok, err := part(buf)
+		if err != nil || !ok {
+			return nil, false, err
+		}
+		values := ActionValues{}
+		values.add(nodes, "")
+		return []Node{&actionResult{Value: code(values)}}, true, nil
+	}
+}
+
+// resultOf gives the value of the action which matched, if the arguments are its result
+func resultOf(args []Node) (any, bool) {
+	if len(args) != 1 {
+		return nil, false
+	}
+	result, isResult := args[0].(*actionResult)
+	if !isResult {
+		return nil, false
+	}
+	return result.Value, true
+}
+
+`
@@ -0,0 +1,124 @@
+package grammar
+
+import (
+	"bufio"
+	"strconv"
+
+	"github.com/VirajAgarwal1/lox/errorhandler"
+)
+
+// withoutExpressions gives the rules of the grammar which are not the levels of a `%expr`, as those are parsed by `operatorPrecedence`
+func withoutExpressions(grammar *Grammar) map[Non_terminal]([]Generic_grammar_term) {
+	if len(grammar.Expressions) == 0 {
+		return grammar.Rules
+	}
+	rules := map[Non_terminal]([]Generic_grammar_term){}
+	for nonTerminal, terms := range grammar.Rules {
+		if grammar.Expression_of(nonTerminal.Name) == nil {
+			rules[nonTerminal] = terms
+		}
+	}
+	return rules
+}
+
+// associativityCode gives the byte `operatorPrecedence` knows an associativity by
+var associativityCode = map[string]string{"left": "'l'", "right": "'r'", "nonassoc": "'n'"}
+
+// WriteExpressionParseFunctions writes the Parse function of every `%expr` of the grammar, with the table of its operators. Its levels have no Parse function of their own.
+func WriteExpressionParseFunctions(writer *bufio.Writer, expressions []Expression) error {
+	for _, expression := range expressions {
+		output := "var operators_" + expression.Name + " = []binaryOperator{\n"
+		for level, precedence := range expression.Levels {
+			for _, operator := range precedence.Operators {
+				output += "\t{token: " + tokenCode(operator) + ", power: " + strconv.Itoa(level+1) + ", associativity: " + associativityCode[precedence.Associativity] + "},\n"
+			}
+		}
+		output += `}
+
+func Parse_` + expression.Name + `(buf *lexer.BufferedLexer) ([]Node, bool, error) {
+	return operatorPrecedence(Parse_` + expression.Operand + `, operators_` + expression.Name + `)(buf)
+}
+
+`
+		_, err := writer.WriteString(output)
+		if err != nil {
+			return errorhandler.RetErr("", err)
+		}
+	}
+	return nil
+}
+
+// Written after the combinator helpers when the grammar has a `%expr`. `operatorPrecedence` is a Pratt parser: it reads an operand, then, as long as an operator which binds at least as tightly as `minPower` follows, the operand on its right with the operators which bind more tightly (or as tightly, for a right-associative one), and makes a `BinaryExpression` of them.
+const operatorHelpersCode = `// -------------------- OPERATOR HELPERS --------------------
+
+// BinaryExpression is the node of two operands of a ` + "`%expr`" + ` and the operator between them
+type BinaryExpression struct {
+	Operator *lexer.Token
+	Left     Node
+	Right    Node
+}
+
+func (non_terminal *BinaryExpression) Evaluate() *Value {
+	return nil
+}
+
+type binaryOperator struct {
+	token         dfa.TokenType
+	power         int  // The higher, the more tightly it binds
+	associativity byte // 'l'eft, 'r'ight or 'n'on-associative
+}
+
+func operatorPrecedence(operand ParseFunc, operators []binaryOperator) ParseFunc {
+	var parse func(buf *lexer.BufferedLexer, minPower int) ([]Node, bool, error)
+	parse = func(buf *lexer.BufferedLexer, minPower int) ([]Node, bool, error) {
+		chk := buf.MakeCheckpoint()
+		nodes, ok, err := operand(buf)
+		if err != nil || !ok || len(nodes) != 1 {
+			return nil, false, err
+		}
+		left := nodes[0]
+		closed := 0 // Power of the non-associative operator just read, which cannot follow its right operand
+		for {
+			var operator *binaryOperator
+			var token *lexer.Token
+			for i := range operators {
+				if operators[i].power < minPower || operators[i].power == closed {
+					continue
+				}
+				matched, ok, err := matchToken(operators[i].token)(buf)
+				if err != nil {
+					buf.RollbackTo(chk)
+					return nil, false, err
+				}
+				if ok {
+					operator, token = &operators[i], matched[0].(*Literal).Value
+					break
+				}
+			}
+			if operator == nil {
+				return []Node{left}, true, nil
+			}
+
+			rightPower := operator.power + 1
+			if operator.associativity == 'r' {
+				rightPower = operator.power
+			}
+			right, ok, err := parse(buf, rightPower)
+			if err != nil || !ok {
+				buf.RollbackTo(chk)
+				return nil, false, err
+			}
+			left = &BinaryExpression{Operator: token, Left: left, Right: right[0]}
+
+			closed = 0
+			if operator.associativity == 'n' {
+				closed = operator.power
+			}
+		}
+	}
+	return func(buf *lexer.BufferedLexer) ([]Node, bool, error) {
+		return parse(buf, 1)
+	}
+}
+
+`
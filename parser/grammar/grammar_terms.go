@@ -0,0 +1,25 @@
+/*
+This file is responsible for generating the code for the Lox grammar. This is needed because manually writing code for the whole grammar wil become tedious
+*/
+
+package grammar
+
+import (
+	"github.com/VirajAgarwal1/lox/grammar_file"
+)
+
+// The grammar files are read by `grammar_file`, which the streamable parser's generator uses as well. These aliases keep the generator's functions taking the same types as before.
+type (
+	Generic_grammar_term = grammar_file.Generic_grammar_term
+	Terminal             = grammar_file.Terminal
+	Non_terminal         = grammar_file.Non_terminal
+	Or                   = grammar_file.Or
+	Star                 = grammar_file.Star
+	Plus                 = grammar_file.Plus
+	Bracket              = grammar_file.Bracket
+	Optional             = grammar_file.Optional
+	Labelled             = grammar_file.Labelled
+	Action               = grammar_file.Action
+	Expression           = grammar_file.Expression
+	Grammar              = grammar_file.Grammar
+)
@@ -0,0 +1,104 @@
+package grammar
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	"github.com/VirajAgarwal1/lox/grammar_file"
+)
+
+// LabelField is the field which a label of a rule (`label:element` in the grammar file) gives to the struct of its non-terminal
+type LabelField struct {
+	Label string
+	Name  string // The label starting with an upper case letter, so that the field is exported
+	Many  bool   // The label can match more than one node (in a repetition, or written more than once in a sequence), so the field is a `[]Node` instead of a `Node`
+}
+
+// LabelFields gives the fields for the labels of a rule, in the order they are first written
+func LabelFields(terms []Generic_grammar_term) ([]LabelField, error) {
+	labels, many := grammar_file.Labels(terms)
+	fields := make([]LabelField, 0, len(labels))
+	for _, label := range labels {
+		name, err := labelFieldName(label)
+		if err != nil {
+			return nil, err
+		}
+		for _, field := range fields {
+			if field.Name == name {
+				return nil, errors.New("labels '" + field.Label + "' and '" + label + "' would both be the field " + name)
+			}
+		}
+		fields = append(fields, LabelField{Label: label, Name: name, Many: many[label]})
+	}
+	return fields, nil
+}
+
+func labelFieldName(label string) (string, error) {
+	name := []rune(strings.TrimLeft(label, "_"))
+	if len(name) == 0 || !unicode.IsLetter(name[0]) {
+		return "", errors.New("label '" + label + "' cannot be the name of a field, it needs to start with a letter")
+	}
+	name[0] = unicode.ToUpper(name[0])
+	if string(name) == "Arguments" {
+		return "", errors.New("label '" + label + "' cannot be used, every node already has the field Arguments")
+	}
+	return string(name), nil
+}
+
+// hasLabels tells if any rule of the grammar has a label, in which case the generated parser needs the helpers of `labelHelpersCode`
+func hasLabels(processedGrammar map[Non_terminal]([]Generic_grammar_term)) bool {
+	for _, terms := range processedGrammar {
+		if fields, _ := LabelFields(terms); len(fields) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Written after the combinator helpers when the grammar has labels. The parser of a labelled element wraps what it matched in a `labelledNodes`, which the Parse function of the rule takes out again with `unlabel`, putting the nodes in the field of the label as well as in `Arguments`.
+const labelHelpersCode = `// -------------------- LABEL HELPERS --------------------
+
+type labelledNodes struct {
+	Label string
+	Nodes []Node
+}
+
+func (non_terminal *labelledNodes) Evaluate() *Value {
+	return nil
+}
+
+func labelled(label string, part ParseFunc) ParseFunc {
+	return func(buf *lexer.BufferedLexer) ([]Node, bool, error) {
+		nodes, ok, err := part(buf)
+		if err != nil || !ok {
+			return nil, false, err
+		}
+		return []Node{&labelledNodes{Label: label, Nodes: nodes}}, true, nil
+	}
+}
+
+// unlabel gives the nodes without their labels, after giving the nodes of every label to assign
+func unlabel(args []Node, assign func(label string, nodes []Node)) []Node {
+	output := []Node{}
+	for _, arg := range args {
+		labelledArg, isLabelled := arg.(*labelledNodes)
+		if !isLabelled {
+			output = append(output, arg)
+			continue
+		}
+		nodes := unlabel(labelledArg.Nodes, assign)
+		assign(labelledArg.Label, nodes)
+		output = append(output, nodes...)
+	}
+	return output
+}
+
+func firstNode(nodes []Node) Node {
+	if len(nodes) == 0 {
+		return nil
+	}
+	return nodes[0]
+}
+
+`
@@ -0,0 +1,181 @@
+package grammar
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+
+	"github.com/VirajAgarwal1/lox/errorhandler"
+	"github.com/VirajAgarwal1/lox/grammar_file"
+)
+
+// reassociatedRules gives the left-recursive rules whose nodes are given back as written (`%reassociate`), which are parsed by `leftRecursive`. Without it, their rewritten rules are parsed like the others. The levels of an expression are left to `operatorPrecedence`.
+func reassociatedRules(grammar *Grammar) []grammar_file.Left_recursion {
+	if !grammar.Reassociate {
+		return nil
+	}
+	reassociated := []grammar_file.Left_recursion{}
+	for _, leftRecursion := range grammar.Left_recursions {
+		if grammar.Expression_of(leftRecursion.Name) == nil {
+			reassociated = append(reassociated, leftRecursion)
+		}
+	}
+	return reassociated
+}
+
+// writtenRules gives the rules with the reassociated ones as written, which their nodes and actions are made for
+func writtenRules(grammar *Grammar, processedGrammar map[Non_terminal]([]Generic_grammar_term)) map[Non_terminal]([]Generic_grammar_term) {
+	reassociated := reassociatedRules(grammar)
+	if len(reassociated) == 0 {
+		return processedGrammar
+	}
+	rules := map[Non_terminal]([]Generic_grammar_term){}
+	for nonTerminal, terms := range processedGrammar {
+		rules[nonTerminal] = terms
+	}
+	for _, leftRecursion := range reassociated {
+		rules[Non_terminal{Name: leftRecursion.Name}] = leftRecursion.Written
+	}
+	return rules
+}
+
+// withoutReassociated gives the rules which are not reassociated, as those have Parse functions of their own
+func withoutReassociated(grammar *Grammar, processedGrammar map[Non_terminal]([]Generic_grammar_term)) map[Non_terminal]([]Generic_grammar_term) {
+	reassociated := reassociatedRules(grammar)
+	if len(reassociated) == 0 {
+		return processedGrammar
+	}
+	rules := map[Non_terminal]([]Generic_grammar_term){}
+	for nonTerminal, terms := range processedGrammar {
+		if grammar.Left_recursion_of(nonTerminal.Name) == nil {
+			rules[nonTerminal] = terms
+		}
+	}
+	return rules
+}
+
+/*
+WriteLeftRecursiveParseFunctions writes the Parse function of every reassociated left-recursive rule. `node_<rule>` makes the node of the rule from what one of its alternatives matched, like the Parse function of any other rule does, and the tails of the rule are given the node matched before them:
+
+	expr -> left:expr "-" number or number
+
+	func Parse_expr(buf *lexer.BufferedLexer) ([]Node, bool, error) {
+		return leftRecursive(node_expr,
+			[]ParseFunc{
+				Parse_number,
+			},
+			[]func(left []Node) ParseFunc{
+				func(left []Node) ParseFunc {
+					return sequence(
+						labelled("left", given(left)),
+						matchToken(dfa.MINUS),
+						Parse_number,
+					)
+				},
+			},
+		)(buf)
+	}
+*/
+func WriteLeftRecursiveParseFunctions(writer *bufio.Writer, grammar *Grammar) error {
+	for _, leftRecursion := range reassociatedRules(grammar) {
+		output, err := leftRecursiveParseFunction(leftRecursion)
+		if err != nil {
+			return errorhandler.RetErr("action of '"+leftRecursion.Name+"'", err)
+		}
+		_, err = writer.WriteString(output)
+		if err != nil {
+			return errorhandler.RetErr("", err)
+		}
+	}
+	return nil
+}
+
+func leftRecursiveParseFunction(leftRecursion grammar_file.Left_recursion) (string, error) {
+	actions := grammar_file.Alternative_actions(leftRecursion.Written)
+	labels := grammar_file.Alternative_labels(leftRecursion.Written)
+
+	bases := ""
+	for _, base := range leftRecursion.Bases {
+		code, err := actionCode(GenerateDescriptionCode(base.Terms, ",\n"), actions[base.Alternative], labels[base.Alternative])
+		if err != nil {
+			return "", err
+		}
+		bases += IndentLines(code, 3)
+	}
+
+	tails := ""
+	for _, tail := range leftRecursion.Tails {
+		left := "given(left),\n"
+		if tail.Label != "" {
+			left = "labelled(" + strconv.Quote(tail.Label) + ", given(left)),\n"
+		}
+		code := "sequence(\n" + left
+		for _, term := range tail.Terms {
+			code += GetStringGeneratorForTerm(term, ",\n")
+		}
+		code, err := actionCode(code+"),\n", actions[tail.Alternative], labels[tail.Alternative])
+		if err != nil {
+			return "", err
+		}
+		tails += "\t\t\tfunc(left []Node) ParseFunc {\n" +
+			"\t\t\t\treturn " + strings.TrimPrefix(IndentLines(strings.TrimSuffix(code, ",\n"), 4), "\t\t\t\t") + "\n" +
+			"\t\t\t},\n"
+	}
+
+	return `// node_` + leftRecursion.Name + ` makes the node of ` + leftRecursion.Name + ` from what one of its alternatives matched
+func node_` + leftRecursion.Name + `(args []Node, ok bool, err error) ([]Node, bool, error) {
+	output := Grammar_` + leftRecursion.Name + `{}
+
+	` + assignArgumentsCode(leftRecursion.Written) + `
+	if err != nil || !ok {
+		return nil, false, err
+	}` + returnResultCode(leftRecursion.Written) + `
+	return []Node{&output}, true, nil
+}
+
+func Parse_` + leftRecursion.Name + `(buf *lexer.BufferedLexer) ([]Node, bool, error) {
+	return leftRecursive(node_` + leftRecursion.Name + `,
+		[]ParseFunc{
+` + bases + `		},
+		[]func(left []Node) ParseFunc{
+` + tails + `		},
+	)(buf)
+}
+
+`, nil
+}
+
+// Written after the combinator helpers when the grammar has reassociated left-recursive rules. `leftRecursive` matches one of the bases of the rule, then its tails as long as one of them matches, each given the node matched before it as its first argument, so that `1 - 2 - 3` gives the nodes of `(1 - 2) - 3`.
+const leftRecursionHelpersCode = `// -------------------- LEFT RECURSION HELPERS --------------------
+
+func leftRecursive(node func([]Node, bool, error) ([]Node, bool, error), bases []ParseFunc, tails []func(left []Node) ParseFunc) ParseFunc {
+	return func(buf *lexer.BufferedLexer) ([]Node, bool, error) {
+		left, ok, err := node(choice(bases...)(buf))
+		if err != nil || !ok {
+			return nil, false, err
+		}
+		for {
+			parts := make([]ParseFunc, len(tails))
+			for i, tail := range tails {
+				parts[i] = tail(left)
+			}
+			nodes, ok, err := node(choice(parts...)(buf))
+			if err != nil {
+				return nil, false, err
+			}
+			if !ok {
+				return left, true, nil
+			}
+			left = nodes
+		}
+	}
+}
+
+// given matches nothing, and gives the nodes it was given
+func given(nodes []Node) ParseFunc {
+	return func(buf *lexer.BufferedLexer) ([]Node, bool, error) {
+		return nodes, true, nil
+	}
+}
+
+`
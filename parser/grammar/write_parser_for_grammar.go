@@ -2,76 +2,56 @@ package grammar
 
 import (
 	"bufio"
+	"bytes"
+	"go/format"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/VirajAgarwal1/lox/errorhandler"
+	"github.com/VirajAgarwal1/lox/grammar_file"
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/grammar_validator"
 )
 
-var TokenStringToType = map[string]string{
-	// Literals
-	"EOF":        "dfa.EOF",
-	"IDENTIFIER": "dfa.IDENTIFIER",
-	"STRING":     "dfa.STRING",
-	"NUMBER":     "dfa.NUMBER",
-	"COMMENT":    "dfa.COMMENT",
-
-	// Single-char tokens
-	" ":   "dfa.WHITESPACE",
-	"\\n": "dfa.NEWLINE",
-	"(":   "dfa.LEFT_PAREN",
-	")":   "dfa.RIGHT_PAREN",
-	"{":   "dfa.LEFT_BRACE",
-	"}":   "dfa.RIGHT_BRACE",
-	",":   "dfa.COMMA",
-	".":   "dfa.DOT",
-	"-":   "dfa.MINUS",
-	"+":   "dfa.PLUS",
-	";":   "dfa.SEMICOLON",
-	"/":   "dfa.SLASH",
-	"*":   "dfa.STAR",
-
-	// One-or-two char tokens
-	"!":  "dfa.BANG",
-	"!=": "dfa.BANG_EQUAL",
-	"=":  "dfa.EQUAL",
-	"==": "dfa.EQUAL_EQUAL",
-	">":  "dfa.GREATER",
-	">=": "dfa.GREATER_EQUAL",
-	"<":  "dfa.LESS",
-	"<=": "dfa.LESS_EQUAL",
-
-	// Keywords
-	"and":    "dfa.AND",
-	"class":  "dfa.CLASS",
-	"else":   "dfa.ELSE",
-	"false":  "dfa.FALSE",
-	"fun":    "dfa.FUN",
-	"for":    "dfa.FOR",
-	"if":     "dfa.IF",
-	"nil":    "dfa.NIL",
-	"or":     "dfa.OR",
-	"print":  "dfa.PRINT",
-	"return": "dfa.RETURN",
-	"super":  "dfa.SUPER",
-	"this":   "dfa.THIS",
-	"true":   "dfa.TRUE",
-	"var":    "dfa.VAR",
-	"while":  "dfa.WHILE",
+// sortedNonTerminals gives the rules of the grammar by name, so that the same grammar always generates the same parser
+func sortedNonTerminals(processedGrammar map[Non_terminal]([]Generic_grammar_term)) []Non_terminal {
+	nonTerminals := make([]Non_terminal, 0, len(processedGrammar))
+	for nonTerminal := range processedGrammar {
+		nonTerminals = append(nonTerminals, nonTerminal)
+	}
+	sort.Slice(nonTerminals, func(i, j int) bool {
+		return nonTerminals[i].Name < nonTerminals[j].Name
+	})
+	return nonTerminals
 }
 
 func WriteStructsForNonTerminals(writer *bufio.Writer, processedGrammar map[Non_terminal]([]Generic_grammar_term)) error {
 
-	getStringForNonTerminal := func(symbol string) string {
+	getStringForNonTerminal := func(symbol string, fields []LabelField) string {
+		width := len("Arguments")
+		for _, field := range fields {
+			width = max(width, len(field.Name))
+		}
 		output := `type Grammar_` + symbol + ` struct {
-	Arguments []Node
-}
+	Arguments` + strings.Repeat(" ", width-len("Arguments")) + ` []Node
 `
-		return output
+		for _, field := range fields {
+			fieldType := "Node"
+			if field.Many {
+				fieldType = "[]Node"
+			}
+			output += "\t" + field.Name + strings.Repeat(" ", width-len(field.Name)) + " " + fieldType + "\n"
+		}
+		return output + "}\n"
 	}
 
-	for nonTerminalSymbol := range processedGrammar {
-		_, err := writer.WriteString(getStringForNonTerminal(nonTerminalSymbol.Name))
+	for _, nonTerminalSymbol := range sortedNonTerminals(processedGrammar) {
+		fields, err := LabelFields(processedGrammar[nonTerminalSymbol])
+		if err != nil {
+			return errorhandler.RetErr("labels of '"+nonTerminalSymbol.Name+"'", err)
+		}
+		_, err = writer.WriteString(getStringForNonTerminal(nonTerminalSymbol.Name, fields))
 		if err != nil {
 			return errorhandler.RetErr("", err)
 		}
@@ -96,7 +76,7 @@ func WriteEvaluateMethodsForNonTerminals(writer *bufio.Writer, processedGrammar
 		return output
 	}
 
-	for nonTerminalSymbol := range processedGrammar {
+	for _, nonTerminalSymbol := range sortedNonTerminals(processedGrammar) {
 		_, err := writer.WriteString(getStringForNonTerminal(nonTerminalSymbol.Name))
 		if err != nil {
 			return errorhandler.RetErr("", err)
@@ -112,26 +92,65 @@ func WriteEvaluateMethodsForNonTerminals(writer *bufio.Writer, processedGrammar
 	return nil
 }
 
+// assignArgumentsCode gives the code which puts the arguments of a Parse function in its node. Labels are taken out of the arguments and put in their fields, see `labelHelpersCode`.
+func assignArgumentsCode(terms []Generic_grammar_term) string {
+	fields, _ := LabelFields(terms)
+	if len(fields) == 0 {
+		return "output.Arguments = args"
+	}
+	assignArguments := "output.Arguments = unlabel(args, func(label string, nodes []Node) {\n\t\tswitch label {\n"
+	for _, field := range fields {
+		assignArguments += "\t\tcase " + strconv.Quote(field.Label) + ":\n"
+		if field.Many {
+			assignArguments += "\t\t\toutput." + field.Name + " = append(output." + field.Name + ", nodes...)\n"
+		} else {
+			assignArguments += "\t\t\toutput." + field.Name + " = firstNode(nodes)\n"
+		}
+	}
+	return assignArguments + "\t\t}\n\t})"
+}
+
+// returnResultCode gives the code which returns the value of the action which matched, as the node of a rule whose alternative has an action is the value the action returned
+func returnResultCode(terms []Generic_grammar_term) string {
+	if !ruleHasActions(terms) {
+		return ""
+	}
+	return `
+	if value, isResult := resultOf(args); isResult {
+		return []Node{&ActionValue{Value: value}}, true, nil
+	}`
+}
+
 func WriteParseFunctionsForNonTerminals(writer *bufio.Writer, processedGrammar map[Non_terminal]([]Generic_grammar_term)) error {
 
-	getStringForNonTerminal := func(nonTerminalSymbol Non_terminal, processedGrammar map[Non_terminal]([]Generic_grammar_term)) string {
-		output := `func Parse_` + nonTerminalSymbol.Name + `(buf *lexer.BufferedLexicalAnalyzer) ([]Node, bool, error) {
+	getStringForNonTerminal := func(nonTerminalSymbol Non_terminal, processedGrammar map[Non_terminal]([]Generic_grammar_term)) (string, error) {
+		assignArguments := assignArgumentsCode(processedGrammar[nonTerminalSymbol])
+		description, err := GenerateRuleCode(processedGrammar[nonTerminalSymbol], "(buf)")
+		if err != nil {
+			return "", errorhandler.RetErr("action of '"+nonTerminalSymbol.Name+"'", err)
+		}
+		returnResult := returnResultCode(processedGrammar[nonTerminalSymbol])
+		output := `func Parse_` + nonTerminalSymbol.Name + `(buf *lexer.BufferedLexer) ([]Node, bool, error) {
 	output := Grammar_` + nonTerminalSymbol.Name + `{}
 	
-	args, ok, err := ` + GenerateDescriptionCode(processedGrammar[nonTerminalSymbol], "(buf)") + `
+	args, ok, err := ` + description + `
 	
-	output.Arguments = args
+	` + assignArguments + `
 	if err != nil || !ok {
 		return nil, false, err
-	}
+	}` + returnResult + `
 	return []Node{&output}, true, nil
 }
 `
-		return output
+		return output, nil
 	}
 
-	for nonTerminalSymbol := range processedGrammar {
-		_, err := writer.WriteString(getStringForNonTerminal(nonTerminalSymbol, processedGrammar))
+	for _, nonTerminalSymbol := range sortedNonTerminals(processedGrammar) {
+		output, err := getStringForNonTerminal(nonTerminalSymbol, processedGrammar)
+		if err != nil {
+			return err
+		}
+		_, err = writer.WriteString(output)
 		if err != nil {
 			return errorhandler.RetErr("", err)
 		}
@@ -151,13 +170,7 @@ func WriteParseFunctionsForNonTerminals(writer *bufio.Writer, processedGrammar m
 // -----------------------------------------------------------------------------------
 
 func DetectOrInDescription(description []Generic_grammar_term) []uint32 {
-	out := make([]uint32, 0, len(description)/4)
-	for i, term := range description {
-		if term.Get_grammar_term_type() == "or" {
-			out = append(out, uint32(i))
-		}
-	}
-	return out
+	return grammar_file.Detect_or_in_sequence(description)
 }
 
 func IndentLines(input string, indentLevel int) string {
@@ -189,14 +202,28 @@ func GetStringGeneratorForTerm(term Generic_grammar_term, endString string) stri
 	if term.Get_grammar_term_type() == "plus" {
 		return GeneratePlusCode(*term.(*Plus), endString)
 	}
+	if term.Get_grammar_term_type() == "optional" {
+		return GenerateOptionalCode(*term.(*Optional), endString)
+	}
 	if term.Get_grammar_term_type() == "bracket" {
 		return GenerateBracketCode(*term.(*Bracket), endString)
 	}
+	if term.Get_grammar_term_type() == "labelled" {
+		return GenerateLabelledCode(*term.(*Labelled), endString)
+	}
 	return ""
 }
 
+// tokenCode gives the Go code for the token type of a terminal. Terminals which the lexer does not know about are token types of their own name, `validateGrammar` made sure that they are declared with `%token`.
+func tokenCode(name string) string {
+	if tokenType, found := grammar_file.Lookup_token(name); found {
+		return grammar_file.Token_type_code(tokenType)
+	}
+	return "dfa.TokenType(" + strconv.Quote(name) + ")"
+}
+
 func GenerateMatchCode(term Terminal, endString string) string {
-	return "matchToken(" + TokenStringToType[string(term.Content)] + ")" + endString
+	return "matchToken(" + tokenCode(string(term.Content)) + ")" + endString
 }
 func GenerateParseReturnCode(term Non_terminal, endString string) string {
 	return "Parse_" + term.Name + endString
@@ -207,6 +234,12 @@ func GenerateStarCode(star_term Star, endString string) string {
 func GeneratePlusCode(plus_term Plus, endString string) string {
 	return "oneOrMore(\n" + GenerateDescriptionCode([]Generic_grammar_term{plus_term.Content}, ",\n") + ")" + endString
 }
+func GenerateOptionalCode(optional_term Optional, endString string) string {
+	return "zeroOrOne(\n" + GenerateDescriptionCode([]Generic_grammar_term{optional_term.Content}, ",\n") + ")" + endString
+}
+func GenerateLabelledCode(labelled_term Labelled, endString string) string {
+	return "labelled(" + strconv.Quote(labelled_term.Label) + ",\n" + GenerateDescriptionCode([]Generic_grammar_term{labelled_term.Content}, ",\n") + ")" + endString
+}
 func GenerateBracketCode(bracket_term Bracket, endString string) string {
 	if len(bracket_term.Contents) == 0 {
 		return ""
@@ -270,9 +303,81 @@ func GenerateDescriptionCode(description []Generic_grammar_term, endString strin
 // -----------------------------------------------------------------------------------
 
 func GenerateGrammarOutput(writer *bufio.Writer, processedGrammar map[Non_terminal]([]Generic_grammar_term)) error {
+	return generateGrammarOutput(writer, &Grammar{Rules: processedGrammar})
+}
+
+// importsCode gives the lines importing the packages of `%import`, which the actions use. `io`, `errorhandler`, `lexer` and `dfa` are always imported.
+func importsCode(imports []string) string {
+	seen := map[string]bool{"io": true, "github.com/VirajAgarwal1/lox/errorhandler": true, "github.com/VirajAgarwal1/lox/lexer": true, "github.com/VirajAgarwal1/lox/lexer/dfa": true}
+	output := ""
+	for _, path := range imports {
+		if !seen[path] {
+			seen[path] = true
+			output += "\n\t" + strconv.Quote(path)
+		}
+	}
+	return output
+}
+
+// WriteSkipTokens writes the set of tokens which `matchToken` ignores, given by `%skip` in the grammar file
+func WriteSkipTokens(writer *bufio.Writer, skip []string) error {
+	output := "var skipTokens = map[dfa.TokenType]struct{}{\n"
+	for _, name := range skip {
+		output += "\t" + tokenCode(name) + ": {},\n"
+	}
+	output += "}\n\n"
+
+	_, err := writer.WriteString(output)
+	if err != nil {
+		return errorhandler.RetErr("", err)
+	}
+	return nil
+}
+
+// validateGrammar refuses the grammars in which `grammar_validator` finds errors, like the streamable parser's generator does, and the terminals of `%skip` and of the operators of `%expr` which are not tokens. Every terminal given to `tokenCode` after it is a token of the lexer or declared with `%token`.
+func validateGrammar(grammar *Grammar) error {
+	validated := *grammar
+	if len(validated.Order) == 0 {
+		// The rules given without a grammar file have no order, they are all checked
+		validated.Order = sortedNonTerminals(grammar.Rules)
+	}
+	if err := grammar_validator.Validate(&validated).Err(); err != nil {
+		return errorhandler.RetErr("Invalid grammar", err)
+	}
+
+	for _, name := range grammar.Skip {
+		if _, found := grammar_file.Resolve_token(name, grammar.Tokens); !found {
+			return errorhandler.RetErr("Unknown token '"+name+"' in %skip", nil)
+		}
+	}
+	for _, expression := range grammar.Expressions {
+		for _, precedence := range expression.Levels {
+			for _, operator := range precedence.Operators {
+				if _, found := grammar_file.Resolve_token(operator, grammar.Tokens); !found {
+					return errorhandler.RetErr("Unknown operator '"+operator+"' in %expr "+expression.Name, nil)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func generateGrammarOutput(writer *bufio.Writer, grammar *Grammar) error {
+	err := validateGrammar(grammar)
+	if err != nil {
+		return err
+	}
+
+	// Left-recursive rules would call themselves forever, they are rewritten to repeat instead
+	grammar, err = grammar_file.Eliminate_left_recursion(grammar)
+	if err != nil {
+		return errorhandler.RetErr("left recursion", err)
+	}
+	processedGrammar := withoutExpressions(grammar)
+	writtenGrammar := writtenRules(grammar, processedGrammar)
 
 	// Writing function and strcuts which are independant of the grammar
-	_, err := writer.WriteString(
+	_, err = writer.WriteString(
 		`// -----------------------------------
 // CODE INDEPENDANT OF GRAMMAR START
 // -----------------------------------
@@ -282,8 +387,9 @@ package parser
 import (
 	"io"
 
+	"github.com/VirajAgarwal1/lox/errorhandler"
 	"github.com/VirajAgarwal1/lox/lexer"
-	"github.com/VirajAgarwal1/lox/lexer/dfa"
+	"github.com/VirajAgarwal1/lox/lexer/dfa"` + importsCode(grammar.Imports) + `
 )
 
 type Value struct {
@@ -320,29 +426,44 @@ func determineLoxType(tok *lexer.Token) string {
 	return string(tok.TypeOfToken)
 }
 
+func isSkipped(tok *lexer.Token) bool {
+	_, skipped := skipTokens[tok.TypeOfToken]
+	return skipped
+}
+
 // -------------------- COMBINATOR HELPERS --------------------
 
-func matchToken(t dfa.TokenType) func(*lexer.BufferedLexicalAnalyzer) ([]Node, bool, error) {
-	return func(buf *lexer.BufferedLexicalAnalyzer) ([]Node, bool, error) {
-		tok, err := buf.CurrentTokenWithoutConsume()
+// ParseFunc matches a part of the grammar. When it does not match, it leaves the buffer where it was, so that the next alternative reads the same tokens.
+type ParseFunc func(*lexer.BufferedLexer) ([]Node, bool, error)
+
+func matchToken(t dfa.TokenType) ParseFunc {
+	return func(buf *lexer.BufferedLexer) ([]Node, bool, error) {
+		chk := buf.MakeCheckpoint()
+		tok, err := buf.ReadToken()
+		for err == nil && isSkipped(tok) {
+			tok, err = buf.ReadToken()
+		}
 		if err != nil && err != io.EOF {
+			buf.RollbackTo(chk)
 			return nil, false, err
 		}
 		if tok.TypeOfToken == t {
-			buf.ConsumeOneToken()
 			return []Node{&Literal{tok}}, true, nil
 		}
+		buf.Mismatch(t)
+		buf.RollbackTo(chk)
 		return nil, false, nil
-		// fmt.Errorf("Unexpected token '%v' found at line %d, offset %d. Expected token '%v'", string(tok.TypeOfToken), tok.Line, tok.Offset, string(t))
 	}
 }
 
-func sequence(parts ...func(*lexer.BufferedLexicalAnalyzer) ([]Node, bool, error)) func(*lexer.BufferedLexicalAnalyzer) ([]Node, bool, error) {
-	return func(buf *lexer.BufferedLexicalAnalyzer) ([]Node, bool, error) {
+func sequence(parts ...ParseFunc) ParseFunc {
+	return func(buf *lexer.BufferedLexer) ([]Node, bool, error) {
+		chk := buf.MakeCheckpoint()
 		output := []Node{}
 		for _, part := range parts {
 			nodes, ok, err := part(buf)
 			if err != nil || !ok {
+				buf.RollbackTo(chk)
 				return nil, false, err
 			}
 			output = append(output, nodes...)
@@ -351,8 +472,8 @@ func sequence(parts ...func(*lexer.BufferedLexicalAnalyzer) ([]Node, bool, error
 	}
 }
 
-func choice(parts ...func(*lexer.BufferedLexicalAnalyzer) ([]Node, bool, error)) func(*lexer.BufferedLexicalAnalyzer) ([]Node, bool, error) {
-	return func(buf *lexer.BufferedLexicalAnalyzer) ([]Node, bool, error) {
+func choice(parts ...ParseFunc) ParseFunc {
+	return func(buf *lexer.BufferedLexer) ([]Node, bool, error) {
 		for _, part := range parts {
 			nodes, ok, err := part(buf)
 			if err != nil {
@@ -366,8 +487,8 @@ func choice(parts ...func(*lexer.BufferedLexicalAnalyzer) ([]Node, bool, error))
 	}
 }
 
-func zeroOrMore(part func(*lexer.BufferedLexicalAnalyzer) ([]Node, bool, error)) func(*lexer.BufferedLexicalAnalyzer) ([]Node, bool, error) {
-	return func(buf *lexer.BufferedLexicalAnalyzer) ([]Node, bool, error) {
+func zeroOrMore(part ParseFunc) ParseFunc {
+	return func(buf *lexer.BufferedLexer) ([]Node, bool, error) {
 		output := []Node{}
 		for {
 			nodes, ok, err := part(buf)
@@ -380,8 +501,8 @@ func zeroOrMore(part func(*lexer.BufferedLexicalAnalyzer) ([]Node, bool, error))
 	}
 }
 
-func oneOrMore(part func(*lexer.BufferedLexicalAnalyzer) ([]Node, bool, error)) func(*lexer.BufferedLexicalAnalyzer) ([]Node, bool, error) {
-	return func(buf *lexer.BufferedLexicalAnalyzer) ([]Node, bool, error) {
+func oneOrMore(part ParseFunc) ParseFunc {
+	return func(buf *lexer.BufferedLexer) ([]Node, bool, error) {
 		output := []Node{}
 		nodes, ok, err := part(buf)
 		if err != nil || !ok {
@@ -400,6 +521,40 @@ func oneOrMore(part func(*lexer.BufferedLexicalAnalyzer) ([]Node, bool, error))
 	}
 }
 
+func zeroOrOne(part ParseFunc) ParseFunc {
+	return func(buf *lexer.BufferedLexer) ([]Node, bool, error) {
+		nodes, ok, err := part(buf)
+		if err != nil || !ok {
+			return []Node{}, true, nil
+		}
+		return nodes, true, nil
+	}
+}
+
+// Parse matches the whole input with a Parse function. When the input does not match, the error is at the furthest token the parser read, with the tokens it expected there.
+func Parse(buf *lexer.BufferedLexer, parse ParseFunc) ([]Node, error) {
+	nodes, ok, err := sequence(parse, matchToken(dfa.EOF))(buf)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		tok, expected := buf.FurthestMismatch()
+		if tok == nil {
+			return nil, errorhandler.RetErr("", errorhandler.ErrSyntax)
+		}
+		return nil, errorhandler.RetErr("", &errorhandler.ParseError{
+			Code:     errorhandler.CodeUnexpectedToken,
+			Pos:      tok.Pos,
+			End:      tok.End,
+			Position: buf.File().Position(tok.Pos),
+			Found:    tok.TypeOfToken.Name(),
+			Lexemme:  string(tok.Lexemme),
+			Expected: dfa.Names(expected),
+		})
+	}
+	return nodes[:len(nodes)-1], nil
+}
+
 // -----------------------------------
 // CODE INDEPENDANT OF GRAMMAR END
 // -----------------------------------
@@ -410,20 +565,70 @@ func oneOrMore(part func(*lexer.BufferedLexicalAnalyzer) ([]Node, bool, error))
 		return errorhandler.RetErr("", err)
 	}
 
+	// Write the helpers for the labels, only needed when the grammar has some. The actions need them to find the labelled values.
+	if hasLabels(writtenGrammar) || hasActions(writtenGrammar) {
+		_, err = writer.WriteString(labelHelpersCode)
+		if err != nil {
+			return errorhandler.RetErr("", err)
+		}
+	}
+
+	// Write the helpers for the actions, only needed when the grammar has some
+	if hasActions(writtenGrammar) {
+		_, err = writer.WriteString(actionHelpersCode)
+		if err != nil {
+			return errorhandler.RetErr("", err)
+		}
+	}
+
+	// Write the helpers for the operators, only needed when the grammar has a `%expr`
+	if len(grammar.Expressions) > 0 {
+		_, err = writer.WriteString(operatorHelpersCode)
+		if err != nil {
+			return errorhandler.RetErr("", err)
+		}
+	}
+
+	// Write the helpers for the left-recursive rules, only needed when their nodes are reassociated
+	if len(reassociatedRules(grammar)) > 0 {
+		_, err = writer.WriteString(leftRecursionHelpersCode)
+		if err != nil {
+			return errorhandler.RetErr("", err)
+		}
+	}
+
+	// Write the tokens which are skipped
+	err = WriteSkipTokens(writer, grammar.Skip)
+	if err != nil {
+		return errorhandler.RetErr("", err)
+	}
+
 	// Write the structs for each non-terminal symbol
-	err = WriteStructsForNonTerminals(writer, processedGrammar)
+	err = WriteStructsForNonTerminals(writer, writtenGrammar)
 	if err != nil {
 		return errorhandler.RetErr("", err)
 	}
 
 	// Write the Evaluate method on struct for each non-terminal symbol
-	err = WriteEvaluateMethodsForNonTerminals(writer, processedGrammar)
+	err = WriteEvaluateMethodsForNonTerminals(writer, writtenGrammar)
 	if err != nil {
 		return errorhandler.RetErr("", err)
 	}
 
 	// Write the Parsing functions for each non-terminal symbol
-	err = WriteParseFunctionsForNonTerminals(writer, processedGrammar)
+	err = WriteParseFunctionsForNonTerminals(writer, withoutReassociated(grammar, processedGrammar))
+	if err != nil {
+		return errorhandler.RetErr("", err)
+	}
+
+	// Write the Parsing functions for the reassociated left-recursive rules
+	err = WriteLeftRecursiveParseFunctions(writer, grammar)
+	if err != nil {
+		return errorhandler.RetErr("", err)
+	}
+
+	// Write the Parsing functions for the expressions, which replace the rules of their levels
+	err = WriteExpressionParseFunctions(writer, grammar.Expressions)
 	if err != nil {
 		return errorhandler.RetErr("", err)
 	}
@@ -436,27 +641,38 @@ func oneOrMore(part func(*lexer.BufferedLexicalAnalyzer) ([]Node, bool, error))
 // -----------------------------------------------------------------------------------
 
 func GenerateGrammarParserFile(processedGrammar map[Non_terminal]([]Generic_grammar_term), filePath string) error {
-	// Open the file with:
-	// - O_CREATE: create if not exists
-	// - O_TRUNC: clear the file first
-	// - O_WRONLY: open in write-only mode
-	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	return generateGrammarParserFile(&Grammar{Rules: processedGrammar}, filePath)
+}
+
+// GenerateGrammarParserFileForGrammar is `GenerateGrammarParserFile` for a whole grammar file, so that its `%skip`, `%import` and `%expr` directives are honoured
+func GenerateGrammarParserFileForGrammar(grammar *Grammar, filePath string) error {
+	return generateGrammarParserFile(grammar, filePath)
+}
+
+func generateGrammarParserFile(grammar *Grammar, filePath string) error {
+	// Generate the whole parser before touching the file, so that an invalid grammar leaves the parser which was there
+	var code bytes.Buffer
+	writer := bufio.NewWriter(&code)
+
+	// Take the processed grammar and output its parser to the buffer
+	err := generateGrammarOutput(writer, grammar)
 	if err != nil {
 		return errorhandler.RetErr("", err)
 	}
-	defer file.Close()
 
-	// Wrap in a buffered writer
-	writer := bufio.NewWriter(file)
-
-	// Take the processed grammar and output its parser to the file
-	err = GenerateGrammarOutput(writer, processedGrammar)
+	// Flush buffered data to the buffer
+	err = writer.Flush()
 	if err != nil {
 		return errorhandler.RetErr("", err)
 	}
 
-	// Flush buffered data to disk
-	err = writer.Flush()
+	// The same grammar always gives the same gofmt'd file, so that parser/generated_parser.go can be checked against its grammar
+	formatted, err := format.Source(code.Bytes())
+	if err != nil {
+		// Only the actions of the grammar can be wrong Go, and `go build` says where better than `format` does
+		formatted = code.Bytes()
+	}
+	err = os.WriteFile(filePath, formatted, 0644)
 	if err != nil {
 		return errorhandler.RetErr("", err)
 	}
@@ -0,0 +1,144 @@
+package source
+
+import (
+	"sort"
+	"sync"
+	"unicode/utf8"
+)
+
+/*
+A File is one source registered in a `FileSet`. Because the lexer reads its input as a stream, a File does not need to know its contents up front. The lexer appends every rune it reads to the File with `AppendRune`, and the File records the start of each line as it goes. Files registered with their full contents get their line table computed immediately.
+
+The source bytes are kept around so that diagnostics can show the offending line (see `Line`).
+*/
+
+type File struct {
+	mutex sync.RWMutex
+	name  string
+	base  int // Pos value of the first byte of this file
+	limit int // number of bytes the range of positions of this file has room for, see `FileSet.AddFile`
+	src   []byte
+	lines []int // byte offsets of the first byte of every line, lines[0] is always 0
+}
+
+func (f *File) Name() string {
+	return f.name
+}
+func (f *File) Base() int {
+	return f.base
+}
+
+// Size returns the number of bytes of the file which are known till now.
+func (f *File) Size() int {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	return len(f.src)
+}
+
+// LineCount returns the number of lines which are known till now.
+func (f *File) LineCount() int {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	return len(f.lines)
+}
+
+// AppendRune records one more rune of the file. It is called by the lexer for every rune it reads.
+func (f *File) AppendRune(r rune) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.src = utf8.AppendRune(f.src, r)
+	if r == '\n' {
+		f.lines = append(f.lines, len(f.src))
+	}
+}
+
+// Pos returns the Pos value for the given byte offset. The offset may be equal to the size of the file (the position right after the last byte). Offsets past the range of the file have no position, they give `NoPos`.
+func (f *File) Pos(offset int) Pos {
+	if offset < 0 {
+		panic("source: negative file offset")
+	}
+	if offset > f.limit {
+		return NoPos
+	}
+	return Pos(f.base + offset)
+}
+
+// Offset returns the byte offset of the given Pos inside the file.
+func (f *File) Offset(p Pos) int {
+	return int(p) - f.base
+}
+
+// Contains reports whether p lies inside the bytes of this file known till now (or right after them).
+func (f *File) Contains(p Pos) bool {
+	return int(p) >= f.base && int(p) <= f.base+min(f.Size(), f.limit)
+}
+
+// Position resolves p into a filename, line and column.
+func (f *File) Position(p Pos) Position {
+	if !p.IsValid() || !f.Contains(p) {
+		return Position{Filename: f.name}
+	}
+	offset := f.Offset(p)
+
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	line_idx := sort.Search(len(f.lines), func(i int) bool { return f.lines[i] > offset }) - 1
+	line_start := f.lines[line_idx]
+	return Position{
+		Filename: f.name,
+		Offset:   offset,
+		Line:     line_idx + 1,
+		Column:   utf8.RuneCount(f.src[line_start:offset]) + 1,
+	}
+}
+
+// LineStart returns the Pos of the first byte of the given line (starting at 1).
+func (f *File) LineStart(line int) Pos {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	if line < 1 || line > len(f.lines) {
+		return NoPos
+	}
+	return f.Pos(f.lines[line-1])
+}
+
+// Line returns the text of the given line (starting at 1) without its trailing newline. The second return value is false if the line is not known.
+func (f *File) Line(line int) (string, bool) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	if line < 1 || line > len(f.lines) {
+		return "", false
+	}
+	start := f.lines[line-1]
+	end := len(f.src)
+	if line < len(f.lines) {
+		end = f.lines[line] - 1 // Exclude the '\n'
+	}
+	text := f.src[start:end]
+	if len(text) > 0 && text[len(text)-1] == '\r' {
+		text = text[:len(text)-1]
+	}
+	return string(text), true
+}
+
+// Slice returns the source text between the two positions (end excluded).
+func (f *File) Slice(start Pos, end Pos) string {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	s, e := f.Offset(start), f.Offset(end)
+	if s < 0 || e > len(f.src) || s > e {
+		return ""
+	}
+	return string(f.src[s:e])
+}
+
+func (f *File) setContent(src []byte) {
+	f.src = src
+	f.lines = []int{0}
+	for i, b := range src {
+		if b == '\n' {
+			f.lines = append(f.lines, i+1)
+		}
+	}
+}
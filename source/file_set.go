@@ -0,0 +1,81 @@
+package source
+
+import (
+	"sync"
+)
+
+/*
+FileSet hands out non-overlapping ranges of `Pos` values to the files added to it, so a single `Pos` is enough to find out which file it belongs to.
+
+*NOTE*:
+A file which is being streamed keeps growing as the lexer reads it, so its size is not known when the next file is added. Such a file gets a range of `MaxStreamedFileSize` positions up front, which the files added after it start past. Files added with their contents only take as many positions as they have bytes.
+*/
+
+// MaxStreamedFileSize is the number of positions kept for a file added without its contents. The bytes of a streamed file past it have no position.
+const MaxStreamedFileSize = 1 << 30
+
+type FileSet struct {
+	mutex sync.RWMutex
+	files []*File
+}
+
+func NewFileSet() *FileSet {
+	return &FileSet{}
+}
+
+// AddFile registers a new file in the set. `src` can be nil, in which case the contents are expected to be appended by the lexer while it reads the file.
+func (s *FileSet) AddFile(filename string, src []byte) *File {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	base := 1 // Pos 0 is `NoPos`
+	if len(s.files) > 0 {
+		last := s.files[len(s.files)-1]
+		base = last.base + last.limit + 1 // +1 so that the position right after the last byte still belongs to the previous file
+	}
+
+	limit := len(src)
+	if src == nil {
+		limit = MaxStreamedFileSize
+	}
+	file := &File{name: filename, base: base, limit: limit}
+	file.setContent(src)
+	s.files = append(s.files, file)
+	return file
+}
+
+// File returns the file which contains p, or nil if there is no such file.
+func (s *FileSet) File(p Pos) *File {
+	if !p.IsValid() {
+		return nil
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	for i := len(s.files) - 1; i >= 0; i-- {
+		if int(p) >= s.files[i].base {
+			if s.files[i].Contains(p) {
+				return s.files[i]
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// Position resolves p into a filename, line and column. It returns the zero Position if p does not belong to any file in the set.
+func (s *FileSet) Position(p Pos) Position {
+	file := s.File(p)
+	if file == nil {
+		return Position{}
+	}
+	return file.Position(p)
+}
+
+// Files returns the files in the order in which they were added.
+func (s *FileSet) Files() []*File {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	out := make([]*File, len(s.files))
+	copy(out, s.files)
+	return out
+}
@@ -0,0 +1,50 @@
+package source
+
+import (
+	"fmt"
+)
+
+/*
+This package is modelled on `go/token`. Every file which is read by the lexer gets registered in a `FileSet`, and each byte of that file gets a unique `Pos` inside the set. A `Pos` is just a number so it is cheap to store in every token and every error, and it can be resolved back to a filename, line and column (a `Position`) only when we actually need to show it to someone.
+*/
+
+// Pos is a compact representation of a byte position inside a `FileSet`. The zero value `NoPos` means that no position is known.
+type Pos int
+
+const NoPos Pos = 0
+
+func (p Pos) IsValid() bool {
+	return p != NoPos
+}
+
+// Position is the human readable form of a `Pos`. Line and Column both start at 1, Column counts runes (not bytes) from the start of the line.
+type Position struct {
+	Filename string
+	Offset   int // byte offset from the start of the file, starting at 0
+	Line     int
+	Column   int
+}
+
+func (pos Position) IsValid() bool {
+	return pos.Line > 0
+}
+
+// String returns the position in one of these forms:
+//
+//	file:line:column    valid position with filename
+//	line:column         valid position without filename
+//	file                invalid position with filename
+//	-                   invalid position without filename
+func (pos Position) String() string {
+	out := pos.Filename
+	if pos.IsValid() {
+		if out != "" {
+			out += ":"
+		}
+		out += fmt.Sprintf("%d:%d", pos.Line, pos.Column)
+	}
+	if out == "" {
+		out = "-"
+	}
+	return out
+}
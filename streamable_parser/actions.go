@@ -0,0 +1,97 @@
+package streamable_parser
+
+import (
+	"errors"
+	"io"
+)
+
+// ActionValues are the values of what an alternative matched, in order: the `*lexer.Token` of every terminal, and the value of every non-terminal. It is what the actions of the grammar read `$1` and `$label` from.
+type ActionValues struct {
+	values []any
+	labels []string
+}
+
+// At gives the value at `index` (`$1` is `At(0)`), nil if the alternative matched less than that
+func (values *ActionValues) At(index int) any {
+	if index < 0 || index >= len(values.values) {
+		return nil
+	}
+	return values.values[index]
+}
+
+// Get gives the first value with the label, nil if there is none
+func (values *ActionValues) Get(label string) any {
+	for i, value := range values.values {
+		if values.labels[i] == label {
+			return value
+		}
+	}
+	return nil
+}
+
+// All gives every value with the label, in order
+func (values *ActionValues) All(label string) []any {
+	out := []any{}
+	for i, value := range values.values {
+		if values.labels[i] == label {
+			out = append(out, value)
+		}
+	}
+	return out
+}
+
+// Len gives how many values there are
+func (values *ActionValues) Len() int {
+	return len(values.values)
+}
+
+func (values *ActionValues) add(value any, label string) {
+	values.values = append(values.values, value)
+	values.labels = append(values.labels, label)
+}
+
+// SemanticActions are the actions of the grammar, by non-terminal and then by the index of their alternative. The generated parser fills it in when its grammar has actions.
+var SemanticActions = map[string]map[int]func(values ActionValues) any{}
+
+/*
+BuildValue parses the whole input and gives the value of the starting non-terminal. It is the one thing in this package which does not stream: the values of the children are kept until the action of their parent has run.
+
+The value of a non-terminal is what the action of the alternative it matched returns. A non-terminal matched by an alternative without an action gives its `*ActionValues`, so that the action of its parent can still get to what it matched.
+
+The node of a start event which `Wraps` gets the last value of its parent as its first one, so that the values of a reassociated left-recursive rule nest as it was written.
+
+The first error event is returned as it is.
+*/
+func (sp *StreamableParser) BuildValue() (any, error) {
+	frames := []*ActionValues{{}} // The values of the non-terminals being parsed, the first one holds the value of the starting non-terminal
+	for {
+		event := sp.Parse()
+		switch event.Type {
+		case EmitElemType_Error:
+			if errors.Is(event.Err, io.EOF) {
+				return frames[0].At(0), nil
+			}
+			return nil, event.Err
+		case EmitElemType_Leaf:
+			frames[len(frames)-1].add(event.Leaf, event.Label)
+		case EmitElemType_Start:
+			values := &ActionValues{}
+			if event.Wraps {
+				// The node which just ended is the first child of this one
+				parent := frames[len(frames)-1]
+				last := len(parent.values) - 1
+				values.add(parent.values[last], parent.labels[last])
+				parent.values, parent.labels = parent.values[:last], parent.labels[:last]
+			}
+			frames = append(frames, values)
+		case EmitElemType_End:
+			values := frames[len(frames)-1]
+			frames = frames[:len(frames)-1]
+			var value any = values
+			if action, found := SemanticActions[event.Content][event.Alternative]; found {
+				value = action(*values)
+			}
+			frames[len(frames)-1].add(value, event.Label)
+		}
+	}
+}
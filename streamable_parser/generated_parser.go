@@ -7,80 +7,71 @@ import (
 
 const StartingNonTerminal string = "expression"
 
+var SkipTokens = map[dfa.TokenType]struct{}{
+	dfa.WHITESPACE: {}, dfa.NEWLINE: {}, dfa.COMMENT: {},
+}
+
 var grammarRules = map[string]ProductionRule{
-	"unary": {
+	"comma": {
 		FollowSet: map[dfa.TokenType]struct{}{
-			dfa.EOF: {},	dfa.SLASH: {},	dfa.STAR: {},	dfa.MINUS: {},	dfa.PLUS: {},	dfa.GREATER: {},	dfa.GREATER_EQUAL: {},	dfa.LESS: {},	dfa.LESS_EQUAL: {},	dfa.BANG_EQUAL: {},	dfa.EQUAL_EQUAL: {},	dfa.COMMA: {},	dfa.RIGHT_PAREN: {},
+			dfa.EOF: {}, dfa.RIGHT_PAREN: {},
 		},
 		Sequences: []GrammarSequence{
 			{
 				FirstSet: map[dfa.TokenType]struct{}{
-					dfa.BANG: {},	dfa.MINUS: {},
+					dfa.BANG: {}, dfa.MINUS: {}, dfa.IDENTIFIER: {}, dfa.NUMBER: {}, dfa.STRING: {}, dfa.TRUE: {}, dfa.FALSE: {}, dfa.NIL: {}, dfa.LEFT_PAREN: {},
 				},
 				Elements: []utils.Grammar_element{
-					{IsNonTerminal: true, Non_term_name: "999_0"},
-				},
-			},	{
-				FirstSet: map[dfa.TokenType]struct{}{
-					dfa.IDENTIFIER: {},	dfa.NUMBER: {},	dfa.STRING: {},	dfa.TRUE: {},	dfa.FALSE: {},	dfa.NIL: {},	dfa.LEFT_PAREN: {},
-				},
-				Elements: []utils.Grammar_element{
-					{IsNonTerminal: true, Non_term_name: "primary"},
+					{IsNonTerminal: true, Non_term_name: "equality"},
+					{IsNonTerminal: true, Non_term_name: "999_comma_star_1"},
 				},
 			},
 		},
-	},	"comma": {
+	},
+	"999_comma_star_1": {
 		FollowSet: map[dfa.TokenType]struct{}{
-			dfa.EOF: {},	dfa.RIGHT_PAREN: {},
+			dfa.EOF: {}, dfa.RIGHT_PAREN: {},
 		},
 		Sequences: []GrammarSequence{
 			{
 				FirstSet: map[dfa.TokenType]struct{}{
-					dfa.BANG: {},	dfa.MINUS: {},	dfa.IDENTIFIER: {},	dfa.NUMBER: {},	dfa.STRING: {},	dfa.TRUE: {},	dfa.FALSE: {},	dfa.NIL: {},	dfa.LEFT_PAREN: {},
+					dfa.COMMA: {},
 				},
 				Elements: []utils.Grammar_element{
-					{IsNonTerminal: true, Non_term_name: "equality"},	{IsNonTerminal: true, Non_term_name: "999_3"},
+					{IsNonTerminal: false, Terminal_type: dfa.COMMA},
+					{IsNonTerminal: true, Non_term_name: "equality"},
+					{IsNonTerminal: true, Non_term_name: "999_comma_star_1"},
 				},
 			},
-		},
-	},	"999_7": {
-		FollowSet: map[dfa.TokenType]struct{}{
-			dfa.EOF: {},	dfa.BANG: {},	dfa.MINUS: {},	dfa.IDENTIFIER: {},	dfa.NUMBER: {},	dfa.STRING: {},	dfa.TRUE: {},	dfa.FALSE: {},	dfa.NIL: {},	dfa.LEFT_PAREN: {},
-		},
-		Sequences: []GrammarSequence{
 			{
 				FirstSet: map[dfa.TokenType]struct{}{
-					dfa.BANG_EQUAL: {},
-				},
-				Elements: []utils.Grammar_element{
-					{IsNonTerminal: false, Terminal_type: dfa.BANG_EQUAL},
-				},
-			},	{
-				FirstSet: map[dfa.TokenType]struct{}{
-					dfa.EQUAL_EQUAL: {},
+					utils.Epsilon: {},
 				},
 				Elements: []utils.Grammar_element{
-					{IsNonTerminal: false, Terminal_type: dfa.EQUAL_EQUAL},
+					{IsNonTerminal: false, Terminal_type: utils.Epsilon},
 				},
 			},
 		},
-	},	"equality": {
+	},
+	"comparison": {
 		FollowSet: map[dfa.TokenType]struct{}{
-			dfa.EOF: {},	dfa.COMMA: {},	dfa.RIGHT_PAREN: {},
+			dfa.BANG_EQUAL: {}, dfa.EQUAL_EQUAL: {}, dfa.COMMA: {}, dfa.EOF: {}, dfa.RIGHT_PAREN: {},
 		},
 		Sequences: []GrammarSequence{
 			{
 				FirstSet: map[dfa.TokenType]struct{}{
-					dfa.BANG: {},	dfa.MINUS: {},	dfa.IDENTIFIER: {},	dfa.NUMBER: {},	dfa.STRING: {},	dfa.TRUE: {},	dfa.FALSE: {},	dfa.NIL: {},	dfa.LEFT_PAREN: {},
+					dfa.BANG: {}, dfa.MINUS: {}, dfa.IDENTIFIER: {}, dfa.NUMBER: {}, dfa.STRING: {}, dfa.TRUE: {}, dfa.FALSE: {}, dfa.NIL: {}, dfa.LEFT_PAREN: {},
 				},
 				Elements: []utils.Grammar_element{
-					{IsNonTerminal: true, Non_term_name: "comparison"},	{IsNonTerminal: true, Non_term_name: "999_5"},
+					{IsNonTerminal: true, Non_term_name: "term"},
+					{IsNonTerminal: true, Non_term_name: "999_comparison_star_1"},
 				},
 			},
 		},
-	},	"999_10": {
+	},
+	"999_comparison_group_3": {
 		FollowSet: map[dfa.TokenType]struct{}{
-			dfa.EOF: {},	dfa.BANG: {},	dfa.MINUS: {},	dfa.IDENTIFIER: {},	dfa.NUMBER: {},	dfa.STRING: {},	dfa.TRUE: {},	dfa.FALSE: {},	dfa.NIL: {},	dfa.LEFT_PAREN: {},
+			dfa.BANG: {}, dfa.MINUS: {}, dfa.IDENTIFIER: {}, dfa.NUMBER: {}, dfa.STRING: {}, dfa.TRUE: {}, dfa.FALSE: {}, dfa.NIL: {}, dfa.LEFT_PAREN: {},
 		},
 		Sequences: []GrammarSequence{
 			{
@@ -90,21 +81,24 @@ var grammarRules = map[string]ProductionRule{
 				Elements: []utils.Grammar_element{
 					{IsNonTerminal: false, Terminal_type: dfa.GREATER},
 				},
-			},	{
+			},
+			{
 				FirstSet: map[dfa.TokenType]struct{}{
 					dfa.GREATER_EQUAL: {},
 				},
 				Elements: []utils.Grammar_element{
 					{IsNonTerminal: false, Terminal_type: dfa.GREATER_EQUAL},
 				},
-			},	{
+			},
+			{
 				FirstSet: map[dfa.TokenType]struct{}{
 					dfa.LESS: {},
 				},
 				Elements: []utils.Grammar_element{
 					{IsNonTerminal: false, Terminal_type: dfa.LESS},
 				},
-			},	{
+			},
+			{
 				FirstSet: map[dfa.TokenType]struct{}{
 					dfa.LESS_EQUAL: {},
 				},
@@ -113,124 +107,87 @@ var grammarRules = map[string]ProductionRule{
 				},
 			},
 		},
-	},	"comparison": {
+	},
+	"999_comparison_star_1": {
 		FollowSet: map[dfa.TokenType]struct{}{
-			dfa.EOF: {},	dfa.BANG_EQUAL: {},	dfa.EQUAL_EQUAL: {},	dfa.COMMA: {},	dfa.RIGHT_PAREN: {},
+			dfa.BANG_EQUAL: {}, dfa.EQUAL_EQUAL: {}, dfa.COMMA: {}, dfa.EOF: {}, dfa.RIGHT_PAREN: {},
 		},
 		Sequences: []GrammarSequence{
 			{
 				FirstSet: map[dfa.TokenType]struct{}{
-					dfa.BANG: {},	dfa.MINUS: {},	dfa.IDENTIFIER: {},	dfa.NUMBER: {},	dfa.STRING: {},	dfa.TRUE: {},	dfa.FALSE: {},	dfa.NIL: {},	dfa.LEFT_PAREN: {},
+					dfa.GREATER: {}, dfa.GREATER_EQUAL: {}, dfa.LESS: {}, dfa.LESS_EQUAL: {},
 				},
 				Elements: []utils.Grammar_element{
-					{IsNonTerminal: true, Non_term_name: "term"},	{IsNonTerminal: true, Non_term_name: "999_8"},
+					{IsNonTerminal: true, Non_term_name: "999_comparison_group_3"},
+					{IsNonTerminal: true, Non_term_name: "term"},
+					{IsNonTerminal: true, Non_term_name: "999_comparison_star_1"},
 				},
 			},
-		},
-	},	"999_12": {
-		FollowSet: map[dfa.TokenType]struct{}{
-			dfa.EOF: {},	dfa.MINUS: {},	dfa.PLUS: {},	dfa.GREATER: {},	dfa.GREATER_EQUAL: {},	dfa.LESS: {},	dfa.LESS_EQUAL: {},	dfa.BANG_EQUAL: {},	dfa.EQUAL_EQUAL: {},	dfa.COMMA: {},	dfa.RIGHT_PAREN: {},
-		},
-		Sequences: []GrammarSequence{
 			{
 				FirstSet: map[dfa.TokenType]struct{}{
-					dfa.MINUS: {},	dfa.PLUS: {},
+					utils.Epsilon: {},
 				},
 				Elements: []utils.Grammar_element{
-					{IsNonTerminal: true, Non_term_name: "999_13"},	{IsNonTerminal: true, Non_term_name: "factor"},
+					{IsNonTerminal: false, Terminal_type: utils.Epsilon},
 				},
 			},
 		},
-	},	"expression": {
+	},
+	"equality": {
 		FollowSet: map[dfa.TokenType]struct{}{
-			dfa.EOF: {},	dfa.RIGHT_PAREN: {},
+			dfa.COMMA: {}, dfa.EOF: {}, dfa.RIGHT_PAREN: {},
 		},
 		Sequences: []GrammarSequence{
 			{
 				FirstSet: map[dfa.TokenType]struct{}{
-					dfa.BANG: {},	dfa.MINUS: {},	dfa.IDENTIFIER: {},	dfa.NUMBER: {},	dfa.STRING: {},	dfa.TRUE: {},	dfa.FALSE: {},	dfa.NIL: {},	dfa.LEFT_PAREN: {},
+					dfa.BANG: {}, dfa.MINUS: {}, dfa.IDENTIFIER: {}, dfa.NUMBER: {}, dfa.STRING: {}, dfa.TRUE: {}, dfa.FALSE: {}, dfa.NIL: {}, dfa.LEFT_PAREN: {},
 				},
 				Elements: []utils.Grammar_element{
-					{IsNonTerminal: true, Non_term_name: "comma"},
+					{IsNonTerminal: true, Non_term_name: "comparison"},
+					{IsNonTerminal: true, Non_term_name: "999_equality_star_1"},
 				},
 			},
 		},
-	},	"999_5": {
+	},
+	"999_equality_group_3": {
 		FollowSet: map[dfa.TokenType]struct{}{
-			dfa.EOF: {},	dfa.COMMA: {},	dfa.RIGHT_PAREN: {},
+			dfa.BANG: {}, dfa.MINUS: {}, dfa.IDENTIFIER: {}, dfa.NUMBER: {}, dfa.STRING: {}, dfa.TRUE: {}, dfa.FALSE: {}, dfa.NIL: {}, dfa.LEFT_PAREN: {},
 		},
 		Sequences: []GrammarSequence{
 			{
 				FirstSet: map[dfa.TokenType]struct{}{
-					dfa.BANG_EQUAL: {},	dfa.EQUAL_EQUAL: {},
-				},
-				Elements: []utils.Grammar_element{
-					{IsNonTerminal: true, Non_term_name: "999_6"},	{IsNonTerminal: true, Non_term_name: "999_5"},
-				},
-			},	{
-				FirstSet: map[dfa.TokenType]struct{}{
-					utils.Epsilon: {},
+					dfa.BANG_EQUAL: {},
 				},
 				Elements: []utils.Grammar_element{
-					{IsNonTerminal: false, Terminal_type: utils.Epsilon},
+					{IsNonTerminal: false, Terminal_type: dfa.BANG_EQUAL},
 				},
 			},
-		},
-	},	"999_8": {
-		FollowSet: map[dfa.TokenType]struct{}{
-			dfa.EOF: {},	dfa.BANG_EQUAL: {},	dfa.EQUAL_EQUAL: {},	dfa.COMMA: {},	dfa.RIGHT_PAREN: {},
-		},
-		Sequences: []GrammarSequence{
 			{
 				FirstSet: map[dfa.TokenType]struct{}{
-					dfa.GREATER: {},	dfa.GREATER_EQUAL: {},	dfa.LESS: {},	dfa.LESS_EQUAL: {},
-				},
-				Elements: []utils.Grammar_element{
-					{IsNonTerminal: true, Non_term_name: "999_9"},	{IsNonTerminal: true, Non_term_name: "999_8"},
-				},
-			},	{
-				FirstSet: map[dfa.TokenType]struct{}{
-					utils.Epsilon: {},
+					dfa.EQUAL_EQUAL: {},
 				},
 				Elements: []utils.Grammar_element{
-					{IsNonTerminal: false, Terminal_type: utils.Epsilon},
+					{IsNonTerminal: false, Terminal_type: dfa.EQUAL_EQUAL},
 				},
 			},
 		},
-	},	"999_13": {
+	},
+	"999_equality_star_1": {
 		FollowSet: map[dfa.TokenType]struct{}{
-			dfa.EOF: {},	dfa.BANG: {},	dfa.MINUS: {},	dfa.IDENTIFIER: {},	dfa.NUMBER: {},	dfa.STRING: {},	dfa.TRUE: {},	dfa.FALSE: {},	dfa.NIL: {},	dfa.LEFT_PAREN: {},
+			dfa.COMMA: {}, dfa.EOF: {}, dfa.RIGHT_PAREN: {},
 		},
 		Sequences: []GrammarSequence{
 			{
 				FirstSet: map[dfa.TokenType]struct{}{
-					dfa.MINUS: {},
-				},
-				Elements: []utils.Grammar_element{
-					{IsNonTerminal: false, Terminal_type: dfa.MINUS},
-				},
-			},	{
-				FirstSet: map[dfa.TokenType]struct{}{
-					dfa.PLUS: {},
+					dfa.BANG_EQUAL: {}, dfa.EQUAL_EQUAL: {},
 				},
 				Elements: []utils.Grammar_element{
-					{IsNonTerminal: false, Terminal_type: dfa.PLUS},
+					{IsNonTerminal: true, Non_term_name: "999_equality_group_3"},
+					{IsNonTerminal: true, Non_term_name: "comparison"},
+					{IsNonTerminal: true, Non_term_name: "999_equality_star_1"},
 				},
 			},
-		},
-	},	"999_11": {
-		FollowSet: map[dfa.TokenType]struct{}{
-			dfa.EOF: {},	dfa.GREATER: {},	dfa.GREATER_EQUAL: {},	dfa.LESS: {},	dfa.LESS_EQUAL: {},	dfa.BANG_EQUAL: {},	dfa.EQUAL_EQUAL: {},	dfa.COMMA: {},	dfa.RIGHT_PAREN: {},
-		},
-		Sequences: []GrammarSequence{
 			{
-				FirstSet: map[dfa.TokenType]struct{}{
-					dfa.MINUS: {},	dfa.PLUS: {},
-				},
-				Elements: []utils.Grammar_element{
-					{IsNonTerminal: true, Non_term_name: "999_12"},	{IsNonTerminal: true, Non_term_name: "999_11"},
-				},
-			},	{
 				FirstSet: map[dfa.TokenType]struct{}{
 					utils.Epsilon: {},
 				},
@@ -239,110 +196,77 @@ var grammarRules = map[string]ProductionRule{
 				},
 			},
 		},
-	},	"term": {
-		FollowSet: map[dfa.TokenType]struct{}{
-			dfa.EOF: {},	dfa.GREATER: {},	dfa.GREATER_EQUAL: {},	dfa.LESS: {},	dfa.LESS_EQUAL: {},	dfa.BANG_EQUAL: {},	dfa.EQUAL_EQUAL: {},	dfa.COMMA: {},	dfa.RIGHT_PAREN: {},
-		},
-		Sequences: []GrammarSequence{
-			{
-				FirstSet: map[dfa.TokenType]struct{}{
-					dfa.BANG: {},	dfa.MINUS: {},	dfa.IDENTIFIER: {},	dfa.NUMBER: {},	dfa.STRING: {},	dfa.TRUE: {},	dfa.FALSE: {},	dfa.NIL: {},	dfa.LEFT_PAREN: {},
-				},
-				Elements: []utils.Grammar_element{
-					{IsNonTerminal: true, Non_term_name: "factor"},	{IsNonTerminal: true, Non_term_name: "999_11"},
-				},
-			},
-		},
-	},	"999_15": {
+	},
+	"expression": {
 		FollowSet: map[dfa.TokenType]struct{}{
-			dfa.EOF: {},	dfa.SLASH: {},	dfa.STAR: {},	dfa.MINUS: {},	dfa.PLUS: {},	dfa.GREATER: {},	dfa.GREATER_EQUAL: {},	dfa.LESS: {},	dfa.LESS_EQUAL: {},	dfa.BANG_EQUAL: {},	dfa.EQUAL_EQUAL: {},	dfa.COMMA: {},	dfa.RIGHT_PAREN: {},
+			dfa.EOF: {}, dfa.RIGHT_PAREN: {},
 		},
 		Sequences: []GrammarSequence{
 			{
 				FirstSet: map[dfa.TokenType]struct{}{
-					dfa.SLASH: {},	dfa.STAR: {},
+					dfa.BANG: {}, dfa.MINUS: {}, dfa.IDENTIFIER: {}, dfa.NUMBER: {}, dfa.STRING: {}, dfa.TRUE: {}, dfa.FALSE: {}, dfa.NIL: {}, dfa.LEFT_PAREN: {},
 				},
 				Elements: []utils.Grammar_element{
-					{IsNonTerminal: true, Non_term_name: "999_16"},	{IsNonTerminal: true, Non_term_name: "unary"},
+					{IsNonTerminal: true, Non_term_name: "comma"},
 				},
 			},
 		},
-	},	"999_1": {
+	},
+	"factor": {
 		FollowSet: map[dfa.TokenType]struct{}{
-			dfa.EOF: {},	dfa.BANG: {},	dfa.MINUS: {},	dfa.IDENTIFIER: {},	dfa.NUMBER: {},	dfa.STRING: {},	dfa.TRUE: {},	dfa.FALSE: {},	dfa.NIL: {},	dfa.LEFT_PAREN: {},
+			dfa.MINUS: {}, dfa.PLUS: {}, dfa.GREATER: {}, dfa.GREATER_EQUAL: {}, dfa.LESS: {}, dfa.LESS_EQUAL: {}, dfa.BANG_EQUAL: {}, dfa.EQUAL_EQUAL: {}, dfa.COMMA: {}, dfa.EOF: {}, dfa.RIGHT_PAREN: {},
 		},
 		Sequences: []GrammarSequence{
 			{
 				FirstSet: map[dfa.TokenType]struct{}{
-					dfa.BANG: {},
-				},
-				Elements: []utils.Grammar_element{
-					{IsNonTerminal: false, Terminal_type: dfa.BANG},
-				},
-			},	{
-				FirstSet: map[dfa.TokenType]struct{}{
-					dfa.MINUS: {},
+					dfa.BANG: {}, dfa.MINUS: {}, dfa.IDENTIFIER: {}, dfa.NUMBER: {}, dfa.STRING: {}, dfa.TRUE: {}, dfa.FALSE: {}, dfa.NIL: {}, dfa.LEFT_PAREN: {},
 				},
 				Elements: []utils.Grammar_element{
-					{IsNonTerminal: false, Terminal_type: dfa.MINUS},
+					{IsNonTerminal: true, Non_term_name: "unary"},
+					{IsNonTerminal: true, Non_term_name: "999_factor_star_1"},
 				},
 			},
 		},
-	},	"999_0": {
+	},
+	"999_factor_group_3": {
 		FollowSet: map[dfa.TokenType]struct{}{
-			dfa.EOF: {},	dfa.SLASH: {},	dfa.STAR: {},	dfa.MINUS: {},	dfa.PLUS: {},	dfa.GREATER: {},	dfa.GREATER_EQUAL: {},	dfa.LESS: {},	dfa.LESS_EQUAL: {},	dfa.BANG_EQUAL: {},	dfa.EQUAL_EQUAL: {},	dfa.COMMA: {},	dfa.RIGHT_PAREN: {},
+			dfa.BANG: {}, dfa.MINUS: {}, dfa.IDENTIFIER: {}, dfa.NUMBER: {}, dfa.STRING: {}, dfa.TRUE: {}, dfa.FALSE: {}, dfa.NIL: {}, dfa.LEFT_PAREN: {},
 		},
 		Sequences: []GrammarSequence{
 			{
 				FirstSet: map[dfa.TokenType]struct{}{
-					dfa.BANG: {},	dfa.MINUS: {},
+					dfa.SLASH: {},
 				},
 				Elements: []utils.Grammar_element{
-					{IsNonTerminal: true, Non_term_name: "999_1"},	{IsNonTerminal: true, Non_term_name: "unary"},
+					{IsNonTerminal: false, Terminal_type: dfa.SLASH},
 				},
 			},
-		},
-	},	"999_2": {
-		FollowSet: map[dfa.TokenType]struct{}{
-			dfa.EOF: {},	dfa.SLASH: {},	dfa.STAR: {},	dfa.MINUS: {},	dfa.PLUS: {},	dfa.GREATER: {},	dfa.GREATER_EQUAL: {},	dfa.LESS: {},	dfa.LESS_EQUAL: {},	dfa.BANG_EQUAL: {},	dfa.EQUAL_EQUAL: {},	dfa.COMMA: {},	dfa.RIGHT_PAREN: {},
-		},
-		Sequences: []GrammarSequence{
 			{
 				FirstSet: map[dfa.TokenType]struct{}{
-					dfa.LEFT_PAREN: {},
+					dfa.STAR: {},
 				},
 				Elements: []utils.Grammar_element{
-					{IsNonTerminal: false, Terminal_type: dfa.LEFT_PAREN},	{IsNonTerminal: true, Non_term_name: "expression"},	{IsNonTerminal: false, Terminal_type: dfa.RIGHT_PAREN},
+					{IsNonTerminal: false, Terminal_type: dfa.STAR},
 				},
 			},
 		},
-	},	"999_6": {
+	},
+	"999_factor_star_1": {
 		FollowSet: map[dfa.TokenType]struct{}{
-			dfa.EOF: {},	dfa.BANG_EQUAL: {},	dfa.EQUAL_EQUAL: {},	dfa.COMMA: {},	dfa.RIGHT_PAREN: {},
+			dfa.MINUS: {}, dfa.PLUS: {}, dfa.GREATER: {}, dfa.GREATER_EQUAL: {}, dfa.LESS: {}, dfa.LESS_EQUAL: {}, dfa.BANG_EQUAL: {}, dfa.EQUAL_EQUAL: {}, dfa.COMMA: {}, dfa.EOF: {}, dfa.RIGHT_PAREN: {},
 		},
 		Sequences: []GrammarSequence{
 			{
 				FirstSet: map[dfa.TokenType]struct{}{
-					dfa.BANG_EQUAL: {},	dfa.EQUAL_EQUAL: {},
+					dfa.SLASH: {}, dfa.STAR: {},
 				},
 				Elements: []utils.Grammar_element{
-					{IsNonTerminal: true, Non_term_name: "999_7"},	{IsNonTerminal: true, Non_term_name: "comparison"},
+					{IsNonTerminal: true, Non_term_name: "999_factor_group_3"},
+					{IsNonTerminal: true, Non_term_name: "unary"},
+					{IsNonTerminal: true, Non_term_name: "999_factor_star_1"},
 				},
 			},
-		},
-	},	"999_14": {
-		FollowSet: map[dfa.TokenType]struct{}{
-			dfa.EOF: {},	dfa.MINUS: {},	dfa.PLUS: {},	dfa.GREATER: {},	dfa.GREATER_EQUAL: {},	dfa.LESS: {},	dfa.LESS_EQUAL: {},	dfa.BANG_EQUAL: {},	dfa.EQUAL_EQUAL: {},	dfa.COMMA: {},	dfa.RIGHT_PAREN: {},
-		},
-		Sequences: []GrammarSequence{
 			{
-				FirstSet: map[dfa.TokenType]struct{}{
-					dfa.SLASH: {},	dfa.STAR: {},
-				},
-				Elements: []utils.Grammar_element{
-					{IsNonTerminal: true, Non_term_name: "999_15"},	{IsNonTerminal: true, Non_term_name: "999_14"},
-				},
-			},	{
 				FirstSet: map[dfa.TokenType]struct{}{
 					utils.Epsilon: {},
 				},
@@ -351,9 +275,10 @@ var grammarRules = map[string]ProductionRule{
 				},
 			},
 		},
-	},	"primary": {
+	},
+	"primary": {
 		FollowSet: map[dfa.TokenType]struct{}{
-			dfa.EOF: {},	dfa.SLASH: {},	dfa.STAR: {},	dfa.MINUS: {},	dfa.PLUS: {},	dfa.GREATER: {},	dfa.GREATER_EQUAL: {},	dfa.LESS: {},	dfa.LESS_EQUAL: {},	dfa.BANG_EQUAL: {},	dfa.EQUAL_EQUAL: {},	dfa.COMMA: {},	dfa.RIGHT_PAREN: {},
+			dfa.SLASH: {}, dfa.STAR: {}, dfa.MINUS: {}, dfa.PLUS: {}, dfa.GREATER: {}, dfa.GREATER_EQUAL: {}, dfa.LESS: {}, dfa.LESS_EQUAL: {}, dfa.BANG_EQUAL: {}, dfa.EQUAL_EQUAL: {}, dfa.COMMA: {}, dfa.EOF: {}, dfa.RIGHT_PAREN: {},
 		},
 		Sequences: []GrammarSequence{
 			{
@@ -363,133 +288,330 @@ var grammarRules = map[string]ProductionRule{
 				Elements: []utils.Grammar_element{
 					{IsNonTerminal: false, Terminal_type: dfa.IDENTIFIER},
 				},
-			},	{
+			},
+			{
 				FirstSet: map[dfa.TokenType]struct{}{
 					dfa.NUMBER: {},
 				},
 				Elements: []utils.Grammar_element{
 					{IsNonTerminal: false, Terminal_type: dfa.NUMBER},
 				},
-			},	{
+			},
+			{
 				FirstSet: map[dfa.TokenType]struct{}{
 					dfa.STRING: {},
 				},
 				Elements: []utils.Grammar_element{
 					{IsNonTerminal: false, Terminal_type: dfa.STRING},
 				},
-			},	{
+			},
+			{
 				FirstSet: map[dfa.TokenType]struct{}{
 					dfa.TRUE: {},
 				},
 				Elements: []utils.Grammar_element{
 					{IsNonTerminal: false, Terminal_type: dfa.TRUE},
 				},
-			},	{
+			},
+			{
 				FirstSet: map[dfa.TokenType]struct{}{
 					dfa.FALSE: {},
 				},
 				Elements: []utils.Grammar_element{
 					{IsNonTerminal: false, Terminal_type: dfa.FALSE},
 				},
-			},	{
+			},
+			{
 				FirstSet: map[dfa.TokenType]struct{}{
 					dfa.NIL: {},
 				},
 				Elements: []utils.Grammar_element{
 					{IsNonTerminal: false, Terminal_type: dfa.NIL},
 				},
-			},	{
+			},
+			{
 				FirstSet: map[dfa.TokenType]struct{}{
 					dfa.LEFT_PAREN: {},
 				},
 				Elements: []utils.Grammar_element{
-					{IsNonTerminal: true, Non_term_name: "999_2"},
+					{IsNonTerminal: false, Terminal_type: dfa.LEFT_PAREN},
+					{IsNonTerminal: true, Non_term_name: "expression"},
+					{IsNonTerminal: false, Terminal_type: dfa.RIGHT_PAREN},
 				},
 			},
 		},
-	},	"999_4": {
+	},
+	"term": {
 		FollowSet: map[dfa.TokenType]struct{}{
-			dfa.EOF: {},	dfa.COMMA: {},	dfa.RIGHT_PAREN: {},
+			dfa.GREATER: {}, dfa.GREATER_EQUAL: {}, dfa.LESS: {}, dfa.LESS_EQUAL: {}, dfa.BANG_EQUAL: {}, dfa.EQUAL_EQUAL: {}, dfa.COMMA: {}, dfa.EOF: {}, dfa.RIGHT_PAREN: {},
 		},
 		Sequences: []GrammarSequence{
 			{
 				FirstSet: map[dfa.TokenType]struct{}{
-					dfa.COMMA: {},
+					dfa.BANG: {}, dfa.MINUS: {}, dfa.IDENTIFIER: {}, dfa.NUMBER: {}, dfa.STRING: {}, dfa.TRUE: {}, dfa.FALSE: {}, dfa.NIL: {}, dfa.LEFT_PAREN: {},
 				},
 				Elements: []utils.Grammar_element{
-					{IsNonTerminal: false, Terminal_type: dfa.COMMA},	{IsNonTerminal: true, Non_term_name: "equality"},
+					{IsNonTerminal: true, Non_term_name: "factor"},
+					{IsNonTerminal: true, Non_term_name: "999_term_star_1"},
 				},
 			},
 		},
-	},	"999_3": {
+	},
+	"999_term_group_3": {
 		FollowSet: map[dfa.TokenType]struct{}{
-			dfa.EOF: {},	dfa.RIGHT_PAREN: {},
+			dfa.BANG: {}, dfa.MINUS: {}, dfa.IDENTIFIER: {}, dfa.NUMBER: {}, dfa.STRING: {}, dfa.TRUE: {}, dfa.FALSE: {}, dfa.NIL: {}, dfa.LEFT_PAREN: {},
 		},
 		Sequences: []GrammarSequence{
 			{
 				FirstSet: map[dfa.TokenType]struct{}{
-					dfa.COMMA: {},
+					dfa.MINUS: {},
 				},
 				Elements: []utils.Grammar_element{
-					{IsNonTerminal: true, Non_term_name: "999_4"},	{IsNonTerminal: true, Non_term_name: "999_3"},
+					{IsNonTerminal: false, Terminal_type: dfa.MINUS},
 				},
-			},	{
+			},
+			{
 				FirstSet: map[dfa.TokenType]struct{}{
-					utils.Epsilon: {},
+					dfa.PLUS: {},
 				},
 				Elements: []utils.Grammar_element{
-					{IsNonTerminal: false, Terminal_type: utils.Epsilon},
+					{IsNonTerminal: false, Terminal_type: dfa.PLUS},
 				},
 			},
 		},
-	},	"999_9": {
+	},
+	"999_term_star_1": {
 		FollowSet: map[dfa.TokenType]struct{}{
-			dfa.EOF: {},	dfa.GREATER: {},	dfa.GREATER_EQUAL: {},	dfa.LESS: {},	dfa.LESS_EQUAL: {},	dfa.BANG_EQUAL: {},	dfa.EQUAL_EQUAL: {},	dfa.COMMA: {},	dfa.RIGHT_PAREN: {},
+			dfa.GREATER: {}, dfa.GREATER_EQUAL: {}, dfa.LESS: {}, dfa.LESS_EQUAL: {}, dfa.BANG_EQUAL: {}, dfa.EQUAL_EQUAL: {}, dfa.COMMA: {}, dfa.EOF: {}, dfa.RIGHT_PAREN: {},
 		},
 		Sequences: []GrammarSequence{
 			{
 				FirstSet: map[dfa.TokenType]struct{}{
-					dfa.GREATER: {},	dfa.GREATER_EQUAL: {},	dfa.LESS: {},	dfa.LESS_EQUAL: {},
+					dfa.MINUS: {}, dfa.PLUS: {},
 				},
 				Elements: []utils.Grammar_element{
-					{IsNonTerminal: true, Non_term_name: "999_10"},	{IsNonTerminal: true, Non_term_name: "term"},
+					{IsNonTerminal: true, Non_term_name: "999_term_group_3"},
+					{IsNonTerminal: true, Non_term_name: "factor"},
+					{IsNonTerminal: true, Non_term_name: "999_term_star_1"},
+				},
+			},
+			{
+				FirstSet: map[dfa.TokenType]struct{}{
+					utils.Epsilon: {},
+				},
+				Elements: []utils.Grammar_element{
+					{IsNonTerminal: false, Terminal_type: utils.Epsilon},
 				},
 			},
 		},
-	},	"999_16": {
+	},
+	"unary": {
 		FollowSet: map[dfa.TokenType]struct{}{
-			dfa.EOF: {},	dfa.BANG: {},	dfa.MINUS: {},	dfa.IDENTIFIER: {},	dfa.NUMBER: {},	dfa.STRING: {},	dfa.TRUE: {},	dfa.FALSE: {},	dfa.NIL: {},	dfa.LEFT_PAREN: {},
+			dfa.SLASH: {}, dfa.STAR: {}, dfa.MINUS: {}, dfa.PLUS: {}, dfa.GREATER: {}, dfa.GREATER_EQUAL: {}, dfa.LESS: {}, dfa.LESS_EQUAL: {}, dfa.BANG_EQUAL: {}, dfa.EQUAL_EQUAL: {}, dfa.COMMA: {}, dfa.EOF: {}, dfa.RIGHT_PAREN: {},
 		},
 		Sequences: []GrammarSequence{
 			{
 				FirstSet: map[dfa.TokenType]struct{}{
-					dfa.SLASH: {},
+					dfa.BANG: {}, dfa.MINUS: {},
 				},
 				Elements: []utils.Grammar_element{
-					{IsNonTerminal: false, Terminal_type: dfa.SLASH},
+					{IsNonTerminal: true, Non_term_name: "999_unary_group_2"},
+					{IsNonTerminal: true, Non_term_name: "unary"},
 				},
-			},	{
+			},
+			{
 				FirstSet: map[dfa.TokenType]struct{}{
-					dfa.STAR: {},
+					dfa.IDENTIFIER: {}, dfa.NUMBER: {}, dfa.STRING: {}, dfa.TRUE: {}, dfa.FALSE: {}, dfa.NIL: {}, dfa.LEFT_PAREN: {},
 				},
 				Elements: []utils.Grammar_element{
-					{IsNonTerminal: false, Terminal_type: dfa.STAR},
+					{IsNonTerminal: true, Non_term_name: "primary"},
 				},
 			},
 		},
-	},	"factor": {
+	},
+	"999_unary_group_2": {
 		FollowSet: map[dfa.TokenType]struct{}{
-			dfa.EOF: {},	dfa.MINUS: {},	dfa.PLUS: {},	dfa.GREATER: {},	dfa.GREATER_EQUAL: {},	dfa.LESS: {},	dfa.LESS_EQUAL: {},	dfa.BANG_EQUAL: {},	dfa.EQUAL_EQUAL: {},	dfa.COMMA: {},	dfa.RIGHT_PAREN: {},
+			dfa.BANG: {}, dfa.MINUS: {}, dfa.IDENTIFIER: {}, dfa.NUMBER: {}, dfa.STRING: {}, dfa.TRUE: {}, dfa.FALSE: {}, dfa.NIL: {}, dfa.LEFT_PAREN: {},
 		},
 		Sequences: []GrammarSequence{
 			{
 				FirstSet: map[dfa.TokenType]struct{}{
-					dfa.BANG: {},	dfa.MINUS: {},	dfa.IDENTIFIER: {},	dfa.NUMBER: {},	dfa.STRING: {},	dfa.TRUE: {},	dfa.FALSE: {},	dfa.NIL: {},	dfa.LEFT_PAREN: {},
+					dfa.BANG: {},
+				},
+				Elements: []utils.Grammar_element{
+					{IsNonTerminal: false, Terminal_type: dfa.BANG},
+				},
+			},
+			{
+				FirstSet: map[dfa.TokenType]struct{}{
+					dfa.MINUS: {},
 				},
 				Elements: []utils.Grammar_element{
-					{IsNonTerminal: true, Non_term_name: "unary"},	{IsNonTerminal: true, Non_term_name: "999_14"},
+					{IsNonTerminal: false, Terminal_type: dfa.MINUS},
 				},
 			},
 		},
 	},
 }
+
+var parseTable = map[string]map[dfa.TokenType]int{
+	"comma": {
+		dfa.IDENTIFIER: 0,
+		dfa.STRING:     0,
+		dfa.NUMBER:     0,
+		dfa.LEFT_PAREN: 0,
+		dfa.MINUS:      0,
+		dfa.BANG:       0,
+		dfa.FALSE:      0,
+		dfa.NIL:        0,
+		dfa.TRUE:       0,
+	},
+	"999_comma_star_1": {
+		dfa.EOF:         1,
+		dfa.RIGHT_PAREN: 1,
+		dfa.COMMA:       0,
+	},
+	"comparison": {
+		dfa.IDENTIFIER: 0,
+		dfa.STRING:     0,
+		dfa.NUMBER:     0,
+		dfa.LEFT_PAREN: 0,
+		dfa.MINUS:      0,
+		dfa.BANG:       0,
+		dfa.FALSE:      0,
+		dfa.NIL:        0,
+		dfa.TRUE:       0,
+	},
+	"999_comparison_group_3": {
+		dfa.GREATER:       0,
+		dfa.GREATER_EQUAL: 1,
+		dfa.LESS:          2,
+		dfa.LESS_EQUAL:    3,
+	},
+	"999_comparison_star_1": {
+		dfa.EOF:           1,
+		dfa.RIGHT_PAREN:   1,
+		dfa.COMMA:         1,
+		dfa.BANG_EQUAL:    1,
+		dfa.EQUAL_EQUAL:   1,
+		dfa.GREATER:       0,
+		dfa.GREATER_EQUAL: 0,
+		dfa.LESS:          0,
+		dfa.LESS_EQUAL:    0,
+	},
+	"equality": {
+		dfa.IDENTIFIER: 0,
+		dfa.STRING:     0,
+		dfa.NUMBER:     0,
+		dfa.LEFT_PAREN: 0,
+		dfa.MINUS:      0,
+		dfa.BANG:       0,
+		dfa.FALSE:      0,
+		dfa.NIL:        0,
+		dfa.TRUE:       0,
+	},
+	"999_equality_group_3": {
+		dfa.BANG_EQUAL:  0,
+		dfa.EQUAL_EQUAL: 1,
+	},
+	"999_equality_star_1": {
+		dfa.EOF:         1,
+		dfa.RIGHT_PAREN: 1,
+		dfa.COMMA:       1,
+		dfa.BANG_EQUAL:  0,
+		dfa.EQUAL_EQUAL: 0,
+	},
+	"expression": {
+		dfa.IDENTIFIER: 0,
+		dfa.STRING:     0,
+		dfa.NUMBER:     0,
+		dfa.LEFT_PAREN: 0,
+		dfa.MINUS:      0,
+		dfa.BANG:       0,
+		dfa.FALSE:      0,
+		dfa.NIL:        0,
+		dfa.TRUE:       0,
+	},
+	"factor": {
+		dfa.IDENTIFIER: 0,
+		dfa.STRING:     0,
+		dfa.NUMBER:     0,
+		dfa.LEFT_PAREN: 0,
+		dfa.MINUS:      0,
+		dfa.BANG:       0,
+		dfa.FALSE:      0,
+		dfa.NIL:        0,
+		dfa.TRUE:       0,
+	},
+	"999_factor_group_3": {
+		dfa.SLASH: 0,
+		dfa.STAR:  1,
+	},
+	"999_factor_star_1": {
+		dfa.EOF:           1,
+		dfa.RIGHT_PAREN:   1,
+		dfa.COMMA:         1,
+		dfa.MINUS:         1,
+		dfa.PLUS:          1,
+		dfa.SLASH:         0,
+		dfa.STAR:          0,
+		dfa.BANG_EQUAL:    1,
+		dfa.EQUAL_EQUAL:   1,
+		dfa.GREATER:       1,
+		dfa.GREATER_EQUAL: 1,
+		dfa.LESS:          1,
+		dfa.LESS_EQUAL:    1,
+	},
+	"primary": {
+		dfa.IDENTIFIER: 0,
+		dfa.STRING:     2,
+		dfa.NUMBER:     1,
+		dfa.LEFT_PAREN: 6,
+		dfa.FALSE:      4,
+		dfa.NIL:        5,
+		dfa.TRUE:       3,
+	},
+	"term": {
+		dfa.IDENTIFIER: 0,
+		dfa.STRING:     0,
+		dfa.NUMBER:     0,
+		dfa.LEFT_PAREN: 0,
+		dfa.MINUS:      0,
+		dfa.BANG:       0,
+		dfa.FALSE:      0,
+		dfa.NIL:        0,
+		dfa.TRUE:       0,
+	},
+	"999_term_group_3": {
+		dfa.MINUS: 0,
+		dfa.PLUS:  1,
+	},
+	"999_term_star_1": {
+		dfa.EOF:           1,
+		dfa.RIGHT_PAREN:   1,
+		dfa.COMMA:         1,
+		dfa.MINUS:         0,
+		dfa.PLUS:          0,
+		dfa.BANG_EQUAL:    1,
+		dfa.EQUAL_EQUAL:   1,
+		dfa.GREATER:       1,
+		dfa.GREATER_EQUAL: 1,
+		dfa.LESS:          1,
+		dfa.LESS_EQUAL:    1,
+	},
+	"unary": {
+		dfa.IDENTIFIER: 1,
+		dfa.STRING:     1,
+		dfa.NUMBER:     1,
+		dfa.LEFT_PAREN: 1,
+		dfa.MINUS:      0,
+		dfa.BANG:       0,
+		dfa.FALSE:      1,
+		dfa.NIL:        1,
+		dfa.TRUE:       1,
+	},
+	"999_unary_group_2": {
+		dfa.MINUS: 1,
+		dfa.BANG:  0,
+	},
+}
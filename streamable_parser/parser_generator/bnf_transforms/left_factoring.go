@@ -0,0 +1,143 @@
+package bnf_transforms
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/ebnf_to_bnf"
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/utils"
+)
+
+/*
+Left_factor rewrites the productions of a non-terminal which start the same way, which an LL(1) parser cannot choose between, into one production followed by an artificial non-terminal for what comes after:
+
+	stmt -> "if" cond "then" stmt | "if" cond "then" stmt "else" stmt
+
+	stmt -> "if" cond "then" stmt 999_stmt_factor_1
+	999_stmt_factor_1 -> Alternative(0) | "else" stmt Alternative(1)
+
+The `+` of the converter gives such productions too, `X -> a X | a` becomes `X -> a 999_X_factor_1` and `999_X_factor_1 -> X | Epsilon`.
+
+The artificial non-terminals emit no events, so the events of the rule are the same. Only the alternative it matched is known later: when a rule of the grammar file is factored, each of its productions is ended with `utils.Alternative_mark`, which the parser uses to give the rule its alternative.
+
+The non-terminals are factored by name and the new ones are named after the one they come from, so the same grammar is always factored the same way.
+*/
+func Left_factor(bnf_grammar map[string]([][]utils.Grammar_element)) int {
+	factored := 0
+	counts := map[string]int{} // How many artificial non-terminals each non-terminal was factored into
+	pending := utils.Sorted_keys(bnf_grammar)
+	for len(pending) > 0 {
+		non_term := pending[0]
+		pending = pending[1:]
+
+		for {
+			group := common_prefix_group(bnf_grammar[non_term])
+			if group == nil {
+				break
+			}
+			if !strings.HasPrefix(non_term, ebnf_to_bnf.Artificial_non_term_prefix) && !is_marked(bnf_grammar[non_term]) {
+				mark_alternatives(bnf_grammar[non_term])
+			}
+
+			productions := bnf_grammar[non_term]
+			prefix := common_prefix(productions, group)
+			new_non_term := factor_name(bnf_grammar, non_term, counts)
+
+			suffixes := [][]utils.Grammar_element{}
+			for _, i := range group {
+				suffix := append([]utils.Grammar_element{}, productions[i][len(prefix):]...)
+				if len(suffix) == 0 {
+					suffix = []utils.Grammar_element{{IsNonTerminal: false, Terminal_type: utils.Epsilon}}
+				}
+				suffixes = append(suffixes, suffix)
+			}
+			bnf_grammar[new_non_term] = suffixes
+
+			// The first production of the group is replaced by the prefix, the others are removed
+			output := [][]utils.Grammar_element{}
+			for i, production := range productions {
+				switch {
+				case i == group[0]:
+					output = append(output, append(append([]utils.Grammar_element{}, prefix...), utils.Grammar_element{IsNonTerminal: true, Non_term_name: new_non_term}))
+				case !utils.Contains(group, i):
+					output = append(output, production)
+				}
+			}
+			bnf_grammar[non_term] = output
+			pending = append(pending, new_non_term)
+			factored++
+		}
+	}
+	return factored
+}
+
+// common_prefix_group gives the indexes of the productions which start with the same element as the first production which shares its start with another, nil if there is none
+func common_prefix_group(productions [][]utils.Grammar_element) []int {
+	for i := range productions {
+		if !can_be_factored(productions[i]) {
+			continue
+		}
+		group := []int{i}
+		for j := i + 1; j < len(productions); j++ {
+			if can_be_factored(productions[j]) && productions[j][0] == productions[i][0] {
+				group = append(group, j)
+			}
+		}
+		if len(group) > 1 {
+			return group
+		}
+	}
+	return nil
+}
+
+// can_be_factored tells if the production starts with something to match. The `Epsilon` elements match nothing, but the tails of a left-recursive rule which start with the same `utils.Reassociation_mark` can be factored as well.
+func can_be_factored(production []utils.Grammar_element) bool {
+	return len(production) > 0 && !(!production[0].IsNonTerminal && production[0].Terminal_type == utils.Epsilon && !production[0].Reassociates)
+}
+
+// common_prefix gives the elements which all the productions of the group start with
+func common_prefix(productions [][]utils.Grammar_element, group []int) []utils.Grammar_element {
+	first := productions[group[0]]
+	length := len(first)
+	for _, i := range group[1:] {
+		length = min(length, len(productions[i]))
+		for k := 0; k < length; k++ {
+			if productions[i][k] != first[k] || !can_be_factored(first[k:]) {
+				length = k
+				break
+			}
+		}
+	}
+	return first[:length]
+}
+
+// is_marked tells if the productions already have the marks of their alternatives, which the left-recursive rules get from the converter
+func is_marked(productions [][]utils.Grammar_element) bool {
+	for _, production := range productions {
+		for _, el := range production {
+			if el.Marks_alternative {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mark_alternatives ends every production of a rule of the grammar file with the mark of its alternative, before it is factored
+func mark_alternatives(productions [][]utils.Grammar_element) {
+	for i := range productions {
+		production := productions[i]
+		productions[i] = append(production[:len(production):len(production)], utils.Alternative_mark(i)) // Not in place, the production could share its array with another
+	}
+}
+
+// factor_name names the next artificial non-terminal which `non_term` is factored into
+func factor_name(bnf_grammar map[string]([][]utils.Grammar_element), non_term string, counts map[string]int) string {
+	for {
+		counts[non_term]++
+		name := ebnf_to_bnf.Artificial_non_term_prefix + strings.TrimPrefix(non_term, ebnf_to_bnf.Artificial_non_term_prefix) + "_factor_" + strconv.Itoa(counts[non_term])
+		if _, taken := bnf_grammar[name]; !taken {
+			return name
+		}
+	}
+}
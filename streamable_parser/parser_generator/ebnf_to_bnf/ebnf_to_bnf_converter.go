@@ -3,47 +3,61 @@ package ebnf_to_bnf
 import (
 	"strconv"
 
-	gfp "github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/grammar_file_parser"
+	"github.com/VirajAgarwal1/lox/grammar_file"
 	utils "github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/utils"
 )
 
 /*
 # NOTEs
 
-1. Artificial non-terminals will always start with '999_'. Example, '999_1'. It starts with a number because non-terminals in grammar definition cannot start with numbers
+1. Artificial non-terminals will always start with '999_'. It starts with a number because non-terminals in grammar definition cannot start with numbers
+2. The rest of their name is the rule they come from, what they replace and their position in the rule, counted from 1 in the order they are met. Example, '999_term_star_1' for the `*` of `term -> factor ( "*" factor )*`. The names do not depend on the other rules, so that generating the same grammar twice gives the same parser
 */
 
 const Artificial_non_term_prefix string = "999_"
 
-var artificial_non_terminal_counter int = 0
-var bnf_grammar = map[string]([][]utils.Grammar_element){}
+// Converter holds what a conversion needs while it goes through the rules. Each conversion starts from scratch, but a Converter must not be used by two goroutines at once.
+type Converter struct {
+	bnf_grammar                     map[string]([][]utils.Grammar_element)
+	declared_tokens                 []string // Declared with `%token` in the grammar file
+	current_rule                    string   // The rule whose artificial non-terminals are being made
+	artificial_non_terminal_counter int      // How many artificial non-terminals `current_rule` has
+	report                          Report
+}
+
+// new_artificial_non_term names the next artificial non-terminal of `current_rule`, `kind` being what it replaces
+func (converter *Converter) new_artificial_non_term(kind string) string {
+	converter.artificial_non_terminal_counter++
+	converter.report.Artificial++
+	return Artificial_non_term_prefix + converter.current_rule + "_" + kind + "_" + strconv.Itoa(converter.artificial_non_terminal_counter)
+}
 
-func process_term(term gfp.Generic_grammar_term) utils.Grammar_element {
+func (converter *Converter) process_term(term grammar_file.Generic_grammar_term) utils.Grammar_element {
 
 	switch term.Get_grammar_term_type() {
 	case "terminal":
-		terminal_term := term.(*gfp.Terminal)
+		terminal_term := term.(*grammar_file.Terminal)
+		terminal_type, _ := utils.Resolve_token(string(terminal_term.Content), converter.declared_tokens)
 		return utils.Grammar_element{
 			IsNonTerminal: false,
-			Terminal_type: utils.String_to_token[string(terminal_term.Content)],
+			Terminal_type: terminal_type,
 		}
 
 	case "non_terminal":
-		non_terminal_term := term.(*gfp.Non_terminal)
+		non_terminal_term := term.(*grammar_file.Non_terminal)
 		return utils.Grammar_element{
 			IsNonTerminal: true,
 			Non_term_name: non_terminal_term.Name,
 		}
 
 	case "star":
-		star_term := term.(*gfp.Star)
-		new_artificial_non_term_name := Artificial_non_term_prefix + strconv.Itoa(artificial_non_terminal_counter)
-		artificial_non_terminal_counter++
+		star_term := term.(*grammar_file.Star)
+		new_artificial_non_term_name := converter.new_artificial_non_term("star")
 
 		// Get the production for the new artifical non-terminal
-		bnf_grammar[new_artificial_non_term_name] = [][]utils.Grammar_element{
+		converter.bnf_grammar[new_artificial_non_term_name] = [][]utils.Grammar_element{
 			{
-				process_term(star_term.Content), {IsNonTerminal: true, Non_term_name: new_artificial_non_term_name},
+				converter.process_term(star_term.Content), {IsNonTerminal: true, Non_term_name: new_artificial_non_term_name},
 			},
 			{
 				{IsNonTerminal: false, Terminal_type: utils.Epsilon},
@@ -56,17 +70,16 @@ func process_term(term gfp.Generic_grammar_term) utils.Grammar_element {
 		}
 
 	case "plus":
-		plus_term := term.(*gfp.Plus)
-		new_artificial_non_term_name := Artificial_non_term_prefix + strconv.Itoa(artificial_non_terminal_counter)
-		artificial_non_terminal_counter++
+		plus_term := term.(*grammar_file.Plus)
+		new_artificial_non_term_name := converter.new_artificial_non_term("plus")
 
 		// Get the production for the new artifical non-terminal
-		bnf_grammar[new_artificial_non_term_name] = [][]utils.Grammar_element{
+		converter.bnf_grammar[new_artificial_non_term_name] = [][]utils.Grammar_element{
 			{
-				process_term(plus_term.Content), {IsNonTerminal: true, Non_term_name: new_artificial_non_term_name},
+				converter.process_term(plus_term.Content), {IsNonTerminal: true, Non_term_name: new_artificial_non_term_name},
 			},
 			{
-				process_term(plus_term.Content),
+				converter.process_term(plus_term.Content),
 			},
 		}
 
@@ -75,47 +88,72 @@ func process_term(term gfp.Generic_grammar_term) utils.Grammar_element {
 			Non_term_name: new_artificial_non_term_name,
 		}
 
+	case "optional":
+		optional_term := term.(*grammar_file.Optional)
+		new_artificial_non_term_name := converter.new_artificial_non_term("opt")
+
+		// Get the production for the new artifical non-terminal, which is the content or nothing
+		var choices [][]utils.Grammar_element
+		if optional_term.Content.Get_grammar_term_type() == "bracket" {
+			// `[ a or b ]` becomes `a | b | Epsilon` instead of going through another artificial non-terminal
+			choices = converter.process_sequence(optional_term.Content.(*grammar_file.Bracket).Contents)
+		} else {
+			choices = [][]utils.Grammar_element{{converter.process_term(optional_term.Content)}}
+		}
+		converter.bnf_grammar[new_artificial_non_term_name] = append(choices, []utils.Grammar_element{
+			{IsNonTerminal: false, Terminal_type: utils.Epsilon},
+		})
+
+		return utils.Grammar_element{
+			IsNonTerminal: true,
+			Non_term_name: new_artificial_non_term_name,
+		}
+
 	case "bracket":
-		bracket_term := term.(*gfp.Bracket)
-		new_artificial_non_term_name := Artificial_non_term_prefix + strconv.Itoa(artificial_non_terminal_counter)
-		artificial_non_terminal_counter++
+		bracket_term := term.(*grammar_file.Bracket)
+		new_artificial_non_term_name := converter.new_artificial_non_term("group")
 
 		// Get the production for the new artifical non-terminal
-		bnf_grammar[new_artificial_non_term_name] = process_sequence(bracket_term.Contents)
+		converter.bnf_grammar[new_artificial_non_term_name] = converter.process_sequence(bracket_term.Contents)
 
 		return utils.Grammar_element{
 			IsNonTerminal: true,
 			Non_term_name: new_artificial_non_term_name,
 		}
+
+	case "labelled":
+		labelled_term := term.(*grammar_file.Labelled)
+		element := converter.process_term(labelled_term.Content)
+		element.Label = labelled_term.Label
+		return element
 	}
 
 	return utils.Grammar_element{}
 }
-func process_or(choices [][]gfp.Generic_grammar_term) [][]utils.Grammar_element {
+func (converter *Converter) process_or(choices [][]grammar_file.Generic_grammar_term) [][]utils.Grammar_element {
 	output := make([][]utils.Grammar_element, 0, len(choices))
 	for _, path := range choices {
 		if len(path) < 1 {
 			continue
 		}
 		if len(path) == 1 {
-			output = append(output, []utils.Grammar_element{process_term(path[0])})
+			output = append(output, []utils.Grammar_element{converter.process_term(path[0])})
 		} else {
-			new_artificial_non_term_name := Artificial_non_term_prefix + strconv.Itoa(artificial_non_terminal_counter)
-			artificial_non_terminal_counter++
+			new_artificial_non_term_name := converter.new_artificial_non_term("alt")
 			output = append(output, []utils.Grammar_element{{
 				IsNonTerminal: true,
 				Non_term_name: new_artificial_non_term_name,
 			}})
-			bnf_grammar[new_artificial_non_term_name] = process_sequence(path)
+			converter.bnf_grammar[new_artificial_non_term_name] = converter.process_sequence(path)
 		}
 	}
 	return output
 }
-func process_sequence(sequence []gfp.Generic_grammar_term) [][]utils.Grammar_element {
+func (converter *Converter) process_sequence(sequence []grammar_file.Generic_grammar_term) [][]utils.Grammar_element {
 
-	or_positions := utils.Detect_or_in_sequence(sequence)
+	or_positions := grammar_file.Detect_or_in_sequence(sequence)
 	if len(or_positions) != 0 {
-		choices := [][]gfp.Generic_grammar_term{}
+		choices := [][]grammar_file.Generic_grammar_term{}
 		for i, pos := range or_positions {
 			start := uint32(0)
 			end := pos
@@ -125,26 +163,48 @@ func process_sequence(sequence []gfp.Generic_grammar_term) [][]utils.Grammar_ele
 			choices = append(choices, sequence[start:end])
 		}
 		choices = append(choices, sequence[or_positions[len(or_positions)-1]+1:])
-		output := process_or(choices)
+		output := converter.process_or(choices)
 		return output
 	}
 
 	output := [][]utils.Grammar_element{{}}
 	for _, term := range sequence {
-		output[0] = append(output[0], process_term(term))
+		output[0] = append(output[0], converter.process_term(term))
 	}
 
 	return output
 }
 
-func EbnfToBnfConverter(ebnf_grammar map[gfp.Non_terminal]([]gfp.Generic_grammar_term)) map[string]([][]utils.Grammar_element) {
+func EbnfToBnfConverter(ebnf_grammar map[grammar_file.Non_terminal]([]grammar_file.Generic_grammar_term)) map[string]([][]utils.Grammar_element) {
+	return ConvertGrammar(&grammar_file.Grammar{Rules: ebnf_grammar})
+}
+
+// ConvertGrammar is `EbnfToBnfConverter` for a whole grammar file, so that the terminals declared with `%token` are known
+func ConvertGrammar(grammar *grammar_file.Grammar) map[string]([][]utils.Grammar_element) {
+	converter := Converter{}
+	return converter.Convert(grammar)
+}
+
+// Convert is `ConvertGrammar` with the state of the conversion kept in the Converter
+func (converter *Converter) Convert(grammar *grammar_file.Grammar) map[string]([][]utils.Grammar_element) {
 	// The first slice is for incorporating 'or' and the internal slices for the actual definition
 
-	bnf_grammar = map[string]([][]utils.Grammar_element){}
+	converter.bnf_grammar = map[string]([][]utils.Grammar_element){}
+	converter.declared_tokens = grammar.Tokens
+	converter.report = Report{}
 
-	for non_term, def := range ebnf_grammar {
-		bnf_grammar[non_term.Name] = process_sequence(def)
+	for non_term, def := range grammar.Rules {
+		converter.current_rule = non_term.Name
+		converter.artificial_non_terminal_counter = 0
+		if left_recursion := grammar.Left_recursion_of(non_term.Name); left_recursion != nil && grammar.Reassociates(non_term.Name) {
+			converter.bnf_grammar[non_term.Name] = converter.process_left_recursion(left_recursion)
+			continue
+		}
+		// The actions only matter to the parser writer, which finds them again from the index of the production
+		converter.bnf_grammar[non_term.Name] = converter.process_sequence(grammar_file.Without_actions(def))
 	}
+	converter.share()
+	converter.inline()
 
-	return bnf_grammar
+	return converter.bnf_grammar
 }
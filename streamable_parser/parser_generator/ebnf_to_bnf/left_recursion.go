@@ -0,0 +1,35 @@
+package ebnf_to_bnf
+
+import (
+	"github.com/VirajAgarwal1/lox/grammar_file"
+	utils "github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/utils"
+)
+
+/*
+process_left_recursion converts a left-recursive rule whose nodes are reassociated (`grammar_file.Grammar.Reassociates`) from the bases and tails `grammar_file.Eliminate_left_recursion` split it into, instead of from its rewritten production:
+
+	expr -> left:expr "+" term or term
+
+	expr -> term Alternative(1) 999_expr_tail_1
+	999_expr_tail_1 -> Reassociation("left") "+" term Alternative(0) 999_expr_tail_1 | Epsilon
+
+Every base ends with the mark of its alternative. Every tail starts with the mark which ends the node of the rule matched so far and starts a new one around it, and ends with the mark of its own alternative, which the new node gets.
+*/
+func (converter *Converter) process_left_recursion(left_recursion *grammar_file.Left_recursion) [][]utils.Grammar_element {
+	tail := converter.new_artificial_non_term("tail")
+	tail_element := utils.Grammar_element{IsNonTerminal: true, Non_term_name: tail}
+
+	productions := [][]utils.Grammar_element{}
+	for _, base := range left_recursion.Bases {
+		production := converter.process_sequence(base.Terms)[0]
+		productions = append(productions, append(production, utils.Alternative_mark(base.Alternative), tail_element))
+	}
+
+	tails := [][]utils.Grammar_element{}
+	for _, alternative := range left_recursion.Tails {
+		production := append([]utils.Grammar_element{utils.Reassociation_mark(alternative.Label)}, converter.process_sequence(alternative.Terms)[0]...)
+		tails = append(tails, append(production, utils.Alternative_mark(alternative.Alternative), tail_element))
+	}
+	converter.bnf_grammar[tail] = append(tails, []utils.Grammar_element{{IsNonTerminal: false, Terminal_type: utils.Epsilon}})
+	return productions
+}
@@ -0,0 +1,170 @@
+package ebnf_to_bnf
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/utils"
+)
+
+/*
+Every `*`, `+`, bracket and alternative of more than one element gives an artificial non-terminal, even when the same construct was already converted somewhere else. Once the whole grammar is converted:
+
+ 1. Artificial non-terminals with the same productions are shared: all but one are removed and their uses point to the one left. The one whose name comes first is kept, so the result does not depend on the order of the rules. A non-terminal using itself, like the one of a `*`, is the same as another which uses itself in the same places.
+ 2. Artificial non-terminals with a single production which are used only once, or whose production is a single element, are inlined: their use is replaced by their production. A label on the use goes on the elements which have none, as the parser would have given it to them.
+
+The artificial non-terminals emit no events, so neither changes what the parser emits.
+*/
+
+// Report tells how many artificial non-terminals the conversion made, and how many of them it got rid of
+type Report struct {
+	Artificial int // Made while converting the rules
+	Shared     int // Removed as the same as another
+	Inlined    int // Removed by putting their production where they were used
+}
+
+// Saved gives how many artificial non-terminals were removed
+func (report Report) Saved() int {
+	return report.Shared + report.Inlined
+}
+
+// Report gives what the last conversion of the Converter did with its artificial non-terminals
+func (converter *Converter) Report() Report {
+	return converter.report
+}
+
+func is_artificial(non_term string) bool {
+	return strings.HasPrefix(non_term, Artificial_non_term_prefix)
+}
+
+// artificial_names gives the artificial non-terminals of the grammar by name
+func (converter *Converter) artificial_names() []string {
+	names := []string{}
+	for _, non_term := range utils.Sorted_keys(converter.bnf_grammar) {
+		if is_artificial(non_term) {
+			names = append(names, non_term)
+		}
+	}
+	return names
+}
+
+// productions_key describes the productions of a non-terminal without its name, so that two non-terminals have the same key if they have the same productions
+func (converter *Converter) productions_key(non_term string) string {
+	productions := []string{}
+	for _, production := range converter.bnf_grammar[non_term] {
+		elements := []string{}
+		for _, el := range production {
+			element := "t:" + string(el.Terminal_type)
+			switch {
+			case el.Marks_alternative:
+				element += ":" + strconv.Itoa(el.Alternative)
+			case el.Reassociates:
+				element += ":reassociates"
+			}
+			if el.IsNonTerminal {
+				element = "n:" + el.Non_term_name
+				if el.Non_term_name == non_term {
+					element = "self"
+				}
+			}
+			elements = append(elements, element+"#"+el.Label)
+		}
+		productions = append(productions, strings.Join(elements, " "))
+	}
+	return strings.Join(productions, " | ")
+}
+
+// rename makes every use of `from` a use of `to`
+func (converter *Converter) rename(from string, to string) {
+	for _, productions := range converter.bnf_grammar {
+		for _, production := range productions {
+			for i := range production {
+				if production[i].IsNonTerminal && production[i].Non_term_name == from {
+					production[i].Non_term_name = to
+				}
+			}
+		}
+	}
+}
+
+// share removes the artificial non-terminals which are the same as another, until none are. Sharing two of them can make those using them the same too.
+func (converter *Converter) share() {
+	for shared := true; shared; {
+		shared = false
+		kept := map[string]string{} // The non-terminal kept for each key
+		for _, non_term := range converter.artificial_names() {
+			key := converter.productions_key(non_term)
+			if same, found := kept[key]; found {
+				delete(converter.bnf_grammar, non_term)
+				converter.rename(non_term, same)
+				converter.report.Shared++
+				shared = true
+				continue
+			}
+			kept[key] = non_term
+		}
+	}
+}
+
+// uses counts how many times each non-terminal is used in the productions
+func (converter *Converter) uses() map[string]int {
+	count := map[string]int{}
+	for _, productions := range converter.bnf_grammar {
+		for _, production := range productions {
+			for _, el := range production {
+				if el.IsNonTerminal {
+					count[el.Non_term_name]++
+				}
+			}
+		}
+	}
+	return count
+}
+
+// inline puts the productions of the trivial artificial non-terminals where they are used. The uses are counted again after every inlining, as putting a production in several places uses what it uses there too.
+func (converter *Converter) inline() {
+	for _, non_term := range converter.artificial_names() {
+		uses := converter.uses()
+		productions := converter.bnf_grammar[non_term]
+		if len(productions) != 1 || production_uses(productions[0], non_term) {
+			continue
+		}
+		if uses[non_term] != 1 && len(productions[0]) != 1 {
+			continue
+		}
+		delete(converter.bnf_grammar, non_term)
+		for name, user_productions := range converter.bnf_grammar {
+			for i, production := range user_productions {
+				converter.bnf_grammar[name][i] = inline_in(production, non_term, productions[0])
+			}
+		}
+		converter.report.Inlined++
+	}
+}
+
+func production_uses(production []utils.Grammar_element, non_term string) bool {
+	for _, el := range production {
+		if el.IsNonTerminal && el.Non_term_name == non_term {
+			return true
+		}
+	}
+	return false
+}
+
+// inline_in replaces the uses of `non_term` in the production by `replacement`
+func inline_in(production []utils.Grammar_element, non_term string, replacement []utils.Grammar_element) []utils.Grammar_element {
+	output := make([]utils.Grammar_element, 0, len(production))
+	for _, el := range production {
+		if !el.IsNonTerminal || el.Non_term_name != non_term {
+			output = append(output, el)
+			continue
+		}
+		for _, inlined := range replacement {
+			if inlined.Label == "" {
+				inlined.Label = el.Label
+			}
+			output = append(output, inlined)
+		}
+	}
+	return output
+}
@@ -7,73 +7,138 @@ import (
 	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/utils"
 )
 
+// FirstSetInfo is the FIRST set of a non-terminal and of each of its productions. A set has `Epsilon` when what it is for can match nothing.
 type FirstSetInfo struct {
 	FirstForNonTerminal []dfa.TokenType
 	FirstForDefinitions [][]dfa.TokenType
 }
 
-var firstSets = map[string]FirstSetInfo{}
+/*
+compute_first finds NULLABLE and FIRST together, as each needs the other:
 
-func ComputeFirstForSequence(definition []utils.Grammar_element) []dfa.TokenType {
-
-	final_first_set := []dfa.TokenType{}
-
-	for i, elem := range definition {
-
-		temp_first_set := FirstSetInfo{}
-		if elem.IsNonTerminal {
-			temp_first_set = ComputeFirstForNonTerminal(elem.Non_term_name)
-		} else {
-			temp_first_set = FirstSetInfo{[]dfa.TokenType{elem.Terminal_type}, nil}
+  - a production is nullable if all its elements are, and a non-terminal if one of its productions is
+  - the FIRST set of a production is the FIRST sets of its elements up to the first one which is not nullable, and the FIRST set of a non-terminal the ones of its productions
+*/
+func (sets *Sets) compute_first() {
+	if sets.first_sets != nil {
+		return
+	}
+	non_terms := utils.Sorted_keys(sets.bnf_grammar)
+	sets.nullable = map[string]bool{}
+	firsts := map[string][]dfa.TokenType{} // Without `Epsilon`, which `nullable` stands for
+	for changed := true; changed; {
+		changed = false
+		for _, non_term := range non_terms {
+			for _, def := range sets.bnf_grammar[non_term] {
+				first, nullable := sets.sequence_first(def, firsts)
+				var grew bool
+				firsts[non_term], grew = union_sets_wo_epsilon(firsts[non_term], first)
+				if nullable && !sets.nullable[non_term] {
+					sets.nullable[non_term] = true
+					grew = true
+				}
+				changed = changed || grew
+			}
 		}
+	}
 
-		if utils.Contains(temp_first_set.FirstForNonTerminal, utils.Epsilon) {
-			final_first_set = union_sets_wo_epsilon(final_first_set, temp_first_set.FirstForNonTerminal)
-			if i == len(definition)-1 {
-				final_first_set = append(final_first_set, utils.Epsilon)
+	sets.first_sets = map[string]FirstSetInfo{}
+	for _, non_term := range non_terms {
+		sets.first_sets[non_term] = sets.first_of_alternatives(sets.bnf_grammar[non_term], firsts)
+	}
+}
+
+// sequence_first gives the FIRST set of a sequence of elements without `Epsilon`, and whether the sequence is nullable, from the sets found so far
+func (sets *Sets) sequence_first(definition []utils.Grammar_element, firsts map[string][]dfa.TokenType) ([]dfa.TokenType, bool) {
+	first_set := []dfa.TokenType{}
+	for _, elem := range definition {
+		switch {
+		case elem.IsNonTerminal:
+			if _, found := sets.bnf_grammar[elem.Non_term_name]; !found {
+				panic(fmt.Sprintf("Trying to access a non-terminal %s which doesnt exist in the BNF grammar", elem.Non_term_name))
 			}
-			continue
+			first_set, _ = union_sets_wo_epsilon(first_set, firsts[elem.Non_term_name])
+			if !sets.nullable[elem.Non_term_name] {
+				return first_set, false
+			}
+		case elem.Terminal_type != utils.Epsilon:
+			first_set, _ = union_sets_wo_epsilon(first_set, []dfa.TokenType{elem.Terminal_type})
+			return first_set, false
 		}
-		final_first_set = union_sets(final_first_set, temp_first_set.FirstForNonTerminal)
-		break
 	}
-
-	return final_first_set
+	return first_set, true
 }
-func ComputeFirstForAlternatives(definitions [][]utils.Grammar_element) FirstSetInfo {
 
-	final_first_set := []dfa.TokenType{}
-	first_sets := [][]dfa.TokenType{}
+func (sets *Sets) first_of_alternatives(definitions [][]utils.Grammar_element, firsts map[string][]dfa.TokenType) FirstSetInfo {
+	info := FirstSetInfo{FirstForNonTerminal: []dfa.TokenType{}, FirstForDefinitions: [][]dfa.TokenType{}}
+	nullable := false
 	for _, def := range definitions {
-		temp_first_set := ComputeFirstForSequence(def)
-		final_first_set = union_sets(final_first_set, temp_first_set)
-		first_sets = append(first_sets, temp_first_set)
+		first_set, def_nullable := sets.sequence_first(def, firsts)
+		info.FirstForNonTerminal, _ = union_sets_wo_epsilon(info.FirstForNonTerminal, first_set)
+		if def_nullable {
+			first_set = append(first_set, utils.Epsilon)
+			nullable = true
+		}
+		info.FirstForDefinitions = append(info.FirstForDefinitions, first_set)
 	}
-	return FirstSetInfo{FirstForNonTerminal: final_first_set, FirstForDefinitions: first_sets}
+	if nullable {
+		info.FirstForNonTerminal = append(info.FirstForNonTerminal, utils.Epsilon)
+	}
+	return info
 }
-func ComputeFirstForNonTerminal(non_term string) FirstSetInfo {
 
-	first_set, processed_already := firstSets[non_term]
-	if processed_already { // Caching
-		return first_set
+// ComputeFirstForSequence gives the FIRST set of a sequence of elements of the grammar, with `Epsilon` if it can match nothing
+func (sets *Sets) ComputeFirstForSequence(definition []utils.Grammar_element) []dfa.TokenType {
+	sets.compute_first()
+	first_set, nullable := sets.sequence_first(definition, sets.first_without_epsilon())
+	if nullable {
+		first_set = append(first_set, utils.Epsilon)
 	}
+	return first_set
+}
+
+// ComputeFirstForAlternatives gives the FIRST sets of productions of the grammar, as if they were the productions of a non-terminal
+func (sets *Sets) ComputeFirstForAlternatives(definitions [][]utils.Grammar_element) FirstSetInfo {
+	sets.compute_first()
+	return sets.first_of_alternatives(definitions, sets.first_without_epsilon())
+}
 
-	definitions, found := bnfGrammar[non_term]
+func (sets *Sets) first_without_epsilon() map[string][]dfa.TokenType {
+	firsts := map[string][]dfa.TokenType{}
+	for non_term, info := range sets.first_sets {
+		firsts[non_term], _ = union_sets_wo_epsilon(nil, info.FirstForNonTerminal)
+	}
+	return firsts
+}
+
+func (sets *Sets) ComputeFirstForNonTerminal(non_term string) FirstSetInfo {
+	sets.compute_first()
+	first_set, found := sets.first_sets[non_term]
 	if !found {
 		panic(fmt.Sprintf("Trying to access a non-terminal %s which doesnt exist in the BNF grammar", non_term))
 	}
-	firstSets[non_term] = ComputeFirstForAlternatives(definitions)
-
-	return firstSets[non_term]
+	return first_set
 }
 
+// ComputeFirstSets gives the FIRST sets of every non-terminal of the grammar
 func ComputeFirstSets(bnf_grammar map[string]([][]utils.Grammar_element)) map[string]FirstSetInfo {
+	sets := Sets{}
+	sets.Initialize(bnf_grammar, "")
+	return sets.ComputeFirstSets()
+}
 
-	bnfGrammar = bnf_grammar
+// ComputeFirstSets is the package's `ComputeFirstSets` for the grammar of the Sets
+func (sets *Sets) ComputeFirstSets() map[string]FirstSetInfo {
+	sets.compute_first()
+	return sets.first_sets
+}
 
-	for non_term := range bnf_grammar {
-		firstSets[non_term] = ComputeFirstForNonTerminal(non_term)
+// ComputeNullable gives the non-terminals of the grammar which can match nothing, all the others are false
+func (sets *Sets) ComputeNullable() map[string]bool {
+	sets.compute_first()
+	nullable := map[string]bool{}
+	for non_term := range sets.bnf_grammar {
+		nullable[non_term] = sets.nullable[non_term]
 	}
-
-	return firstSets
+	return nullable
 }
@@ -1,65 +1,75 @@
 package first_follow
 
 import (
+	"fmt"
+
 	"github.com/VirajAgarwal1/lox/lexer/dfa"
 	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/utils"
 )
 
-var followSets = map[string]([]dfa.TokenType){}
-
-func ComputeFollowFromSequence(non_term_on_lhs string, choices_idx int, non_term_idx int) []dfa.TokenType {
+/*
+compute_follow finds the FOLLOW sets, starting from `EOF` for the start symbol only. For every occurrence of a non-terminal B in a production of A:
 
-	follow_set := []dfa.TokenType{dfa.EOF}
-
-	if non_term_idx >= len(bnfGrammar[non_term_on_lhs][choices_idx])-1 {
-		if non_term_on_lhs == bnfGrammar[non_term_on_lhs][choices_idx][non_term_idx].Non_term_name {
-			return follow_set
-		}
-		follow_set = union_sets(follow_set, ComputeFollowForNonTerminal(non_term_on_lhs))
-		return follow_set
+  - the FIRST set of what comes after B in the production is added to FOLLOW(B)
+  - FOLLOW(A) is added to FOLLOW(B) when what comes after B is nullable
+*/
+func (sets *Sets) compute_follow() {
+	if sets.follow_sets != nil {
+		return
 	}
+	sets.compute_first()
+	firsts := sets.first_without_epsilon()
+	non_terms := utils.Sorted_keys(sets.bnf_grammar)
 
-	first_of_following_sequence := ComputeFirstForSequence(bnfGrammar[non_term_on_lhs][choices_idx][non_term_idx+1:])
-	follow_set = union_sets_wo_epsilon(follow_set, first_of_following_sequence)
-
-	if utils.Contains(first_of_following_sequence, utils.Epsilon) {
-		if non_term_on_lhs == bnfGrammar[non_term_on_lhs][choices_idx][non_term_idx].Non_term_name {
-			return follow_set
-		}
-		follow_set = union_sets(follow_set, ComputeFollowForNonTerminal(non_term_on_lhs))
+	follows := map[string]([]dfa.TokenType){}
+	for _, non_term := range non_terms {
+		follows[non_term] = []dfa.TokenType{}
 	}
-	return follow_set
-}
-
-func ComputeFollowForNonTerminal(non_term string) []dfa.TokenType {
-
-	follow_set, processed_already := followSets[non_term]
-	if processed_already {
-		return follow_set
+	if _, found := sets.bnf_grammar[sets.start]; found {
+		follows[sets.start] = []dfa.TokenType{dfa.EOF}
 	}
 
-	follow_set = []dfa.TokenType{dfa.EOF}
-	followSets[non_term] = follow_set // So that this non-term is not tried again in the recursion
-
-	for lhs_non_term, definitions := range bnfGrammar {
-		for or_idx, def := range definitions {
-			idx := production_contains_non_term(def, non_term)
-			if idx != -1 {
-				follow_set = union_sets_wo_epsilon(follow_set, ComputeFollowFromSequence(lhs_non_term, or_idx, idx))
+	for changed := true; changed; {
+		changed = false
+		for _, non_term := range non_terms {
+			for _, def := range sets.bnf_grammar[non_term] {
+				for i, elem := range def {
+					if !elem.IsNonTerminal {
+						continue
+					}
+					rest, nullable := sets.sequence_first(def[i+1:], firsts)
+					var grew bool
+					follows[elem.Non_term_name], grew = union_sets_wo_epsilon(follows[elem.Non_term_name], rest)
+					changed = changed || grew
+					if nullable {
+						follows[elem.Non_term_name], grew = union_sets_wo_epsilon(follows[elem.Non_term_name], follows[non_term])
+						changed = changed || grew
+					}
+				}
 			}
 		}
 	}
+	sets.follow_sets = follows
+}
 
-	followSets[non_term] = follow_set
+func (sets *Sets) ComputeFollowForNonTerminal(non_term string) []dfa.TokenType {
+	sets.compute_follow()
+	follow_set, found := sets.follow_sets[non_term]
+	if !found {
+		panic(fmt.Sprintf("Trying to access a non-terminal %s which doesnt exist in the BNF grammar", non_term))
+	}
 	return follow_set
 }
 
-func ComputeFollowSets(bnf_grammar map[string]([][]utils.Grammar_element)) map[string]([]dfa.TokenType) {
-
-	bnfGrammar = bnf_grammar
+// ComputeFollowSets gives the FOLLOW sets of every non-terminal of the grammar whose start symbol is `start`
+func ComputeFollowSets(bnf_grammar map[string]([][]utils.Grammar_element), start string) map[string]([]dfa.TokenType) {
+	sets := Sets{}
+	sets.Initialize(bnf_grammar, start)
+	return sets.ComputeFollowSets()
+}
 
-	for non_term := range bnf_grammar {
-		followSets[non_term] = ComputeFollowForNonTerminal(non_term)
-	}
-	return followSets
+// ComputeFollowSets is the package's `ComputeFollowSets` for the grammar of the Sets
+func (sets *Sets) ComputeFollowSets() map[string]([]dfa.TokenType) {
+	sets.compute_follow()
+	return sets.follow_sets
 }
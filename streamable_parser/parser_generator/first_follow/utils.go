@@ -5,33 +5,59 @@ import (
 	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/utils"
 )
 
-var bnfGrammar = map[string]([][]utils.Grammar_element){}
+/*
+Sets holds the FIRST, NULLABLE and FOLLOW sets of one BNF grammar, computed when they are first needed and then cached. Nothing is shared between two Sets, so that grammars can be worked on side by side, but a Sets must not be used by two goroutines at once.
 
-// A U (B - {E})
-func union_sets_wo_epsilon(A []dfa.TokenType, B []dfa.TokenType) []dfa.TokenType {
-	for _, tok := range B {
-		if tok != utils.Epsilon && !utils.Contains(A, tok) {
-			A = append(A, tok)
-		}
-	}
-	return A
+The sets are computed with the usual fixed-point algorithm over the whole grammar: every production adds what it can to the sets, and this is repeated till none of them changes. Each set only grows and is bounded by the tokens of the grammar, so this always ends, whatever the order of the rules or the cycles between them.
+*/
+type Sets struct {
+	bnf_grammar map[string]([][]utils.Grammar_element)
+	start       string
+	nullable    map[string]bool
+	first_sets  map[string]FirstSetInfo
+	follow_sets map[string]([]dfa.TokenType)
 }
 
-// A U B
-func union_sets(A []dfa.TokenType, B []dfa.TokenType) []dfa.TokenType {
-	for _, tok := range B {
-		if !utils.Contains(A, tok) {
-			A = append(A, tok)
-		}
+// Analysis is everything `Sets` computes for a grammar
+type Analysis struct {
+	First    map[string]FirstSetInfo
+	Nullable map[string]bool // The non-terminals which can match nothing
+	Follow   map[string]([]dfa.TokenType)
+}
+
+// Initialize makes the Sets those of `bnf_grammar`, whose start symbol is `start`, forgetting what was computed for another one
+func (sets *Sets) Initialize(bnf_grammar map[string]([][]utils.Grammar_element), start string) {
+	sets.bnf_grammar = bnf_grammar
+	sets.start = start
+	sets.nullable = nil
+	sets.first_sets = nil
+	sets.follow_sets = nil
+}
+
+// Compute gives the FIRST, NULLABLE and FOLLOW sets of every non-terminal of the grammar
+func Compute(bnf_grammar map[string]([][]utils.Grammar_element), start string) Analysis {
+	sets := Sets{}
+	sets.Initialize(bnf_grammar, start)
+	return sets.Compute()
+}
+
+// Compute is the package's `Compute` for the grammar of the Sets
+func (sets *Sets) Compute() Analysis {
+	return Analysis{
+		First:    sets.ComputeFirstSets(),
+		Nullable: sets.ComputeNullable(),
+		Follow:   sets.ComputeFollowSets(),
 	}
-	return A
 }
 
-func production_contains_non_term(production []utils.Grammar_element, non_term string) int {
-	for i, elem := range production {
-		if elem.IsNonTerminal && elem.Non_term_name == non_term {
-			return i
+// A U (B - {E}), and whether A grew
+func union_sets_wo_epsilon(A []dfa.TokenType, B []dfa.TokenType) ([]dfa.TokenType, bool) {
+	grew := false
+	for _, tok := range B {
+		if tok != utils.Epsilon && !utils.Contains(A, tok) {
+			A = append(A, tok)
+			grew = true
 		}
 	}
-	return -1
+	return A, grew
 }
@@ -0,0 +1,351 @@
+package generator
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/VirajAgarwal1/lox/errorhandler"
+	"github.com/VirajAgarwal1/lox/grammar_file"
+	"github.com/VirajAgarwal1/lox/lexer/dfa"
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/ebnf_to_bnf"
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/utils"
+)
+
+/*
+A grammar is LL(1) when the parser can always tell which production to expand a non-terminal with from the next token. When it cannot, the grammar has a conflict:
+
+  - FIRST/FIRST: two productions start with the token, like `a -> "NUMBER" "+" or b` with `b -> "NUMBER"`
+  - FIRST/FOLLOW: a production can match nothing and the token can come after the non-terminal, while another production starts with it, like the `else` of `stmt -> "if" cond stmt ( "else" stmt )?`
+
+The parse table keeps one of the productions (see `Prediction_table.Parse_table`), which makes some inputs parse another way than the grammar says, or not at all. `Generate` refuses a grammar with conflicts unless they are accepted in the grammar file with `%resolve rule TOKEN ...`, when keeping that production is what was meant, like for the `else` which goes with the closest `if`.
+*/
+
+// Conflict is a token on which more than one production of a non-terminal could be chosen
+type Conflict struct {
+	Non_term     string
+	Rules        []string // The rules of the grammar file the non-terminal was made for, itself if it is not artificial
+	Token        dfa.TokenType
+	Predictions  []Prediction    // Every production which could be chosen, the parse table keeps the first
+	Alternatives []string        // Those productions, as they would be written in a grammar file
+	Example      []dfa.TokenType // Input on which the parser has to choose, it ends with the token
+	Resolved     bool            // Accepted by a `%resolve` of one of its rules
+}
+
+// Kind is "FIRST/FOLLOW" when one of the productions is predicted because it can match nothing, "FIRST/FIRST" otherwise
+func (conflict Conflict) Kind() string {
+	for _, prediction := range conflict.Predictions {
+		if prediction.By_follow {
+			return "FIRST/FOLLOW"
+		}
+	}
+	return "FIRST/FIRST"
+}
+
+func (conflict Conflict) String() string {
+	return conflict.Kind() + " conflict in " + describe_non_term(conflict.Non_term, conflict.Rules) + " on '" + conflict.Token.Name() + "': " +
+		strings.Join(conflict.Alternatives, " or ") + ", as in `" + describe_tokens(conflict.Example) + "`"
+}
+
+// The EBNF constructs the artificial non-terminals are made for, by the kind in their name: how they are written in a production, and what they are of their rule
+var artificial_constructs = map[string]struct{ notation, of string }{
+	"star":  {"( ... )*", "the `*` of"},
+	"plus":  {"( ... )+", "the `+` of"},
+	"opt":   {"[ ... ]", "the `?` of"},
+	"group": {"( ... )", "a bracket of"},
+	"alt":   {"( ... )", "an alternative of"},
+	"tail":  {"( ... )*", "the left recursion of"},
+}
+
+// artificial_kind gives the kind of an artificial non-terminal, named `999_<rule>_<kind>_<n>`, empty for the ones made by left factoring a rule of the grammar file. `factored` tells if it is the rest of productions which were left factored, named with `_factor_<k>` after the non-terminal they came from.
+func artificial_kind(non_term string) (kind string, factored bool) {
+	parts := strings.Split(strings.TrimPrefix(non_term, ebnf_to_bnf.Artificial_non_term_prefix), "_")
+	numbered := func(name string) bool {
+		_, err := strconv.Atoi(parts[len(parts)-1])
+		return len(parts) > 2 && parts[len(parts)-2] == name && err == nil
+	}
+	for numbered("factor") {
+		parts = parts[:len(parts)-2]
+		factored = true
+	}
+	for kind := range artificial_constructs {
+		if numbered(kind) {
+			return kind, factored
+		}
+	}
+	return "", factored
+}
+
+// describe_non_term names a non-terminal as the grammar file wrote it: an artificial non-terminal is the EBNF construct of its rules it was made for, like "the `*` of 'args'"
+func describe_non_term(non_term string, rules []string) string {
+	if !strings.HasPrefix(non_term, ebnf_to_bnf.Artificial_non_term_prefix) || len(rules) == 0 {
+		return "'" + non_term + "'"
+	}
+	quoted := []string{}
+	for _, rule := range rules {
+		quoted = append(quoted, "'"+rule+"'")
+	}
+	description := strings.Join(quoted, ", ")
+	kind, factored := artificial_kind(non_term)
+	if kind != "" {
+		description = artificial_constructs[kind].of + " " + description
+	}
+	if factored {
+		description += ", after the start its alternatives have in common"
+	}
+	return description
+}
+
+// describe_alternative writes a production of a non-terminal, with the rule it belongs to when the non-terminal is artificial
+func describe_alternative(non_term string, rules []string, production []utils.Grammar_element) string {
+	if !strings.HasPrefix(non_term, ebnf_to_bnf.Artificial_non_term_prefix) {
+		return "`" + non_term + " -> " + describe_production(production) + "`"
+	}
+	return "`" + describe_production(production) + "` in " + describe_non_term(non_term, rules)
+}
+
+/*
+Find_conflicts gives every entry of the prediction table with more than one production, by non-terminal and then token. The non-terminals which cannot be reached from the start are left out, as no input gets to them.
+
+The example of a conflict is the shortest input which gets the parser to the non-terminal, followed by the token. It is found from the shortest input every non-terminal can match, and the shortest input which comes before every non-terminal.
+*/
+func Find_conflicts(bnf_grammar map[string]([][]utils.Grammar_element), table Prediction_table, start string) []Conflict {
+	rules := rules_of(bnf_grammar)
+	yields := shortest_yields(bnf_grammar)
+	prefixes := shortest_prefixes(bnf_grammar, start, yields)
+
+	conflicts := []Conflict{}
+	for _, non_term := range utils.Sorted_keys(table) {
+		prefix, reached := prefixes[non_term]
+		if !reached {
+			continue
+		}
+		row := table[non_term]
+		tokens := []string{}
+		for token, predictions := range row {
+			if len(predictions) > 1 {
+				tokens = append(tokens, string(token))
+			}
+		}
+		slices.Sort(tokens)
+		for _, token := range tokens {
+			conflict := Conflict{
+				Non_term:    non_term,
+				Rules:       rules[non_term],
+				Token:       dfa.TokenType(token),
+				Predictions: row[dfa.TokenType(token)],
+				Example:     append(slices.Clone(prefix), dfa.TokenType(token)),
+			}
+			for _, prediction := range conflict.Predictions {
+				conflict.Alternatives = append(conflict.Alternatives, describe_alternative(non_term, conflict.Rules, bnf_grammar[non_term][prediction.Production]))
+			}
+			conflicts = append(conflicts, conflict)
+		}
+	}
+	return conflicts
+}
+
+// resolve_conflicts marks the conflicts accepted by the `%resolve` directives of the grammar
+func resolve_conflicts(conflicts []Conflict, grammar *grammar_file.Grammar) error {
+	for _, resolution := range grammar.Resolutions {
+		if _, found := grammar.Rules[grammar_file.Non_terminal{Name: resolution.Rule}]; !found {
+			return errorhandler.RetErr("Unknown rule '"+resolution.Rule+"' in %resolve", nil)
+		}
+		for _, name := range resolution.Tokens {
+			token, found := utils.Resolve_token(name, grammar.Tokens)
+			if !found {
+				return errorhandler.RetErr("Unknown token '"+name+"' in %resolve", nil)
+			}
+			for i := range conflicts {
+				if conflicts[i].Token == token && slices.Contains(conflicts[i].Rules, resolution.Rule) {
+					conflicts[i].Resolved = true
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// rules_of gives the rules of the grammar file every non-terminal was made for: a rule is its own, and an artificial non-terminal belongs to the rules which use it, directly or through other artificial non-terminals. Shared artificial non-terminals belong to more than one rule.
+func rules_of(bnf_grammar map[string]([][]utils.Grammar_element)) map[string][]string {
+	rules := map[string][]string{}
+	for _, rule := range utils.Sorted_keys(bnf_grammar) {
+		if strings.HasPrefix(rule, ebnf_to_bnf.Artificial_non_term_prefix) {
+			continue
+		}
+		rules[rule] = append(rules[rule], rule)
+		pending := []string{rule}
+		for len(pending) > 0 {
+			non_term := pending[0]
+			pending = pending[1:]
+			for _, production := range bnf_grammar[non_term] {
+				for _, elem := range production {
+					name := elem.Non_term_name
+					if !elem.IsNonTerminal || !strings.HasPrefix(name, ebnf_to_bnf.Artificial_non_term_prefix) || slices.Contains(rules[name], rule) {
+						continue
+					}
+					rules[name] = append(rules[name], rule)
+					pending = append(pending, name)
+				}
+			}
+		}
+	}
+	return rules
+}
+
+// shortest_yields gives the shortest input every non-terminal can match, repeating till none of them gets shorter
+func shortest_yields(bnf_grammar map[string]([][]utils.Grammar_element)) map[string][]dfa.TokenType {
+	yields := map[string][]dfa.TokenType{}
+	for changed := true; changed; {
+		changed = false
+		for _, non_term := range utils.Sorted_keys(bnf_grammar) {
+			for _, production := range bnf_grammar[non_term] {
+				yield, found := sequence_yield(production, yields)
+				if known, done := yields[non_term]; found && (!done || len(yield) < len(known)) {
+					yields[non_term] = yield
+					changed = true
+				}
+			}
+		}
+	}
+	return yields
+}
+
+// sequence_yield gives the shortest input the elements can match, false if one of them cannot be matched yet
+func sequence_yield(elements []utils.Grammar_element, yields map[string][]dfa.TokenType) ([]dfa.TokenType, bool) {
+	yield := []dfa.TokenType{}
+	for _, elem := range elements {
+		switch {
+		case elem.IsNonTerminal:
+			matched, found := yields[elem.Non_term_name]
+			if !found {
+				return nil, false
+			}
+			yield = slices.Concat(yield, matched)
+		case elem.Terminal_type != utils.Epsilon:
+			yield = append(yield, elem.Terminal_type)
+		}
+	}
+	return yield, true
+}
+
+// shortest_prefixes gives the shortest input which comes before every non-terminal reached from the start, repeating till none of them gets shorter
+func shortest_prefixes(bnf_grammar map[string]([][]utils.Grammar_element), start string, yields map[string][]dfa.TokenType) map[string][]dfa.TokenType {
+	prefixes := map[string][]dfa.TokenType{start: {}}
+	for changed := true; changed; {
+		changed = false
+		for _, non_term := range utils.Sorted_keys(bnf_grammar) {
+			prefix, reached := prefixes[non_term]
+			if !reached {
+				continue
+			}
+			for _, production := range bnf_grammar[non_term] {
+				for i, elem := range production {
+					if !elem.IsNonTerminal {
+						continue
+					}
+					before, found := sequence_yield(production[:i], yields)
+					if !found {
+						break
+					}
+					before = slices.Concat(prefix, before)
+					if known, done := prefixes[elem.Non_term_name]; !done || len(before) < len(known) {
+						prefixes[elem.Non_term_name] = before
+						changed = true
+					}
+				}
+			}
+		}
+	}
+	return prefixes
+}
+
+// describe_production writes a production like in a grammar file, without its marks, and with the artificial non-terminals as the construct they were made for
+func describe_production(production []utils.Grammar_element) string {
+	parts := []string{}
+	for _, elem := range production {
+		switch {
+		case elem.IsNonTerminal && strings.HasPrefix(elem.Non_term_name, ebnf_to_bnf.Artificial_non_term_prefix):
+			notation := "( ... )"
+			if kind, _ := artificial_kind(elem.Non_term_name); kind != "" {
+				notation = artificial_constructs[kind].notation
+			}
+			parts = append(parts, notation)
+		case elem.IsNonTerminal:
+			parts = append(parts, elem.Non_term_name)
+		case elem.Terminal_type != utils.Epsilon:
+			parts = append(parts, strconv.Quote(elem.Terminal_type.Name()))
+		}
+	}
+	if len(parts) == 0 {
+		return "ε"
+	}
+	return strings.Join(parts, " ")
+}
+
+func describe_tokens(tokens []dfa.TokenType) string {
+	names := []string{}
+	for _, token := range tokens {
+		names = append(names, token.Name())
+	}
+	return strings.Join(names, " ")
+}
+
+// Diagnostic gives the G0012 error of the conflict, at the definition of its first rule
+func (conflict Conflict) Diagnostic(grammar *grammar_file.Grammar) *errorhandler.Diagnostic {
+	span := errorhandler.Span{}
+	if len(conflict.Rules) > 0 {
+		if spans := grammar.Definitions[grammar_file.Non_terminal{Name: conflict.Rules[0]}]; len(spans) > 0 {
+			span = spans[len(spans)-1]
+		}
+	}
+	token := conflict.Token.Name()
+	diag := errorhandler.NewDiagnostic(
+		errorhandler.CodeConflict,
+		conflict.Kind()+" conflict in "+describe_non_term(conflict.Non_term, conflict.Rules)+" on '"+token+"'",
+		span,
+		"more than one production can be chosen on '"+token+"'",
+	)
+	for i, alternative := range conflict.Alternatives {
+		if i == 0 {
+			diag.WithNote("the parser would choose " + alternative)
+		} else {
+			diag.WithNote("over " + alternative)
+		}
+	}
+	diag.WithNote("for example on `" + describe_tokens(conflict.Example) + "`")
+	if len(conflict.Rules) > 0 {
+		diag.WithHelp("rewrite the rule so that one token is enough to choose, or add `%resolve " + conflict.Rules[0] + " " + strconv.Quote(token) + "` if the first production is the one meant")
+	} else {
+		diag.WithHelp("rewrite the rule so that one token is enough to choose")
+	}
+	diag.Position = grammar.Position(span)
+	return diag
+}
+
+// Conflicts_error is what `Generate` returns for a grammar whose conflicts are not all resolved, with every one of them
+type Conflicts_error struct {
+	Conflicts []Conflict
+	Grammar   *grammar_file.Grammar
+}
+
+func (err *Conflicts_error) Error() string {
+	lines := []string{"the grammar is not LL(1), it has " + strconv.Itoa(len(err.Conflicts)) + " unresolved conflicts:"}
+	for _, conflict := range err.Conflicts {
+		lines = append(lines, "  - "+conflict.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (err *Conflicts_error) Is(target error) bool {
+	return target == errorhandler.ErrGrammar
+}
+
+// Diagnostics gives the diagnostic of every conflict, see `Conflict.Diagnostic`
+func (err *Conflicts_error) Diagnostics() []*errorhandler.Diagnostic {
+	diagnostics := []*errorhandler.Diagnostic{}
+	for _, conflict := range err.Conflicts {
+		diagnostics = append(diagnostics, conflict.Diagnostic(err.Grammar))
+	}
+	return diagnostics
+}
@@ -0,0 +1,115 @@
+package generator
+
+import (
+	"github.com/VirajAgarwal1/lox/errorhandler"
+	"github.com/VirajAgarwal1/lox/grammar_file"
+	"github.com/VirajAgarwal1/lox/lexer/dfa"
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/bnf_transforms"
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/ebnf_to_bnf"
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/first_follow"
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/grammar_validator"
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/utils"
+)
+
+/*
+The generator runs the whole pipeline of the streamable parser on a grammar file: validation, left recursion elimination, EBNF to BNF, left factoring, FIRST and FOLLOW sets, and the parse table, which must be free of conflicts. `parser_writer` writes the parser from its result.
+
+A Generator owns everything the pipeline keeps while it runs, nothing is kept in the packages themselves. Each grammar needs its own Generator to be generated at the same time as others, one Generator can generate several grammars one after the other.
+*/
+
+// Options change how a grammar is generated
+type Options struct {
+	Start       string // The starting non-terminal, instead of the one of `%start` when given
+	Reassociate bool   // Give back the nodes of the left-recursive rules as written, like `%reassociate`
+}
+
+// Result is everything generated for a grammar, which the parser is written from
+type Result struct {
+	Start       string
+	Skip        []dfa.TokenType // From `%skip`
+	Bnf         map[string]([][]utils.Grammar_element)
+	First       map[string]first_follow.FirstSetInfo
+	Nullable    map[string]bool
+	Follow      map[string]([]dfa.TokenType)
+	Table       Parse_table
+	Predictions Prediction_table      // Every production which could be chosen, `Table` keeps the first
+	Conflicts   []Conflict            // The conflicts of the table, all of them resolved with `%resolve`
+	Report      ebnf_to_bnf.Report    // How many artificial non-terminals the conversion shared or inlined
+	Factored    int                   // How many times productions were left factored
+	Grammar     *grammar_file.Grammar // The grammar it was generated from, with the options and its left recursion rewritten
+}
+
+type Generator struct {
+	converter ebnf_to_bnf.Converter
+	sets      first_follow.Sets
+}
+
+// Generate validates the grammar and computes its BNF, FIRST and FOLLOW sets and parse table. Nothing is generated if `grammar_validator` finds errors in the grammar, nor if the parse table has conflicts which the grammar does not resolve with `%resolve`: the error is then a `*Conflicts_error` with all of them.
+func (generator *Generator) Generate(grammar *grammar_file.Grammar, opts Options) (*Result, error) {
+	if opts.Start != "" || opts.Reassociate {
+		with_options := *grammar
+		if opts.Start != "" {
+			with_options.Start = opts.Start
+		}
+		with_options.Reassociate = grammar.Reassociate || opts.Reassociate
+		grammar = &with_options
+	}
+
+	if err := grammar_validator.Validate(grammar).Err(); err != nil {
+		return nil, errorhandler.RetErr("Invalid grammar", err)
+	}
+
+	skip_tokens := []dfa.TokenType{}
+	for _, name := range grammar.Skip {
+		token, found := utils.Resolve_token(name, grammar.Tokens)
+		if !found {
+			return nil, errorhandler.RetErr("Unknown token '"+name+"' in %skip", nil)
+		}
+		skip_tokens = append(skip_tokens, token)
+	}
+
+	grammar, err := grammar_file.Eliminate_left_recursion(grammar)
+	if err != nil {
+		return nil, errorhandler.RetErr("Left recursion", err)
+	}
+
+	bnf_grammar := generator.converter.Convert(grammar)
+	if _, found := bnf_grammar[grammar.Start]; !found {
+		return nil, errorhandler.RetErr("The start symbol '"+grammar.Start+"' has no production rule", nil)
+	}
+	factored := bnf_transforms.Left_factor(bnf_grammar)
+
+	generator.sets.Initialize(bnf_grammar, grammar.Start)
+	sets := generator.sets.Compute()
+	firsts, follows := sets.First, sets.Follow
+
+	predictions := Compute_prediction_table(bnf_grammar, firsts, follows)
+	conflicts := Find_conflicts(bnf_grammar, predictions, grammar.Start)
+	if err := resolve_conflicts(conflicts, grammar); err != nil {
+		return nil, errorhandler.RetErr("Invalid %resolve", err)
+	}
+	unresolved := []Conflict{}
+	for _, conflict := range conflicts {
+		if !conflict.Resolved {
+			unresolved = append(unresolved, conflict)
+		}
+	}
+	if len(unresolved) > 0 {
+		return nil, errorhandler.RetErr("Conflicts in the parse table", &Conflicts_error{Conflicts: unresolved, Grammar: grammar})
+	}
+
+	return &Result{
+		Start:       grammar.Start,
+		Skip:        skip_tokens,
+		Bnf:         bnf_grammar,
+		First:       firsts,
+		Nullable:    sets.Nullable,
+		Follow:      follows,
+		Table:       predictions.Parse_table(),
+		Predictions: predictions,
+		Conflicts:   conflicts,
+		Report:      generator.converter.Report(),
+		Factored:    factored,
+		Grammar:     grammar,
+	}, nil
+}
@@ -0,0 +1,77 @@
+package generator
+
+import (
+	"github.com/VirajAgarwal1/lox/lexer/dfa"
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/first_follow"
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/utils"
+)
+
+// Parse_table is the LL(1) parse table: for a non-terminal and the next token, the index of the production to expand it with. A token missing from the table is a syntax error.
+type Parse_table map[string]map[dfa.TokenType]int
+
+// Prediction_table is the full prediction table: for a non-terminal and the next token, every production which could be chosen. The grammar is LL(1) when no entry has more than one, see `Find_conflicts`.
+type Prediction_table map[string]map[dfa.TokenType][]Prediction
+
+// Prediction is a production which could be chosen on a token
+type Prediction struct {
+	Production int
+	By_follow  bool // Chosen because the production can match nothing and the token is in the FOLLOW set of its non-terminal, not in its FIRST set
+}
+
+/*
+Compute_prediction_table fills the table in from the FIRST and FOLLOW sets. A production is predicted on the tokens of its FIRST set, and, when it can match nothing, on the tokens of the FOLLOW set of its non-terminal.
+
+The predictions of a token are in the order in which the parse table prefers them: the productions which match the token first, then the ones which match nothing, each in the order of the productions.
+*/
+func Compute_prediction_table(bnf_grammar map[string]([][]utils.Grammar_element), firsts map[string]first_follow.FirstSetInfo, follows map[string]([]dfa.TokenType)) Prediction_table {
+	table := Prediction_table{}
+	for _, non_term := range utils.Sorted_keys(bnf_grammar) {
+		row := map[dfa.TokenType][]Prediction{}
+		predict := func(token dfa.TokenType, production int, by_follow bool) {
+			for _, prediction := range row[token] {
+				if prediction.Production == production {
+					return
+				}
+			}
+			row[token] = append(row[token], Prediction{Production: production, By_follow: by_follow})
+		}
+		for production, first_set := range firsts[non_term].FirstForDefinitions {
+			for _, token := range first_set {
+				if token != utils.Epsilon {
+					predict(token, production, false)
+				}
+			}
+		}
+		for production, first_set := range firsts[non_term].FirstForDefinitions {
+			if utils.Contains(first_set, utils.Epsilon) {
+				for _, token := range follows[non_term] {
+					predict(token, production, true)
+				}
+			}
+		}
+		table[non_term] = row
+	}
+	return table
+}
+
+/*
+Parse_table keeps the first prediction of every token, which is the production the parser expands the non-terminal with.
+
+When two productions could be chosen on the same token, the grammar is not LL(1) and `Generate` refuses it unless the conflict is resolved with `%resolve`. A production which matches the token is kept over one which matches nothing, so that `else` goes with the closest `if`, and otherwise the first of them, the one the parser tries first.
+*/
+func (table Prediction_table) Parse_table() Parse_table {
+	parse_table := Parse_table{}
+	for non_term, predictions := range table {
+		row := map[dfa.TokenType]int{}
+		for token, chosen := range predictions {
+			row[token] = chosen[0].Production
+		}
+		parse_table[non_term] = row
+	}
+	return parse_table
+}
+
+// Compute_parse_table fills the parse table in from the FIRST and FOLLOW sets, see `Compute_prediction_table`
+func Compute_parse_table(bnf_grammar map[string]([][]utils.Grammar_element), firsts map[string]first_follow.FirstSetInfo, follows map[string]([]dfa.TokenType)) Parse_table {
+	return Compute_prediction_table(bnf_grammar, firsts, follows).Parse_table()
+}
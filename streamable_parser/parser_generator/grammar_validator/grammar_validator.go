@@ -0,0 +1,331 @@
+package grammar_validator
+
+import (
+	"github.com/VirajAgarwal1/lox/errorhandler"
+	"github.com/VirajAgarwal1/lox/grammar_file"
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/utils"
+)
+
+/*
+The validator runs over a parsed grammar file before a parser is generated from it. Without it, mistakes in the grammar only show up much later: unknown terminals silently become an empty token type in `ebnf_to_bnf`, and undefined non-terminals panic inside `first_follow`.
+
+Errors (the grammar cannot give a working parser):
+
+  - G0002 a non-terminal is used (or given to `%start`) but has no production rule
+  - G0003 a terminal is neither a token of the lexer nor declared with `%token`
+  - G0004 a non-terminal is defined more than once, only the last definition would be used
+  - G0005 an alternative, a production or a bracket is empty
+  - G0006 a non-terminal is unproductive: none of its alternatives can ever be fully matched
+  - G0011 an action refers to a value its alternative does not have, like `$0` or a label written in another alternative
+
+Warnings (the grammar works, but has dead rules):
+
+  - G0007 a non-terminal is never used by any other rule and is not the start symbol
+  - G0008 a non-terminal is used, but only by rules which cannot be reached from the start symbol
+*/
+
+type validator struct {
+	grammar     *grammar_file.Grammar
+	diagnostics *errorhandler.Diagnostics
+}
+
+// Validate checks the grammar and returns what it found, sorted by position. Use `Err()` on the result to know if a parser can be generated.
+func Validate(grammar *grammar_file.Grammar) *errorhandler.Diagnostics {
+	v := validator{grammar: grammar, diagnostics: errorhandler.NewDiagnostics(0)}
+
+	v.check_duplicates()
+	for _, non_term := range grammar.Order {
+		v.check_terms(non_term, grammar.Rules[non_term])
+		v.check_actions(grammar.Rules[non_term])
+	}
+	v.check_start()
+	v.check_productive()
+	v.check_usage()
+
+	return v.diagnostics
+}
+
+func (v *validator) report(diag *errorhandler.Diagnostic) {
+	if primary := diag.PrimaryLabel(); primary != nil {
+		diag.Position = v.grammar.Position(primary.Span)
+	}
+	v.diagnostics.Add(diag)
+}
+
+// definition gives the span of the first production of a non-terminal
+func (v *validator) definition(non_term grammar_file.Non_terminal) errorhandler.Span {
+	if spans := v.grammar.Definitions[non_term]; len(spans) > 0 {
+		return spans[0]
+	}
+	return errorhandler.Span{}
+}
+
+func (v *validator) is_defined(name string) bool {
+	_, found := v.grammar.Rules[grammar_file.Non_terminal{Name: name}]
+	return found
+}
+
+func (v *validator) check_duplicates() {
+	for _, non_term := range v.grammar.Order {
+		spans := v.grammar.Definitions[non_term]
+		for _, span := range spans[min(1, len(spans)):] {
+			v.report(errorhandler.NewDiagnostic(
+				errorhandler.CodeDuplicateRule,
+				"non-terminal '"+non_term.Name+"' is defined more than once",
+				span,
+				"defined again here",
+			).WithLabel(spans[0], "first defined here").
+				WithNote("only the last definition would be used").
+				WithHelp("join the productions into one with `or`"))
+		}
+	}
+}
+
+// check_terms looks for undefined non-terminals, unknown terminals and empty alternatives in a production
+func (v *validator) check_terms(non_term grammar_file.Non_terminal, terms []grammar_file.Generic_grammar_term) {
+	if len(terms) == 0 {
+		v.report(errorhandler.NewDiagnostic(
+			errorhandler.CodeEmptyAlternative,
+			"the production of '"+non_term.Name+"' is empty",
+			v.definition(non_term),
+			"nothing after '->'",
+		).WithHelp("use `?` or `[ ... ]` to make a part of another production optional"))
+		return
+	}
+	v.check_sequence(non_term, terms)
+}
+
+func (v *validator) check_sequence(non_term grammar_file.Non_terminal, terms []grammar_file.Generic_grammar_term) {
+	previous_is_or := true // The start of the sequence behaves like an `or` before it
+	for _, term := range terms {
+		is_or := term.Get_grammar_term_type() == "or"
+		if is_or && previous_is_or {
+			v.report_empty_alternative(non_term, term)
+		}
+		previous_is_or = is_or
+		v.check_term(non_term, term)
+	}
+	if previous_is_or {
+		v.report_empty_alternative(non_term, terms[len(terms)-1])
+	}
+}
+
+// check_actions makes sure that the values used by the actions of a production are there
+func (v *validator) check_actions(terms []grammar_file.Generic_grammar_term) {
+	labels := grammar_file.Alternative_labels(terms)
+	for i, action := range grammar_file.Alternative_actions(terms) {
+		if action == nil {
+			continue
+		}
+		if _, err := grammar_file.Expand_action(action.Code, labels[i]); err != nil {
+			v.report(errorhandler.NewDiagnostic(
+				errorhandler.CodeInvalidAction,
+				err.Error(),
+				v.grammar.Locations[action],
+				"in this action",
+			).WithHelp("use `$1`, `$2`, ... or the labels written before the action"))
+		}
+	}
+}
+
+func (v *validator) report_empty_alternative(non_term grammar_file.Non_terminal, or_term grammar_file.Generic_grammar_term) {
+	v.report(errorhandler.NewDiagnostic(
+		errorhandler.CodeEmptyAlternative,
+		"empty alternative in the production of '"+non_term.Name+"'",
+		v.grammar.Locations[or_term],
+		"nothing on one side of this 'or'",
+	).WithHelp("use `?` or `[ ... ]` to make something optional"))
+}
+
+func (v *validator) check_term(non_term grammar_file.Non_terminal, term grammar_file.Generic_grammar_term) {
+	switch term.Get_grammar_term_type() {
+	case "terminal":
+		name := string(term.(*grammar_file.Terminal).Content)
+		if _, found := utils.Resolve_token(name, v.grammar.Tokens); !found {
+			v.report(errorhandler.NewDiagnostic(
+				errorhandler.CodeUnknownTerminal,
+				"unknown terminal \""+name+"\"",
+				v.grammar.Locations[term],
+				"not a token of the lexer",
+			).WithHelp("declare it with `%token " + name + "` if the tokens come from somewhere else"))
+		}
+	case "non_terminal":
+		name := term.(*grammar_file.Non_terminal).Name
+		if !v.is_defined(name) {
+			v.report(errorhandler.NewDiagnostic(
+				errorhandler.CodeUndefinedNonTerminal,
+				"non-terminal '"+name+"' has no production rule",
+				v.grammar.Locations[term],
+				"used here",
+			).WithHelp("add a rule `" + name + " -> ...` or fix the name"))
+		}
+	case "star":
+		v.check_term(non_term, term.(*grammar_file.Star).Content)
+	case "plus":
+		v.check_term(non_term, term.(*grammar_file.Plus).Content)
+	case "optional":
+		v.check_term(non_term, term.(*grammar_file.Optional).Content)
+	case "labelled":
+		v.check_term(non_term, term.(*grammar_file.Labelled).Content)
+	case "bracket":
+		contents := term.(*grammar_file.Bracket).Contents
+		if len(contents) == 0 {
+			v.report(errorhandler.NewDiagnostic(
+				errorhandler.CodeEmptyAlternative,
+				"empty brackets in the production of '"+non_term.Name+"'",
+				v.grammar.Locations[term],
+				"nothing inside",
+			))
+			return
+		}
+		v.check_sequence(non_term, contents)
+	}
+}
+
+func (v *validator) check_start() {
+	if v.grammar.Start == "" || v.is_defined(v.grammar.Start) {
+		return
+	}
+	v.report(errorhandler.NewDiagnostic(
+		errorhandler.CodeUndefinedNonTerminal,
+		"start symbol '"+v.grammar.Start+"' has no production rule",
+		v.grammar.Start_location,
+		"given to %start here",
+	))
+}
+
+// ---------------------------------------------------------------------------------
+// Productivity
+// ---------------------------------------------------------------------------------
+
+// check_productive finds the non-terminals which can never be fully matched, like `a -> "(" a ")"`. A non-terminal is productive if one of its alternatives only has terminals and productive non-terminals, this is repeated till nothing changes.
+func (v *validator) check_productive() {
+	productive := map[string]bool{}
+	for changed := true; changed; {
+		changed = false
+		for _, non_term := range v.grammar.Order {
+			if !productive[non_term.Name] && v.sequence_is_productive(v.grammar.Rules[non_term], productive) {
+				productive[non_term.Name] = true
+				changed = true
+			}
+		}
+	}
+
+	for _, non_term := range v.grammar.Order {
+		if productive[non_term.Name] || len(v.grammar.Rules[non_term]) == 0 {
+			continue // Empty productions were already reported
+		}
+		v.report(errorhandler.NewDiagnostic(
+			errorhandler.CodeUnproductiveRule,
+			"non-terminal '"+non_term.Name+"' can never be fully matched",
+			v.definition(non_term),
+			"every alternative of this rule needs a non-terminal which never finishes",
+		).WithNote("a parser would keep expecting more input forever").
+			WithHelp("give '" + non_term.Name + "' an alternative which does not lead back to itself"))
+	}
+}
+
+func (v *validator) sequence_is_productive(terms []grammar_file.Generic_grammar_term, productive map[string]bool) bool {
+	alternative_is_productive := true
+	for _, term := range terms {
+		if term.Get_grammar_term_type() == "or" {
+			if alternative_is_productive {
+				return true
+			}
+			alternative_is_productive = true
+			continue
+		}
+		alternative_is_productive = alternative_is_productive && v.term_is_productive(term, productive)
+	}
+	return alternative_is_productive
+}
+
+func (v *validator) term_is_productive(term grammar_file.Generic_grammar_term, productive map[string]bool) bool {
+	switch term.Get_grammar_term_type() {
+	case "non_terminal":
+		name := term.(*grammar_file.Non_terminal).Name
+		return productive[name] || !v.is_defined(name) // Undefined ones are reported on their own
+	case "plus":
+		return v.term_is_productive(term.(*grammar_file.Plus).Content, productive)
+	case "labelled":
+		return v.term_is_productive(term.(*grammar_file.Labelled).Content, productive)
+	case "bracket":
+		return v.sequence_is_productive(term.(*grammar_file.Bracket).Contents, productive)
+	}
+	return true // Terminals, and `*` and `?` which can match nothing
+}
+
+// ---------------------------------------------------------------------------------
+// Usage
+// ---------------------------------------------------------------------------------
+
+// check_usage warns about the rules which a parser starting from the start symbol would never use
+func (v *validator) check_usage() {
+	references := map[string][]string{} // Non-terminals used by each rule
+	used_by_others := map[string]bool{}
+	for _, non_term := range v.grammar.Order {
+		collect_non_terminals(v.grammar.Rules[non_term], func(name string) {
+			references[non_term.Name] = append(references[non_term.Name], name)
+			if name != non_term.Name {
+				used_by_others[name] = true
+			}
+		})
+	}
+
+	if !v.is_defined(v.grammar.Start) {
+		return // Without a start there is nothing to be reachable from
+	}
+	reachable := map[string]bool{v.grammar.Start: true}
+	queue := []string{v.grammar.Start}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, next := range references[name] {
+			if !reachable[next] {
+				reachable[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	for _, non_term := range v.grammar.Order {
+		if reachable[non_term.Name] {
+			continue
+		}
+		if !used_by_others[non_term.Name] {
+			v.report(errorhandler.NewWarning(
+				errorhandler.CodeUnusedRule,
+				"non-terminal '"+non_term.Name+"' is never used",
+				v.definition(non_term),
+				"defined here",
+			).WithHelp("remove the rule, or make it the start symbol with `%start " + non_term.Name + "`"))
+			continue
+		}
+		v.report(errorhandler.NewWarning(
+			errorhandler.CodeUnreachableRule,
+			"non-terminal '"+non_term.Name+"' cannot be reached from the start symbol '"+v.grammar.Start+"'",
+			v.definition(non_term),
+			"defined here",
+		).WithNote("it is only used by rules which cannot be reached either"))
+	}
+}
+
+// collect_non_terminals calls found with the name of every non-terminal in the terms, however deeply nested
+func collect_non_terminals(terms []grammar_file.Generic_grammar_term, found func(name string)) {
+	for _, term := range terms {
+		switch term.Get_grammar_term_type() {
+		case "non_terminal":
+			found(term.(*grammar_file.Non_terminal).Name)
+		case "star":
+			collect_non_terminals([]grammar_file.Generic_grammar_term{term.(*grammar_file.Star).Content}, found)
+		case "plus":
+			collect_non_terminals([]grammar_file.Generic_grammar_term{term.(*grammar_file.Plus).Content}, found)
+		case "optional":
+			collect_non_terminals([]grammar_file.Generic_grammar_term{term.(*grammar_file.Optional).Content}, found)
+		case "labelled":
+			collect_non_terminals([]grammar_file.Generic_grammar_term{term.(*grammar_file.Labelled).Content}, found)
+		case "bracket":
+			collect_non_terminals(term.(*grammar_file.Bracket).Contents, found)
+		}
+	}
+}
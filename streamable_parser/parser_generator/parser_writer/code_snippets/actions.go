@@ -0,0 +1,54 @@
+package code_snippets
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/VirajAgarwal1/lox/grammar_file"
+)
+
+// Lexer_import is the package of the tokens, which the actions are given
+const Lexer_import = "github.com/VirajAgarwal1/lox/lexer"
+
+// Package_and_Imports_code gives the header of the parser with the packages of `%import` added to it, which the actions use
+func Package_and_Imports_code(imports []string) string {
+	if len(imports) == 0 {
+		return Package_and_Imports
+	}
+	code := strings.TrimSuffix(Package_and_Imports, ")") + "\n"
+	seen := map[string]bool{}
+	for _, path := range imports {
+		if !seen[path] {
+			seen[path] = true
+			code += "\t" + strconv.Quote(path) + "\n"
+		}
+	}
+	return code + ")"
+}
+
+// SemanticActions_code gives the code which fills `SemanticActions` in with the actions of the grammar, by non-terminal and index of their alternative. It is empty when the grammar has no actions, otherwise the header needs `Lexer_import`.
+func SemanticActions_code(grammar *grammar_file.Grammar) (string, error) {
+	if !grammar.Has_actions() {
+		return "", nil
+	}
+	code := "var _ *lexer.Token // The actions are given tokens, whether they name their type or not\n\nfunc init() {\n\tSemanticActions = map[string]map[int]func(values ActionValues) any{\n"
+	for _, non_term := range grammar.Order {
+		terms := grammar.Written_rule(non_term) // The alternatives of a left-recursive rule are the ones it was written with
+		labels := grammar_file.Alternative_labels(terms)
+		actions := ""
+		for i, action := range grammar_file.Alternative_actions(terms) {
+			if action == nil {
+				continue
+			}
+			expanded, err := grammar_file.Expand_action(action.Code, labels[i])
+			if err != nil {
+				return "", err
+			}
+			actions += "\t\t\t" + strconv.Itoa(i) + ": func(values ActionValues) any {" + expanded + "},\n"
+		}
+		if actions != "" {
+			code += "\t\t\"" + non_term.Name + "\": {\n" + actions + "\t\t},\n"
+		}
+	}
+	return code + "\t}\n}", nil
+}
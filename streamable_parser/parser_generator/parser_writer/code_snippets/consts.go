@@ -1,5 +1,12 @@
 package code_snippets
 
+import "github.com/VirajAgarwal1/lox/lexer/dfa"
+
 func Consts_code(starting_non_terminal string) string {
 	return `const StartingNonTerminal string = "` + starting_non_terminal + `"`
 }
+
+// SkipTokens_code gives the set of tokens which the parser ignores, from `%skip`
+func SkipTokens_code(skip_tokens []dfa.TokenType) string {
+	return "var SkipTokens = " + code_tokens_set(skip_tokens)
+}
@@ -1,7 +1,12 @@
 package code_snippets
 
 import (
+	"sort"
+	"strconv"
+	"strings"
+
 	"github.com/VirajAgarwal1/lox/lexer/dfa"
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/ebnf_to_bnf"
 	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/first_follow"
 	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/utils"
 )
@@ -11,7 +16,7 @@ func code_tokens_set(tokens []dfa.TokenType) string {
 	middle := "\n"
 	for _, term := range tokens {
 		middle += utils.Indent_lines(
-			utils.String_to_type_string[string(term)]+": {},",
+			utils.Token_type_code(term)+": {},",
 			1,
 		)
 	}
@@ -25,10 +30,20 @@ func code_tokens_set(tokens []dfa.TokenType) string {
 }
 
 func code_a_Grammar_elem(el utils.Grammar_element) string {
+	label := ""
+	if el.Label != "" {
+		label = ", Label: \"" + el.Label + "\""
+	}
 	if el.IsNonTerminal {
-		return "{IsNonTerminal: true, Non_term_name: \"" + el.Non_term_name + "\"},"
+		return "{IsNonTerminal: true, Non_term_name: \"" + el.Non_term_name + "\"" + label + "},"
+	}
+	if el.Marks_alternative {
+		return "{IsNonTerminal: false, Terminal_type: " + utils.Token_type_code(el.Terminal_type) + ", Marks_alternative: true, Alternative: " + strconv.Itoa(el.Alternative) + "},"
+	}
+	if el.Reassociates {
+		return "{IsNonTerminal: false, Terminal_type: " + utils.Token_type_code(el.Terminal_type) + label + ", Reassociates: true},"
 	}
-	return "{IsNonTerminal: false, Terminal_type: " + utils.String_to_type_string[string(el.Terminal_type)] + "},"
+	return "{IsNonTerminal: false, Terminal_type: " + utils.Token_type_code(el.Terminal_type) + label + "},"
 }
 
 func code_Grammar_elements(elems []utils.Grammar_element) string {
@@ -38,9 +53,8 @@ func code_Grammar_elements(elems []utils.Grammar_element) string {
 		middle += utils.Indent_lines(
 			code_a_Grammar_elem(el),
 			1,
-		)
+		) + "\n"
 	}
-	middle += "\n"
 	end := "}"
 
 	if len(elems) < 1 {
@@ -73,9 +87,8 @@ func code_slice_of_GrammarSequence(first_sets [][]dfa.TokenType, definitions [][
 		middle += utils.Indent_lines(
 			code_GrammarSequence(first_sets[i], definitions[i])+",",
 			1,
-		)
+		) + "\n"
 	}
-	middle += "\n"
 	end := "}"
 
 	if len(first_sets) < 1 {
@@ -85,7 +98,7 @@ func code_slice_of_GrammarSequence(first_sets [][]dfa.TokenType, definitions [][
 }
 
 func code_non_terminal(non_term string, follow_set []dfa.TokenType, first_sets [][]dfa.TokenType, definitions [][]utils.Grammar_element) string {
-	if len(non_term) < 1 || len(follow_set) < 1 {
+	if len(non_term) < 1 {
 		return ""
 	}
 
@@ -106,6 +119,18 @@ func code_non_terminal(non_term string, follow_set []dfa.TokenType, first_sets [
 	return start + middle + end
 }
 
+// rules_order gives the non-terminals in the order they are written in: by name, with the artificial ones right after the rule they come from
+func rules_order(bnf_grammar map[string]([][]utils.Grammar_element)) []string {
+	order := utils.Sorted_keys(bnf_grammar)
+	sort_key := func(non_term string) string {
+		return strings.TrimPrefix(non_term, ebnf_to_bnf.Artificial_non_term_prefix)
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return sort_key(order[i]) < sort_key(order[j])
+	})
+	return order
+}
+
 func GrammarRules_code(bnf_grammar map[string]([][]utils.Grammar_element), firstSet map[string]first_follow.FirstSetInfo, followSet map[string]([]dfa.TokenType)) string {
 
 	if len(bnf_grammar) != len(firstSet) && len(firstSet) != len(followSet) {
@@ -114,13 +139,12 @@ func GrammarRules_code(bnf_grammar map[string]([][]utils.Grammar_element), first
 
 	start := "var grammarRules = map[string]ProductionRule{"
 	middle := "\n"
-	for non_term := range bnf_grammar {
+	for _, non_term := range rules_order(bnf_grammar) {
 		middle += utils.Indent_lines(
 			code_non_terminal(non_term, followSet[non_term], firstSet[non_term].FirstForDefinitions, bnf_grammar[non_term])+",",
 			1,
-		)
+		) + "\n"
 	}
-	middle += "\n"
 	end := "}"
 
 	if len(bnf_grammar) < 1 {
@@ -0,0 +1,43 @@
+package code_snippets
+
+import (
+	"strconv"
+
+	"github.com/VirajAgarwal1/lox/lexer/dfa"
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/utils"
+)
+
+func code_parse_table_row(non_term string, row map[dfa.TokenType]int) string {
+	if len(row) < 1 {
+		return "\"" + non_term + "\": {},"
+	}
+	middle := ""
+	for _, token := range utils.Ordered_tokens(row) {
+		middle += "\n" + utils.Indent_lines(utils.Token_type_code(token)+": "+strconv.Itoa(row[token])+",", 1)
+	}
+	return "\"" + non_term + "\": {" + middle + "\n},"
+}
+
+/*
+ParseTable_code gives the LL(1) parse table M of the parser: for a non-terminal and the next token, the index of the production to expand it with. The productions which can match nothing are under the tokens of the FOLLOW set of their non-terminal, so a token missing from a row is a syntax error as soon as the non-terminal is expanded.
+
+	var parseTable = map[string]map[dfa.TokenType]int{
+		"999_term_star_1": {
+			dfa.MINUS: 0,
+			dfa.PLUS:  0,
+			dfa.EOF:   1,
+			...
+*/
+func ParseTable_code(bnf_grammar map[string]([][]utils.Grammar_element), table map[string]map[dfa.TokenType]int) string {
+	start := "var parseTable = map[string]map[dfa.TokenType]int{"
+	middle := "\n"
+	for _, non_term := range rules_order(bnf_grammar) {
+		middle += utils.Indent_lines(code_parse_table_row(non_term, table[non_term]), 1) + "\n"
+	}
+	end := "}"
+
+	if len(bnf_grammar) < 1 {
+		return start + end
+	}
+	return start + middle + end
+}
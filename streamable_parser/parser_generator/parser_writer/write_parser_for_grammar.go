@@ -1,32 +1,64 @@
 package parser_writer
 
 import (
-	"bufio"
+	"go/format"
 	"os"
 
+	"github.com/VirajAgarwal1/lox/errorhandler"
+	"github.com/VirajAgarwal1/lox/grammar_file"
 	"github.com/VirajAgarwal1/lox/lexer/dfa"
 	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/first_follow"
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/generator"
 	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/parser_writer/code_snippets"
 	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/utils"
 )
 
 func WriteParser(path string, bnf_grammar map[string][][]utils.Grammar_element, starting_non_terminal string, firstSet map[string]first_follow.FirstSetInfo, followSet map[string][]dfa.TokenType) error {
-	// Always overwrite the file cleanly
-	file, err := os.Create(path)
+	table := generator.Compute_parse_table(bnf_grammar, firstSet, followSet)
+	return write_parser(path, code_snippets.Package_and_Imports, bnf_grammar, starting_non_terminal, nil, firstSet, followSet, table, "")
+}
+
+// WriteParserForGrammar generates the parser for a grammar file on its own: its start symbol comes from `%start`, the tokens it ignores from `%skip`, and its actions fill `SemanticActions` in, with the packages of `%import`. Nothing is written if `grammar_validator` finds errors in the grammar.
+func WriteParserForGrammar(path string, grammar *grammar_file.Grammar) error {
+	parser_generator := generator.Generator{}
+	result, err := parser_generator.Generate(grammar, generator.Options{})
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	return WriteResult(path, result)
+}
 
-	writer := bufio.NewWriter(file)
+// WriteResult writes the parser of what `generator.Generate` gave for a grammar file
+func WriteResult(path string, result *generator.Result) error {
+	actions, err := code_snippets.SemanticActions_code(result.Grammar)
+	if err != nil {
+		return errorhandler.RetErr("Invalid action", err)
+	}
+	header := code_snippets.Package_and_Imports
+	if actions != "" {
+		header = code_snippets.Package_and_Imports_code(append([]string{code_snippets.Lexer_import}, result.Grammar.Imports...))
+	}
 
+	return write_parser(path, header, result.Bnf, result.Start, result.Skip, result.First, result.Follow, result.Table, actions)
+}
+
+// write_parser writes the parser gofmt'd. The same grammar always gives the same file, so that the changes to a generated parser can be read in a diff.
+func write_parser(path string, header string, bnf_grammar map[string][][]utils.Grammar_element, starting_non_terminal string, skip_tokens []dfa.TokenType, firstSet map[string]first_follow.FirstSetInfo, followSet map[string][]dfa.TokenType, table generator.Parse_table, actions string) error {
 	code := ""
-	code += code_snippets.Package_and_Imports + "\n\n"
+	code += header + "\n\n"
 	code += code_snippets.Consts_code(starting_non_terminal) + "\n\n"
-	code += code_snippets.GrammarRules_code(bnf_grammar, firstSet, followSet) + "\n"
+	code += code_snippets.SkipTokens_code(skip_tokens) + "\n\n"
+	code += code_snippets.GrammarRules_code(bnf_grammar, firstSet, followSet) + "\n\n"
+	code += code_snippets.ParseTable_code(bnf_grammar, table) + "\n"
+	if actions != "" {
+		code += "\n" + actions + "\n"
+	}
 
-	if _, err := writer.WriteString(code); err != nil {
-		return err
+	formatted, err := format.Source([]byte(code))
+	if err != nil {
+		// Only the actions of the grammar can be wrong Go, and `go build` says where better than `format` does
+		formatted = []byte(code)
 	}
-	return writer.Flush()
+	// Always overwrite the file cleanly
+	return os.WriteFile(path, formatted, 0644)
 }
@@ -1,135 +1,49 @@
 package utils
 
 import (
+	"sort"
 	"strings"
 
-	"github.com/VirajAgarwal1/lox/lexer"
+	"github.com/VirajAgarwal1/lox/grammar_file"
 	"github.com/VirajAgarwal1/lox/lexer/dfa"
-	gfp "github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/grammar_file_parser"
 )
 
 type Grammar_element struct {
-	IsNonTerminal bool
-	Non_term_name string
-	Terminal_type dfa.TokenType
+	IsNonTerminal     bool
+	Non_term_name     string
+	Terminal_type     dfa.TokenType
+	Label             string // Given in the grammar file with `label:element`, empty if the element has none
+	Marks_alternative bool   // For an `Epsilon` element which ends an alternative moved out of its rule by left factoring, see `Alternative_mark`
+	Alternative       int    // The index of that alternative in its rule
+	Reassociates      bool   // For an `Epsilon` element which starts a tail of a left-recursive rule, see `Reassociation_mark`
 }
 
-const Epsilon = dfa.TokenType("Epsilon")
-
-var String_to_type_string = map[string]string{
-	// Literals
-	"EOF":        "dfa.EOF",
-	"IDENTIFIER": "dfa.IDENTIFIER",
-	"STRING":     "dfa.STRING",
-	"NUMBER":     "dfa.NUMBER",
-	"COMMENT":    "dfa.COMMENT",
-	"Epsilon":    "utils.Epsilon",
-
-	// Single-char tokens
-	" ":   "dfa.WHITESPACE",
-	"\\n": "dfa.NEWLINE",
-	"(":   "dfa.LEFT_PAREN",
-	")":   "dfa.RIGHT_PAREN",
-	"{":   "dfa.LEFT_BRACE",
-	"}":   "dfa.RIGHT_BRACE",
-	",":   "dfa.COMMA",
-	".":   "dfa.DOT",
-	"-":   "dfa.MINUS",
-	"+":   "dfa.PLUS",
-	";":   "dfa.SEMICOLON",
-	"/":   "dfa.SLASH",
-	"*":   "dfa.STAR",
-
-	// One-or-two char tokens
-	"!":  "dfa.BANG",
-	"!=": "dfa.BANG_EQUAL",
-	"=":  "dfa.EQUAL",
-	"==": "dfa.EQUAL_EQUAL",
-	">":  "dfa.GREATER",
-	">=": "dfa.GREATER_EQUAL",
-	"<":  "dfa.LESS",
-	"<=": "dfa.LESS_EQUAL",
-
-	// Keywords
-	"and":    "dfa.AND",
-	"class":  "dfa.CLASS",
-	"else":   "dfa.ELSE",
-	"false":  "dfa.FALSE",
-	"fun":    "dfa.FUN",
-	"for":    "dfa.FOR",
-	"if":     "dfa.IF",
-	"nil":    "dfa.NIL",
-	"or":     "dfa.OR",
-	"print":  "dfa.PRINT",
-	"return": "dfa.RETURN",
-	"super":  "dfa.SUPER",
-	"this":   "dfa.THIS",
-	"true":   "dfa.TRUE",
-	"var":    "dfa.VAR",
-	"while":  "dfa.WHILE",
+// Alternative_mark gives the element which ends the alternative `alternative` of a rule when left factoring moves it to an artificial non-terminal. It matches nothing, but tells the parser which alternative of the rule was matched, as the rule itself cannot choose it anymore.
+func Alternative_mark(alternative int) Grammar_element {
+	return Grammar_element{IsNonTerminal: false, Terminal_type: Epsilon, Marks_alternative: true, Alternative: alternative}
 }
 
-var String_to_token = map[string]dfa.TokenType{
-	// Literals
-	"EOF":        dfa.EOF,
-	"IDENTIFIER": dfa.IDENTIFIER,
-	"STRING":     dfa.STRING,
-	"NUMBER":     dfa.NUMBER,
-	"COMMENT":    dfa.COMMENT,
-	"Epsilon":    Epsilon,
-
-	// Single-char tokens
-	" ":   dfa.WHITESPACE,
-	"\\n": dfa.NEWLINE,
-	"(":   dfa.LEFT_PAREN,
-	")":   dfa.RIGHT_PAREN,
-	"{":   dfa.LEFT_BRACE,
-	"}":   dfa.RIGHT_BRACE,
-	",":   dfa.COMMA,
-	".":   dfa.DOT,
-	"-":   dfa.MINUS,
-	"+":   dfa.PLUS,
-	";":   dfa.SEMICOLON,
-	"/":   dfa.SLASH,
-	"*":   dfa.STAR,
+// Reassociation_mark gives the element which starts a tail of a left-recursive rule when its nodes are reassociated (see `grammar_file.Eliminate_left_recursion`). It matches nothing, but tells the parser to end the node of the rule, labelled `label`, and to start a new one around it.
+func Reassociation_mark(label string) Grammar_element {
+	return Grammar_element{IsNonTerminal: false, Terminal_type: Epsilon, Label: label, Reassociates: true}
+}
 
-	// One-or-two char tokens
-	"!":  dfa.BANG,
-	"!=": dfa.BANG_EQUAL,
-	"=":  dfa.EQUAL,
-	"==": dfa.EQUAL_EQUAL,
-	">":  dfa.GREATER,
-	">=": dfa.GREATER_EQUAL,
-	"<":  dfa.LESS,
-	"<=": dfa.LESS_EQUAL,
+const Epsilon = dfa.TokenType("Epsilon")
 
-	// Keywords
-	"and":    dfa.AND,
-	"class":  dfa.CLASS,
-	"else":   dfa.ELSE,
-	"false":  dfa.FALSE,
-	"fun":    dfa.FUN,
-	"for":    dfa.FOR,
-	"if":     dfa.IF,
-	"nil":    dfa.NIL,
-	"or":     dfa.OR,
-	"print":  dfa.PRINT,
-	"return": dfa.RETURN,
-	"super":  dfa.SUPER,
-	"this":   dfa.THIS,
-	"true":   dfa.TRUE,
-	"var":    dfa.VAR,
-	"while":  dfa.WHILE,
+// Resolve_token is `grammar_file.Resolve_token`, which also knows about `Epsilon`
+func Resolve_token(name string, declared_tokens []string) (dfa.TokenType, bool) {
+	if name == string(Epsilon) {
+		return Epsilon, true
+	}
+	return grammar_file.Resolve_token(name, declared_tokens)
 }
 
-func Detect_or_in_sequence(description []gfp.Generic_grammar_term) []uint32 {
-	out := make([]uint32, 0, len(description)/4)
-	for i, term := range description {
-		if term.Get_grammar_term_type() == "or" {
-			out = append(out, uint32(i))
-		}
+// Token_type_code gives the Go code for a token type, for the generated parsers
+func Token_type_code(token dfa.TokenType) string {
+	if token == Epsilon {
+		return "utils.Epsilon"
 	}
-	return out
+	return grammar_file.Token_type_code(token)
 }
 
 func Indent_lines(input string, indentLevel int) string {
@@ -157,14 +71,33 @@ func Contains[T comparable](slice []T, item T) bool {
 	return false
 }
 
-func InFirstSet(tok *lexer.Token, firstSet map[dfa.TokenType]struct{}) bool {
-	for k := range firstSet {
-		if tok.TypeOfToken == k {
-			return true
+// Sorted_keys gives the keys of a map in order, for the generator to go through the non-terminals the same way every time
+func Sorted_keys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Ordered_tokens gives the tokens of a set in the order of `dfa.TokensList`, then the ones declared with `%token` by name, so that the parse table and the tokens an error expects are always written the same way
+func Ordered_tokens[V any](set map[dfa.TokenType]V) []dfa.TokenType {
+	tokens := []dfa.TokenType{}
+	for _, token := range dfa.TokensList {
+		if _, found := set[token]; found {
+			tokens = append(tokens, token)
 		}
-		if k == Epsilon {
-			return true
+	}
+	declared := []string{}
+	for token := range set {
+		if !Contains(dfa.TokensList, token) {
+			declared = append(declared, string(token))
 		}
 	}
-	return false
+	sort.Strings(declared)
+	for _, token := range declared {
+		tokens = append(tokens, dfa.TokenType(token))
+	}
+	return tokens
 }
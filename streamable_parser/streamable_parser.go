@@ -1,13 +1,13 @@
 package streamable_parser
 
 import (
-	"fmt"
 	"io"
-	"strconv"
 	"strings"
 
+	"github.com/VirajAgarwal1/lox/errorhandler"
 	"github.com/VirajAgarwal1/lox/lexer"
 	dfa "github.com/VirajAgarwal1/lox/lexer/dfa"
+	"github.com/VirajAgarwal1/lox/source"
 	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/ebnf_to_bnf"
 	utils "github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/utils"
 )
@@ -30,22 +30,32 @@ type ProductionRule struct {
 
 // StackElem represents an event emitted by the parser during parsing. It can be either a non-terminal expansion or a leaf (token) emission. It is also the type of the object in the stack
 type StackElem struct {
-	Type         StackElemType // kind of emit: start, end or leaf
-	NonTermName  string
-	TerminalType dfa.TokenType
+	Type             StackElemType // kind of emit: start, end or leaf
+	NonTermName      string
+	TerminalType     dfa.TokenType
+	Label            string // label given to the element in the grammar, like `left` for `left:term`
+	Alternative      int    // index of the production chosen for the non-terminal (valid for end), or of the alternative marked (valid for a leaf which marks one)
+	MarksAlternative bool   // the leaf is a `utils.Alternative_mark`, which gives its alternative to the rule it was factored out of
+	Reassociates     bool   // the leaf is a `utils.Reassociation_mark`, which ends the node of its left-recursive rule and starts a new one around it
 }
 
 type EmitElem struct {
-	Type    EmitElemType // kind of emit: start, end, leaf or error
-	Content string       // name of the non-terminal (valid for start/end) or error message (for error event)
-	Leaf    *lexer.Token
+	Type        EmitElemType // kind of emit: start, end, leaf or error
+	Content     string       // name of the non-terminal (valid for start/end) or error message (for error event)
+	Leaf        *lexer.Token
+	Label       string // label of the element in the rule of its parent (valid for start/end/leaf), empty if it has none
+	Alternative int    // index of the alternative of the rule which was matched (valid for end), the one whose action runs
+	Wraps       bool   // the node starts around the node of the same non-terminal which just ended, which becomes its first child (valid for start, only given by the left-recursive rules of a grammar with `%reassociate` and the left-associative levels of a `%expr`)
+	Err         error  // the error itself (for error event), use `errors.As` to get the `*errorhandler.ParseError` or `*errorhandler.LexError` out of it
 }
 
 // TODO: Add an abstraction layer for the stack where on exceeding 80% capacity it will, offload 60% of the stack to a file (aka disk). If even that file gets over 100% capacity, then create a new file. There will be 1 file which keeps tracks of what files hold what indexes of the stack, this manager file is what the abstraction will keep track of...
 // StreamableParser represents the LL(1) parser state machine. It maintains a parsing stack and a lexical scanner to consume tokens.
 type StreamableParser struct {
-	stack   []StackElem                    // the parser’s working stack (terminals & non-terminals)
-	scanner *lexer.BufferedLexicalAnalyzer // the input token stream
+	stack       []StackElem                    // the parser’s working stack (terminals & non-terminals)
+	scanner     *lexer.BufferedLexicalAnalyzer // the input token stream
+	diagnostics *errorhandler.Diagnostics      // optional, every error event is recorded here as well
+	pending     *EmitElem                      // the start event of a reassociated node, given by the next call to `Parse`
 }
 
 const (
@@ -61,12 +71,10 @@ const (
 	EmitElemType_Error
 )
 
-func in_first_of_non_term(tok *lexer.Token, non_term string) int {
-	seqs := grammarRules[non_term].Sequences
-	for i := range seqs {
-		if utils.InFirstSet(tok, seqs[i].FirstSet) {
-			return i
-		}
+// predict gives the production to expand the non-terminal with from the parse table, -1 if the token cannot come next. A production which can match nothing is only chosen on the tokens which can follow its non-terminal.
+func predict(tok *lexer.Token, non_term string) int {
+	if production, found := parseTable[non_term][tok.TypeOfToken]; found {
+		return production
 	}
 	return -1
 }
@@ -92,17 +100,117 @@ func (sp *StreamableParser) stack_peek() StackElem {
 	return sp.stack[len(sp.stack)-1]
 }
 
+// peek returns the next token which is not in `SkipTokens`. The skipped tokens are consumed.
+func (sp *StreamableParser) peek() (*lexer.Token, error) {
+	tok, err := sp.scanner.Peek()
+	for err == nil {
+		if _, skipped := SkipTokens[tok.TypeOfToken]; !skipped {
+			break
+		}
+		sp.scanner.ReadToken()
+		tok, err = sp.scanner.Peek()
+	}
+	return tok, err
+}
+
+// position resolves a token position from the file the scanner is reading
+func (sp *StreamableParser) position(p source.Pos) source.Position {
+	return sp.scanner.File().Position(p)
+}
+
+// expected_tokens_of_non_term lists the tokens on which the non-terminal can be expanded, in the order of `dfa.TokensList` then the tokens declared with `%token`
+func expected_tokens_of_non_term(non_term string) []dfa.TokenType {
+	return utils.Ordered_tokens(parseTable[non_term])
+}
+
+// unexpectedTokenError builds the error for a token which the parser could not use. `recovered_at` is the token on which error recovery stopped, everything in between was skipped.
+func (sp *StreamableParser) unexpectedTokenError(code string, found *lexer.Token, recovered_at *lexer.Token, expected []dfa.TokenType, while_parsing string) error {
+	if strings.HasPrefix(while_parsing, ebnf_to_bnf.Artificial_non_term_prefix) {
+		while_parsing = "" // Users never wrote the artificial non-terminals
+	}
+	parse_err := &errorhandler.ParseError{
+		Code:        code,
+		Pos:         found.Pos,
+		End:         found.End,
+		Position:    sp.position(found.Pos),
+		Found:       found.TypeOfToken.Name(),
+		Lexemme:     string(found.Lexemme),
+		Expected:    dfa.Names(expected),
+		NonTerminal: while_parsing,
+	}
+	if recovered_at != nil {
+		parse_err.RecoveredAt = recovered_at.Pos
+	}
+	return parse_err
+}
+
+// child_label gives the label of an element of the production chosen for `parent`. The artificial non-terminals emit no events of their own, so the elements they expand to carry their label (`op:( "+" or "-" )` labels the `+` or `-` leaf with `op`), unless they have one of their own.
+func child_label(parent *StackElem, child utils.Grammar_element) string {
+	if child.Label == "" && strings.HasPrefix(parent.NonTermName, ebnf_to_bnf.Artificial_non_term_prefix) {
+		return parent.Label
+	}
+	return child.Label
+}
+
+// rule_below gives the index in the stack of the end of the rule a mark belongs to, -1 if there is none. Everything between the mark and it is of the artificial non-terminals the rule was converted into, so it is the first end of a rule of the grammar file below the mark.
+func (sp *StreamableParser) rule_below() int {
+	for i := len(sp.stack) - 1; i > -1; i-- {
+		if sp.stack[i].Type == StackElemType_End && !strings.HasPrefix(sp.stack[i].NonTermName, ebnf_to_bnf.Artificial_non_term_prefix) {
+			return i
+		}
+	}
+	return -1
+}
+
+// mark_alternative gives the alternative of a `utils.Alternative_mark` to its rule
+func (sp *StreamableParser) mark_alternative(alternative int) {
+	if i := sp.rule_below(); i > -1 {
+		sp.stack[i].Alternative = alternative
+	}
+}
+
+// reassociate ends the node of the left-recursive rule a `utils.Reassociation_mark` belongs to, and leaves the start of a new node around it for the next call. The node which ends gets the label of the mark, the new one the label of the rule in its parent.
+func (sp *StreamableParser) reassociate(mark *StackElem) *EmitElem {
+	i := sp.rule_below()
+	if i < 0 {
+		return nil
+	}
+	rule := sp.stack[i]
+	sp.pending = &EmitElem{
+		Type:    EmitElemType_Start,
+		Content: rule.NonTermName,
+		Label:   rule.Label,
+		Wraps:   true,
+	}
+	return &EmitElem{
+		Type:        EmitElemType_End,
+		Content:     rule.NonTermName,
+		Label:       mark.Label,
+		Alternative: rule.Alternative,
+	}
+}
+
 func (sp *StreamableParser) Initialize(scanner *lexer.BufferedLexicalAnalyzer) {
 	sp.stack = make([]StackElem, 0, 30)
 	sp.scanner = scanner
+	sp.pending = nil
 
 	sp.stack = append(sp.stack, StackElem{Type: StackElemType_Start, NonTermName: StartingNonTerminal})
 }
+
+// SetDiagnostics makes the parser record the error of every error event in the given collector
+func (sp *StreamableParser) SetDiagnostics(diagnostics *errorhandler.Diagnostics) {
+	sp.diagnostics = diagnostics
+}
 func (sp *StreamableParser) EmitEvent(err error, el *StackElem, tok *lexer.Token) *EmitElem {
 	if err != nil {
+		if sp.diagnostics != nil {
+			sp.diagnostics.AddError(err)
+		}
 		return &EmitElem{
 			Type:    EmitElemType_Error,
 			Content: err.Error(),
+			Err:     err,
 		}
 	}
 
@@ -114,6 +222,7 @@ func (sp *StreamableParser) EmitEvent(err error, el *StackElem, tok *lexer.Token
 		return &EmitElem{
 			Type:    EmitElemType_Start,
 			Content: el.NonTermName,
+			Label:   el.Label,
 		}
 
 	case StackElemType_End:
@@ -121,8 +230,10 @@ func (sp *StreamableParser) EmitEvent(err error, el *StackElem, tok *lexer.Token
 			return nil
 		}
 		return &EmitElem{
-			Type:    EmitElemType_End,
-			Content: el.NonTermName,
+			Type:        EmitElemType_End,
+			Content:     el.NonTermName,
+			Label:       el.Label,
+			Alternative: el.Alternative,
 		}
 
 	case StackElemType_Leaf:
@@ -130,6 +241,7 @@ func (sp *StreamableParser) EmitEvent(err error, el *StackElem, tok *lexer.Token
 			Type:    EmitElemType_Leaf,
 			Content: string(tok.Lexemme),
 			Leaf:    tok,
+			Label:   el.Label,
 		}
 	}
 
@@ -137,35 +249,50 @@ func (sp *StreamableParser) EmitEvent(err error, el *StackElem, tok *lexer.Token
 }
 func (sp *StreamableParser) Parse() *EmitElem {
 
+	if sp.pending != nil {
+		output := sp.pending
+		sp.pending = nil
+		return output
+	}
+
 	if len(sp.stack) < 1 {
+		sp.peek() // Nothing is expected anymore, but the tokens in `SkipTokens` are still allowed
 		next_tok, err := sp.scanner.ReadToken()
 		if err != nil {
 			return &EmitElem{
 				Type:    EmitElemType_Error,
 				Content: err.Error(),
+				Err:     err,
 			}
 		}
-		return &EmitElem{
-			Type:    EmitElemType_Error,
-			Content: fmt.Sprintf("Expected \"EOF\" but got \"%s\"", next_tok.ToString()),
-		}
+		return sp.EmitEvent(
+			sp.unexpectedTokenError(errorhandler.CodeUnexpectedToken, next_tok, nil, []dfa.TokenType{dfa.EOF}, ""),
+			nil,
+			next_tok,
+		)
 	}
 
 	for {
 
 		top := sp.stack_peek()
-		lookahead_token, err := sp.scanner.Peek()
+		lookahead_token, err := sp.peek()
 		if err != nil && err != io.EOF {
-			return &EmitElem{
-				Type:    EmitElemType_Error,
-				Content: err.Error(),
-			}
+			sp.scanner.ReadToken() // Skip the invalid token so that the next call can make progress
+			return sp.EmitEvent(err, nil, lookahead_token)
 		}
 
 		switch top.Type {
 		case StackElemType_Leaf:
 			if top.TerminalType == utils.Epsilon {
 				sp.stack_pop()
+				if top.MarksAlternative {
+					sp.mark_alternative(top.Alternative)
+				}
+				if top.Reassociates {
+					if output := sp.reassociate(&top); output != nil {
+						return output
+					}
+				}
 				continue
 			}
 			if lookahead_token.TypeOfToken == top.TerminalType {
@@ -174,18 +301,21 @@ func (sp *StreamableParser) Parse() *EmitElem {
 				return sp.EmitEvent(nil, &top, lookahead_token)
 			}
 			// Else consume tokens to put into the error message until, we get to a token, which is equal to the top
-			err_start := strconv.FormatUint(uint64(lookahead_token.Line), 10) + "," + strconv.FormatUint(uint64(lookahead_token.Offset), 10)
+			err_start := lookahead_token
 			for lookahead_token.TypeOfToken != top.TerminalType {
 				sp.scanner.ReadToken()
-				lookahead_token, err = sp.scanner.Peek()
+				lookahead_token, err = sp.peek()
 				if err != nil {
 					// Hit EOF or other error during recovery - can't continue
 					break
 				}
 			}
-			err_end := strconv.FormatUint(uint64(lookahead_token.Line), 10) + "," + strconv.FormatUint(uint64(lookahead_token.Offset), 10)
+			if lookahead_token.TypeOfToken != top.TerminalType {
+				// The expected token never came, act as if it was there so that the parser does not get stuck on it
+				sp.stack_pop()
+			}
 			return sp.EmitEvent(
-				fmt.Errorf("parse error from %s to %s, expected \"%v\"", err_start, err_end, top.TerminalType),
+				sp.unexpectedTokenError(errorhandler.CodeUnexpectedToken, err_start, lookahead_token, []dfa.TokenType{top.TerminalType}, ""),
 				nil,
 				lookahead_token,
 			)
@@ -199,23 +329,31 @@ func (sp *StreamableParser) Parse() *EmitElem {
 			return output
 
 		case StackElemType_Start:
-			prod_rule := in_first_of_non_term(lookahead_token, top.NonTermName)
+			prod_rule := predict(lookahead_token, top.NonTermName)
 			sp.stack_pop()
 			if prod_rule != -1 {
 				sp.stack_push(&StackElem{
 					Type:        StackElemType_End,
 					NonTermName: top.NonTermName,
+					Label:       top.Label,
+					Alternative: prod_rule,
 				})
 				for i := len(grammarRules[top.NonTermName].Sequences[prod_rule].Elements) - 1; i > -1; i-- {
+					label := child_label(&top, grammarRules[top.NonTermName].Sequences[prod_rule].Elements[i])
 					if grammarRules[top.NonTermName].Sequences[prod_rule].Elements[i].IsNonTerminal {
 						sp.stack_push(&StackElem{
 							Type:        StackElemType_Start,
 							NonTermName: grammarRules[top.NonTermName].Sequences[prod_rule].Elements[i].Non_term_name,
+							Label:       label,
 						})
 					} else {
 						sp.stack_push(&StackElem{
-							Type:         StackElemType_Leaf,
-							TerminalType: grammarRules[top.NonTermName].Sequences[prod_rule].Elements[i].Terminal_type,
+							Type:             StackElemType_Leaf,
+							TerminalType:     grammarRules[top.NonTermName].Sequences[prod_rule].Elements[i].Terminal_type,
+							Label:            label,
+							Alternative:      grammarRules[top.NonTermName].Sequences[prod_rule].Elements[i].Alternative,
+							MarksAlternative: grammarRules[top.NonTermName].Sequences[prod_rule].Elements[i].Marks_alternative,
+							Reassociates:     grammarRules[top.NonTermName].Sequences[prod_rule].Elements[i].Reassociates,
 						})
 					}
 				}
@@ -225,18 +363,17 @@ func (sp *StreamableParser) Parse() *EmitElem {
 				}
 				return output
 			}
-			err_start := strconv.FormatUint(uint64(lookahead_token.Line), 10) + "," + strconv.FormatUint(uint64(lookahead_token.Offset), 10)
+			err_start := lookahead_token
 			for !in_follow_of_non_term(lookahead_token, top.NonTermName) {
 				sp.scanner.ReadToken()
-				lookahead_token, err = sp.scanner.Peek()
+				lookahead_token, err = sp.peek()
 				if err != nil {
 					// Hit EOF or other error during recovery - can't continue
 					break
 				}
 			}
-			err_end := strconv.FormatUint(uint64(lookahead_token.Line), 10) + "," + strconv.FormatUint(uint64(lookahead_token.Offset), 10)
 			return sp.EmitEvent(
-				fmt.Errorf("parse error from %s to %s", err_start, err_end),
+				sp.unexpectedTokenError(errorhandler.CodeNoMatchingProduction, err_start, lookahead_token, expected_tokens_of_non_term(top.NonTermName), top.NonTermName),
 				nil,
 				lookahead_token,
 			)
@@ -0,0 +1,103 @@
+package errorhandler_tests
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/VirajAgarwal1/lox/errorhandler"
+	"github.com/VirajAgarwal1/lox/lexer"
+	"github.com/VirajAgarwal1/lox/source"
+)
+
+func TestDiagnosticsDeduplicatesAndCaps(t *testing.T) {
+	fset := source.NewFileSet()
+	file := fset.AddFile("a.lox", []byte("abcdefghij"))
+
+	ds := errorhandler.NewDiagnostics(3)
+	span := errorhandler.NewSpan(file.Pos(2), file.Pos(3))
+	if !ds.Add(errorhandler.NewDiagnostic("X0001", "first", span, "")) {
+		t.Fatalf("Expected the first diagnostic to be kept")
+	}
+	if ds.Add(errorhandler.NewDiagnostic("X0001", "first", span, "")) {
+		t.Errorf("Expected the duplicate diagnostic to be dropped")
+	}
+	ds.Add(errorhandler.NewWarning("X0002", "second", span, ""))
+	ds.Add(errorhandler.NewDiagnostic("X0003", "third", span, ""))
+	if ds.Add(errorhandler.NewDiagnostic("X0004", "fourth", span, "")) {
+		t.Errorf("Expected the diagnostic over the cap to be dropped")
+	}
+
+	if ds.Len() != 3 || ds.Dropped() != 1 {
+		t.Errorf("Expected 3 kept and 1 dropped, got %d and %d", ds.Len(), ds.Dropped())
+	}
+	if ds.ErrorCount() != 2 || ds.WarningCount() != 1 {
+		t.Errorf("Expected 2 errors and 1 warning, got %d and %d", ds.ErrorCount(), ds.WarningCount())
+	}
+}
+
+func TestDiagnosticsSortedByPosition(t *testing.T) {
+	fset := source.NewFileSet()
+	a := fset.AddFile("a.lox", []byte("0123456789"))
+	b := fset.AddFile("b.lox", []byte("0123456789"))
+
+	ds := errorhandler.NewDiagnostics(0)
+	ds.AddError(errors.New("no position"))
+	ds.Add(errorhandler.NewDiagnostic("", "b5", errorhandler.NewSpan(b.Pos(5), source.NoPos), ""))
+	ds.Add(errorhandler.NewDiagnostic("", "a7", errorhandler.NewSpan(a.Pos(7), source.NoPos), ""))
+	ds.Add(errorhandler.NewDiagnostic("", "a1", errorhandler.NewSpan(a.Pos(1), source.NoPos), ""))
+
+	got := []string{}
+	for _, d := range ds.Sorted() {
+		got = append(got, d.Message)
+	}
+	if strings.Join(got, ",") != "a1,a7,b5,no position" {
+		t.Errorf("Unexpected order: %v", got)
+	}
+	if ds.Err() == nil || ds.Err().Error() != "error: a1" {
+		t.Errorf("Expected Err() to return the first error, got %v", ds.Err())
+	}
+}
+
+func TestDiagnosticsSharedAcrossConcurrentLexers(t *testing.T) {
+	fset := source.NewFileSet()
+	ds := errorhandler.NewDiagnostics(0)
+
+	var files []*source.File
+	for i := range 4 {
+		files = append(files, fset.AddFile(fmt.Sprintf("f%d.lox", i), []byte("var a = @ + #;\n")))
+	}
+
+	var wg sync.WaitGroup
+	for _, file := range files {
+		wg.Add(1)
+		go func(file *source.File) {
+			defer wg.Done()
+			src, _ := file.Line(1)
+			scanner := lexer.LexicalAnalyzer{}
+			scanner.InitializeWithFile(bufio.NewReader(strings.NewReader(src+"\n")), file)
+			scanner.SetDiagnostics(ds)
+			for {
+				_, err := scanner.ReadToken()
+				if err == io.EOF {
+					return
+				}
+			}
+		}(file)
+	}
+	wg.Wait()
+
+	if ds.Len() != 8 {
+		t.Fatalf("Expected 2 errors from each of the 4 files, got %d", ds.Len())
+	}
+	sorted := ds.Sorted()
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i-1].Position.Filename > sorted[i].Position.Filename {
+			t.Errorf("Diagnostics are not sorted by file: %v before %v", sorted[i-1], sorted[i])
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package errorhandler_tests
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/VirajAgarwal1/lox/errorhandler"
+	"github.com/VirajAgarwal1/lox/lexer"
+	"github.com/VirajAgarwal1/lox/lexer/dfa"
+	"github.com/VirajAgarwal1/lox/streamable_parser"
+)
+
+func TestLexErrorIsInspectable(t *testing.T) {
+	scanner := lexer.LexicalAnalyzer{}
+	scanner.Initialize(bufio.NewReader(strings.NewReader("\"never closed")))
+
+	var err error
+	for err == nil {
+		_, err = scanner.ReadToken()
+	}
+
+	var lex_err *errorhandler.LexError
+	if !errors.As(err, &lex_err) {
+		t.Fatalf("Expected a *LexError, got %v", err)
+	}
+	if !errors.Is(err, errorhandler.ErrLexical) || errors.Is(err, errorhandler.ErrSyntax) {
+		t.Errorf("Expected the error to be only a lexical error")
+	}
+	if lex_err.Code != errorhandler.CodeInvalidToken {
+		t.Errorf("Expected code %s, got %s", errorhandler.CodeInvalidToken, lex_err.Code)
+	}
+	if lex_err.Resembling != dfa.STRING.Name() {
+		t.Errorf("Expected resembling token STRING, got %q", lex_err.Resembling)
+	}
+	if lex_err.Position.Line != 1 || lex_err.Position.Column != 1 {
+		t.Errorf("Expected the error at 1:1, got %v", lex_err.Position)
+	}
+}
+
+func TestBufferOverflowIsLimitError(t *testing.T) {
+	buf := lexer.BufferedLexer{}
+	buf.Initialize(bufio.NewReader(strings.NewReader("1 2 3")), 2)
+
+	var err error
+	for i := 0; i < 3 && err == nil; i++ {
+		_, err = buf.ReadToken()
+	}
+
+	var limit_err *errorhandler.LimitError
+	if !errors.As(err, &limit_err) {
+		t.Fatalf("Expected a *LimitError, got %v", err)
+	}
+	if !errors.Is(err, errorhandler.ErrLimit) {
+		t.Errorf("Expected errors.Is(err, ErrLimit)")
+	}
+	if limit_err.Limit != 2 {
+		t.Errorf("Expected limit 2, got %d", limit_err.Limit)
+	}
+}
+
+func TestStreamableParserErrorEvents(t *testing.T) {
+	scanner := lexer.BufferedLexicalAnalyzer{}
+	scanner.Initialize(bufio.NewReader(strings.NewReader("(1+2")))
+	sp := streamable_parser.StreamableParser{}
+	sp.Initialize(&scanner)
+
+	var parse_err *errorhandler.ParseError
+	for range 100 {
+		ev := sp.Parse()
+		if ev.Type != streamable_parser.EmitElemType_Error {
+			continue
+		}
+		if ev.Err == io.EOF {
+			break
+		}
+		if !errors.As(ev.Err, &parse_err) {
+			t.Fatalf("Expected a *ParseError on the error event, got %v", ev.Err)
+		}
+		if ev.Content != ev.Err.Error() {
+			t.Errorf("Expected Content to hold the error message")
+		}
+	}
+
+	if parse_err == nil {
+		t.Fatalf("Expected a parse error for the missing ')'")
+	}
+	if parse_err.Code != errorhandler.CodeUnexpectedToken {
+		t.Errorf("Expected code %s, got %s", errorhandler.CodeUnexpectedToken, parse_err.Code)
+	}
+	if parse_err.Found != errorhandler.EndOfFile {
+		t.Errorf("Expected to find EOF, got %q", parse_err.Found)
+	}
+	if len(parse_err.Expected) != 1 || parse_err.Expected[0] != dfa.RIGHT_PAREN.Name() {
+		t.Errorf("Expected \")\" to be expected, got %v", parse_err.Expected)
+	}
+	if !errors.Is(errorhandler.RetErr("wrapped", parse_err), errorhandler.ErrSyntax) {
+		t.Errorf("Expected errors.Is(err, ErrSyntax) through RetErr")
+	}
+}
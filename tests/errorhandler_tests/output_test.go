@@ -0,0 +1,143 @@
+package errorhandler_tests
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/VirajAgarwal1/lox/errorhandler"
+	"github.com/VirajAgarwal1/lox/grammar_file"
+	"github.com/VirajAgarwal1/lox/lexer"
+	"github.com/VirajAgarwal1/lox/source"
+	"github.com/VirajAgarwal1/lox/streamable_parser"
+)
+
+// Run `go test ./tests/errorhandler_tests -update` to rewrite the golden files after an intended change of the output
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// collect_sample_diagnostics runs the lexer, the grammar file parser and the streamable parser over broken inputs, all reporting to one collector
+func collect_sample_diagnostics() (*source.FileSet, *errorhandler.Diagnostics) {
+	fset := source.NewFileSet()
+	ds := errorhandler.NewDiagnostics(0)
+
+	lox_src := "var x = @;\nprint \"oops\n"
+	lox_file := fset.AddFile("main.lox", []byte(lox_src))
+	scanner := lexer.LexicalAnalyzer{}
+	scanner.InitializeWithFile(bufio.NewReader(strings.NewReader(lox_src)), lox_file)
+	scanner.SetDiagnostics(ds)
+	for {
+		if _, err := scanner.ReadToken(); err == io.EOF {
+			break
+		}
+	}
+
+	grammar_src := "expression -> term\nterm -> * NUMBER\n"
+	grammar_source := fset.AddFile("expr.grammar", []byte(grammar_src))
+	grammar_scanner := lexer.LexicalAnalyzer{}
+	grammar_scanner.InitializeWithFile(bufio.NewReader(strings.NewReader(grammar_src)), grammar_source)
+	grammar_scanner.SetDiagnostics(ds)
+	grammar_file.ProcessGrammarDefinition(&grammar_scanner)
+
+	expr_src := "(1+2"
+	expr_file := fset.AddFile("expr.lox", []byte(expr_src))
+	buf_scanner := lexer.BufferedLexicalAnalyzer{}
+	buf_scanner.InitializeWithFile(bufio.NewReader(strings.NewReader(expr_src)), expr_file)
+	sp := streamable_parser.StreamableParser{}
+	sp.Initialize(&buf_scanner)
+	sp.SetDiagnostics(ds)
+	for range 100 {
+		ev := sp.Parse()
+		if ev != nil && ev.Type == streamable_parser.EmitElemType_Error && ev.Err == io.EOF {
+			break
+		}
+	}
+
+	ds.Add(errorhandler.NewWarning(
+		"W0001",
+		"variable is never used",
+		errorhandler.NewSpan(lox_file.Pos(4), lox_file.Pos(5)),
+		"declared here",
+	).WithLabel(
+		errorhandler.NewSpan(lox_file.Pos(0), lox_file.Pos(3)),
+		"in this declaration",
+	).WithHelp("remove the variable"))
+
+	return fset, ds
+}
+
+func check_golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("Could not update %s: %v", path, err)
+		}
+	}
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Could not read %s: %v", path, err)
+	}
+	if !bytes.Equal(got, expected) {
+		t.Errorf("Output does not match %s (run with -update if the change is intended):\n%s", path, got)
+	}
+}
+
+func TestWriteJSONGolden(t *testing.T) {
+	fset, ds := collect_sample_diagnostics()
+	var out bytes.Buffer
+	if err := ds.WriteJSON(&out, fset); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	check_golden(t, "diagnostics.json.golden", out.Bytes())
+	if !strings.Contains(out.String(), `"snippet": "term -> * NUMBER"`) {
+		t.Errorf("Expected the snippet of the grammar file as it is, without escaping")
+	}
+
+	var report errorhandler.JSONReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	if report.Version != errorhandler.JSONSchemaVersion {
+		t.Errorf("Expected schema version %d, got %d", errorhandler.JSONSchemaVersion, report.Version)
+	}
+	if len(report.Diagnostics) != ds.Len() {
+		t.Errorf("Expected %d diagnostics, got %d", ds.Len(), len(report.Diagnostics))
+	}
+}
+
+func TestWriteSARIFGolden(t *testing.T) {
+	fset, ds := collect_sample_diagnostics()
+	var out bytes.Buffer
+	if err := ds.WriteSARIF(&out, fset); err != nil {
+		t.Fatalf("WriteSARIF failed: %v", err)
+	}
+	check_golden(t, "diagnostics.sarif.golden", out.Bytes())
+	if !strings.Contains(out.String(), `"text": "term -> * NUMBER"`) {
+		t.Errorf("Expected the snippet of the grammar file as it is, without escaping")
+	}
+
+	var log map[string]any
+	if err := json.Unmarshal(out.Bytes(), &log); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	if log["version"] != "2.1.0" {
+		t.Errorf("Expected SARIF version 2.1.0, got %v", log["version"])
+	}
+}
+
+func TestWriteJSONEmpty(t *testing.T) {
+	var out bytes.Buffer
+	if err := errorhandler.NewDiagnostics(0).WriteJSON(&out, nil); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	expected := "{\n  \"version\": 1,\n  \"diagnostics\": []\n}\n"
+	if out.String() != expected {
+		t.Errorf("Unexpected output:\n%s", out.String())
+	}
+}
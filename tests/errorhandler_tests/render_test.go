@@ -0,0 +1,134 @@
+package errorhandler_tests
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/VirajAgarwal1/lox/errorhandler"
+	"github.com/VirajAgarwal1/lox/lexer"
+	"github.com/VirajAgarwal1/lox/source"
+)
+
+func TestRenderSingleLineSpan(t *testing.T) {
+	fset := source.NewFileSet()
+	file := fset.AddFile("main.lox", []byte("var a = 1;\nvar b = a + );\n"))
+
+	diag := errorhandler.NewDiagnostic(
+		"P0002",
+		"unexpected \")\"",
+		errorhandler.NewSpan(file.Pos(23), file.Pos(24)),
+		"expected an expression",
+	).WithLabel(
+		errorhandler.NewSpan(file.Pos(19), file.Pos(22)),
+		"while parsing this",
+	).WithHelp("remove the ')'")
+
+	var out bytes.Buffer
+	renderer := errorhandler.Renderer{Files: fset}
+	if err := renderer.Render(&out, diag); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	expected := `error[P0002]: unexpected ")"
+ --> main.lox:2:13
+  |
+2 | var b = a + );
+  |             ^ expected an expression
+  |         --- while parsing this
+  |
+  = help: remove the ')'
+`
+	if out.String() != expected {
+		t.Errorf("Unexpected output:\n%s\nExpected:\n%s", out.String(), expected)
+	}
+}
+
+func TestRenderErrorAtEndOfFile(t *testing.T) {
+	// The end of the file is after the trailing newline, on a line with no text
+	fset := source.NewFileSet()
+	file := fset.AddFile("main.lox", []byte("var a = (1;\n"))
+
+	diag := errorhandler.NewDiagnostic(
+		"P0001",
+		"unexpected end of file",
+		errorhandler.NewSpan(file.Pos(12), file.Pos(12)),
+		"expected \")\"",
+	)
+
+	var out bytes.Buffer
+	renderer := errorhandler.Renderer{Files: fset}
+	if err := renderer.Render(&out, diag); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	expected := `error[P0001]: unexpected end of file
+ --> main.lox:1:12
+  |
+1 | var a = (1;
+  |            ^ expected ")"
+`
+	if out.String() != expected {
+		t.Errorf("Unexpected output:\n%s\nExpected:\n%s", out.String(), expected)
+	}
+}
+
+func TestRenderMultiLineSpanWithColour(t *testing.T) {
+	fset := source.NewFileSet()
+	file := fset.AddFile("multi.lox", []byte("print \"abc\ndef\n"))
+
+	diag := errorhandler.NewDiagnostic("L0001", "invalid token", errorhandler.NewSpan(file.Pos(6), file.Pos(14)), "not a valid token")
+
+	var plain, coloured bytes.Buffer
+	(&errorhandler.Renderer{Files: fset}).Render(&plain, diag)
+	(&errorhandler.Renderer{Files: fset, Colour: true}).Render(&coloured, diag)
+
+	expected := `error[L0001]: invalid token
+ --> multi.lox:1:7
+  |
+1 | print "abc
+  |       ^^^^
+2 | def
+  | ^^^ not a valid token
+`
+	if plain.String() != expected {
+		t.Errorf("Unexpected output:\n%s\nExpected:\n%s", plain.String(), expected)
+	}
+	if !strings.Contains(coloured.String(), "\x1b[") {
+		t.Errorf("Expected ANSI colour codes in coloured output")
+	}
+	if strings.Contains(plain.String(), "\x1b[") {
+		t.Errorf("Did not expect ANSI colour codes in plain output")
+	}
+}
+
+func TestLexerErrorsCarryDiagnostics(t *testing.T) {
+	fset := source.NewFileSet()
+	file := fset.AddFile("bad.lox", nil)
+	scanner := lexer.LexicalAnalyzer{}
+	scanner.InitializeWithFile(bufio.NewReader(strings.NewReader("x = @;")), file)
+
+	var out bytes.Buffer
+	renderer := errorhandler.Renderer{Files: fset}
+	for {
+		_, err := scanner.ReadToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			renderer.RenderError(&out, err)
+		}
+	}
+
+	expected := `error[L0001]: invalid token
+ --> bad.lox:1:5
+  |
+1 | x = @
+  |     ^ not a valid token
+`
+	if out.String() != expected {
+		t.Errorf("Unexpected output:\n%s\nExpected:\n%s", out.String(), expected)
+	}
+}
@@ -0,0 +1,168 @@
+package grammar_file_tests
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/VirajAgarwal1/lox/grammar_file"
+	"github.com/VirajAgarwal1/lox/lexer"
+)
+
+// helper: parses a grammar file and fails the test if it is not valid
+func parseGrammar(t *testing.T, src string) *grammar_file.Grammar {
+	t.Helper()
+	scanner := lexer.LexicalAnalyzer{}
+	scanner.Initialize(bufio.NewReader(strings.NewReader(src)))
+	grammar, err := grammar_file.ParseGrammar(&scanner)
+	if err != nil && err != io.EOF {
+		t.Fatalf("Could not parse the grammar: %v\n%s", err, src)
+	}
+	return grammar
+}
+
+// helper: prints the rules and directives of a grammar, ignoring how they were laid out
+func describeGrammar(grammar *grammar_file.Grammar) string {
+	lines := []string{}
+	for _, production := range grammar.Productions {
+		lines = append(lines, production.Name.Name+" -> "+grammar_file.Format_terms(production.Terms))
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n") + "\nstart " + grammar.Start + "\ntokens " + strings.Join(grammar.Tokens, " ") + "\nskip " + strings.Join(grammar.Skip, " ")
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:  "spacing and alignment",
+			input: "expr->term\nterm   ->factor((\"-\"or\"+\")factor)*\nlonger_name ->  [\"-\"]  term ;\n",
+			expected: `expr        -> term
+term        -> factor ( ( "-" or "+" ) factor )*
+longer_name -> [ "-" ] term
+`,
+		},
+		{
+			name:  "labels",
+			input: "binary -> left : term op:(\"+\"or\"-\") right:term\nlist -> items:item*[last:item]\n",
+			expected: `binary -> left:term op:( "+" or "-" ) right:term
+list   -> items:item* [ last:item ]
+`,
+		},
+		{
+			name:  "actions",
+			input: "%import \"strconv\"\nnumber -> value:\"NUMBER\"{ return $value }or\"(\"number\")\"{\n\treturn $2\n}\n",
+			expected: `%import "strconv"
+number -> value:"NUMBER" { return $value } or "(" number ")" {
+	return $2
+}
+`,
+		},
+		{
+			name:  "expressions keep their directives",
+			input: "%left   \"+\" \"-\"\n%right \"^\"\n%expr sum   unary\nunary -> \"NUMBER\"\n",
+			expected: `%left "+" "-"
+%right "^"
+%expr sum unary
+unary -> "NUMBER"
+`,
+		},
+		{
+			name:  "blank lines separate blocks",
+			input: "a -> b\n\n\n\nlong_name -> \"NUMBER\"\nb -> \"STRING\"",
+			expected: `a -> b
+
+long_name -> "NUMBER"
+b         -> "STRING"
+`,
+		},
+		{
+			name:  "directives and semicolons",
+			input: "%token NAME; %skip   \" \"  COMMENT\n%start a\na -> \"NAME\"; b -> a",
+			expected: `%token NAME
+%skip " " COMMENT
+%start a
+a -> "NAME"
+b -> a
+`,
+		},
+		{
+			name:  "optional forms",
+			input: "a -> ( b or c )? d? [ e ]+ ( f )",
+			expected: `a -> [ b or c ] d? [ e ]+ ( f )
+`,
+		},
+		{
+			name:  "long productions are wrapped before an or",
+			input: `primary -> "IDENTIFIER" or "NUMBER" or "STRING" or "true" or "false" or "nil" or "(" expression ")" or "this" or "super" "." "IDENTIFIER"`,
+			expected: `primary -> "IDENTIFIER" or "NUMBER" or "STRING" or "true" or "false" or "nil"
+        or "(" expression ")" or "this" or "super" "." "IDENTIFIER"
+`,
+		},
+		{
+			name: "comments",
+			input: `// header
+
+a -> b // after a
+// between rules
+b -> "NUMBER"
+  // about strings
+  or "STRING" // strings
+  or c // more
+c -> "nil" // last
+`,
+			expected: `// header
+
+a -> b // after a
+// between rules
+b -> "NUMBER"
+  // about strings
+  or "STRING" // strings
+  or c // more
+c -> "nil" // last
+`,
+		},
+		{
+			name:  "comments in the middle of an alternative go above the production",
+			input: "a -> b\n    // inside\n    c or d",
+			expected: `// inside
+a -> b c or d
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grammar := parseGrammar(t, tt.input)
+			got := string(grammar_file.Format(grammar))
+			if got != tt.expected {
+				t.Fatalf("Unexpected output:\n%s\nExpected:\n%s", got, tt.expected)
+			}
+
+			// The formatted grammar must mean the same, and formatting it again must not change it
+			formatted := parseGrammar(t, got)
+			if describeGrammar(formatted) != describeGrammar(grammar) {
+				t.Errorf("Formatting changed the grammar:\n%s\nExpected:\n%s", describeGrammar(formatted), describeGrammar(grammar))
+			}
+			if again := string(grammar_file.Format(formatted)); again != got {
+				t.Errorf("Formatting is not idempotent:\n%s", again)
+			}
+		})
+	}
+}
+
+func TestLoxGrammarIsFormatted(t *testing.T) {
+	content, err := os.ReadFile("../../parser/lox.grammar")
+	if err != nil {
+		t.Fatalf("Could not read lox.grammar: %v", err)
+	}
+	if got := string(grammar_file.Format(parseGrammar(t, string(content)))); got != string(content) {
+		t.Errorf("lox.grammar is not formatted, run `go run ./cmd/lox grammar fmt -w parser/lox.grammar`:\n%s", got)
+	}
+}
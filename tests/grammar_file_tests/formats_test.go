@@ -0,0 +1,381 @@
+package grammar_file_tests
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/VirajAgarwal1/lox/errorhandler"
+	"github.com/VirajAgarwal1/lox/grammar_file"
+	"github.com/VirajAgarwal1/lox/grammar_file/formats"
+	"github.com/VirajAgarwal1/lox/source"
+)
+
+type grammarFormat struct {
+	name  string
+	write func(grammar *grammar_file.Grammar) []byte
+	read  func(file *source.File) (*grammar_file.Grammar, *errorhandler.Diagnostics)
+	skip  bool // Whether `%skip` can be written in the format
+}
+
+var grammarFormats = []grammarFormat{
+	{"w3c-ebnf", formats.To_w3c_ebnf, formats.Read_w3c_ebnf, false},
+	{"abnf", formats.To_abnf, formats.Read_abnf, false},
+	{"antlr4", func(grammar *grammar_file.Grammar) []byte { return formats.To_antlr4(grammar, "Test") }, formats.Read_antlr4, true},
+}
+
+// helper: reads a grammar written in another format
+func readFormat(t *testing.T, read func(file *source.File) (*grammar_file.Grammar, *errorhandler.Diagnostics), src string) *grammar_file.Grammar {
+	t.Helper()
+	file := source.NewFileSet().AddFile("exported", []byte(src))
+	grammar, diagnostics := read(file)
+	if err := diagnostics.Err(); err != nil {
+		t.Fatalf("Could not read the grammar back: %v\n%s", err, src)
+	}
+	if diagnostics.WarningCount() > 0 {
+		t.Fatalf("Expected no warnings, got %v\n%s", diagnostics.Sorted(), src)
+	}
+	return grammar
+}
+
+// helper: `describeGrammar` with the skipped tokens in any order, or without them
+func describeExported(grammar *grammar_file.Grammar, with_skip bool) string {
+	copied := *grammar
+	copied.Skip = slices.Sorted(slices.Values(grammar.Skip))
+	if !with_skip {
+		copied.Skip = nil
+	}
+	return describeGrammar(&copied)
+}
+
+func TestFormatsRoundTrip(t *testing.T) {
+	lox_grammar, err := os.ReadFile("../../parser/lox.grammar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	grammars := map[string]string{
+		"lox": string(lox_grammar),
+		"directives and optionals": `%start program
+%token NAME "=>"
+%skip WHITESPACE NEWLINE
+declaration -> "var" "NAME" [ "=" expression ] ";"
+program     -> declaration* "EOF"
+expression  -> "NAME" "=>" expression or ( "NUMBER" or "STRING" )+ or "(" expression? ")"
+empty       -> "nil" or
+`,
+	}
+	for grammar_name, src := range grammars {
+		grammar := parseGrammar(t, src)
+		for _, format := range grammarFormats {
+			t.Run(grammar_name+" "+format.name, func(t *testing.T) {
+				exported := string(format.write(grammar))
+				read := readFormat(t, format.read, exported)
+				if expected, got := describeExported(grammar, format.skip), describeExported(read, format.skip); expected != got {
+					t.Errorf("Grammar changed through %s\nexpected:\n%s\ngot:\n%s\nexported:\n%s", format.name, expected, got, exported)
+				}
+				expected_again := exported
+				if !format.skip {
+					without_skip := *grammar
+					without_skip.Skip = nil
+					expected_again = string(format.write(&without_skip))
+				}
+				if again := string(format.write(read)); again != expected_again {
+					t.Errorf("Exporting the grammar read back gives something else\nfirst:\n%s\nsecond:\n%s", expected_again, again)
+				}
+			})
+		}
+	}
+}
+
+func TestFormatsWriteTerminals(t *testing.T) {
+	grammar := parseGrammar(t, `%token NAME
+%skip WHITESPACE
+program -> statement
+statement -> "print" "IDENTIFIER" "NUMBER" "NAME" ";" or "'" or Upper
+Upper -> "fun"
+`)
+	tests := []struct {
+		format   string
+		output   string
+		expected []string
+	}{
+		{"w3c-ebnf", string(formats.To_w3c_ebnf(grammar)), []string{
+			"/* Skipped between the other tokens: WHITESPACE */",
+			"/* Tokens which are not defined here: NAME */",
+			`statement ::= "print" IDENTIFIER NUMBER NAME ";" | "'" | Upper`,
+			"IDENTIFIER ::= \"_\"? [a-zA-Z] [a-zA-Z0-9_]*",
+			"WHITESPACE ::= [#x9#xB#xC#xD#x20]+",
+		}},
+		{"abnf", string(formats.To_abnf(grammar)), []string{
+			`statement = %x70.72.69.6E.74 IDENTIFIER NUMBER NAME ";" / "'" / Upper`,
+			"NUMBER = 1*DIGIT [ \".\" 1*DIGIT ]",
+		}},
+		{"antlr4", string(formats.To_antlr4(grammar, "Test")), []string{
+			"grammar Test;",
+			"tokens { NAME }",
+			`statement : 'print' IDENTIFIER NUMBER NAME ';' | '\'' | r_Upper ;`,
+			"r_Upper : 'fun' ;",
+			`WHITESPACE : [ \t\u000B\f\r]+ -> skip ;`,
+		}},
+	}
+	for _, tt := range tests {
+		if strings.Index(tt.output, "program") > strings.Index(tt.output, "statement") {
+			t.Errorf("Expected the start symbol first in %s\n%s", tt.format, tt.output)
+		}
+		for _, line := range tt.expected {
+			if !strings.Contains(tt.output, line+"\n") {
+				t.Errorf("Expected the %s output to have the line %q\n%s", tt.format, line, tt.output)
+			}
+		}
+	}
+	if antlr := string(formats.To_antlr4(grammar, "Test")); strings.Contains(antlr, "STRING :") {
+		t.Errorf("Expected only the tokens used by the grammar to be defined\n%s", antlr)
+	}
+}
+
+func TestFormatsLabels(t *testing.T) {
+	grammar := parseGrammar(t, `binary -> left:term ops:( "+" or "-" )* right:term? pair:( term term )
+term -> "NUMBER"
+`)
+	antlr := string(formats.To_antlr4(grammar, "Test"))
+	if !strings.Contains(antlr, "binary : left=term ops+=( '+' | '-' )* right=term? ( term term ) ;\n") {
+		t.Errorf("Expected the labels which ANTLR allows to be written\n%s", antlr)
+	}
+	read := readFormat(t, formats.Read_antlr4, antlr)
+	if got, expected := grammar_file.Format_terms(read.Rules[grammar_file.Non_terminal{Name: "binary"}]), `left:term ops:( "+" or "-" )* right:term? ( term term )`; got != expected {
+		t.Errorf("Expected the labels to be read back, got:\n%s", got)
+	}
+
+	for _, format := range grammarFormats {
+		if format.name == "antlr4" {
+			continue
+		}
+		if output := string(format.write(grammar)); strings.Contains(output, "left") {
+			t.Errorf("Expected %s to leave the labels out\n%s", format.name, output)
+		}
+	}
+}
+
+func TestFormatsActions(t *testing.T) {
+	grammar := parseGrammar(t, `sum -> term "+" term { return $1 } or term
+term -> "NUMBER" { return $1 }
+`)
+	for _, format := range grammarFormats {
+		output := string(format.write(grammar))
+		if strings.Contains(output, "return") {
+			t.Errorf("Expected %s to leave the actions out\n%s", format.name, output)
+		}
+		read := readFormat(t, format.read, output)
+		if got := grammar_file.Format_terms(read.Rules[grammar_file.Non_terminal{Name: "sum"}]); got != `term "+" term or term` {
+			t.Errorf("Expected %s to keep the rule without its action, got %s", format.name, got)
+		}
+	}
+}
+
+func TestFormatsStartSymbolFirst(t *testing.T) {
+	grammar := parseGrammar(t, "%start b\na -> \"NUMBER\"\nb -> a \"+\" a\n")
+	for _, format := range grammarFormats {
+		read := readFormat(t, format.read, string(format.write(grammar)))
+		if read.Start != "b" {
+			t.Errorf("Expected the start symbol to survive %s, got %q", format.name, read.Start)
+		}
+	}
+}
+
+func TestFormatsReadHandWritten(t *testing.T) {
+	tests := []struct {
+		name     string
+		read     func(file *source.File) (*grammar_file.Grammar, *errorhandler.Diagnostics)
+		input    string
+		expected string
+	}{
+		{
+			name: "w3c-ebnf",
+			read: formats.Read_w3c_ebnf,
+			input: `/* A comment */
+list ::= '[' ( item ( ',' item )* )? ']'
+item ::= NUMBER | list | Name
+Name ::= [a-z]+`,
+			expected: `item -> "NUMBER" or list or "Name"
+list -> "[" [ item ( "," item )* ] "]"
+start list
+tokens Name
+skip `,
+		},
+		{
+			name: "abnf",
+			read: formats.Read_abnf,
+			input: `; A comment
+list-rule = "[" [item *("," item)] "]"
+item      = NUMBER / List-Rule
+item      =/ %s"true" / %d102.97.108.115.101 / 0*1"-" 1*DIGIT
+NUMBER    = 1*DIGIT`,
+			expected: `item -> "NUMBER" or list_rule or "true" or "false" or "-"? "DIGIT"+
+list_rule -> "[" [ item ( "," item )* ] "]"
+start list_rule
+tokens DIGIT
+skip `,
+		},
+		{
+			name: "antlr4",
+			read: formats.Read_antlr4,
+			input: `parser grammar List;
+options { tokenVocab = ListLexer; }
+tokens { NAME, PLUS }
+
+/** The whole input */
+list : '[' ( item ( ',' item )* )? ']' EOF ;
+item
+    : NUMBER
+    | list
+    | NAME PLUS
+    ;
+fragment DIGIT : [0-9] ;
+NUMBER : DIGIT+ ;
+WS : [ \t]+ -> skip ;`,
+			expected: `item -> "NUMBER" or list or "NAME" "+"
+list -> "[" [ item ( "," item )* ] "]" "EOF"
+start list
+tokens NAME WS
+skip WS`,
+		},
+		{
+			name: "yacc",
+			read: formats.Read_yacc,
+			input: `%{
+#include "list.h"
+%}
+%token NAME NUMBER
+%token PLUS_ "+" WHILE_KEYWORD "while"
+%start list
+%%
+item : NUMBER
+     | list
+     | NAME "+" WHILE_KEYWORD
+     | %empty ;
+list : '[' items ']' ;
+items : item | items ',' item
+items : /* nothing */
+%%
+int main(void) { return yyparse(); }
+`,
+			expected: `item -> [ "NUMBER" or list or "NAME" "+" "while" ]
+items -> [ item or items "," item ]
+list -> "[" items "]"
+start list
+tokens NAME
+skip `,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := describeGrammar(readFormat(t, tt.read, tt.input))
+			if got != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFormatsReadErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		read     func(file *source.File) (*grammar_file.Grammar, *errorhandler.Diagnostics)
+		input    string
+		position string
+		message  string
+	}{
+		{"w3c-ebnf exception", formats.Read_w3c_ebnf, "a ::= b - c\nb ::= 'x'\nc ::= 'y'", "1:9", "exceptions"},
+		{"w3c-ebnf no rule", formats.Read_w3c_ebnf, "a ::= 'x'\n)", "2:1", "expected a rule"},
+		{"w3c-ebnf unclosed", formats.Read_w3c_ebnf, "a ::= ( 'x'", "1:12", "expected ')'"},
+		{"abnf repetition", formats.Read_abnf, "a = 2*3\"x\"", "1:5", "repetitions"},
+		{"abnf incremental", formats.Read_abnf, "a = \"x\"\nb =/ \"y\"", "2:1", "'=/'"},
+		{"abnf defined twice", formats.Read_abnf, "a = \"x\"\na = \"y\"", "2:1", "more than once"},
+		{"antlr4 no header", formats.Read_antlr4, "a : 'x' ;", "1:1", "expected 'grammar Name;'"},
+		{"antlr4 no semicolon", formats.Read_antlr4, "grammar G;\na : 'x'\nb : 'y' ;", "3:1", "expected ';'"},
+		{"antlr4 unclosed literal", formats.Read_antlr4, "grammar G;\na : 'x ;", "2:5", "never closed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := source.NewFileSet().AddFile("test", []byte(tt.input))
+			_, diagnostics := tt.read(file)
+			var grammar_err *errorhandler.Diagnostic
+			if !errors.As(diagnostics.Err(), &grammar_err) {
+				t.Fatalf("Expected a grammar error, got %v", diagnostics.Err())
+			}
+			if got := fmt.Sprintf("%d:%d", grammar_err.Position.Line, grammar_err.Position.Column); got != tt.position {
+				t.Errorf("Expected the error at %s, got %s (%s)", tt.position, got, grammar_err.Message)
+			}
+			if !strings.Contains(grammar_err.Message, tt.message) {
+				t.Errorf("Expected the message to mention %q, got %q", tt.message, grammar_err.Message)
+			}
+		})
+	}
+}
+
+func TestFormatsReadUnsupported(t *testing.T) {
+	tests := []struct {
+		name     string
+		read     func(file *source.File) (*grammar_file.Grammar, *errorhandler.Diagnostics)
+		input    string
+		expected []string
+		grammar  string
+	}{
+		{
+			name: "antlr4 dropped",
+			read: formats.Read_antlr4,
+			input: `grammar Calc;
+@header { package calc; }
+expr[int depth] returns [int value]
+    : left=term ( op+=('+' | '-') term { $value++; } )* # Sum
+    ;
+term : NUMBER ;
+catch [RecognitionException e] { throw e; }`,
+			expected: []string{"warning[G0010] 2:1", "warning[G0010] 3:5", "warning[G0010] 4:40", "warning[G0010] 4:57", "warning[G0010] 7:1"},
+			grammar: `expr -> left:term ( op:( "+" or "-" ) term )*
+term -> "NUMBER"`,
+		},
+		{
+			name: "antlr4 unsupported",
+			read: formats.Read_antlr4,
+			input: `grammar G;
+import Common;
+a : {isType()}? NAME | ~';' | . ;
+b : ( NAME )*? ;`,
+			expected: []string{"error[G0009] 2:1", "error[G0009] 3:5", "error[G0009] 3:24", "error[G0009] 3:31", "warning[G0010] 4:14"},
+			grammar: `a -> "NAME" or or
+b -> ( "NAME" )*`,
+		},
+		{
+			name: "yacc dropped and unsupported",
+			read: formats.Read_yacc,
+			input: `%left '+'
+%%
+expr : expr '+' expr { $$ = $1 + $3; }
+     | '-' expr %prec UMINUS
+     | NUMBER
+     | error ;
+%%`,
+			expected: []string{"warning[G0010] 1:1", "warning[G0010] 3:22", "warning[G0010] 4:17", "error[G0009] 6:8"},
+			grammar:  `expr -> [ expr "+" expr or "-" expr or "NUMBER" ]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grammar, diagnostics := tt.read(source.NewFileSet().AddFile("test", []byte(tt.input)))
+			found := []string{}
+			for _, diag := range diagnostics.Sorted() {
+				found = append(found, fmt.Sprintf("%s[%s] %d:%d", diag.Severity, diag.Code, diag.Position.Line, diag.Position.Column))
+			}
+			if strings.Join(found, ", ") != strings.Join(tt.expected, ", ") {
+				t.Errorf("Unexpected diagnostics:\n%v\nExpected:\n%v", found, tt.expected)
+			}
+			if got := strings.SplitN(describeGrammar(grammar), "\nstart", 2)[0]; got != tt.grammar {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.grammar, got)
+			}
+		})
+	}
+}
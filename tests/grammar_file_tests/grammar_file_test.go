@@ -0,0 +1,136 @@
+package grammar_file_tests
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/VirajAgarwal1/lox/grammar_file"
+	"github.com/VirajAgarwal1/lox/lexer"
+	"github.com/VirajAgarwal1/lox/lexer/dfa"
+)
+
+func TestLookupToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected dfa.TokenType
+		found    bool
+	}{
+		{"(", dfa.LEFT_PAREN, true},
+		{"NUMBER", dfa.NUMBER, true},
+		{"while", dfa.WHILE, true},
+		{"?", dfa.QUESTION, true},
+		{" ", dfa.WHITESPACE, true},
+		{"WHITESPACE", dfa.WHITESPACE, true},
+		{"NEWLINE", dfa.NEWLINE, true},
+		{"\\n", dfa.NEWLINE, true},
+		{"LEFT_PAREN", "", false},
+		{"NAME", "", false},
+	}
+
+	for _, tt := range tests {
+		got, found := grammar_file.Lookup_token(tt.name)
+		if got != tt.expected || found != tt.found {
+			t.Errorf("Lookup_token(%q) = %q, %v, expected %q, %v", tt.name, got, found, tt.expected, tt.found)
+		}
+	}
+
+	if got, found := grammar_file.Resolve_token("NAME", []string{"NAME"}); !found || got != dfa.TokenType("NAME") {
+		t.Errorf("Expected a declared token to resolve to its own name, got %q, %v", got, found)
+	}
+}
+
+func TestTokenTypeCode(t *testing.T) {
+	tests := map[dfa.TokenType]string{
+		dfa.LEFT_PAREN:        "dfa.LEFT_PAREN",
+		dfa.NEWLINE:           "dfa.NEWLINE",
+		dfa.AND:               "dfa.AND",
+		dfa.TokenType("NAME"): `dfa.TokenType("NAME")`,
+		dfa.TokenType("a\"b"): `dfa.TokenType("a\"b")`,
+	}
+	for token, expected := range tests {
+		if got := grammar_file.Token_type_code(token); got != expected {
+			t.Errorf("Token_type_code(%q) = %s, expected %s", token, got, expected)
+		}
+	}
+
+	// Every token type of the lexer must have a name, else generated parsers could not refer to it
+	for _, token := range dfa.TokensList {
+		if _, found := grammar_file.Token_names[token]; !found {
+			t.Errorf("Token type %q has no name", token)
+		}
+	}
+}
+
+func TestExpandAction(t *testing.T) {
+	labels := map[string]bool{"left": false, "items": true}
+	tests := []struct {
+		code     string
+		expected string
+	}{
+		{code: " return $1 ", expected: " return values.At(0) "},
+		{code: "return $left, $items, $12", expected: `return values.Get("left"), values.All("items"), values.At(11)`},
+		{code: "s := \"$1\" + `$left` + string('$') // $2\n/* $3 */ return $2", expected: "s := \"$1\" + `$left` + string('$') // $2\n/* $3 */ return values.At(1)"},
+	}
+	for _, tt := range tests {
+		got, err := grammar_file.Expand_action(tt.code, labels)
+		if err != nil || got != tt.expected {
+			t.Errorf("Expand_action(%q) = %q, %v, expected %q", tt.code, got, err, tt.expected)
+		}
+	}
+
+	for _, invalid := range []string{"return $0", "return $right", "return $", "return $ 1"} {
+		if _, err := grammar_file.Expand_action(invalid, labels); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestAlternativeActions(t *testing.T) {
+	grammar := parseGrammar(t, `a -> x:b "+" c { return $x } or b or ( b c )* { return nil }
+b -> "NUMBER"
+c -> "STRING"
+`)
+	terms := grammar.Rules[grammar_file.Non_terminal{Name: "a"}]
+	actions := grammar_file.Alternative_actions(terms)
+	if len(actions) != 3 || actions[0] == nil || actions[0].Code != " return $x " || actions[1] != nil || actions[2] == nil {
+		t.Errorf("Unexpected actions %v", actions)
+	}
+	labels := grammar_file.Alternative_labels(terms)
+	if _, found := labels[0]["x"]; len(labels) != 3 || !found || len(labels[1]) != 0 {
+		t.Errorf("Unexpected labels %v", labels)
+	}
+	if got := grammar_file.Format_terms(grammar_file.Without_actions(terms)); got != `x:b "+" c or b or ( b c )*` {
+		t.Errorf("Unexpected terms without actions %s", got)
+	}
+	if !grammar.Has_actions() {
+		t.Errorf("Expected the grammar to have actions")
+	}
+}
+
+func TestOrderedRulesAndPositions(t *testing.T) {
+	scanner := lexer.LexicalAnalyzer{}
+	scanner.Initialize(bufio.NewReader(strings.NewReader("b -> \"NUMBER\"\na -> b ( \",\" b )*\nb -> \"STRING\"\n")))
+	grammar, err := grammar_file.ParseGrammar(&scanner)
+	if err != nil && err != io.EOF {
+		t.Fatalf("Could not parse the grammar: %v", err)
+	}
+
+	rules := grammar.Ordered_rules()
+	if len(rules) != 2 || rules[0].Name.Name != "b" || rules[1].Name.Name != "a" {
+		t.Fatalf("Unexpected rules %v", rules)
+	}
+	if position := grammar.Position(rules[0].Span); position.Line != 3 || position.Column != 1 {
+		t.Errorf("Expected the rule to be at its last definition 3:1, got %v", position)
+	}
+
+	star := rules[1].Terms[1].(*grammar_file.Star)
+	if position := grammar.Position(grammar.Locations[star]); position.Line != 2 || position.Column != 8 {
+		t.Errorf("Expected the starred bracket at 2:8, got %v", position)
+	}
+	comma := star.Content.(*grammar_file.Bracket).Contents[0]
+	if position := grammar.Position(grammar.Locations[comma]); position.Line != 2 || position.Column != 10 {
+		t.Errorf("Expected the comma at 2:10, got %v", position)
+	}
+}
@@ -0,0 +1,112 @@
+package grammar_file_tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/VirajAgarwal1/lox/grammar_file"
+)
+
+// helper: prints the rules of a grammar in order, one per line
+func describeRules(grammar *grammar_file.Grammar) string {
+	lines := []string{}
+	for _, rule := range grammar.Ordered_rules() {
+		lines = append(lines, rule.Name.Name+" -> "+grammar_file.Format_terms(rule.Terms))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestEliminateLeftRecursion(t *testing.T) {
+	grammar := parseGrammar(t, `expr -> left:expr "+" term or expr "-" term or term or "(" expr ")"
+term -> "NUMBER"`)
+	rewritten, err := grammar_file.Eliminate_left_recursion(grammar)
+	if err != nil {
+		t.Fatalf("Eliminate_left_recursion failed: %v", err)
+	}
+	expected := `expr -> ( term or "(" expr ")" ) ( "+" term or "-" term )*
+term -> "NUMBER"`
+	if got := describeRules(rewritten); got != expected {
+		t.Errorf("Unexpected rules\n%s\nexpected\n%s", got, expected)
+	}
+	if !strings.HasPrefix(describeRules(grammar), `expr -> left:expr "+" term`) {
+		t.Errorf("Expected the grammar given to be left as it was\n%s", describeRules(grammar))
+	}
+
+	left_recursion := rewritten.Left_recursion_of("expr")
+	if left_recursion == nil || rewritten.Left_recursion_of("term") != nil || len(rewritten.Left_recursions) != 1 {
+		t.Fatalf("Expected only expr to be recorded, got %v", rewritten.Left_recursions)
+	}
+	if len(left_recursion.Bases) != 2 || left_recursion.Bases[0].Alternative != 2 || left_recursion.Bases[1].Alternative != 3 {
+		t.Errorf("Unexpected bases %+v", left_recursion.Bases)
+	}
+	if len(left_recursion.Tails) != 2 || left_recursion.Tails[0].Label != "left" || left_recursion.Tails[1].Label != "" || left_recursion.Tails[1].Alternative != 1 {
+		t.Errorf("Unexpected tails %+v", left_recursion.Tails)
+	}
+	if got := grammar_file.Format_terms(left_recursion.Tails[0].Terms); got != `"+" term` {
+		t.Errorf("Expected the tail without the rule it starts with, got %s", got)
+	}
+	if got := grammar_file.Format_terms(rewritten.Written_rule(grammar_file.Non_terminal{Name: "expr"})); !strings.HasPrefix(got, "left:expr") {
+		t.Errorf("Expected the rule as written, got %s", got)
+	}
+
+	// Nothing to rewrite, the grammar is given back as it is
+	plain := parseGrammar(t, `list -> "NUMBER" ( "," "NUMBER" )*`)
+	if same, err := grammar_file.Eliminate_left_recursion(plain); err != nil || same != plain {
+		t.Errorf("Expected the same grammar back, got %v", err)
+	}
+}
+
+func TestEliminateIndirectLeftRecursion(t *testing.T) {
+	// a starts with b which starts with a: b, coming later, gets the alternatives of a in place of it
+	grammar := parseGrammar(t, `a -> b "x" or "y"
+b -> a "z" or "w"
+c -> a`)
+	if got := grammar_file.Left_recursive_rules(grammar.Rules); strings.Join(got, " ") != "a b" {
+		t.Errorf("Expected a and b to be left-recursive, got %v", got)
+	}
+	rewritten, err := grammar_file.Eliminate_left_recursion(grammar)
+	if err != nil {
+		t.Fatalf("Eliminate_left_recursion failed: %v", err)
+	}
+	expected := `a -> b "x" or "y"
+b -> ( "y" "z" or "w" ) ( "x" "z" )*
+c -> a`
+	if got := describeRules(rewritten); got != expected {
+		t.Errorf("Unexpected rules\n%s\nexpected\n%s", got, expected)
+	}
+	if got := grammar_file.Left_recursive_rules(rewritten.Rules); len(got) != 0 {
+		t.Errorf("Expected no left recursion left, got %v", got)
+	}
+}
+
+func TestEliminateLeftRecursionErrors(t *testing.T) {
+	tests := []struct {
+		grammar string
+		error   string
+	}{
+		{`a -> a "x"`, "every alternative of 'a' starts with 'a'"},
+		{`a -> a or "x"`, "is nothing but 'a' itself"},
+		{`a -> a "x" { return 1 } or "y"`, "add '%reassociate'"},
+		{"%reassociate\na -> b \"x\" { return 1 } or \"y\"\nb -> a \"z\" or \"w\"", "which cannot be put in its place"},
+		{`a -> ( a "x" ) or "y"`, "cannot be rewritten, it is inside a bracket"},
+	}
+	for _, tt := range tests {
+		_, err := grammar_file.Eliminate_left_recursion(parseGrammar(t, tt.grammar))
+		if err == nil || !strings.Contains(err.Error(), tt.error) {
+			t.Errorf("Expected an error containing %q for\n%s\ngot %v", tt.error, tt.grammar, err)
+		}
+	}
+
+	// With %reassociate, the actions are kept for the rule as written
+	grammar := parseGrammar(t, "%reassociate\na -> a \"x\" { return 1 } or \"y\"")
+	if !grammar.Reassociate {
+		t.Fatalf("Expected %%reassociate to be read")
+	}
+	rewritten, err := grammar_file.Eliminate_left_recursion(grammar)
+	if err != nil {
+		t.Fatalf("Eliminate_left_recursion failed: %v", err)
+	}
+	if !rewritten.Has_actions() {
+		t.Errorf("Expected the actions of the rule as written to be found")
+	}
+}
@@ -0,0 +1,209 @@
+package grammar_file_tests
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/VirajAgarwal1/lox/grammar_file/railroad"
+)
+
+// helper: checks that an SVG is well formed XML and that every box and track is drawn inside it. Returns the texts of the boxes, in order.
+func checkDiagram(t *testing.T, svg string) []string {
+	t.Helper()
+	width, height := 0.0, 0.0
+	texts := []string{}
+	inside := func(x float64, y float64) {
+		if x < 0 || y < 0 || x > width || y > height {
+			t.Errorf("Point (%v, %v) is outside of the %vx%v diagram\n%s", x, y, width, height, svg)
+		}
+	}
+
+	decoder := xml.NewDecoder(strings.NewReader(svg))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Diagram is not well formed: %v\n%s", err, svg)
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			attrs := map[string]string{}
+			for _, attr := range token.Attr {
+				attrs[attr.Name.Local] = attr.Value
+			}
+			number := func(name string) float64 {
+				value, _ := strconv.ParseFloat(attrs[name], 64)
+				return value
+			}
+			switch token.Name.Local {
+			case "svg":
+				width, height = number("width"), number("height")
+			case "rect":
+				inside(number("x"), number("y"))
+				inside(number("x")+number("width"), number("y")+number("height"))
+			case "path":
+				for _, point := range pathPoints(t, attrs["d"]) {
+					inside(point[0], point[1])
+				}
+			case "text":
+				text, _ := decoder.Token()
+				if data, is_data := text.(xml.CharData); is_data {
+					texts = append(texts, string(data))
+				}
+			}
+		}
+	}
+	return texts
+}
+
+// helper: follows the commands of a path, as the diagrams write them, and gives the points it goes through
+func pathPoints(t *testing.T, d string) [][2]float64 {
+	t.Helper()
+	fields := regexp.MustCompile(`[MHva]|-?[0-9.]+`).FindAllString(d, -1)
+	points := [][2]float64{}
+	x, y := 0.0, 0.0
+	next := func(i *int) float64 {
+		*i++
+		value, err := strconv.ParseFloat(fields[*i], 64)
+		if err != nil {
+			t.Fatalf("Bad number in path %q", d)
+		}
+		return value
+	}
+	for i := 0; i < len(fields); i++ {
+		switch fields[i] {
+		case "M":
+			x, y = next(&i), next(&i)
+		case "H":
+			x = next(&i)
+		case "v":
+			y += next(&i)
+		case "a":
+			for range 5 { // Radii, rotation and flags
+				next(&i)
+			}
+			x, y = x+next(&i), y+next(&i)
+		default:
+			t.Fatalf("Unexpected command %q in path %q", fields[i], d)
+		}
+		points = append(points, [2]float64{x, y})
+	}
+	return points
+}
+
+func TestRailroadDiagram(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		texts []string
+	}{
+		{"sequence", `a -> "(" b ")"`, []string{"(", "b", ")"}},
+		{"choice", `a -> "NUMBER" or "STRING" or b`, []string{"NUMBER", "STRING", "b"}},
+		{"empty alternative", `a -> "NUMBER" or`, []string{"NUMBER"}},
+		{"loops", `a -> b* "," c+`, []string{"b", ",", "c"}},
+		{"optional", `a -> [ "-" ] b? "!"`, []string{"-", "b", "!"}},
+		{"nested", `a -> ( ( "-" or "+" ) ( b or [ c "," ]+ ) )* d`, []string{"-", "+", "b", "c", ",", "d"}},
+		{"escaped", `a -> "<" b "&&"`, []string{"<", "b", "&&"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := parseGrammar(t, tt.input).Ordered_rules()[0]
+			svg := railroad.Diagram(rule.Terms, func(name string) string { return "#" + name })
+
+			texts := checkDiagram(t, svg)
+			if strings.Join(texts, " ") != strings.Join(tt.texts, " ") {
+				t.Errorf("Expected the boxes %q, got %q", tt.texts, texts)
+			}
+			for _, text := range tt.texts {
+				if text != "" && text[0] >= 'a' && text[0] <= 'z' && !strings.Contains(svg, `<a href="#`+text+`">`) {
+					t.Errorf("Expected a link to the diagram of %q\n%s", text, svg)
+				}
+			}
+		})
+	}
+}
+
+func TestRailroadChoiceIsTallerThanItsAlternatives(t *testing.T) {
+	one := railroad.Diagram(parseGrammar(t, `a -> "NUMBER"`).Ordered_rules()[0].Terms, func(string) string { return "" })
+	three := railroad.Diagram(parseGrammar(t, `a -> "NUMBER" or "STRING" or "nil"`).Ordered_rules()[0].Terms, func(string) string { return "" })
+	size := regexp.MustCompile(`width="(\d+)" height="(\d+)"`)
+	one_height, _ := strconv.Atoi(size.FindStringSubmatch(one)[2])
+	three_height, _ := strconv.Atoi(size.FindStringSubmatch(three)[2])
+	if three_height < one_height+2*24 {
+		t.Errorf("Expected the 3 alternatives to be stacked, got heights %d and %d", one_height, three_height)
+	}
+}
+
+func TestRailroadHtml(t *testing.T) {
+	grammar := parseGrammar(t, `
+expression -> term ( "+" term )*
+term       -> "NUMBER" or "(" expression ")" or missing
+`)
+	var page bytes.Buffer
+	if err := railroad.Write_html(&page, grammar, "test.grammar"); err != nil {
+		t.Fatal(err)
+	}
+	html := page.String()
+
+	if count := strings.Count(html, "<svg "); count != 2 {
+		t.Errorf("Expected one diagram per non-terminal, got %d", count)
+	}
+	if strings.Index(html, `id="rule-expression"`) > strings.Index(html, `id="rule-term"`) {
+		t.Errorf("Expected the diagrams in the order of the grammar file")
+	}
+	for _, href := range regexp.MustCompile(`href="#([^"]+)"`).FindAllStringSubmatch(html, -1) {
+		if !strings.Contains(html, `id="`+href[1]+`"`) {
+			t.Errorf("Link to %q has no target", href[1])
+		}
+	}
+	for _, expected := range []string{
+		`<a href="#rule-term"><g class="nonterminal">`,
+		`<a href="#rule-expression"><g class="nonterminal">`,
+		`<p class="used-by">Used by: <a href="#rule-expression">expression</a></p>`,
+		`<title>test.grammar</title>`,
+	} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("Expected the page to contain %q", expected)
+		}
+	}
+	if strings.Contains(html, `href="#rule-missing"`) {
+		t.Errorf("Expected no link for the undefined non-terminal 'missing'")
+	}
+	for _, svg := range regexp.MustCompile(`(?s)<svg .*?</svg>`).FindAllString(html, -1) {
+		checkDiagram(t, svg)
+	}
+}
+
+func TestRailroadSvgFiles(t *testing.T) {
+	src, err := os.ReadFile("../../parser/lox.grammar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	grammar := parseGrammar(t, string(src))
+	dir := t.TempDir()
+	if err := railroad.Write_svgs(dir, grammar); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, rule := range grammar.Ordered_rules() {
+		svg, err := os.ReadFile(filepath.Join(dir, rule.Name.Name+".svg"))
+		if err != nil {
+			t.Fatalf("Expected a diagram for %q: %v", rule.Name.Name, err)
+		}
+		checkDiagram(t, string(svg))
+		for _, href := range regexp.MustCompile(`href="([^"]+)"`).FindAllStringSubmatch(string(svg), -1) {
+			if _, err := os.Stat(filepath.Join(dir, href[1])); err != nil {
+				t.Errorf("%s.svg links to %q which was not written", rule.Name.Name, href[1])
+			}
+		}
+	}
+}
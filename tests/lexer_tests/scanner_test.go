@@ -454,6 +454,24 @@ func TestMaximalMunching(t *testing.T) {
 }
 
 // Test invalid token handling
+func TestGrammarNotationTokens(t *testing.T) {
+	// The tokens of the grammar file notation are lexed in Lox source too, it is up to the parser to reject them
+	tokens, errors := scanAllTokens(t, "a ? [b] % c : d")
+	if len(errors) != 0 {
+		t.Errorf("Unexpected errors: %v", errors)
+	}
+	got := []dfa.TokenType{}
+	for _, token := range tokens {
+		if token.TypeOfToken != dfa.WHITESPACE {
+			got = append(got, token.TypeOfToken)
+		}
+	}
+	expected := []dfa.TokenType{dfa.IDENTIFIER, dfa.QUESTION, dfa.LEFT_BRACKET, dfa.IDENTIFIER, dfa.RIGHT_BRACKET, dfa.PERCENT, dfa.IDENTIFIER, dfa.COLON, dfa.IDENTIFIER, dfa.EOF}
+	if fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
 func TestInvalidTokenHandling(t *testing.T) {
 	testCases := []string{
 		"@", // Invalid character
@@ -664,6 +682,43 @@ func TestComplexExpression(t *testing.T) {
 	}
 }
 
+func TestReadEnclosed(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		next     dfa.TokenType
+	}{
+		{name: "nested braces", input: "{ if a { b() } }.", expected: " if a { b() } ", next: dfa.DOT},
+		{name: "braces in strings", input: "{ s := \"}\" + `{` + string('}') }+", expected: " s := \"}\" + `{` + string('}') ", next: dfa.PLUS},
+		{name: "escaped quote", input: "{ \"\\\"}\" }-", expected: " \"\\\"}\" ", next: dfa.MINUS},
+		{name: "braces in comments", input: "{ a // }\n /* } */ }*", expected: " a // }\n /* } */ ", next: dfa.STAR},
+		{name: "division is not a comment", input: "{ a / b }/", expected: " a / b ", next: dfa.SLASH},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := createScannerFromString(tt.input)
+			if token, err := scanner.ReadToken(); err != nil || token.TypeOfToken != dfa.LEFT_BRACE {
+				t.Fatalf("Expected '{' first, got %v %v", token, err)
+			}
+			text, end, err := scanner.ReadEnclosed('{', '}')
+			if err != nil || text != tt.expected {
+				t.Errorf("Expected %q, got %q %v", tt.expected, text, err)
+			}
+			token, err := scanner.ReadToken()
+			if err != nil || token.TypeOfToken != tt.next || token.Pos != end {
+				t.Errorf("Expected %v to be read at %v after the braces, got %v %v", tt.next, end, token, err)
+			}
+		})
+	}
+
+	scanner := createScannerFromString("{ a { b }")
+	scanner.ReadToken()
+	if _, _, err := scanner.ReadEnclosed('{', '}'); err != io.ErrUnexpectedEOF {
+		t.Errorf("Expected io.ErrUnexpectedEOF for a brace which is never closed, got %v", err)
+	}
+}
+
 // Benchmark test for performance
 func BenchmarkScanner(b *testing.B) {
 	input := strings.Repeat(`var x = 123.45;
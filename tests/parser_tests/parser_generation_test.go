@@ -3,11 +3,18 @@ package parser_tests
 import (
 	"bufio"
 	"bytes"
+	"errors"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/VirajAgarwal1/lox/errorhandler"
+	"github.com/VirajAgarwal1/lox/grammar_file"
+	"github.com/VirajAgarwal1/lox/lexer"
 	"github.com/VirajAgarwal1/lox/parser/grammar"
+	"github.com/VirajAgarwal1/lox/tests/test_utils"
 )
 
 // Test helper functions
@@ -264,7 +271,7 @@ func TestWriteParseFunctionsForNonTerminals(t *testing.T) {
 
 			// Check that all expected functions are present
 			for _, expected := range tc.expected {
-				funcSignature := "func " + expected + "(buf *lexer.BufferedLexicalAnalyzer) ([]Node, bool, error)"
+				funcSignature := "func " + expected + "(buf *lexer.BufferedLexer) ([]Node, bool, error)"
 				if !strings.Contains(output, funcSignature) {
 					t.Errorf("Expected function signature %s not found in output", funcSignature)
 				}
@@ -404,6 +411,40 @@ func TestGeneratePlusCode(t *testing.T) {
 	}
 }
 
+func TestGenerateOptionalCode(t *testing.T) {
+	testCases := []struct {
+		name     string
+		term     grammar.Optional
+		endStr   string
+		expected string
+	}{
+		{
+			name:     "Optional with grammar.Terminal",
+			term:     grammar.Optional{Content: &grammar.Terminal{Content: []rune("NUMBER")}},
+			endStr:   "(buf)",
+			expected: "zeroOrOne(\nmatchToken(dfa.NUMBER),\n)(buf)",
+		},
+		{
+			name: "Optional with grammar.Bracket",
+			term: grammar.Optional{Content: &grammar.Bracket{Contents: []grammar.Generic_grammar_term{
+				&grammar.Terminal{Content: []rune("-")},
+				&grammar.Non_terminal{Name: "expr"},
+			}}},
+			endStr:   ",\n",
+			expected: "zeroOrOne(\nsequence(\nmatchToken(dfa.MINUS),\nParse_expr,\n),\n),\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := grammar.GenerateOptionalCode(tc.term, tc.endStr)
+			if result != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, result)
+			}
+		})
+	}
+}
+
 func TestDetectOrInDescription(t *testing.T) {
 	testCases := []struct {
 		name        string
@@ -589,6 +630,7 @@ func TestGenerateGrammarParserFile(t *testing.T) {
 				"func choice",
 				"func zeroOrMore",
 				"func oneOrMore",
+				"func zeroOrOne",
 			}
 
 			for _, component := range expectedComponents {
@@ -618,6 +660,321 @@ func TestGenerateGrammarParserFile(t *testing.T) {
 	}
 }
 
+func TestGenerateGrammarParserFileKeepsParserOnError(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "generated_parser.go")
+	if err := os.WriteFile(filePath, []byte("package parser\n"), 0644); err != nil {
+		t.Fatalf("Could not write the parser: %v", err)
+	}
+
+	// The action uses a label which is not there
+	invalidGrammar := map[grammar.Non_terminal][]grammar.Generic_grammar_term{
+		{Name: "number"}: {
+			&grammar.Terminal{Content: []rune("NUMBER")},
+			&grammar.Action{Code: " return $right "},
+		},
+	}
+	if err := grammar.GenerateGrammarParserFile(invalidGrammar, filePath); err == nil {
+		t.Fatalf("Expected an error for an action using a label which is not there")
+	}
+	if content, err := os.ReadFile(filePath); err != nil || string(content) != "package parser\n" {
+		t.Errorf("Expected the parser to be left as it was, got %q %v", content, err)
+	}
+}
+
+func TestGenerateGrammarParserFileValidatesGrammar(t *testing.T) {
+	generate := func(source string) error {
+		t.Helper()
+		scanner := lexer.LexicalAnalyzer{}
+		scanner.Initialize(bufio.NewReader(strings.NewReader(source)))
+		parsed, err := grammar_file.ParseGrammar(&scanner)
+		if err != nil && err != io.EOF {
+			t.Fatalf("Could not parse the grammar: %v", err)
+		}
+		return grammar.GenerateGrammarParserFileForGrammar(parsed, filepath.Join(t.TempDir(), "generated_parser.go"))
+	}
+
+	// A terminal which is not a token used to become a token type which the lexer never gives
+	var diag *errorhandler.Diagnostic
+	if err := generate(`list -> "(" "NAME" ")"`); !errors.As(err, &diag) || diag.Code != errorhandler.CodeUnknownTerminal {
+		t.Errorf("Expected an unknown terminal error, got %v", err)
+	}
+	if err := generate(`list -> "(" item ")"`); !errors.As(err, &diag) || diag.Code != errorhandler.CodeUndefinedNonTerminal {
+		t.Errorf("Expected an undefined non-terminal error, got %v", err)
+	}
+	if err := generate("%skip SPACES\nlist -> \"(\" \")\""); err == nil || !strings.Contains(err.Error(), "Unknown token 'SPACES' in %skip") {
+		t.Errorf("Expected an unknown %%skip token error, got %v", err)
+	}
+
+	// The rules given without a grammar file are checked too
+	if err := grammar.GenerateGrammarParserFile(map[grammar.Non_terminal][]grammar.Generic_grammar_term{
+		{Name: "list"}: {&grammar.Terminal{Content: []rune("NAME")}},
+	}, filepath.Join(t.TempDir(), "generated_parser.go")); !errors.As(err, &diag) || diag.Code != errorhandler.CodeUnknownTerminal {
+		t.Errorf("Expected an unknown terminal error, got %v", err)
+	}
+
+	// Declared with %token, it is a token type of its own name
+	if err := generate("%token NAME\nlist -> \"(\" \"NAME\" \")\""); err != nil {
+		t.Errorf("Expected the declared token to be accepted, got %v", err)
+	}
+}
+
+func TestGeneratedParserIsUpToDate(t *testing.T) {
+	content, err := os.ReadFile("../../parser/lox.grammar")
+	if err != nil {
+		t.Fatalf("Could not read lox.grammar: %v", err)
+	}
+	scanner := lexer.LexicalAnalyzer{}
+	scanner.Initialize(bufio.NewReader(strings.NewReader(string(content))))
+	loxGrammar, err := grammar_file.ParseGrammar(&scanner)
+	if err != nil && err != io.EOF {
+		t.Fatalf("Could not parse lox.grammar: %v", err)
+	}
+
+	filePath := filepath.Join(t.TempDir(), "generated_parser.go")
+	if err := grammar.GenerateGrammarParserFileForGrammar(loxGrammar, filePath); err != nil {
+		t.Fatalf("GenerateGrammarParserFileForGrammar failed: %v", err)
+	}
+	generated, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Could not read the generated file: %v", err)
+	}
+	checkedIn, err := os.ReadFile("../../parser/generated_parser.go")
+	if err != nil {
+		t.Fatalf("Could not read parser/generated_parser.go: %v", err)
+	}
+	if !bytes.Equal(generated, checkedIn) {
+		t.Errorf("parser/generated_parser.go is not what parser/lox.grammar generates, generate it again rather than editing it")
+	}
+}
+
+func TestGenerateLabelledFields(t *testing.T) {
+	// binary -> left:term ( ops:( "+" or "-" ) rights:term )* or "(" inner:term? ")"
+	labelledGrammar := map[grammar.Non_terminal][]grammar.Generic_grammar_term{
+		{Name: "binary"}: {
+			&grammar.Labelled{Label: "left", Content: &grammar.Non_terminal{Name: "term"}},
+			&grammar.Star{Content: &grammar.Bracket{Contents: []grammar.Generic_grammar_term{
+				&grammar.Labelled{Label: "ops", Content: &grammar.Bracket{Contents: []grammar.Generic_grammar_term{
+					&grammar.Terminal{Content: []rune("+")},
+					&grammar.Or{},
+					&grammar.Terminal{Content: []rune("-")},
+				}}},
+				&grammar.Labelled{Label: "rights", Content: &grammar.Non_terminal{Name: "term"}},
+			}}},
+			&grammar.Or{},
+			&grammar.Terminal{Content: []rune("(")},
+			&grammar.Labelled{Label: "inner", Content: &grammar.Optional{Content: &grammar.Non_terminal{Name: "term"}}},
+			&grammar.Terminal{Content: []rune(")")},
+		},
+		{Name: "term"}: {
+			&grammar.Labelled{Label: "digits", Content: &grammar.Plus{Content: &grammar.Terminal{Content: []rune("NUMBER")}}},
+		},
+	}
+
+	var buf bytes.Buffer
+	writer := bufio.NewWriter(&buf)
+	if err := grammar.GenerateGrammarOutput(writer, labelledGrammar); err != nil {
+		t.Fatalf("GenerateGrammarOutput failed: %v", err)
+	}
+	writer.Flush()
+	output := buf.String()
+
+	for _, expected := range []string{
+		"func labelled(label string",
+		"func unlabel(args []Node",
+		"type Grammar_binary struct {\n\tArguments []Node\n\tLeft      Node\n\tOps       []Node\n\tRights    []Node\n\tInner     Node\n}",
+		"type Grammar_term struct {\n\tArguments []Node\n\tDigits    []Node\n}",
+		"labelled(\"left\",",
+		"output.Left = firstNode(nodes)",
+		"output.Ops = append(output.Ops, nodes...)",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected the generated parser to contain %q\n%s", expected, output)
+		}
+	}
+
+	// Grammars without labels do not need the helpers
+	buf.Reset()
+	writer = bufio.NewWriter(&buf)
+	grammar.GenerateGrammarOutput(writer, createTestGrammar1())
+	writer.Flush()
+	if output := buf.String(); strings.Contains(output, "func unlabel") || !strings.Contains(output, "output.Arguments = args") {
+		t.Errorf("Expected a grammar without labels to be generated as before\n%s", output)
+	}
+}
+
+func TestLabelFieldsErrors(t *testing.T) {
+	for name, terms := range map[string][]grammar.Generic_grammar_term{
+		"reserved": {&grammar.Labelled{Label: "arguments", Content: &grammar.Non_terminal{Name: "a"}}},
+		"same field": {
+			&grammar.Labelled{Label: "value", Content: &grammar.Non_terminal{Name: "a"}},
+			&grammar.Labelled{Label: "_value", Content: &grammar.Non_terminal{Name: "b"}},
+		},
+	} {
+		if _, err := grammar.LabelFields(terms); err == nil {
+			t.Errorf("Expected an error for the %s label", name)
+		}
+	}
+}
+
+func TestGenerateActions(t *testing.T) {
+	// sum -> left:number "+" number { return $left } or number
+	// number -> "NUMBER" { return $1 }
+	actionGrammar := map[grammar.Non_terminal][]grammar.Generic_grammar_term{
+		{Name: "sum"}: {
+			&grammar.Labelled{Label: "left", Content: &grammar.Non_terminal{Name: "number"}},
+			&grammar.Terminal{Content: []rune("+")},
+			&grammar.Non_terminal{Name: "number"},
+			&grammar.Action{Code: " return $left "},
+			&grammar.Or{},
+			&grammar.Non_terminal{Name: "number"},
+		},
+		{Name: "number"}: {
+			&grammar.Terminal{Content: []rune("NUMBER")},
+			&grammar.Action{Code: " return $1 "},
+		},
+	}
+
+	var buf bytes.Buffer
+	writer := bufio.NewWriter(&buf)
+	if err := grammar.GenerateGrammarOutput(writer, actionGrammar); err != nil {
+		t.Fatalf("GenerateGrammarOutput failed: %v", err)
+	}
+	writer.Flush()
+	output := buf.String()
+
+	for _, expected := range []string{
+		"func labelled(label string",
+		"type ActionValue struct {",
+		"func action(code func(values ActionValues) any",
+		"choice(\n\taction(func(values ActionValues) any { return values.Get(\"left\") },\n\t\tsequence(\n",
+		"\tParse_number,\n)(buf)",
+		"action(func(values ActionValues) any { return values.At(0) },\n\tmatchToken(dfa.NUMBER),\n)(buf)",
+		"if value, isResult := resultOf(args); isResult {\n\t\treturn []Node{&ActionValue{Value: value}}, true, nil\n\t}",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected the generated parser to contain %q\n%s", expected, output)
+		}
+	}
+
+	// Grammars without actions do not need the helpers
+	buf.Reset()
+	writer = bufio.NewWriter(&buf)
+	grammar.GenerateGrammarOutput(writer, createTestGrammar1())
+	writer.Flush()
+	if strings.Contains(buf.String(), "func action(") || strings.Contains(buf.String(), "resultOf") {
+		t.Errorf("Expected a grammar without actions to be generated as before\n%s", buf.String())
+	}
+
+	actionGrammar[grammar.Non_terminal{Name: "number"}][1] = &grammar.Action{Code: " return $right "}
+	if err := grammar.GenerateGrammarOutput(bufio.NewWriter(&bytes.Buffer{}), actionGrammar); err == nil {
+		t.Errorf("Expected an error for an action using a label which is not there")
+	}
+}
+
+func TestGenerateExpressions(t *testing.T) {
+	scanner := lexer.LexicalAnalyzer{}
+	scanner.Initialize(bufio.NewReader(strings.NewReader(`%nonassoc "==" "!="
+%left "+" "-"
+%right "="
+%expr binary unary
+unary -> "-" unary or "NUMBER"
+`)))
+	expressionGrammar, err := grammar_file.ParseGrammar(&scanner)
+	if err != nil && err != io.EOF {
+		t.Fatalf("Could not parse the grammar: %v", err)
+	}
+
+	filePath := filepath.Join(t.TempDir(), "generated_parser.go")
+	if err := grammar.GenerateGrammarParserFileForGrammar(expressionGrammar, filePath); err != nil {
+		t.Fatalf("GenerateGrammarParserFileForGrammar failed: %v", err)
+	}
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Could not read the generated file: %v", err)
+	}
+	output := string(content)
+
+	for _, expected := range []string{
+		"type BinaryExpression struct {",
+		"func operatorPrecedence(operand",
+		"var operators_binary = []binaryOperator{\n" +
+			"\t{token: dfa.EQUAL_EQUAL, power: 1, associativity: 'n'},\n" +
+			"\t{token: dfa.BANG_EQUAL, power: 1, associativity: 'n'},\n" +
+			"\t{token: dfa.PLUS, power: 2, associativity: 'l'},\n" +
+			"\t{token: dfa.MINUS, power: 2, associativity: 'l'},\n" +
+			"\t{token: dfa.EQUAL, power: 3, associativity: 'r'},\n}",
+		"return operatorPrecedence(Parse_unary, operators_binary)(buf)",
+		"func Parse_unary(",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected the generated parser to contain %q\n%s", expected, output)
+		}
+	}
+	// The levels of the expression are parsed by operatorPrecedence
+	for _, unexpected := range []string{"Parse_binary_1", "Grammar_binary"} {
+		if strings.Contains(output, unexpected) {
+			t.Errorf("Expected the generated parser not to contain %q", unexpected)
+		}
+	}
+}
+
+func TestGenerateLeftRecursion(t *testing.T) {
+	generate := func(source string) string {
+		t.Helper()
+		scanner := lexer.LexicalAnalyzer{}
+		scanner.Initialize(bufio.NewReader(strings.NewReader(source)))
+		leftRecursiveGrammar, err := grammar_file.ParseGrammar(&scanner)
+		if err != nil && err != io.EOF {
+			t.Fatalf("Could not parse the grammar: %v", err)
+		}
+		filePath := filepath.Join(t.TempDir(), "generated_parser.go")
+		if err := grammar.GenerateGrammarParserFileForGrammar(leftRecursiveGrammar, filePath); err != nil {
+			t.Fatalf("GenerateGrammarParserFileForGrammar failed: %v", err)
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			t.Fatalf("Could not read the generated file: %v", err)
+		}
+		return string(content)
+	}
+
+	// Parse_expr used to call itself first thing, it repeats its tails instead
+	output := generate(`expr -> expr "-" number or number
+number -> "NUMBER"`)
+	if !strings.Contains(output, "args, ok, err := sequence(\n\t\tParse_number,\n\t\tzeroOrMore(\n\t\t\tsequence(\n\t\t\t\tmatchToken(dfa.MINUS),\n\t\t\t\tParse_number,\n\t\t\t),\n\t\t),\n\t)(buf)") {
+		t.Errorf("Expected expr to be parsed as its base then its tails repeated\n%s", output)
+	}
+	if strings.Contains(output, "func leftRecursive(") {
+		t.Errorf("Expected no left recursion helpers without %%reassociate")
+	}
+
+	// The rules alone, without their order in the file, are rewritten the same way
+	if err := grammar.GenerateGrammarOutput(bufio.NewWriter(&bytes.Buffer{}), map[grammar.Non_terminal][]grammar.Generic_grammar_term{
+		{Name: "expr"}: {&grammar.Non_terminal{Name: "expr"}, &grammar.Terminal{Content: []rune("-")}, &grammar.Terminal{Content: []rune("NUMBER")}, &grammar.Or{}, &grammar.Terminal{Content: []rune("NUMBER")}},
+	}); err != nil {
+		t.Errorf("GenerateGrammarOutput failed: %v", err)
+	}
+
+	// With %reassociate, each tail makes the node matched before it its first argument
+	output = generate(`%reassociate
+expr -> left:expr "-" right:number { return $left.(int) - $right.(int) } or number
+number -> "NUMBER"`)
+	for _, expected := range []string{
+		"func leftRecursive(node func([]Node, bool, error) ([]Node, bool, error)",
+		"type Grammar_expr struct {\n\tArguments []Node\n\tLeft      Node\n\tRight     Node\n}",
+		"func node_expr(args []Node, ok bool, err error) ([]Node, bool, error) {",
+		"return leftRecursive(node_expr,\n\t\t[]ParseFunc{\n\t\t\tParse_number,\n\t\t},",
+		"return action(func(values ActionValues) any { return values.Get(\"left\").(int) - values.Get(\"right\").(int) },\n\t\t\t\t\tsequence(\n\t\t\t\t\t\tlabelled(\"left\", given(left)),\n\t\t\t\t\t\tmatchToken(dfa.MINUS),",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected the generated parser to contain %q\n%s", expected, output)
+		}
+	}
+	if strings.Count(output, "func Parse_expr(") != 1 {
+		t.Errorf("Expected a single Parse function for expr\n%s", output)
+	}
+}
+
 // Benchmark tests
 func BenchmarkGenerateGrammarParserFile(b *testing.B) {
 	generated_grammar := createComplexGrammar()
@@ -720,3 +1077,82 @@ func TestEdgeCases(t *testing.T) {
 		}
 	})
 }
+
+// The program run by `runGeneratedParser`: it prints the value of the action of the starting rule
+const generatedParserMain = `package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/VirajAgarwal1/lox/lexer"
+	"generated/parser"
+)
+
+func main() {
+	buf := lexer.BufferedLexer{}
+	buf.Initialize(bufio.NewReader(os.Stdin), 1024)
+	nodes, ok, err := parser.Parse_start(&buf)
+	if err != nil || !ok || len(nodes) != 1 {
+		fmt.Print("no match ", err)
+		return
+	}
+	fmt.Print(nodes[0].(*parser.ActionValue).Value)
+}
+`
+
+// helper: generates the parser of a grammar file in a module of its own, and gives the value its rule `start` gives for each input
+func runGeneratedParser(t *testing.T, source string, inputs ...string) []string {
+	t.Helper()
+	scanner := lexer.LexicalAnalyzer{}
+	scanner.Initialize(bufio.NewReader(strings.NewReader(source)))
+	parsed, err := grammar_file.ParseGrammar(&scanner)
+	if err != nil && err != io.EOF {
+		t.Fatalf("Could not parse the grammar: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "generated_parser.go")
+	if err := grammar.GenerateGrammarParserFileForGrammar(parsed, path); err != nil {
+		t.Fatalf("GenerateGrammarParserFileForGrammar failed: %v", err)
+	}
+	code, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Could not read the generated parser: %v", err)
+	}
+	return test_utils.RunGeneratedModule(t, map[string]string{
+		"main.go":                    generatedParserMain,
+		"parser/generated_parser.go": string(code),
+	}, inputs...)
+}
+
+func TestGeneratedActionsRun(t *testing.T) {
+	// `$1` is the token of "NUMBER", `$left` and `$right` the values of the actions of their rules
+	values := runGeneratedParser(t, `%import "strconv"
+%reassociate
+start -> left:start "-" right:number { return $left.(int) - $right.(int) } or number { return $1 }
+number -> "NUMBER" {
+	value, _ := strconv.Atoi(string($1.(*lexer.Token).Lexemme))
+	return value
+}
+`, "7", "10-4-3")
+	if strings.Join(values, " ") != "7 3" {
+		t.Errorf("Expected the actions to give 7 and 3, got %v", values)
+	}
+}
+
+func TestGeneratedParserSkipsTokens(t *testing.T) {
+	// The tokens of `%skip` are read past between the tokens of the rules, the others are not
+	values := runGeneratedParser(t, `%import "strconv"
+%skip WHITESPACE
+%reassociate
+start -> left:start "-" right:number { return $left.(int) - $right.(int) } or number { return $1 }
+number -> "NUMBER" {
+	value, _ := strconv.Atoi(string($1.(*lexer.Token).Lexemme))
+	return value
+}
+`, " 10 -  4\t- 3", "10\n-4")
+	if values[0] != "3" || !strings.HasPrefix(values[1], "10") {
+		t.Errorf("Expected whitespace to be skipped and newlines not to be, got %v", values)
+	}
+}
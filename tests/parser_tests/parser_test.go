@@ -2,11 +2,15 @@ package parser_tests
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"testing"
 
+	"github.com/VirajAgarwal1/lox/errorhandler"
 	"github.com/VirajAgarwal1/lox/lexer"
+	"github.com/VirajAgarwal1/lox/lexer/dfa"
 	"github.com/VirajAgarwal1/lox/parser"
 )
 
@@ -24,9 +28,6 @@ func checkNodeNestingInOrder(nodes []parser.Node, expectedTypes []string) bool {
 		var children []parser.Node
 
 		switch n := node.(type) {
-		case *parser.Grammar_grammar:
-			nodeType = "grammar"
-			children = n.Arguments
 		case *parser.Grammar_expression:
 			nodeType = "expression"
 			children = n.Arguments
@@ -70,9 +71,6 @@ func checkNodeNestingInOrder(nodes []parser.Node, expectedTypes []string) bool {
 func logAST(nodes []parser.Node, indent string) {
 	for _, node := range nodes {
 		switch n := node.(type) {
-		case *parser.Grammar_grammar:
-			fmt.Println(indent + "Grammar_grammar")
-			logAST(n.Arguments, indent+"\t")
 		case *parser.Grammar_expression:
 			fmt.Println(indent + "Grammar_expression")
 			logAST(n.Arguments, indent+"\t")
@@ -220,7 +218,7 @@ func Test_ParserCases(t *testing.T) {
 			buf_reader := bufio.NewReader(strings.NewReader(test.code))
 			scanner.Initialize(buf_reader, scanner_buf_cap)
 
-			nodes, ok, err := parser.Parse_grammar(&scanner)
+			nodes, ok, err := parser.Parse_expression(&scanner)
 			if err != nil && test.expectSuccess {
 				t.Fatalf("Unexpected parse error: %v", err)
 			}
@@ -249,3 +247,53 @@ func Test_ParserCases(t *testing.T) {
 		})
 	}
 }
+
+func TestParseReportsFurthestFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     string
+		found    dfa.TokenType
+		column   int
+		expected []dfa.TokenType // some of the tokens which must be expected
+	}{
+		{name: "Unclosed parenthesis", code: "(1 + 2", found: dfa.EOF, column: 7, expected: []dfa.TokenType{dfa.RIGHT_PAREN, dfa.PLUS, dfa.STAR}},
+		{name: "Missing operand", code: "1 + * 2", found: dfa.STAR, column: 5, expected: []dfa.TokenType{dfa.NUMBER, dfa.LEFT_PAREN, dfa.MINUS}},
+		{name: "Trailing token", code: "1 2", found: dfa.NUMBER, column: 3, expected: []dfa.TokenType{dfa.EOF, dfa.PLUS}},
+		{name: "Grammar notation token", code: "a ? b", found: dfa.QUESTION, column: 3, expected: []dfa.TokenType{dfa.EOF, dfa.PLUS}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			scanner := lexer.BufferedLexer{}
+			scanner.Initialize(bufio.NewReader(strings.NewReader(test.code)), 64)
+
+			_, err := parser.Parse(&scanner, parser.Parse_expression)
+			if !errors.Is(err, errorhandler.ErrSyntax) {
+				t.Errorf("Expected a syntax error, got %v", err)
+			}
+			var parse_err *errorhandler.ParseError
+			if !errors.As(err, &parse_err) {
+				t.Fatalf("Expected a *errorhandler.ParseError, got %v", err)
+			}
+			if parse_err.Found != test.found.Name() || parse_err.Position.Line != 1 || parse_err.Position.Column != test.column {
+				t.Errorf("Expected the error at %q on column %d, got %q at %v", test.found, test.column, parse_err.Found, parse_err.Position)
+			}
+			for _, expected := range test.expected {
+				if !slices.Contains(parse_err.Expected, expected.Name()) {
+					t.Errorf("Expected %q to be expected, got %v", expected, parse_err.Expected)
+				}
+			}
+		})
+	}
+
+	// Input which matches gives the nodes of the rule
+	scanner := lexer.BufferedLexer{}
+	scanner.Initialize(bufio.NewReader(strings.NewReader("(1 + 2) * 3")), 64)
+	nodes, err := parser.Parse(&scanner, parser.Parse_expression)
+	if err != nil || len(nodes) != 1 {
+		t.Fatalf("Expected the expression to parse, got %v %v", nodes, err)
+	}
+	if _, isExpression := nodes[0].(*parser.Grammar_expression); !isExpression {
+		t.Errorf("Expected the node of the expression, got %T", nodes[0])
+	}
+}
@@ -0,0 +1,130 @@
+package source_tests
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/VirajAgarwal1/lox/lexer"
+	"github.com/VirajAgarwal1/lox/source"
+)
+
+func TestFileSetResolvesPositions(t *testing.T) {
+	fset := source.NewFileSet()
+	a := fset.AddFile("a.lox", []byte("var x\nprint x\n"))
+	b := fset.AddFile("b.lox", []byte("1 + 2"))
+
+	tests := []struct {
+		pos      source.Pos
+		expected string
+	}{
+		{a.Pos(0), "a.lox:1:1"},
+		{a.Pos(4), "a.lox:1:5"},
+		{a.Pos(6), "a.lox:2:1"},
+		{a.Pos(12), "a.lox:2:7"},
+		{b.Pos(0), "b.lox:1:1"},
+		{b.Pos(4), "b.lox:1:5"},
+		{source.NoPos, "-"},
+	}
+	for _, tt := range tests {
+		if got := fset.Position(tt.pos).String(); got != tt.expected {
+			t.Errorf("Position(%d) = %q, expected %q", tt.pos, got, tt.expected)
+		}
+	}
+
+	if fset.File(b.Pos(2)) != b {
+		t.Errorf("Expected position to resolve to b.lox")
+	}
+	line, ok := a.Line(2)
+	if !ok || line != "print x" {
+		t.Errorf("Expected line 2 to be %q, got %q (ok=%v)", "print x", line, ok)
+	}
+	if _, ok := a.Line(4); ok {
+		t.Errorf("Expected line 4 to be unknown")
+	}
+}
+
+func TestColumnsCountRunes(t *testing.T) {
+	fset := source.NewFileSet()
+	f := fset.AddFile("unicode.lox", []byte("\"héllo\" + x"))
+
+	// 'é' is two bytes long, so byte offset 10 is the 10th rune
+	if got := f.Position(f.Pos(10)).Column; got != 10 {
+		t.Errorf("Expected column 10, got %d", got)
+	}
+}
+
+func TestLexerRecordsStreamedFile(t *testing.T) {
+	input := "var a = 1;\n  a = \"b\""
+	fset := source.NewFileSet()
+	file := fset.AddFile("stream.lox", nil)
+
+	scanner := lexer.LexicalAnalyzer{}
+	scanner.InitializeWithFile(bufio.NewReader(strings.NewReader(input)), file)
+
+	var tokens []*lexer.Token
+	for {
+		tok, err := scanner.ReadToken()
+		if err != nil && err != io.EOF {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		tokens = append(tokens, tok)
+		if err == io.EOF {
+			break
+		}
+	}
+
+	if file.Size() != len(input) {
+		t.Fatalf("Expected file to record %d bytes, got %d", len(input), file.Size())
+	}
+	for _, tok := range tokens {
+		if got := file.Slice(tok.Pos, tok.End); tok.TypeOfToken != "EOF" && got != string(tok.Lexemme) {
+			t.Errorf("Token %s spans %q in the file", tok.ToString(), got)
+		}
+	}
+
+	last := tokens[len(tokens)-2] // the string "b"
+	if got := fset.Position(last.Pos).String(); got != "stream.lox:2:7" {
+		t.Errorf("Expected string token at stream.lox:2:7, got %s", got)
+	}
+	line, _ := file.Line(2)
+	if line != "  a = \"b\"" {
+		t.Errorf("Unexpected text for line 2: %q", line)
+	}
+}
+
+func TestStreamedFileDoesNotOverlapNextFile(t *testing.T) {
+	// The second file is added while the first one has only been read in part
+	fset := source.NewFileSet()
+	streamed := fset.AddFile("stream.lox", nil)
+	streamed.AppendRune('a')
+	next := fset.AddFile("next.lox", []byte("1 + 2"))
+	for _, r := range " = 1;\nb" {
+		streamed.AppendRune(r)
+	}
+
+	tests := []struct {
+		pos      source.Pos
+		expected string
+	}{
+		{streamed.Pos(0), "stream.lox:1:1"},
+		{streamed.Pos(4), "stream.lox:1:5"},
+		{streamed.Pos(7), "stream.lox:2:1"},
+		{next.Pos(0), "next.lox:1:1"},
+		{next.Pos(4), "next.lox:1:5"},
+	}
+	for _, tt := range tests {
+		if got := fset.Position(tt.pos).String(); got != tt.expected {
+			t.Errorf("Position(%d) = %q, expected %q", tt.pos, got, tt.expected)
+		}
+	}
+	if int(streamed.Pos(streamed.Size())) >= next.Base() {
+		t.Errorf("Expected the positions of the streamed file to stay before %d, got %d", next.Base(), streamed.Pos(streamed.Size()))
+	}
+
+	// Past its range, a streamed file has no positions rather than the ones of the next file
+	if streamed.Pos(source.MaxStreamedFileSize+1) != source.NoPos {
+		t.Errorf("Expected no position past the range of the streamed file")
+	}
+}
@@ -0,0 +1,170 @@
+package streamable_parser_tests
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/VirajAgarwal1/lox/errorhandler"
+	"github.com/VirajAgarwal1/lox/lexer/dfa"
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/generator"
+)
+
+const danglingElse = `stmt -> "if" "(" "IDENTIFIER" ")" stmt ( "else" stmt )? or "NUMBER"`
+
+// helper: generates a grammar which must have conflicts, and gives them
+func conflictsOf(t *testing.T, grammar string) []generator.Conflict {
+	t.Helper()
+	parser_generator := generator.Generator{}
+	_, err := parser_generator.Generate(parseGrammarFile(t, grammar), generator.Options{})
+	var conflicts *generator.Conflicts_error
+	if !errors.As(err, &conflicts) {
+		t.Fatalf("Expected the conflicts of the grammar, got %v", err)
+	}
+	if !errors.Is(err, errorhandler.ErrGrammar) {
+		t.Errorf("Expected the conflicts to be a grammar error")
+	}
+	return conflicts.Conflicts
+}
+
+func TestConflictFirstFollow(t *testing.T) {
+	conflicts := conflictsOf(t, danglingElse)
+	if len(conflicts) != 1 {
+		t.Fatalf("Expected one conflict, got %v", conflicts)
+	}
+	conflict := conflicts[0]
+	if conflict.Kind() != "FIRST/FOLLOW" || conflict.Token != dfa.ELSE || strings.Join(conflict.Rules, ",") != "stmt" {
+		t.Errorf("Unexpected conflict %s", conflict)
+	}
+	// The production which matches the `else` is the one the parse table keeps, both are in the `?` the grammar file wrote
+	if strings.Join(conflict.Alternatives, " ") != "`\"else\" stmt` in the `?` of 'stmt' `ε` in the `?` of 'stmt'" {
+		t.Errorf("Unexpected alternatives %v", conflict.Alternatives)
+	}
+	if describeTokens(conflict.Example) != "if ( IDENTIFIER ) NUMBER else" {
+		t.Errorf("Unexpected example %v", describeTokens(conflict.Example))
+	}
+}
+
+func TestConflictFirstFirst(t *testing.T) {
+	// The example goes through the rules which lead to the conflict
+	conflicts := conflictsOf(t, `program -> "(" "STRING" value ")"
+value -> "NUMBER" "+" or number
+number -> "NUMBER" or "-" "NUMBER"`)
+	if len(conflicts) != 1 {
+		t.Fatalf("Expected one conflict, got %v", conflicts)
+	}
+	conflict := conflicts[0]
+	if conflict.Kind() != "FIRST/FIRST" || conflict.Non_term != "value" || conflict.Token != dfa.NUMBER {
+		t.Errorf("Unexpected conflict %s", conflict)
+	}
+	if strings.Join(conflict.Alternatives, " ") != "`value -> \"NUMBER\" \"+\"` `value -> number`" {
+		t.Errorf("Unexpected alternatives %v", conflict.Alternatives)
+	}
+	if describeTokens(conflict.Example) != "( STRING NUMBER" {
+		t.Errorf("Unexpected example %v", describeTokens(conflict.Example))
+	}
+}
+
+func TestConflictsAreAllReported(t *testing.T) {
+	conflicts := conflictsOf(t, `program -> item* "NUMBER"
+item -> "NUMBER" "," or "STRING" or "STRING" "."
+other -> "(" or inner
+inner -> "("`)
+	described := []string{}
+	for _, conflict := range conflicts {
+		described = append(described, conflict.Non_term+" "+string(conflict.Token)+" "+strings.Join(conflict.Rules, ","))
+	}
+	// The common prefix of `item` is factored, `other` cannot be reached
+	if strings.Join(described, "; ") != "999_program_star_1 NUMBER program" {
+		t.Errorf("Unexpected conflicts %v", described)
+	}
+
+	parser_generator := generator.Generator{}
+	_, err := parser_generator.Generate(parseGrammarFile(t, "a -> b or c\nb -> \"NUMBER\"\nc -> \"NUMBER\" or \"STRING\" d\nd -> e or \"+\"\ne -> \"+\""), generator.Options{})
+	if err == nil || !strings.Contains(err.Error(), "2 unresolved conflicts") {
+		t.Fatalf("Expected both conflicts in the error, got %v", err)
+	}
+	for _, expected := range []string{
+		"FIRST/FIRST conflict in 'a' on 'NUMBER': `a -> b` or `a -> c`, as in `NUMBER`",
+		"FIRST/FIRST conflict in 'd' on '+': `d -> e` or `d -> \"+\"`, as in `STRING +`",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected %q in the error\n%v", expected, err)
+		}
+	}
+}
+
+func TestConflictsResolved(t *testing.T) {
+	parser_generator := generator.Generator{}
+	result, err := parser_generator.Generate(parseGrammarFile(t, "%resolve stmt \"else\"\n"+danglingElse), generator.Options{})
+	if err != nil {
+		t.Fatalf("Expected the conflict to be resolved, got %v", err)
+	}
+	if len(result.Conflicts) != 1 || !result.Conflicts[0].Resolved {
+		t.Errorf("Expected the resolved conflict in the result, got %v", result.Conflicts)
+	}
+	opt := result.Conflicts[0].Non_term
+	if predictions := result.Predictions[opt][dfa.ELSE]; len(predictions) != 2 || result.Table[opt][dfa.ELSE] != predictions[0].Production {
+		t.Errorf("Expected the table to keep the first prediction, got %v and %v", result.Table[opt], predictions)
+	}
+
+	// Only the tokens given are resolved
+	conflicts := conflictsOf(t, "%resolve stmt NUMBER\n"+danglingElse)
+	if len(conflicts) != 1 || conflicts[0].Token != dfa.ELSE {
+		t.Errorf("Expected the conflict on else to be left, got %v", conflicts)
+	}
+
+	for grammar, expected := range map[string]string{
+		"%resolve statement \"else\"\n" + danglingElse: "Unknown rule 'statement'",
+		"%resolve stmt ELSE\n" + danglingElse:          "Unknown token 'ELSE'",
+	} {
+		_, err := parser_generator.Generate(parseGrammarFile(t, grammar), generator.Options{})
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected %q, got %v", expected, err)
+		}
+	}
+}
+
+func TestConflictDiagnostics(t *testing.T) {
+	parser_generator := generator.Generator{}
+	_, err := parser_generator.Generate(parseGrammarFile(t, "// Statements\n"+danglingElse), generator.Options{})
+	var conflicts *generator.Conflicts_error
+	if !errors.As(err, &conflicts) {
+		t.Fatalf("Expected the conflicts of the grammar, got %v", err)
+	}
+	diagnostics := conflicts.Diagnostics()
+	if len(diagnostics) != 1 {
+		t.Fatalf("Expected one diagnostic, got %v", diagnostics)
+	}
+	diag := diagnostics[0]
+	if diag.Error() != "2:1: error[G0012]: FIRST/FOLLOW conflict in the `?` of 'stmt' on 'else'" {
+		t.Errorf("Unexpected diagnostic %q", diag.Error())
+	}
+	if len(diag.Help) != 1 || !strings.Contains(diag.Help[0], "`%resolve stmt \"else\"`") {
+		t.Errorf("Expected the help to give the directive, got %v", diag.Help)
+	}
+	if !strings.Contains(strings.Join(diag.Notes, "\n"), "for example on `if ( IDENTIFIER ) NUMBER else`") {
+		t.Errorf("Expected the example in the notes, got %v", diag.Notes)
+	}
+
+	// The artificial non-terminals are never named, as they cannot be written in the grammar file
+	_, err = parser_generator.Generate(parseGrammarFile(t, "program -> ( item \";\" )* \"NUMBER\"\nitem -> \"NUMBER\" \",\""), generator.Options{})
+	if !errors.As(err, &conflicts) || len(conflicts.Diagnostics()) != 1 {
+		t.Fatalf("Expected one conflict, got %v", err)
+	}
+	diag = conflicts.Diagnostics()[0]
+	described := strings.Join(append(append([]string{diag.Error()}, diag.Notes...), diag.Help...), "\n")
+	if strings.Contains(described, "999_") || !strings.Contains(described, "conflict in the `*` of 'program' on 'NUMBER'") ||
+		!strings.Contains(described, "the parser would choose `item \";\" ( ... )*` in the `*` of 'program'") || !strings.Contains(described, "`%resolve program \"NUMBER\"`") {
+		t.Errorf("Expected the conflict to be described with the grammar file, got\n%s", described)
+	}
+}
+
+// helper: writes tokens separated by spaces
+func describeTokens(tokens []dfa.TokenType) string {
+	names := []string{}
+	for _, token := range tokens {
+		names = append(names, string(token))
+	}
+	return strings.Join(names, " ")
+}
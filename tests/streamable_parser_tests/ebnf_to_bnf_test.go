@@ -0,0 +1,163 @@
+package streamable_parser_tests
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/ebnf_to_bnf"
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/utils"
+)
+
+// helper: converts a grammar to BNF and returns the alternatives of every non-terminal, each printed as a sorted list
+func bnfOf(t *testing.T, grammar string) map[string][]string {
+	t.Helper()
+	bnf := ebnf_to_bnf.ConvertGrammar(parseGrammarFile(t, grammar))
+
+	out := map[string][]string{}
+	for non_term, alternatives := range bnf {
+		for _, alternative := range alternatives {
+			parts := []string{}
+			for _, elem := range alternative {
+				part := string(elem.Terminal_type)
+				if elem.IsNonTerminal {
+					part = "<" + elem.Non_term_name + ">"
+				}
+				if elem.Label != "" {
+					part = elem.Label + ":" + part
+				}
+				parts = append(parts, part)
+			}
+			out[non_term] = append(out[non_term], strings.Join(parts, " "))
+		}
+		sort.Strings(out[non_term])
+	}
+	return out
+}
+
+func TestEbnfToBnfOptional(t *testing.T) {
+	bnf := bnfOf(t, `unary -> "-"? "NUMBER"`)
+	if len(bnf) != 2 || len(bnf["unary"]) != 1 {
+		t.Fatalf("Expected unary and one artificial non-terminal, got %v", bnf)
+	}
+	optional_name := strings.Fields(bnf["unary"][0])[0]
+	optional_name = strings.Trim(optional_name, "<>")
+	if !strings.HasPrefix(optional_name, ebnf_to_bnf.Artificial_non_term_prefix) {
+		t.Fatalf("Expected an artificial non-terminal for `?`, got %v", bnf["unary"])
+	}
+	expected := []string{"-", string(utils.Epsilon)}
+	sort.Strings(expected)
+	if strings.Join(bnf[optional_name], "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected %v for the optional part, got %v", expected, bnf[optional_name])
+	}
+}
+
+func TestEbnfToBnfOptionalGroup(t *testing.T) {
+	// The alternatives of `[ ... ]` are used directly, next to an epsilon alternative
+	bnf := bnfOf(t, `sign -> [ "-" or "+" or "!" "!" ]`)
+	optional_name := strings.Trim(bnf["sign"][0], "<>")
+	alternatives := bnf[optional_name]
+	if len(alternatives) != 4 {
+		t.Fatalf("Expected 4 alternatives, got %v", alternatives)
+	}
+	if alternatives[0] != "! !" || alternatives[1] != "+" || alternatives[2] != "-" || alternatives[3] != string(utils.Epsilon) {
+		t.Errorf("Unexpected alternatives %v", alternatives)
+	}
+}
+
+func TestEbnfToBnfLabels(t *testing.T) {
+	bnf := bnfOf(t, `binary -> left:term op:( "+" or "-" ) right:term
+term -> "NUMBER"`)
+	parts := strings.Fields(bnf["binary"][0])
+	if len(parts) != 3 || parts[0] != "left:<term>" || parts[2] != "right:<term>" || !strings.HasPrefix(parts[1], "op:<"+ebnf_to_bnf.Artificial_non_term_prefix) {
+		t.Fatalf("Expected the labels on the elements of binary, got %v", bnf["binary"])
+	}
+	// The alternatives of the labelled group have no labels of their own, the parser gives them the label of the group
+	if operators := bnf[strings.Trim(strings.TrimPrefix(parts[1], "op:"), "<>")]; strings.Join(operators, "|") != "+|-" {
+		t.Errorf("Unexpected alternatives for the operator %v", operators)
+	}
+}
+
+func TestEbnfToBnfArtificialNames(t *testing.T) {
+	// Named after the rule, what they replace and their position in it, whatever the other rules are
+	bnf := bnfOf(t, `term -> factor ( "*" factor )*
+factor -> [ "-" ] "NUMBER" ( "NUMBER" or "(" term ")" )*`)
+	expected := map[string][]string{
+		"term":             {"<factor> <999_term_star_1>"},
+		"999_term_star_1":  {"* <factor> <999_term_star_1>", string(utils.Epsilon)}, // The group of the star is inlined
+		"factor":           {"<999_factor_opt_1> NUMBER <999_factor_star_2>"},
+		"999_factor_opt_1": {"-", string(utils.Epsilon)},
+	}
+	for non_term, alternatives := range expected {
+		sort.Strings(alternatives)
+		if strings.Join(bnf[non_term], "|") != strings.Join(alternatives, "|") {
+			t.Errorf("Expected %v for %s, got %v", alternatives, non_term, bnf[non_term])
+		}
+	}
+	if _, found := bnf["999_factor_star_2"]; !found || len(bnf) != 6 {
+		t.Errorf("Expected 999_factor_star_2 and its group in %v", bnf)
+	}
+}
+
+func TestEbnfToBnfSharing(t *testing.T) {
+	grammar := `list -> "[" [ item ( "," item )* ] "]" or "(" [ item ( "," item )* ] ")"
+item -> "NUMBER" ( "," item )* or "IDENTIFIER"
+pair -> ( "NUMBER" "," )+`
+	bnf := bnfOf(t, grammar)
+
+	// The two lists and the repetition of `item` are one star
+	stars := []string{}
+	for non_term := range bnf {
+		if strings.Contains(non_term, "_star_") {
+			stars = append(stars, non_term)
+		}
+	}
+	if len(stars) != 1 || strings.Join(bnf[stars[0]], "|") != ", <item> <"+stars[0]+">|"+string(utils.Epsilon) {
+		t.Fatalf("Expected one shared star, got %v", stars)
+	}
+	if strings.Join(bnf["item"], "|") != "IDENTIFIER|NUMBER <"+stars[0]+">" {
+		t.Errorf("Unexpected productions for item %v", bnf["item"])
+	}
+
+	// The content of `+` is converted twice, but gives one non-terminal
+	plus := strings.Trim(bnf["pair"][0], "<>")
+	group := strings.Trim(bnf[plus][0], "<>")
+	if len(bnf[plus]) != 2 || bnf[plus][1] != "<"+group+"> <"+plus+">" || strings.Join(bnf[group], "|") != "NUMBER ," {
+		t.Errorf("Expected the content of the plus to be shared, got %v", bnf[plus])
+	}
+
+	// What was removed is reported
+	parsed := parseGrammarFile(t, grammar)
+	converter := ebnf_to_bnf.Converter{}
+	converted := converter.Convert(parsed)
+	report := converter.Report()
+	if report.Artificial-report.Saved() != len(converted)-3 || report.Shared == 0 || report.Inlined == 0 {
+		t.Errorf("Unexpected report %+v for %d non-terminals", report, len(converted))
+	}
+}
+
+func TestEbnfToBnfInliningKeepsSharing(t *testing.T) {
+	// The outer brackets are shared by both rules and inlined as they are a single element, which makes both rules use the inner ones
+	bnf := bnfOf(t, `a -> ( ( "NUMBER" "," ) ) "+"
+b -> ( ( "NUMBER" "," ) ) "-"`)
+	group := strings.Fields(bnf["a"][0])[0]
+	if len(bnf) != 3 || bnf["b"][0] != group+" -" || strings.Join(bnf[strings.Trim(group, "<>")], "|") != "NUMBER ," {
+		t.Errorf("Expected the inner brackets to be shared rather than put in both rules, got %v", bnf)
+	}
+}
+
+func TestEbnfToBnfSharingOnLox(t *testing.T) {
+	content, err := os.ReadFile("../../parser/lox.grammar")
+	if err != nil {
+		t.Fatalf("Could not read lox.grammar: %v", err)
+	}
+	parsed := parseGrammarFile(t, string(content))
+	converter := ebnf_to_bnf.Converter{}
+	converted := converter.Convert(parsed)
+	report := converter.Report()
+	t.Logf("lox.grammar: %d artificial non-terminals, %d shared and %d inlined, %d non-terminals left", report.Artificial, report.Shared, report.Inlined, len(converted))
+	if report.Saved() == 0 || report.Artificial-report.Saved() != len(converted)-len(parsed.Rules) {
+		t.Errorf("Unexpected report %+v for %d non-terminals", report, len(converted))
+	}
+}
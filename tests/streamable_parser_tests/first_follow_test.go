@@ -0,0 +1,162 @@
+package streamable_parser_tests
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/VirajAgarwal1/lox/lexer/dfa"
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/first_follow"
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/generator"
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/utils"
+)
+
+// helper: builds a BNF grammar from productions separated by `|`, whose symbols are separated by spaces. A symbol is a non-terminal if it has productions, `ε` is `Epsilon`, anything else a token type.
+func bnfFrom(rules map[string]string) map[string]([][]utils.Grammar_element) {
+	bnf := map[string]([][]utils.Grammar_element){}
+	for non_term, productions := range rules {
+		for _, production := range strings.Split(productions, "|") {
+			elements := []utils.Grammar_element{}
+			for _, symbol := range strings.Fields(production) {
+				switch _, found := rules[symbol]; {
+				case found:
+					elements = append(elements, utils.Grammar_element{IsNonTerminal: true, Non_term_name: symbol})
+				case symbol == "ε":
+					elements = append(elements, utils.Grammar_element{Terminal_type: utils.Epsilon})
+				default:
+					elements = append(elements, utils.Grammar_element{Terminal_type: dfa.TokenType(symbol)})
+				}
+			}
+			bnf[non_term] = append(bnf[non_term], elements)
+		}
+	}
+	return bnf
+}
+
+// helper: prints a set of tokens in order, `ε` standing for `Epsilon`
+func describeSet(tokens []dfa.TokenType) string {
+	names := []string{}
+	for _, token := range tokens {
+		if token == utils.Epsilon {
+			names = append(names, "ε")
+		} else {
+			names = append(names, string(token))
+		}
+	}
+	slices.Sort(names)
+	return "{" + strings.Join(names, " ") + "}"
+}
+
+// helper: compares the sets computed for every non-terminal with the expected ones
+func checkSets(t *testing.T, sets first_follow.Analysis, first map[string]string, follow map[string]string, nullable []string) {
+	t.Helper()
+	for non_term, expected := range first {
+		if got := describeSet(sets.First[non_term].FirstForNonTerminal); got != expected {
+			t.Errorf("FIRST(%s): expected %s, got %s", non_term, expected, got)
+		}
+	}
+	for non_term, expected := range follow {
+		if got := describeSet(sets.Follow[non_term]); got != expected {
+			t.Errorf("FOLLOW(%s): expected %s, got %s", non_term, expected, got)
+		}
+	}
+	for non_term := range first {
+		if sets.Nullable[non_term] != slices.Contains(nullable, non_term) {
+			t.Errorf("NULLABLE(%s): expected %v, got %v", non_term, !sets.Nullable[non_term], sets.Nullable[non_term])
+		}
+	}
+}
+
+func TestFirstFollowDragonBookExpressions(t *testing.T) {
+	// The expression grammar of the dragon book (Aho, Lam, Sethi and Ullman, grammar 4.28)
+	sets := first_follow.Compute(bnfFrom(map[string]string{
+		"E":  "T E'",
+		"E'": "+ T E' | ε",
+		"T":  "F T'",
+		"T'": "* F T' | ε",
+		"F":  "( E ) | IDENTIFIER",
+	}), "E")
+	checkSets(t, sets, map[string]string{
+		"E":  "{( IDENTIFIER}",
+		"E'": "{+ ε}",
+		"T":  "{( IDENTIFIER}",
+		"T'": "{* ε}",
+		"F":  "{( IDENTIFIER}",
+	}, map[string]string{
+		"E":  "{) EOF}",
+		"E'": "{) EOF}",
+		"T":  "{) + EOF}",
+		"T'": "{) + EOF}",
+		"F":  "{) * + EOF}",
+	}, []string{"E'", "T'"})
+
+	if got := describeSet(sets.First["E'"].FirstForDefinitions[0]) + describeSet(sets.First["E'"].FirstForDefinitions[1]); got != "{+}{ε}" {
+		t.Errorf("Unexpected FIRST sets of the productions of E': %s", got)
+	}
+}
+
+func TestFirstFollowDanglingElse(t *testing.T) {
+	// The dangling else of the dragon book (grammar 4.13 left factored), whose FIRST(S') and FOLLOW(S') share `else`
+	bnf := bnfFrom(map[string]string{
+		"S":  "if ( E ) S S' | NUMBER",
+		"S'": "else S | ε",
+		"E":  "IDENTIFIER",
+	})
+	sets := first_follow.Compute(bnf, "S")
+	checkSets(t, sets, map[string]string{
+		"S":  "{NUMBER if}",
+		"S'": "{else ε}",
+		"E":  "{IDENTIFIER}",
+	}, map[string]string{
+		"S":  "{EOF else}",
+		"S'": "{EOF else}",
+		"E":  "{)}",
+	}, []string{"S'"})
+
+	// Both productions of S' are predicted on `else`, the one which matches it first
+	table := generator.Compute_prediction_table(bnf, sets.First, sets.Follow)
+	if predictions := table["S'"][dfa.ELSE]; len(predictions) != 2 || predictions[0].Production != 0 || !predictions[1].By_follow {
+		t.Errorf("Expected the conflict of the dangling else, got %v", predictions)
+	}
+	if predictions := table["S'"][dfa.EOF]; len(predictions) != 1 || predictions[0].Production != 1 {
+		t.Errorf("Expected S' to match nothing at the end of the input, got %v", predictions)
+	}
+}
+
+func TestFirstFollowMutualRecursion(t *testing.T) {
+	// FOLLOW(A) and FOLLOW(B) need each other, and the start symbol is the only one followed by the end of the input
+	sets := first_follow.Compute(bnfFrom(map[string]string{
+		"S": "A z | B w",
+		"A": "x B",
+		"B": "y A | ε",
+	}), "S")
+	checkSets(t, sets, map[string]string{
+		"S": "{w x y}",
+		"A": "{x}",
+		"B": "{y ε}",
+	}, map[string]string{
+		"S": "{EOF}",
+		"A": "{w z}",
+		"B": "{w z}",
+	}, []string{"B"})
+}
+
+func TestFirstFollowRepeatedNonTerminal(t *testing.T) {
+	// Every occurrence of a non-terminal counts, and nullable non-terminals let the sets through
+	sets := first_follow.Compute(bnfFrom(map[string]string{
+		"S": "B x B y | C D z",
+		"B": "NUMBER",
+		"C": "D D",
+		"D": "STRING | ε",
+	}), "S")
+	checkSets(t, sets, map[string]string{
+		"S": "{NUMBER STRING z}",
+		"B": "{NUMBER}",
+		"C": "{STRING ε}",
+		"D": "{STRING ε}",
+	}, map[string]string{
+		"B": "{x y}",
+		"C": "{STRING z}",
+		"D": "{STRING z}",
+	}, []string{"C", "D"})
+}
@@ -0,0 +1,143 @@
+package streamable_parser_tests
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/VirajAgarwal1/lox/tests/test_utils"
+)
+
+// The program run by `runGeneratedParser`: it prints the nodes of the events as `name(children)`, the leaves as their lexemme, and the errors as `error(message; expected tokens)`
+const generatedRuntimeMain = `package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/VirajAgarwal1/lox/errorhandler"
+	"github.com/VirajAgarwal1/lox/lexer"
+	"generated/streamable_parser"
+)
+
+func main() {
+	scanner := lexer.BufferedLexicalAnalyzer{}
+	scanner.Initialize(bufio.NewReader(os.Stdin))
+	sp := streamable_parser.StreamableParser{}
+	sp.Initialize(&scanner)
+
+	nodes := [][]string{{}}
+	for {
+		ev := sp.Parse()
+		if ev == nil {
+			continue
+		}
+		last := len(nodes) - 1
+		switch ev.Type {
+		case streamable_parser.EmitElemType_Error:
+			if ev.Err == io.EOF {
+				fmt.Print(strings.Join(nodes[0], " "))
+				return
+			}
+			expected := []string{}
+			var parse_err *errorhandler.ParseError
+			if errors.As(ev.Err, &parse_err) {
+				expected = parse_err.Expected
+			}
+			nodes[last] = append(nodes[last], "error("+ev.Content+"; "+strings.Join(expected, " ")+")")
+		case streamable_parser.EmitElemType_Start:
+			node := []string{ev.Content}
+			if ev.Wraps {
+				node = append(node, nodes[last][len(nodes[last])-1])
+				nodes[last] = nodes[last][:len(nodes[last])-1]
+			}
+			nodes = append(nodes, node)
+		case streamable_parser.EmitElemType_End:
+			node := nodes[last]
+			nodes = nodes[:last]
+			nodes[last-1] = append(nodes[last-1], node[0]+"("+strings.Join(node[1:], " ")+")")
+		case streamable_parser.EmitElemType_Leaf:
+			nodes[last] = append(nodes[last], ev.Content)
+		}
+	}
+}
+`
+
+// helper: generates the streamable parser of a grammar in a module of its own, with the runtime of this one, and gives the nodes it parses each input into
+func runGeneratedParser(t *testing.T, grammar string, inputs ...string) []string {
+	t.Helper()
+	code, err := writeParserFor(t, grammar)
+	if err != nil {
+		t.Fatalf("WriteParserForGrammar failed: %v", err)
+	}
+
+	files := map[string]string{
+		"main.go":                               generatedRuntimeMain,
+		"streamable_parser/generated_parser.go": code,
+	}
+	for _, runtime := range []string{"streamable_parser.go", "actions.go"} {
+		content, err := os.ReadFile(filepath.Join(test_utils.ModuleRoot(t), "streamable_parser", runtime))
+		if err != nil {
+			t.Fatalf("Could not read the runtime: %v", err)
+		}
+		files["streamable_parser/"+runtime] = string(content)
+	}
+	return test_utils.RunGeneratedModule(t, files, inputs...)
+}
+
+func TestExpressionLevelsAreLeftAssociative(t *testing.T) {
+	trees := runGeneratedParser(t, `%left "-"
+%left "*" "/"
+%right "="
+%expr sum number
+number -> "NUMBER"
+`, "1-2-3", "1*2-3/4/5", "1=2=3")
+	for i, expected := range []string{
+		// ((1 - 2) - 3)
+		"sum(sum(sum(sum_1(sum_2(number(1)))) - sum_1(sum_2(number(2)))) - sum_1(sum_2(number(3))))",
+		// (1 * 2) - ((3 / 4) / 5)
+		"sum(sum(sum_1(sum_1(sum_2(number(1))) * sum_2(number(2)))) - sum_1(sum_1(sum_1(sum_2(number(3))) / sum_2(number(4))) / sum_2(number(5))))",
+		// 1 = (2 = 3)
+		"sum(sum_1(sum_2(number(1) = sum_2(number(2) = sum_2(number(3))))))",
+	} {
+		if trees[i] != expected {
+			t.Errorf("Unexpected nodes:\n%s\nExpected:\n%s", trees[i], expected)
+		}
+	}
+}
+
+func TestExpectedTokensOfDeclaredTokens(t *testing.T) {
+	// NAME is never given by the lexer, but the parse table has it
+	trees := runGeneratedParser(t, `%token NAME
+list -> "(" item* ")"
+item -> "NUMBER" or "NAME"
+`, "(;")
+	if !strings.Contains(trees[0], `error(1:2: error[P0002]: unexpected ";"; NUMBER ) NAME)`) {
+		t.Errorf("Expected the declared token with the others, got %s", trees[0])
+	}
+}
+
+func TestErrorRecoveryMakesProgress(t *testing.T) {
+	// Every call of Parse after an error goes on from a later token or a shorter stack, so that the parser always gets to the end of the input
+	trees := runGeneratedParser(t, `%skip WHITESPACE
+list -> "(" "NUMBER" ")"
+`, "( 1", "( 1 2 )", "( 1 @ )")
+
+	// The ")" never comes, it is taken as there rather than expected again at the end of the input
+	if trees[0] != `list(( 1 error(1:4: error[P0001]: unexpected end of file; )))` {
+		t.Errorf("Expected the missing terminal to be reported once, got %s", trees[0])
+	}
+	// The tokens before the ")" are skipped
+	if trees[1] != `list(( 1 error(1:5: error[P0001]: unexpected "2"; )) ))` {
+		t.Errorf("Expected the unexpected token to be skipped, got %s", trees[1])
+	}
+	// The invalid token is skipped rather than read again
+	if !strings.HasPrefix(trees[2], "list(( 1 error(") || !strings.Contains(trees[2], "1:5: error[L0001]: invalid token") || !strings.HasSuffix(trees[2], "; ) ))") {
+		t.Errorf("Expected the invalid token to be skipped, got %s", trees[2])
+	}
+}
@@ -0,0 +1,148 @@
+package streamable_parser_tests
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/VirajAgarwal1/lox/grammar_file"
+	"github.com/VirajAgarwal1/lox/lexer"
+	"github.com/VirajAgarwal1/lox/lexer/dfa"
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/generator"
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/parser_writer"
+)
+
+// helper: parses a grammar file, failing the test if it has a syntax error
+func parseGrammarFile(t *testing.T, grammar string) *grammar_file.Grammar {
+	t.Helper()
+	scanner := lexer.LexicalAnalyzer{}
+	scanner.Initialize(bufio.NewReader(strings.NewReader(grammar)))
+	parsed, err := grammar_file.ParseGrammar(&scanner)
+	if err != nil && err != io.EOF { // io.EOF is how the grammar parser reports that it read the whole grammar
+		t.Fatalf("Could not parse the grammar: %v", err)
+	}
+	return parsed
+}
+
+// Grammars whose artificial non-terminals have the same names, and which mixed up when the pipeline kept its state in package variables
+var generatorGrammars = map[string]string{
+	"sum": `%skip WHITESPACE
+sum -> term ( ( "+" or "-" ) term )*
+term -> "NUMBER" or "(" sum ")"`,
+	"list": `list -> "[" [ item ( "," item )* ] "]"
+item -> "NUMBER" or list`,
+	"labels": `binary -> left:term op:( "+" or "-" ) right:term
+term -> "NUMBER" or "IDENTIFIER"`,
+	"expressions": `%left "==" "!="
+%left "+" "-"
+%right "="
+%expr binary unary
+unary -> "-" unary or "NUMBER"`,
+}
+
+func TestGenerateGrammar(t *testing.T) {
+	parser_generator := generator.Generator{}
+	result, err := parser_generator.Generate(parseGrammarFile(t, generatorGrammars["sum"]), generator.Options{})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if result.Start != "sum" || !reflect.DeepEqual(result.Skip, []dfa.TokenType{dfa.WHITESPACE}) {
+		t.Errorf("Unexpected start %q and skipped tokens %v", result.Start, result.Skip)
+	}
+
+	// The star goes on with a `+` or a `-`, and stops before what follows `sum`
+	star := result.Table["999_sum_star_1"]
+	expected := map[dfa.TokenType]int{dfa.PLUS: 0, dfa.MINUS: 0, dfa.EOF: 1, dfa.RIGHT_PAREN: 1}
+	if !reflect.DeepEqual(star, expected) {
+		t.Errorf("Expected the row %v for the star, got %v", expected, star)
+	}
+	if term := result.Table["term"]; !reflect.DeepEqual(term, map[dfa.TokenType]int{dfa.NUMBER: 0, dfa.LEFT_PAREN: 1}) {
+		t.Errorf("Unexpected row for term %v", term)
+	}
+
+	// The options can start the parser elsewhere
+	result, err = parser_generator.Generate(parseGrammarFile(t, generatorGrammars["sum"]), generator.Options{Start: "term"})
+	if err != nil || result.Start != "term" {
+		t.Errorf("Expected the parser to start with term, got %v (%v)", result, err)
+	}
+	if _, err := parser_generator.Generate(parseGrammarFile(t, generatorGrammars["sum"]), generator.Options{Start: "nothing"}); err == nil {
+		t.Errorf("Expected an error for a start symbol without a rule")
+	}
+}
+
+func TestGeneratorCanBeReused(t *testing.T) {
+	generate := func(parser_generator *generator.Generator, name string) *generator.Result {
+		result, err := parser_generator.Generate(parseGrammarFile(t, generatorGrammars[name]), generator.Options{})
+		if err != nil {
+			t.Fatalf("Generate failed for %s: %v", name, err)
+		}
+		return result
+	}
+
+	fresh := generate(&generator.Generator{}, "list")
+	reused := generator.Generator{}
+	generate(&reused, "sum")
+	generate(&reused, "labels")
+	again := generate(&reused, "list")
+	if !reflect.DeepEqual(fresh.Bnf, again.Bnf) || !reflect.DeepEqual(fresh.First, again.First) || !reflect.DeepEqual(fresh.Follow, again.Follow) || !reflect.DeepEqual(fresh.Table, again.Table) {
+		t.Errorf("Expected the grammars generated before not to change the result")
+	}
+}
+
+// Run with `go test -race`: the grammars are generated and written at the same time, each several times
+func TestGenerateGrammarsInParallel(t *testing.T) {
+	lox_grammar, err := os.ReadFile("../../parser/lox.grammar")
+	if err != nil {
+		t.Fatalf("Could not read lox.grammar: %v", err)
+	}
+	grammars := map[string]string{"lox": string(lox_grammar)}
+	for name, grammar := range generatorGrammars {
+		grammars[name] = grammar
+	}
+
+	// What each grammar gives on its own
+	expected := map[string]*generator.Result{}
+	expected_code := map[string]string{}
+	for name, grammar := range grammars {
+		if expected_code[name], err = writeParserFor(t, grammar); err != nil {
+			t.Fatalf("WriteParserForGrammar failed for %s: %v", name, err)
+		}
+		parser_generator := generator.Generator{}
+		result, err := parser_generator.Generate(parseGrammarFile(t, grammar), generator.Options{})
+		if err != nil {
+			t.Fatalf("Generate failed for %s: %v", name, err)
+		}
+		expected[name] = result
+	}
+
+	for name, grammar := range grammars {
+		for run := range 4 {
+			t.Run(name+"/"+string(rune('a'+run)), func(t *testing.T) {
+				t.Parallel()
+				parser_generator := generator.Generator{}
+				result, err := parser_generator.Generate(parseGrammarFile(t, grammar), generator.Options{})
+				if err != nil {
+					t.Fatalf("Generate failed: %v", err)
+				}
+				if !reflect.DeepEqual(result.Bnf, expected[name].Bnf) || !reflect.DeepEqual(result.Table, expected[name].Table) {
+					t.Errorf("Expected the same BNF and parse table as when generated alone")
+				}
+				if !reflect.DeepEqual(result.First, expected[name].First) || !reflect.DeepEqual(result.Follow, expected[name].Follow) {
+					t.Errorf("Expected the same FIRST and FOLLOW sets as when generated alone")
+				}
+
+				path := filepath.Join(t.TempDir(), "generated_parser.go")
+				if err := parser_writer.WriteParserForGrammar(path, parseGrammarFile(t, grammar)); err != nil {
+					t.Fatalf("WriteParserForGrammar failed: %v", err)
+				}
+				if code, _ := os.ReadFile(path); string(code) != expected_code[name] {
+					t.Errorf("Expected the same parser as when written alone")
+				}
+			})
+		}
+	}
+}
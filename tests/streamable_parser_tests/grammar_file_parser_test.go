@@ -0,0 +1,347 @@
+package streamable_parser_tests
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/VirajAgarwal1/lox/errorhandler"
+	"github.com/VirajAgarwal1/lox/grammar_file"
+	"github.com/VirajAgarwal1/lox/lexer"
+)
+
+// helper: prints a grammar term back in the grammar file notation
+func describeTerm(term grammar_file.Generic_grammar_term) string {
+	switch term.Get_grammar_term_type() {
+	case "terminal":
+		return "\"" + string(term.(*grammar_file.Terminal).Content) + "\""
+	case "non_terminal":
+		return term.(*grammar_file.Non_terminal).Name
+	case "or":
+		return "or"
+	case "star":
+		return describeTerm(term.(*grammar_file.Star).Content) + "*"
+	case "plus":
+		return describeTerm(term.(*grammar_file.Plus).Content) + "+"
+	case "optional":
+		return describeTerm(term.(*grammar_file.Optional).Content) + "?"
+	case "bracket":
+		return "( " + describeTerms(term.(*grammar_file.Bracket).Contents) + " )"
+	case "action":
+		return "{" + term.(*grammar_file.Action).Code + "}"
+	case "labelled":
+		return term.(*grammar_file.Labelled).Label + ":" + describeTerm(term.(*grammar_file.Labelled).Content)
+	}
+	return "?"
+}
+func describeTerms(terms []grammar_file.Generic_grammar_term) string {
+	parts := make([]string, len(terms))
+	for i, term := range terms {
+		parts[i] = describeTerm(term)
+	}
+	return strings.Join(parts, " ")
+}
+
+// helper: parses a grammar and prints its rules sorted by name, one per line
+func describeGrammar(t *testing.T, grammar string) string {
+	t.Helper()
+	scanner := lexer.LexicalAnalyzer{}
+	scanner.Initialize(bufio.NewReader(strings.NewReader(grammar)))
+	rules, err := grammar_file.ProcessGrammarDefinition(&scanner)
+	if err != nil && err != io.EOF {
+		t.Fatalf("Could not parse the grammar: %v", err)
+	}
+	lines := []string{}
+	for non_term, terms := range rules {
+		lines = append(lines, non_term.Name+" -> "+describeTerms(terms))
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+func TestGrammarFileMultiLineProductions(t *testing.T) {
+	single_line := `expression -> term
+term -> factor ( ( "-" or "+" ) factor )*
+factor -> "NUMBER" or "(" expression ")"`
+
+	tests := []struct {
+		name    string
+		grammar string
+	}{
+		{
+			name: "indented continuation lines",
+			grammar: `expression -> term
+term -> factor
+    ( ( "-" or "+" ) factor )*
+factor -> "NUMBER"
+	or "(" expression ")"
+`,
+		},
+		{
+			name: "semicolon terminated",
+			grammar: `expression -> term;
+term -> factor
+( ( "-" or "+" ) factor )*
+;
+factor -> "NUMBER" or "(" expression ")";`,
+		},
+		{
+			name:    "several productions on one line",
+			grammar: `expression -> term; term -> factor ( ( "-" or "+" ) factor )*; factor -> "NUMBER" or "(" expression ")"`,
+		},
+		{
+			name: "comments and blank lines between and inside rules",
+			grammar: `// A tiny expression grammar
+
+expression -> term // the start
+// terms
+term -> factor
+
+    // the operators
+    ( ( "-" or "+" ) factor )*
+
+
+factor -> "NUMBER" or "(" expression ")"
+// trailing comment`,
+		},
+	}
+
+	expected := describeGrammar(t, single_line)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeGrammar(t, tt.grammar); got != expected {
+				t.Errorf("Unexpected rules:\n%s\nExpected:\n%s", got, expected)
+			}
+		})
+	}
+}
+
+func TestGrammarFileLoxGrammarUnchanged(t *testing.T) {
+	content, err := os.ReadFile("../../parser/lox.grammar")
+	if err != nil {
+		t.Fatalf("Could not read lox.grammar: %v", err)
+	}
+
+	expected := `comma -> equality ( "," equality )*
+comparison -> term ( ( ">" or ">=" or "<" or "<=" ) term )*
+equality -> comparison ( ( "!=" or "==" ) comparison )*
+expression -> comma
+factor -> unary ( ( "/" or "*" ) unary )*
+primary -> "IDENTIFIER" or "NUMBER" or "STRING" or "true" or "false" or "nil" or "(" expression ")"
+term -> factor ( ( "-" or "+" ) factor )*
+unary -> ( "!" or "-" ) unary or primary`
+	if got := describeGrammar(t, string(content)); got != expected {
+		t.Errorf("Unexpected rules:\n%s\nExpected:\n%s", got, expected)
+	}
+}
+
+func TestGrammarFileStraySemicolon(t *testing.T) {
+	scanner := lexer.LexicalAnalyzer{}
+	scanner.Initialize(bufio.NewReader(strings.NewReader("expression -> term\n;\n")))
+	_, err := grammar_file.ProcessGrammarDefinition(&scanner)
+	if err != nil && err != io.EOF {
+		t.Fatalf("Expected a ';' on its own line to end the production, got %v", err)
+	}
+
+	scanner = lexer.LexicalAnalyzer{}
+	scanner.Initialize(bufio.NewReader(strings.NewReader("expression -> term;;\n")))
+	_, err = grammar_file.ProcessGrammarDefinition(&scanner)
+	if err == nil || err == io.EOF {
+		t.Errorf("Expected an error for a ';' outside of a production")
+	}
+}
+
+func TestGrammarFileUnclosedBracket(t *testing.T) {
+	// The production ends at the end of the file, the next rule or a ';', with the bracket still open
+	for input, expected := range map[string]string{
+		"a -> ( \"NUMBER\"":                             "1:6: error[G0001]: invalid grammar: unclosed '('",
+		"a -> \"STRING\" [ \"NUMBER\"\nb -> \"STRING\"": "1:15: error[G0001]: invalid grammar: unclosed '['",
+		"a -> ( \"NUMBER\" ( \"STRING\" );":             "1:6: error[G0001]: invalid grammar: unclosed '('",
+	} {
+		scanner := lexer.LexicalAnalyzer{}
+		scanner.Initialize(bufio.NewReader(strings.NewReader(input)))
+		_, err := grammar_file.ParseGrammar(&scanner)
+		var grammar_err *errorhandler.GrammarError
+		if !errors.As(err, &grammar_err) || grammar_err.Error() != expected {
+			t.Errorf("Expected %q for %q, got %v", expected, input, err)
+		}
+	}
+}
+
+func TestGrammarFileOptional(t *testing.T) {
+	got := describeGrammar(t, `call -> primary ( "(" [ expression ( "," expression )* ] ")" )*
+unary -> "-"? primary`)
+	expected := `call -> primary ( "(" ( expression ( "," expression )* )? ")" )*
+unary -> "-"? primary`
+	if got != expected {
+		t.Errorf("Unexpected rules:\n%s\nExpected:\n%s", got, expected)
+	}
+
+	if describeGrammar(t, `a -> [ "-" or b ] c`) != describeGrammar(t, `a -> ( "-" or b )? c`) {
+		t.Errorf("Expected `[ ... ]` to be the same as `( ... )?`")
+	}
+
+	for _, invalid := range []string{"a -> ? b", "a -> ( b ]", "a -> [ b )", "a -> b or ?", "a -> b ]"} {
+		scanner := lexer.LexicalAnalyzer{}
+		scanner.Initialize(bufio.NewReader(strings.NewReader(invalid)))
+		_, err := grammar_file.ProcessGrammarDefinition(&scanner)
+		if err == nil || err == io.EOF {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestGrammarFileLabels(t *testing.T) {
+	got := describeGrammar(t, `binary -> left:term op:( "+" or "-" ) right:term
+list -> "(" items:item* [ last : item ] ")"
+item -> ( name:"IDENTIFIER" or value:"NUMBER" )+`)
+	expected := `binary -> left:term op:( "+" or "-" ) right:term
+item -> ( name:"IDENTIFIER" or value:"NUMBER" )+
+list -> "(" items:item* ( last:item )? ")"`
+	if got != expected {
+		t.Errorf("Unexpected rules:\n%s\nExpected:\n%s", got, expected)
+	}
+
+	for _, invalid := range []string{"a -> b:", "a -> b: or c", "a -> : b", "a -> \"+\":b", "a -> b:*", "a -> ( b: )", "a -> b:c:d", "a -> ( b ):c"} {
+		scanner := lexer.LexicalAnalyzer{}
+		scanner.Initialize(bufio.NewReader(strings.NewReader(invalid)))
+		_, err := grammar_file.ProcessGrammarDefinition(&scanner)
+		if err == nil || err == io.EOF {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestGrammarFileActions(t *testing.T) {
+	got := describeGrammar(t, `binary -> left:term "+" right:term { return $left.(int) + $right.(int) }
+    or term {
+        if s := "}"; s != "" { return $1 }
+        return nil
+    }
+term -> "NUMBER"`)
+	expected := `binary -> left:term "+" right:term { return $left.(int) + $right.(int) } or term {
+        if s := "}"; s != "" { return $1 }
+        return nil
+    }
+term -> "NUMBER"`
+	if got != expected {
+		t.Errorf("Unexpected rules:\n%s\nExpected:\n%s", got, expected)
+	}
+
+	for _, invalid := range []string{
+		"a -> { return 1 }",
+		"a -> b or { return 1 }",
+		"a -> b { return 1 } c",
+		"a -> b { return 1 }*",
+		"a -> ( b { return 1 } )",
+		"a -> b x:{ return 1 }",
+		"a -> b { return 1",
+	} {
+		scanner := lexer.LexicalAnalyzer{}
+		scanner.Initialize(bufio.NewReader(strings.NewReader(invalid)))
+		_, err := grammar_file.ProcessGrammarDefinition(&scanner)
+		if err == nil || err == io.EOF {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestGrammarFileExpressions(t *testing.T) {
+	got := describeGrammar(t, `%nonassoc "==" "!="
+%left "+" "-"
+%right "^"
+%expr binary unary
+unary -> "-" unary or "NUMBER"`)
+	expected := `binary -> binary_1 ( ( "==" or "!=" ) binary_1 )?
+binary_1 -> binary_1 ( "+" or "-" ) binary_2 or binary_2
+binary_2 -> unary ( "^" binary_2 )?
+unary -> "-" unary or "NUMBER"`
+	if got != expected {
+		t.Errorf("Unexpected rules:\n%s\nExpected:\n%s", got, expected)
+	}
+
+	for _, invalid := range []string{
+		"%expr binary unary\nunary -> \"NUMBER\"",
+		"%left \"+\"\n%expr binary\nunary -> \"NUMBER\"",
+		"%left \"+\"\n%expr binary \"NUMBER\"",
+		"%left\na -> \"NUMBER\"",
+		"%nonassoc\na -> \"NUMBER\"",
+	} {
+		scanner := lexer.LexicalAnalyzer{}
+		scanner.Initialize(bufio.NewReader(strings.NewReader(invalid)))
+		_, err := grammar_file.ParseGrammar(&scanner)
+		if err == nil || err == io.EOF {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestGrammarFileDirectives(t *testing.T) {
+	scanner := lexer.LexicalAnalyzer{}
+	scanner.Initialize(bufio.NewReader(strings.NewReader(`%token NAME
+%skip WHITESPACE " " COMMENT; %token OTHER
+%import "strconv" "github.com/example/ast"
+expr -> "NAME" or "OTHER"
+%start program
+program -> expr ( "," expr )*
+`)))
+	grammar, err := grammar_file.ParseGrammar(&scanner)
+	if err != nil && err != io.EOF {
+		t.Fatalf("Could not parse the grammar: %v", err)
+	}
+
+	if grammar.Start != "program" {
+		t.Errorf("Expected start symbol program, got %q", grammar.Start)
+	}
+	if strings.Join(grammar.Tokens, ",") != "NAME,OTHER" {
+		t.Errorf("Unexpected tokens %v", grammar.Tokens)
+	}
+	if strings.Join(grammar.Skip, ",") != "WHITESPACE, ,COMMENT" {
+		t.Errorf("Unexpected skip set %v", grammar.Skip)
+	}
+	if strings.Join(grammar.Imports, ",") != "strconv,github.com/example/ast" {
+		t.Errorf("Unexpected imports %v", grammar.Imports)
+	}
+	if len(grammar.Order) != 2 || grammar.Order[0].Name != "expr" || grammar.Order[1].Name != "program" {
+		t.Errorf("Unexpected rule order %v", grammar.Order)
+	}
+	if len(grammar.Rules[grammar_file.Non_terminal{Name: "expr"}]) != 3 {
+		t.Errorf("Expected the directive to end the production before it, got %v", describeTerms(grammar.Rules[grammar_file.Non_terminal{Name: "expr"}]))
+	}
+}
+
+func TestGrammarFileDefaultStart(t *testing.T) {
+	scanner := lexer.LexicalAnalyzer{}
+	scanner.Initialize(bufio.NewReader(strings.NewReader("b -> \"NUMBER\"\na -> b\n")))
+	grammar, _ := grammar_file.ParseGrammar(&scanner)
+	if grammar.Start != "b" {
+		t.Errorf("Expected the first rule to be the start symbol, got %q", grammar.Start)
+	}
+}
+
+func TestGrammarFileInvalidDirectives(t *testing.T) {
+	for _, invalid := range []string{
+		"%\na -> b",
+		"%begin a\na -> b",
+		"%start\na -> b",
+		"%start a b\na -> b",
+		"%start a\n%start b\na -> b",
+		"%token\na -> b",
+		"%skip ( \na -> b",
+		"%import\na -> b",
+		"%resolve\na -> b",
+		"%resolve a\na -> b",
+		"%resolve \"a\" \"else\"\na -> b",
+	} {
+		scanner := lexer.LexicalAnalyzer{}
+		scanner.Initialize(bufio.NewReader(strings.NewReader(invalid)))
+		_, err := grammar_file.ParseGrammar(&scanner)
+		if err == nil || err == io.EOF {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
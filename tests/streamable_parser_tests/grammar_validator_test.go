@@ -0,0 +1,138 @@
+package streamable_parser_tests
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/VirajAgarwal1/lox/errorhandler"
+	"github.com/VirajAgarwal1/lox/grammar_file"
+	"github.com/VirajAgarwal1/lox/lexer"
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/grammar_validator"
+)
+
+// helper: validates a grammar and describes each diagnostic as "severity[code] line:column"
+func validateGrammar(t *testing.T, grammar string) []string {
+	t.Helper()
+	scanner := lexer.LexicalAnalyzer{}
+	scanner.Initialize(bufio.NewReader(strings.NewReader(grammar)))
+	parsed, err := grammar_file.ParseGrammar(&scanner)
+	if err != nil && err != io.EOF {
+		t.Fatalf("Could not parse the grammar: %v", err)
+	}
+	found := []string{}
+	for _, diag := range grammar_validator.Validate(parsed).Sorted() {
+		found = append(found, fmt.Sprintf("%s[%s] %d:%d", diag.Severity, diag.Code, diag.Position.Line, diag.Position.Column))
+	}
+	return found
+}
+
+func TestGrammarValidator(t *testing.T) {
+	tests := []struct {
+		name     string
+		grammar  string
+		expected []string
+	}{
+		{
+			name:     "valid grammar",
+			grammar:  "expr -> term ( \"+\" term )*\nterm -> \"NUMBER\" or \"(\" expr \")\"",
+			expected: []string{},
+		},
+		{
+			name:     "undefined non-terminal",
+			grammar:  "expr -> term \"+\" factor\nterm -> \"NUMBER\"",
+			expected: []string{"error[G0002] 1:18"},
+		},
+		{
+			name:     "undefined start symbol",
+			grammar:  "%start program\nexpr -> \"NUMBER\"",
+			expected: []string{"error[G0002] 1:8"},
+		},
+		{
+			name:     "unknown terminal",
+			grammar:  "expr -> \"NAME\" or \"NUMBER\"",
+			expected: []string{"error[G0003] 1:9"},
+		},
+		{
+			name:     "declared terminal",
+			grammar:  "%token NAME\nexpr -> \"NAME\" or \"NUMBER\"",
+			expected: []string{},
+		},
+		{
+			name:     "duplicate rule",
+			grammar:  "expr -> term\nterm -> \"NUMBER\"\nterm -> \"STRING\"",
+			expected: []string{"error[G0004] 3:1"},
+		},
+		{
+			name:     "empty alternatives",
+			grammar:  "a -> or b\nb -> \"NUMBER\" or or c\nc -> ( ) \"+\"\nd -> a or;",
+			expected: []string{"error[G0005] 1:6", "error[G0005] 2:18", "error[G0005] 3:6", "warning[G0007] 4:1", "error[G0005] 4:8"},
+		},
+		{
+			name:     "empty production",
+			grammar:  "a -> ;",
+			expected: []string{"error[G0005] 1:1"},
+		},
+		{
+			name:     "unproductive rules",
+			grammar:  "expr -> \"NUMBER\" or loop\nloop -> \"(\" loop \")\" or other+\nother -> loop",
+			expected: []string{"error[G0006] 2:1", "error[G0006] 3:1"},
+		},
+		{
+			name:     "star and optional can match nothing",
+			grammar:  "list -> \"(\" list* \")\" list?",
+			expected: []string{},
+		},
+		{
+			name:     "unused and unreachable rules",
+			grammar:  "expr -> \"NUMBER\"\nunused -> dead\ndead -> \"STRING\" or dead\nself -> \"nil\" or self",
+			expected: []string{"warning[G0007] 2:1", "warning[G0008] 3:1", "warning[G0007] 4:1"},
+		},
+		{
+			name:     "expressions",
+			grammar:  "%left \"+\" \"#\"\n%right \"^\"\n%expr sum operand\nsum_1 -> \"NUMBER\"",
+			expected: []string{"error[G0003] 3:1", "error[G0004] 4:1"},
+		},
+		{
+			name:     "expression without operands",
+			grammar:  "%left \"+\"\n%expr sum operand",
+			expected: []string{"error[G0002] 2:1"},
+		},
+		{
+			name:     "actions",
+			grammar:  "sum -> left:n \"+\" n { return $left } or n { return $0 } or x:n { return $left }\nn -> \"NUMBER\" { return $1 }",
+			expected: []string{"error[G0011] 1:43", "error[G0011] 1:64"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validateGrammar(t, tt.grammar)
+			if strings.Join(got, ", ") != strings.Join(tt.expected, ", ") {
+				t.Errorf("Unexpected diagnostics:\n%v\nExpected:\n%v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGrammarValidatorLoxGrammar(t *testing.T) {
+	content, err := os.ReadFile("../../parser/lox.grammar")
+	if err != nil {
+		t.Fatalf("Could not read lox.grammar: %v", err)
+	}
+	if got := validateGrammar(t, string(content)); len(got) != 0 {
+		t.Errorf("Expected lox.grammar to be valid, got %v", got)
+	}
+}
+
+func TestWriteParserForGrammarValidates(t *testing.T) {
+	_, err := writeParserFor(t, "expr -> term\n")
+	var diag *errorhandler.Diagnostic
+	if !errors.As(err, &diag) || diag.Code != errorhandler.CodeUndefinedNonTerminal {
+		t.Errorf("Expected the undefined non-terminal to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package streamable_parser_tests
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/VirajAgarwal1/lox/errorhandler"
+	"github.com/VirajAgarwal1/lox/grammar_file"
+	"github.com/VirajAgarwal1/lox/grammar_file/formats"
+	"github.com/VirajAgarwal1/lox/source"
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/ebnf_to_bnf"
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/parser_writer"
+)
+
+func TestImportedGrammarsGenerateParsers(t *testing.T) {
+	tests := []struct {
+		name     string
+		read     func(file *source.File) (*grammar_file.Grammar, *errorhandler.Diagnostics)
+		input    string
+		warnings int
+		expected []string
+	}{
+		{
+			name: "antlr4",
+			read: formats.Read_antlr4,
+			input: `grammar Config;
+tokens { KEY }
+
+config : entry* EOF ;
+entry returns [String text]
+    : key=KEY '=' value { $text = $key.text; } # Assignment
+    ;
+value : NUMBER | STRING | '(' value ( ',' value )* ')' ;
+
+WHITESPACE : [ \t]+ -> skip ;
+NEWLINE : '\n' -> channel(HIDDEN) ;`,
+			warnings: 3,
+			expected: []string{
+				`const StartingNonTerminal string = "config"`,
+				`Terminal_type: dfa.TokenType("KEY"), Label: "key"`,
+				`dfa.WHITESPACE: {},`,
+				`dfa.NEWLINE: {},`,
+			},
+		},
+		{
+			name: "yacc",
+			read: formats.Read_yacc,
+			input: `%token KEY
+%token WHILE_KW "while"
+%start program
+%%
+program : statements ;
+statements : statement statements { $$ = append($1, $2); }
+           | %empty
+           ;
+statement : WHILE_KW '(' KEY ')' block
+          | KEY '=' NUMBER ';'
+          ;
+block : '{' statements '}' ;
+%%
+`,
+			warnings: 1,
+			expected: []string{
+				`const StartingNonTerminal string = "program"`,
+				`dfa.TokenType("KEY")`,
+				`dfa.WHILE`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grammar, diagnostics := tt.read(source.NewFileSet().AddFile(tt.name, []byte(tt.input)))
+			if err := diagnostics.Err(); err != nil {
+				t.Fatalf("Could not import the grammar: %v", err)
+			}
+			if diagnostics.WarningCount() != tt.warnings {
+				t.Errorf("Expected %d warnings about dropped constructs, got %v", tt.warnings, diagnostics.Sorted())
+			}
+			if bnf := ebnf_to_bnf.ConvertGrammar(grammar); len(bnf) < len(grammar.Order) {
+				t.Errorf("Expected every rule to be converted to BNF, got %v", bnf)
+			}
+
+			path := filepath.Join(t.TempDir(), "generated_parser.go")
+			if err := parser_writer.WriteParserForGrammar(path, grammar); err != nil {
+				t.Fatalf("WriteParserForGrammar failed: %v", err)
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Could not read the generated parser: %v", err)
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(string(content), expected) {
+					t.Errorf("Expected the generated parser to contain %q", expected)
+				}
+			}
+		})
+	}
+}
+
+func TestImportedGrammarWithErrorsIsRejected(t *testing.T) {
+	_, diagnostics := formats.Read_yacc(source.NewFileSet().AddFile("errors.y", []byte("%%\nlist : item | list error ;\nitem : NUMBER ;\n")))
+	var diag *errorhandler.Diagnostic
+	if err := diagnostics.Err(); err == nil || !errors.As(err, &diag) || diag.Code != errorhandler.CodeUnsupportedConstruct {
+		t.Errorf("Expected the error token to be reported as unsupported, got %v", diagnostics.Sorted())
+	}
+}
@@ -0,0 +1,161 @@
+package streamable_parser_tests
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/VirajAgarwal1/lox/lexer/dfa"
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/bnf_transforms"
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/ebnf_to_bnf"
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/generator"
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/utils"
+)
+
+// helper: converts and left factors a grammar, and prints its productions with `describeBnf`
+func factoredOf(t *testing.T, grammar string) (map[string]string, int) {
+	t.Helper()
+	bnf := ebnf_to_bnf.ConvertGrammar(parseGrammarFile(t, grammar))
+	factored := bnf_transforms.Left_factor(bnf)
+	return describeBnf(bnf), factored
+}
+
+// helper: prints the productions of every non-terminal in order, `#n` standing for the mark of the alternative n and `^` for the mark which reassociates
+func describeBnf(bnf map[string]([][]utils.Grammar_element)) map[string]string {
+	out := map[string]string{}
+	for non_term, productions := range bnf {
+		printed := []string{}
+		for _, production := range productions {
+			parts := []string{}
+			for _, el := range production {
+				part := string(el.Terminal_type)
+				switch {
+				case el.IsNonTerminal:
+					part = "<" + el.Non_term_name + ">"
+				case el.Marks_alternative:
+					part = "#" + strconv.Itoa(el.Alternative)
+				case el.Reassociates:
+					part = "^"
+				}
+				if el.Label != "" {
+					part = el.Label + ":" + part
+				}
+				parts = append(parts, part)
+			}
+			printed = append(printed, strings.Join(parts, " "))
+		}
+		out[non_term] = strings.Join(printed, " | ")
+	}
+	return out
+}
+
+func TestLeftFactorRules(t *testing.T) {
+	bnf, factored := factoredOf(t, `stmt -> "if" "(" cond ")" stmt or "if" "(" cond ")" stmt "else" stmt or "NUMBER"
+cond -> "IDENTIFIER"`)
+	if factored != 1 {
+		t.Errorf("Expected stmt to be factored once, got %d", factored)
+	}
+	// The alternatives are marked, as stmt cannot choose them anymore
+	if bnf["stmt"] != `if ( <cond> ) <stmt> <999_stmt_factor_1> | NUMBER #2` {
+		t.Errorf("Unexpected productions for stmt: %s", bnf["stmt"])
+	}
+	if bnf["999_stmt_factor_1"] != `#0 | else <stmt> #1` {
+		t.Errorf("Unexpected productions for what follows the common prefix: %s", bnf["999_stmt_factor_1"])
+	}
+	if bnf["cond"] != `IDENTIFIER` {
+		t.Errorf("Expected cond to be left alone, got %s", bnf["cond"])
+	}
+}
+
+func TestLeftFactorNested(t *testing.T) {
+	// The productions left after a prefix can share a prefix of their own
+	bnf, factored := factoredOf(t, `a -> "NUMBER" "+" "NUMBER" or "NUMBER" "+" "STRING" or "NUMBER" or "STRING"`)
+	if factored != 2 {
+		t.Errorf("Expected two factorings, got %d", factored)
+	}
+	if bnf["a"] != `NUMBER <999_a_factor_1> | STRING #3` {
+		t.Errorf("Unexpected productions for a: %s", bnf["a"])
+	}
+	if bnf["999_a_factor_1"] != `+ <999_a_factor_1_factor_1> | #2` {
+		t.Errorf("Unexpected productions for 999_a_factor_1: %s", bnf["999_a_factor_1"])
+	}
+	if bnf["999_a_factor_1_factor_1"] != `NUMBER #0 | STRING #1` {
+		t.Errorf("Unexpected productions for 999_a_factor_1_factor_1: %s", bnf["999_a_factor_1_factor_1"])
+	}
+}
+
+func TestLeftFactorPlus(t *testing.T) {
+	// `X -> a X | a`, given by `+`, is not LL(1). Artificial non-terminals need no marks.
+	bnf, _ := factoredOf(t, `digits -> "NUMBER"+`)
+	plus := strings.Trim(bnf["digits"], "<>")
+	if bnf[plus] != `NUMBER <`+"999_digits_plus_1_factor_1"+`>` {
+		t.Fatalf("Unexpected productions for %s: %s", plus, bnf[plus])
+	}
+	if bnf["999_digits_plus_1_factor_1"] != `<`+plus+`> | `+string(utils.Epsilon) {
+		t.Errorf("Unexpected productions for the rest of the plus: %s", bnf["999_digits_plus_1_factor_1"])
+	}
+}
+
+func TestLeftFactorKeepsLabels(t *testing.T) {
+	// Elements with different labels are different: the events of the alternatives are not the same
+	bnf, factored := factoredOf(t, `pair -> left:"NUMBER" "+" or right:"NUMBER" "-" or left:"NUMBER" "*"`)
+	if factored != 1 {
+		t.Errorf("Expected one factoring, got %d", factored)
+	}
+	if bnf["pair"] != `left:NUMBER <999_pair_factor_1> | right:NUMBER - #1` || bnf["999_pair_factor_1"] != `+ #0 | * #2` {
+		t.Errorf("Unexpected productions %v", bnf)
+	}
+}
+
+func TestLeftFactorIsDeterministic(t *testing.T) {
+	grammar := `a -> "NUMBER" "+" or "NUMBER" "-" or b "+" or b "-"
+b -> "STRING" or "STRING" "STRING"
+c -> ( "NUMBER" "+" )+ ( "NUMBER" "-" )+`
+	first, _ := factoredOf(t, grammar)
+	for range 10 {
+		again, _ := factoredOf(t, grammar)
+		for non_term := range first {
+			if first[non_term] != again[non_term] {
+				t.Fatalf("Expected the same productions for %s, got %s and %s", non_term, first[non_term], again[non_term])
+			}
+		}
+		if len(first) != len(again) {
+			t.Fatalf("Expected the same non-terminals, got %d and %d", len(first), len(again))
+		}
+	}
+}
+
+func TestGenerateLeftFactored(t *testing.T) {
+	parser_generator := generator.Generator{}
+	result, err := parser_generator.Generate(parseGrammarFile(t, `%resolve stmt "else"
+stmt -> "if" "(" "IDENTIFIER" ")" stmt or "if" "(" "IDENTIFIER" ")" stmt "else" stmt or "NUMBER"`), generator.Options{})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if result.Factored != 1 {
+		t.Errorf("Expected one factoring, got %d", result.Factored)
+	}
+
+	// The dangling `else` goes with the closest `if`, the production which matches it is chosen over the one which matches nothing
+	row := result.Table["999_stmt_factor_1"]
+	if row[dfa.ELSE] != 1 || row[dfa.EOF] != 0 {
+		t.Errorf("Unexpected row for what follows the `if` %v", row)
+	}
+	tokens := []string{}
+	for token := range result.Table["stmt"] {
+		tokens = append(tokens, string(token))
+	}
+	sort.Strings(tokens)
+	if strings.Join(tokens, " ") != "NUMBER if" {
+		t.Errorf("Expected stmt to be chosen on `if` and NUMBER only, got %v", tokens)
+	}
+
+	code, err := writeParserFor(t, `a -> "NUMBER" "+" or "NUMBER" "-"`)
+	if err != nil {
+		t.Fatalf("WriteParserForGrammar failed: %v", err)
+	}
+	if !strings.Contains(code, "{IsNonTerminal: false, Terminal_type: utils.Epsilon, Marks_alternative: true, Alternative: 1},") {
+		t.Errorf("Expected the marks of the alternatives in the generated parser\n%s", code)
+	}
+}
@@ -0,0 +1,89 @@
+package streamable_parser_tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/generator"
+)
+
+const leftRecursiveGrammar = `%skip WHITESPACE
+expr -> left:expr "-" term or expr "+" term or term
+term -> "NUMBER" or "(" expr ")"`
+
+func TestGenerateLeftRecursive(t *testing.T) {
+	// Used to recurse forever while computing the FIRST set of expr
+	parser_generator := generator.Generator{}
+	result, err := parser_generator.Generate(parseGrammarFile(t, leftRecursiveGrammar), generator.Options{})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if len(result.Grammar.Left_recursions) != 1 {
+		t.Errorf("Expected expr to be rewritten, got %v", result.Grammar.Left_recursions)
+	}
+	bnf := describeBnf(result.Bnf)
+	if bnf["expr"] != `<term> <999_expr_star_1>` {
+		t.Errorf("Expected expr to repeat its tails, got %s", bnf["expr"])
+	}
+	if row := result.Table["999_expr_star_1"]; len(row) != 4 {
+		t.Errorf("Expected the repetition to go on with `-` and `+`, and stop before `)` and the end, got %v", row)
+	}
+}
+
+func TestGenerateLeftRecursiveReassociated(t *testing.T) {
+	parser_generator := generator.Generator{}
+	result, err := parser_generator.Generate(parseGrammarFile(t, leftRecursiveGrammar), generator.Options{Reassociate: true})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	// The base ends with the mark of its alternative, each tail starts with the mark which reassociates
+	bnf := describeBnf(result.Bnf)
+	if bnf["expr"] != `<term> #2 <999_expr_tail_1>` {
+		t.Errorf("Unexpected productions for expr: %s", bnf["expr"])
+	}
+	if bnf["999_expr_tail_1"] != `left:^ - <term> #0 <999_expr_tail_1> | ^ + <term> #1 <999_expr_tail_1> | Epsilon` {
+		t.Errorf("Unexpected productions for the tails: %s", bnf["999_expr_tail_1"])
+	}
+
+	// Tails which start the same way are factored with their marks
+	result, err = parser_generator.Generate(parseGrammarFile(t, `expr -> expr "-" "NUMBER" or expr "-" "STRING" or "NUMBER"`), generator.Options{Reassociate: true})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	bnf = describeBnf(result.Bnf)
+	if bnf["999_expr_tail_1"] != `^ - <999_expr_tail_1_factor_1> | Epsilon` || bnf["999_expr_tail_1_factor_1"] != `NUMBER #0 <999_expr_tail_1> | STRING #1 <999_expr_tail_1>` {
+		t.Errorf("Unexpected factored tails %v", bnf)
+	}
+
+	code, err := writeParserFor(t, "%reassociate\n"+leftRecursiveGrammar)
+	if err != nil {
+		t.Fatalf("WriteParserForGrammar failed: %v", err)
+	}
+	if !strings.Contains(code, `{IsNonTerminal: false, Terminal_type: utils.Epsilon, Label: "left", Reassociates: true},`) {
+		t.Errorf("Expected the marks which reassociate in the generated parser\n%s", code)
+	}
+}
+
+func TestGenerateLeftRecursiveActions(t *testing.T) {
+	grammar := `expr -> left:expr "-" right:term { return $left.(int) - $right.(int) } or term { return $1 }
+term -> "NUMBER" { return 1 }`
+	parser_generator := generator.Generator{}
+	if _, err := parser_generator.Generate(parseGrammarFile(t, grammar), generator.Options{}); err == nil || !strings.Contains(err.Error(), "%reassociate") {
+		t.Errorf("Expected actions on a left-recursive rule to need %%reassociate, got %v", err)
+	}
+
+	// The actions are found by the alternatives the rule was written with
+	code, err := writeParserFor(t, "%reassociate\n"+grammar)
+	if err != nil {
+		t.Fatalf("WriteParserForGrammar failed: %v", err)
+	}
+	for _, expected := range []string{
+		`0: func(values ActionValues) any { return values.Get("left").(int) - values.Get("right").(int) },`,
+		`1: func(values ActionValues) any { return values.At(0) },`,
+	} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Expected the generated parser to contain %q\n%s", expected, code)
+		}
+	}
+}
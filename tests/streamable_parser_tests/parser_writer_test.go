@@ -0,0 +1,185 @@
+package streamable_parser_tests
+
+import (
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/VirajAgarwal1/lox/streamable_parser/parser_generator/parser_writer"
+)
+
+// helper: parses a grammar file and writes its parser to a temporary file, returning the generated code
+func writeParserFor(t *testing.T, grammar string) (string, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "generated_parser.go")
+	if err := parser_writer.WriteParserForGrammar(path, parseGrammarFile(t, grammar)); err != nil {
+		return "", err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Could not read the generated parser: %v", err)
+	}
+	return string(content), nil
+}
+
+func TestWriteParserForGrammarDirectives(t *testing.T) {
+	code, err := writeParserFor(t, `%token NAME
+%skip WHITESPACE
+%start program
+item -> "NAME" or "NUMBER"
+program -> item ( "," item )*
+`)
+	if err != nil {
+		t.Fatalf("WriteParserForGrammar failed: %v", err)
+	}
+
+	for _, expected := range []string{
+		`const StartingNonTerminal string = "program"`,
+		`var SkipTokens = map[dfa.TokenType]struct{}{`,
+		`dfa.WHITESPACE: {},`,
+		`dfa.TokenType("NAME")`,
+	} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Expected the generated parser to contain %q", expected)
+		}
+	}
+}
+
+func TestWriteParserForGrammarLabels(t *testing.T) {
+	code, err := writeParserFor(t, `binary -> left:term "+" right:term
+term -> value:"NUMBER"
+`)
+	if err != nil {
+		t.Fatalf("WriteParserForGrammar failed: %v", err)
+	}
+	for _, expected := range []string{
+		`{IsNonTerminal: true, Non_term_name: "term", Label: "left"},`,
+		`{IsNonTerminal: false, Terminal_type: dfa.PLUS},`,
+		`{IsNonTerminal: true, Non_term_name: "term", Label: "right"},`,
+		`{IsNonTerminal: false, Terminal_type: dfa.NUMBER, Label: "value"},`,
+	} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Expected the generated parser to contain %q", expected)
+		}
+	}
+}
+
+func TestWriteParserForGrammarActions(t *testing.T) {
+	code, err := writeParserFor(t, `%import "strconv"
+sum -> left:number "+" right:number { return $left.(float64) + $right.(float64) } or "-" number
+number -> "NUMBER" { value, _ := strconv.ParseFloat(string($1.(*lexer.Token).Lexemme), 64); return value }
+`)
+	if err != nil {
+		t.Fatalf("WriteParserForGrammar failed: %v", err)
+	}
+	for _, expected := range []string{
+		"\t\"github.com/VirajAgarwal1/lox/lexer\"\n\t\"strconv\"\n)",
+		"func init() {\n\tSemanticActions = map[string]map[int]func(values ActionValues) any{\n",
+		"\t\t\"sum\": {\n\t\t\t0: func(values ActionValues) any { return values.Get(\"left\").(float64) + values.Get(\"right\").(float64) },\n\t\t},\n",
+		// The parser is gofmt'd, which puts the statements of an action on their own lines
+		"\t\t\"number\": {\n\t\t\t0: func(values ActionValues) any {\n\t\t\t\tvalue, _ := strconv.ParseFloat(string(values.At(0).(*lexer.Token).Lexemme), 64)\n\t\t\t\treturn value\n\t\t\t},\n\t\t},\n",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Expected the generated parser to contain %q\n%s", expected, code)
+		}
+	}
+
+	// Grammars without actions leave `SemanticActions` alone
+	code, _ = writeParserFor(t, "a -> \"NUMBER\"")
+	if strings.Contains(code, "SemanticActions") {
+		t.Errorf("Expected no actions to be written\n%s", code)
+	}
+}
+
+func TestWriteParserForGrammarExpressions(t *testing.T) {
+	code, err := writeParserFor(t, `%left "+" "-"
+%right "="
+%expr sum unary
+unary -> "NUMBER"
+`)
+	if err != nil {
+		t.Fatalf("WriteParserForGrammar failed: %v", err)
+	}
+	for _, expected := range []string{
+		`const StartingNonTerminal string = "sum"`,
+		`{IsNonTerminal: true, Non_term_name: "sum_1"},`,
+		`{IsNonTerminal: true, Non_term_name: "unary"},`,
+		`{IsNonTerminal: false, Terminal_type: dfa.EQUAL},`,
+	} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Expected the generated parser to contain %q", expected)
+		}
+	}
+}
+
+func TestWriteParserForGrammarErrors(t *testing.T) {
+	for _, invalid := range []string{
+		"%skip SPACES\na -> \"NUMBER\"",
+		"%start missing\na -> \"NUMBER\"",
+	} {
+		if _, err := writeParserFor(t, invalid); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestWriteParserForGrammarIsDeterministic(t *testing.T) {
+	grammar, err := os.ReadFile("../../parser/lox.grammar")
+	if err != nil {
+		t.Fatalf("Could not read lox.grammar: %v", err)
+	}
+	first, err := writeParserFor(t, string(grammar))
+	if err != nil {
+		t.Fatalf("WriteParserForGrammar failed: %v", err)
+	}
+	for range 5 {
+		again, _ := writeParserFor(t, string(grammar))
+		if again != first {
+			t.Fatalf("Generating the same grammar twice gave different parsers")
+		}
+	}
+	// The parser is gofmt'd, and its rules are in order
+	if formatted, err := format.Source([]byte(first)); err != nil || string(formatted) != first {
+		t.Errorf("Expected the generated parser to be gofmt'd: %v", err)
+	}
+	if strings.Index(first, "\t\"term\": {") > strings.Index(first, "\t\"999_term_star_1\": {") || strings.Index(first, "\t\"999_term_star_1\": {") > strings.Index(first, "\t\"unary\": {") {
+		t.Errorf("Expected the rules by name, with the artificial ones after their rule")
+	}
+}
+
+func TestWriteParserForGrammarParseTable(t *testing.T) {
+	code, err := writeParserFor(t, `list -> "(" "NUMBER"* ")"
+`)
+	if err != nil {
+		t.Fatalf("WriteParserForGrammar failed: %v", err)
+	}
+	// The empty production of the repetition is under what can follow it, and nowhere else
+	for _, expected := range []string{
+		"var parseTable = map[string]map[dfa.TokenType]int{",
+		"\t\"list\": {\n\t\tdfa.LEFT_PAREN: 0,\n\t},",
+		"\t\"999_list_star_1\": {\n\t\tdfa.NUMBER:      0,\n\t\tdfa.RIGHT_PAREN: 1,\n\t},",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Expected the generated parser to contain %q\n%s", expected, code)
+		}
+	}
+}
+
+func TestWriteParserForGrammarWithUnreachableRule(t *testing.T) {
+	// A rule which cannot be reached follows nothing, it is written all the same
+	grammar := "a -> \"NUMBER\"\nb -> a \"+\""
+	code, err := writeParserFor(t, grammar)
+	if err != nil {
+		t.Fatalf("WriteParserForGrammar failed: %v", err)
+	}
+	if !strings.Contains(code, "\t\"b\": {\n\t\tFollowSet: map[dfa.TokenType]struct{}{},") {
+		t.Errorf("Expected the unreachable rule with an empty FOLLOW set\n%s", code)
+	}
+
+	// The parser builds, and parses its start rule
+	if trees := runGeneratedParser(t, grammar, "1"); trees[0] != "a(1)" {
+		t.Errorf("Unexpected nodes %s", trees[0])
+	}
+}
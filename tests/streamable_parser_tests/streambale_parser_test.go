@@ -2,11 +2,16 @@ package streamable_parser_tests
 
 import (
 	"bufio"
+	"errors"
 	"io"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
 
+	"github.com/VirajAgarwal1/lox/errorhandler"
 	"github.com/VirajAgarwal1/lox/lexer"
+	"github.com/VirajAgarwal1/lox/lexer/dfa"
 	"github.com/VirajAgarwal1/lox/streamable_parser"
 )
 
@@ -259,3 +264,168 @@ func TestParserIntegration(t *testing.T) {
 		})
 	}
 }
+
+func TestParserSkipsWhitespaceAndComments(t *testing.T) {
+	expected := collectEvents("(1+2)*3")
+	got := collectEvents("( 1 +\n 2 ) // three\n* 3")
+
+	if len(got) != len(expected) {
+		t.Fatalf("len mismatch: got %v, expected %v\nEvents: %#v", len(got), len(expected), got)
+	}
+	for i := range got {
+		if got[i].Type != expected[i].Type || got[i].Content != expected[i].Content {
+			t.Errorf("mismatch at %d: got %v, expected %v", i, got[i], expected[i])
+		}
+	}
+}
+
+func TestParserEventsCarryLabels(t *testing.T) {
+	var sp streamable_parser.StreamableParser
+	start := sp.EmitEvent(nil, &streamable_parser.StackElem{Type: streamable_parser.StackElemType_Start, NonTermName: "term", Label: "left"}, nil)
+	if start == nil || start.Type != streamable_parser.EmitElemType_Start || start.Label != "left" {
+		t.Errorf("Expected a start event labelled left, got %#v", start)
+	}
+	leaf := sp.EmitEvent(nil, &streamable_parser.StackElem{Type: streamable_parser.StackElemType_Leaf, Label: "op"}, &lexer.Token{Lexemme: []rune("+")})
+	if leaf == nil || leaf.Content != "+" || leaf.Label != "op" {
+		t.Errorf("Expected a leaf event labelled op, got %#v", leaf)
+	}
+
+	// The Lox grammar has no labels
+	for _, event := range collectEvents("(1+2)*3") {
+		if event.Label != "" {
+			t.Errorf("Expected no labels, got %#v", event)
+		}
+	}
+}
+
+// helper: the value of a left-associative chain like `factor ( ( "/" or "*" ) factor )*`, with its operators applied
+func foldArithmetic(values streamable_parser.ActionValues) any {
+	result := values.At(0).(float64)
+	for i := 1; i+1 < values.Len(); i += 2 {
+		right := values.At(i + 1).(float64)
+		switch string(values.At(i).(*lexer.Token).Lexemme) {
+		case "+":
+			result += right
+		case "-":
+			result -= right
+		case "*":
+			result *= right
+		case "/":
+			result /= right
+		}
+	}
+	return result
+}
+
+func TestBuildValueRunsSemanticActions(t *testing.T) {
+	first := func(values streamable_parser.ActionValues) any { return values.At(0) }
+	streamable_parser.SemanticActions = map[string]map[int]func(values streamable_parser.ActionValues) any{
+		"expression": {0: first},
+		"comma":      {0: first},
+		"equality":   {0: first},
+		"comparison": {0: first},
+		"term":       {0: foldArithmetic},
+		"factor":     {0: foldArithmetic},
+		"unary": {
+			0: func(values streamable_parser.ActionValues) any { return -values.At(1).(float64) },
+			1: first,
+		},
+		"primary": {
+			1: func(values streamable_parser.ActionValues) any {
+				value, _ := strconv.ParseFloat(string(values.At(0).(*lexer.Token).Lexemme), 64)
+				return value
+			},
+			6: func(values streamable_parser.ActionValues) any { return values.At(1) },
+		},
+	}
+	defer func() {
+		streamable_parser.SemanticActions = map[string]map[int]func(values streamable_parser.ActionValues) any{}
+	}()
+
+	for input, expected := range map[string]float64{"(1+2)*3": 9, "10 - 4 / 2": 8, "-(2 * -3)": 6} {
+		scanner := lexer.BufferedLexicalAnalyzer{}
+		scanner.Initialize(bufio.NewReader(strings.NewReader(input)))
+		var sp streamable_parser.StreamableParser
+		sp.Initialize(&scanner)
+		value, err := sp.BuildValue()
+		if err != nil || value != expected {
+			t.Errorf("Expected %q to give %v, got %v %v", input, expected, value, err)
+		}
+	}
+
+	scanner := lexer.BufferedLexicalAnalyzer{}
+	scanner.Initialize(bufio.NewReader(strings.NewReader("(1+")))
+	var sp streamable_parser.StreamableParser
+	sp.Initialize(&scanner)
+	if _, err := sp.BuildValue(); err == nil {
+		t.Errorf("Expected the syntax error to be returned")
+	}
+}
+
+func TestBuildValueWithoutActions(t *testing.T) {
+	scanner := lexer.BufferedLexicalAnalyzer{}
+	scanner.Initialize(bufio.NewReader(strings.NewReader("1")))
+	var sp streamable_parser.StreamableParser
+	sp.Initialize(&scanner)
+	value, err := sp.BuildValue()
+	if err != nil {
+		t.Fatalf("BuildValue failed: %v", err)
+	}
+
+	// expression -> comma -> equality -> comparison -> term -> factor -> unary -> primary -> NUMBER
+	for i := 0; i < 8; i++ {
+		values, is_values := value.(*streamable_parser.ActionValues)
+		if !is_values || values.Len() != 1 {
+			t.Fatalf("Expected the values of a non-terminal without actions, got %#v", value)
+		}
+		value = values.At(0)
+	}
+	if token, is_token := value.(*lexer.Token); !is_token || string(token.Lexemme) != "1" {
+		t.Errorf("Expected the NUMBER token at the bottom, got %#v", value)
+	}
+}
+
+func TestParserEndEventsCarryAlternative(t *testing.T) {
+	alternatives := []int{}
+	for _, event := range collectEvents("(1)") {
+		if event.Type == streamable_parser.EmitElemType_End && event.Content == "primary" {
+			alternatives = append(alternatives, event.Alternative)
+		}
+	}
+	if len(alternatives) != 2 || alternatives[0] != 1 || alternatives[1] != 6 {
+		t.Errorf("Expected the inner primary to match NUMBER and the outer one the brackets, got %v", alternatives)
+	}
+}
+
+func TestParserPredictsFromTheParseTable(t *testing.T) {
+	// The `2` cannot follow the `1`, which is found as soon as the repetitions after it are expanded, not at the end of the input
+	for _, event := range collectEvents("1 2") {
+		if event.Type != streamable_parser.EmitElemType_Error {
+			continue
+		}
+		var parse_err *errorhandler.ParseError
+		if !errors.As(event.Err, &parse_err) {
+			t.Fatalf("Expected a parse error, got %v", event.Err)
+		}
+		if parse_err.Code != errorhandler.CodeNoMatchingProduction || parse_err.Lexemme != "2" || !slices.Contains(parse_err.Expected, dfa.PLUS.Name()) || !slices.Contains(parse_err.Expected, dfa.EOF.Name()) || slices.Contains(parse_err.Expected, dfa.NUMBER.Name()) {
+			t.Errorf("Unexpected error %v, expecting %v", parse_err, parse_err.Expected)
+		}
+		return
+	}
+	t.Errorf("Expected a syntax error")
+}
+
+func TestParserRejectsGrammarNotationTokens(t *testing.T) {
+	// The lexer gives `?` a token of its own, which Lox has no use for
+	for _, event := range collectEvents("1 ? 2") {
+		if event.Type != streamable_parser.EmitElemType_Error {
+			continue
+		}
+		var parse_err *errorhandler.ParseError
+		if !errors.As(event.Err, &parse_err) || parse_err.Found != dfa.QUESTION.Name() {
+			t.Errorf("Expected a syntax error on the `?`, got %v", event.Err)
+		}
+		return
+	}
+	t.Errorf("Expected a syntax error")
+}
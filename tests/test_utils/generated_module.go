@@ -0,0 +1,71 @@
+// Package test_utils has the helpers shared by the test packages
+package test_utils
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// How long a generated parser may take on one input
+const runTimeout = 10 * time.Second
+
+// ModuleRoot gives the directory of the lox module
+func ModuleRoot(t *testing.T) string {
+	t.Helper()
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		t.Fatalf("Could not find the module")
+	}
+	return filepath.Join(filepath.Dir(file), "..", "..")
+}
+
+// RunGeneratedModule writes the files in a module of their own which can import the lox module, builds its `main` package and gives what it prints for each input. A run which does not end within `runTimeout` fails the test, so that a parser stuck on its input cannot hang the tests.
+//
+// The test is skipped when the go command is not there to build the module
+func RunGeneratedModule(t *testing.T, files map[string]string, inputs ...string) []string {
+	t.Helper()
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("The go command is needed to build the generated parser")
+	}
+
+	dir := t.TempDir()
+	files["go.mod"] = "module generated\n\ngo 1.24\n\nrequire github.com/VirajAgarwal1/lox v0.0.0\n\nreplace github.com/VirajAgarwal1/lox => " + ModuleRoot(t) + "\n"
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Could not write the module: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Could not write the module: %v", err)
+		}
+	}
+
+	build := exec.Command("go", "build", "-o", "parse", ".")
+	build.Dir = dir
+	build.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off")
+	if output, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("The generated parser does not build: %v\n%s", err, output)
+	}
+	outputs := []string{}
+	for _, input := range inputs {
+		ctx, cancel := context.WithTimeout(context.Background(), runTimeout)
+		defer cancel()
+		parse := exec.CommandContext(ctx, filepath.Join(dir, "parse"))
+		parse.Stdin = strings.NewReader(input)
+		output, err := parse.Output()
+		if ctx.Err() != nil {
+			t.Fatalf("The generated parser did not end on %q", input)
+		}
+		if err != nil {
+			t.Fatalf("The generated parser failed on %q: %v", input, err)
+		}
+		outputs = append(outputs, string(output))
+	}
+	return outputs
+}